@@ -11,11 +11,19 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
+	_ "time/tzdata" // schedules name IANA time zones; don't depend on the host's zoneinfo
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/gordcurrie/unifi-mcp/internal/adoption"
+	"github.com/gordcurrie/unifi-mcp/internal/dnssync"
+	"github.com/gordcurrie/unifi-mcp/internal/inventory"
+	"github.com/gordcurrie/unifi-mcp/internal/quarantine"
+	"github.com/gordcurrie/unifi-mcp/internal/schedule"
+	"github.com/gordcurrie/unifi-mcp/internal/toggles"
 	"github.com/gordcurrie/unifi-mcp/internal/unifi"
 	"github.com/gordcurrie/unifi-mcp/tools"
 )
@@ -24,6 +32,10 @@ import (
 // Falls back to "dev" when built without ldflags (e.g. go run or go build locally).
 var version = "dev"
 
+// scheduleCheckInterval is how often the schedulers look for due jobs and
+// toggles, and retry those waiting for a guest to connect or after a failure.
+const scheduleCheckInterval = 30 * time.Second
+
 func main() {
 	if err := run(); err != nil {
 		slog.Error("fatal", "err", err)
@@ -50,28 +62,133 @@ func run() error {
 		return fmt.Errorf("unifi client: %w", err)
 	}
 
+	// The client inventory is opt-in: without a file to keep it in, no state
+	// is written and no poller runs.
+	var (
+		inv          *inventory.Store
+		pollInterval time.Duration
+	)
+	if p := os.Getenv("UNIFI_INVENTORY_PATH"); p != "" {
+		if inv, err = inventory.Open(p); err != nil {
+			return fmt.Errorf("client inventory: %w", err)
+		}
+		if pollInterval, err = durationEnv("UNIFI_INVENTORY_POLL_INTERVAL", 5*time.Minute); err != nil {
+			return err
+		}
+	}
+	// Quarantine is opt-in the same way: its records say how to undo each
+	// quarantine, so they must live in a file the operator chose.
+	var quarantined *quarantine.Store
+	if p := os.Getenv("UNIFI_QUARANTINE_PATH"); p != "" {
+		if quarantined, err = quarantine.Open(p); err != nil {
+			return fmt.Errorf("quarantine records: %w", err)
+		}
+	}
+
+	// Scheduled jobs and toggles are opt-in too: without a file, nothing is
+	// scheduled and no scheduler runs.
+	var jobs *schedule.Store
+	if p := os.Getenv("UNIFI_SCHEDULE_PATH"); p != "" {
+		if jobs, err = schedule.Open(p); err != nil {
+			return fmt.Errorf("scheduled jobs: %w", err)
+		}
+	}
+	var toggleRules *toggles.Store
+	if p := os.Getenv("UNIFI_TOGGLE_SCHEDULE_PATH"); p != "" {
+		if toggleRules, err = toggles.Open(p); err != nil {
+			return fmt.Errorf("toggle schedules: %w", err)
+		}
+	}
+
+	// Client DNS sync is opt-in: without a domain suffix there is nothing to
+	// sync into, and no registry file is created.
+	var (
+		dnsSync         *dnssync.Store
+		dnsSyncDomain   string
+		dnsSyncInterval time.Duration
+	)
+	if v := os.Getenv("UNIFI_DNS_SYNC_DOMAIN"); v != "" {
+		if dnsSyncDomain, err = dnssync.ParseDomain(v); err != nil {
+			return fmt.Errorf("UNIFI_DNS_SYNC_DOMAIN: %w", err)
+		}
+		dnsSyncPath, err := stateFilePath("UNIFI_DNS_SYNC_PATH", "dnssync.json")
+		if err != nil {
+			return err
+		}
+		if dnsSync, err = dnssync.Open(dnsSyncPath); err != nil {
+			return fmt.Errorf("dns sync registry: %w", err)
+		}
+		if dnsSyncInterval, err = durationEnv("UNIFI_DNS_SYNC_INTERVAL", 0); err != nil {
+			return err
+		}
+	}
+
+	adoptPolicy, err := adoption.ParsePolicy(os.Getenv("UNIFI_AUTO_ADOPT_MACS"), os.Getenv("UNIFI_AUTO_ADOPT_MODELS"))
+	if err != nil {
+		return fmt.Errorf("UNIFI_AUTO_ADOPT_MACS / UNIFI_AUTO_ADOPT_MODELS: %w", err)
+	}
+	autoAdoptInterval, err := durationEnv("UNIFI_AUTO_ADOPT_INTERVAL", 0)
+	if err != nil {
+		return err
+	}
+
 	s := mcp.NewServer(&mcp.Implementation{
 		Name:    "unifi-mcp",
 		Version: version,
 	}, nil)
 
-	tools.RegisterAll(s, client, allowDestructive)
+	tools.RegisterAll(s, client, tools.Config{
+		AllowDestructive: allowDestructive,
+		Inventory:        inv,
+		Quarantine:       quarantined,
+		Schedule:         jobs,
+		Toggles:          toggleRules,
+		BlocklistDir:     os.Getenv("UNIFI_BLOCKLIST_DIR"),
+		DNSSync:          dnsSync,
+		DNSSyncDomain:    dnsSyncDomain,
+		AdoptPolicy:      adoptPolicy,
+	})
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	if inv != nil && pollInterval > 0 {
+		go inv.Poll(ctx, pollInterval, func(ctx context.Context) ([]inventory.Observation, error) {
+			return tools.ClientObservations(ctx, client, "")
+		}, slog.Default())
+	}
+	if jobs != nil {
+		go jobs.Run(ctx, scheduleCheckInterval, tools.ScheduleExecutor(client), slog.Default())
+	}
+	if toggleRules != nil {
+		go toggleRules.Run(ctx, scheduleCheckInterval, tools.ToggleExecutor(client), slog.Default())
+	}
+	if dnsSync != nil && dnsSyncInterval > 0 {
+		go dnssync.Poll(ctx, dnsSyncInterval, func(ctx context.Context) (dnssync.Report, error) {
+			opts := dnssync.Options{Domain: dnsSyncDomain, Now: time.Now()}
+			return tools.SyncClientDNS(ctx, client, dnsSync, "", opts, true)
+		}, slog.Default())
+	}
+	if !adoptPolicy.IsZero() && autoAdoptInterval > 0 {
+		go adoption.Poll(ctx, autoAdoptInterval, func(ctx context.Context) ([]adoption.Decision, error) {
+			return tools.AutoAdopt(ctx, client, "", adoptPolicy, true)
+		}, slog.Default())
+	}
+
 	switch transport {
 	case "stdio":
 		if err := s.Run(ctx, &mcp.StdioTransport{}); err != nil && !errors.Is(err, context.Canceled) {
 			return fmt.Errorf("stdio transport: %w", err)
 		}
 	case "http":
+		// There is no WriteTimeout: a tool call's response is written as the
+		// tool runs, and rolling_restart or upgrade_devices may run for many
+		// minutes. A cancelled call still ends with its request context.
 		httpServer := &http.Server{
 			Addr:              addr,
 			Handler:           http.MaxBytesHandler(mcp.NewStreamableHTTPHandler(func(_ *http.Request) *mcp.Server { return s }, nil), 4<<20),
 			ReadHeaderTimeout: 10 * time.Second,
 			ReadTimeout:       30 * time.Second,
-			WriteTimeout:      30 * time.Second,
 			IdleTimeout:       120 * time.Second,
 			MaxHeaderBytes:    1 << 20, // 1 MiB
 		}
@@ -93,3 +210,30 @@ func run() error {
 	}
 	return nil
 }
+
+// stateFilePath returns the value of the env var named key, or name inside the
+// per-user config directory (e.g. ~/.config/unifi-mcp/) when it is unset.
+func stateFilePath(key, name string) (string, error) {
+	if p := os.Getenv(key); p != "" {
+		return p, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("%s is unset and no user config directory is available: %w", key, err)
+	}
+	return filepath.Join(dir, "unifi-mcp", name), nil
+}
+
+// durationEnv parses the env var named key as a Go duration, returning def when
+// it is unset. "0" disables the feature the duration controls.
+func durationEnv(key string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%s: invalid duration %q (use e.g. 5m, 1h, or 0 to disable)", key, v)
+	}
+	return d, nil
+}
@@ -2,7 +2,10 @@ module github.com/gordcurrie/unifi-mcp
 
 go 1.26.2
 
-require github.com/modelcontextprotocol/go-sdk v1.4.1
+require (
+	github.com/modelcontextprotocol/go-sdk v1.4.1
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
+)
 
 require (
 	github.com/google/jsonschema-go v0.4.2 // indirect
@@ -0,0 +1,151 @@
+// Package adoption decides which pending devices may be adopted without a
+// person approving each one: those whose MAC address is on an allowlist or
+// whose model matches a pattern. Everything else is treated as a possible
+// rogue device.
+package adoption
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/oui"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+// minModelPrefix is how many literal characters a model pattern must start
+// with. It keeps patterns such as "*" or "u*", which would adopt any UniFi
+// device that shows up, out of the allowlist.
+const minModelPrefix = 3
+
+// Policy is the auto-adopt allowlist.
+type Policy struct {
+	// MACs holds allowlisted addresses in lower-case colon form.
+	MACs map[string]bool
+	// Models holds case-insensitive shell patterns such as "U6-*".
+	Models []string
+}
+
+// ParsePolicy builds a policy from comma-separated MAC addresses (any
+// notation) and model patterns. Each pattern must start with at least
+// minModelPrefix characters before any wildcard.
+func ParsePolicy(macs, models string) (Policy, error) {
+	p := Policy{MACs: map[string]bool{}}
+	for m := range strings.SplitSeq(macs, ",") {
+		if m = strings.TrimSpace(m); m == "" {
+			continue
+		}
+		hw, err := oui.ParseMAC(m)
+		if err != nil {
+			return Policy{}, fmt.Errorf("allowlisted MAC %q: %w", m, err)
+		}
+		p.MACs[hw.String()] = true
+	}
+	for m := range strings.SplitSeq(models, ",") {
+		if m = strings.ToLower(strings.TrimSpace(m)); m == "" {
+			continue
+		}
+		if _, err := path.Match(m, ""); err != nil {
+			return Policy{}, fmt.Errorf("model pattern %q: %w", m, err)
+		}
+		if i := strings.IndexAny(m, "*?["); i >= 0 && i < minModelPrefix {
+			return Policy{}, fmt.Errorf("model pattern %q is too broad: give at least %d characters before any wildcard, e.g. U6-*",
+				m, minModelPrefix)
+		}
+		p.Models = append(p.Models, m)
+	}
+	return p, nil
+}
+
+// IsZero reports whether p allows nothing.
+func (p Policy) IsZero() bool { return len(p.MACs) == 0 && len(p.Models) == 0 }
+
+// Allows reports whether d may be adopted, and why.
+func (p Policy) Allows(d *unifi.PendingDevice) (string, bool) {
+	if hw, err := oui.ParseMAC(d.MAC); err == nil && p.MACs[hw.String()] {
+		return "MAC is allowlisted", true
+	}
+	model := strings.ToLower(d.Model)
+	for _, pat := range p.Models {
+		if ok, _ := path.Match(pat, model); ok && model != "" {
+			return fmt.Sprintf("model matches %q", pat), true
+		}
+	}
+	return "", false
+}
+
+// Decision is what auto-adopt does with one pending device.
+type Decision struct {
+	MAC     string `json:"macAddress"`
+	Model   string `json:"model,omitempty"`
+	IP      string `json:"ipAddress,omitempty"`
+	Adopt   bool   `json:"adopt"`
+	Reason  string `json:"reason"`
+	Adopted bool   `json:"adopted,omitempty"`
+	// DeviceID is the adopted device's ID.
+	DeviceID string `json:"deviceId,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Decide applies p to the pending devices.
+func (p Policy) Decide(pending []unifi.PendingDevice) []Decision {
+	out := make([]Decision, len(pending))
+	for i := range pending {
+		d := &pending[i]
+		out[i] = Decision{MAC: d.MAC, Model: d.Model, IP: d.IP}
+		if reason, ok := p.Allows(d); ok {
+			out[i].Adopt, out[i].Reason = true, reason
+		} else {
+			out[i].Reason = "not allowlisted; possible rogue device"
+		}
+	}
+	return out
+}
+
+// Poll runs adopt immediately and then every interval until ctx is done. It
+// logs each adoption, and each device left pending as a rogue-device alert;
+// a rogue device is reported again only after it has been gone from the
+// pending list. A device that keeps failing to adopt is logged on its 1st,
+// 2nd, 4th, 8th... consecutive failure, so a stuck adoption does not flood
+// the log.
+func Poll(ctx context.Context, interval time.Duration, adopt func(ctx context.Context) ([]Decision, error), logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	alerted := map[string]bool{}
+	failures := map[string]int{}
+	for {
+		decisions, err := adopt(ctx)
+		if err != nil {
+			logger.Warn("auto-adopt", "err", err)
+		} else {
+			seen := map[string]bool{}
+			failing := map[string]int{}
+			for _, d := range decisions {
+				switch {
+				case d.Adopted:
+					logger.Info("auto-adopt: adopted device", "mac", d.MAC, "model", d.Model, "device", d.DeviceID, "reason", d.Reason)
+				case d.Adopt:
+					n := failures[d.MAC] + 1
+					failing[d.MAC] = n
+					if n&(n-1) == 0 {
+						logger.Warn("auto-adopt: adoption failed", "mac", d.MAC, "model", d.Model, "attempts", n, "err", d.Error)
+					}
+				default:
+					seen[d.MAC] = true
+					if !alerted[d.MAC] {
+						logger.Warn("rogue device alert: pending device is not allowlisted", "mac", d.MAC, "model", d.Model, "ip", d.IP)
+					}
+				}
+			}
+			alerted, failures = seen, failing
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
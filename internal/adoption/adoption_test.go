@@ -0,0 +1,103 @@
+package adoption
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy("AA-BB-CC-00-00-01, aabb.cc00.0002,", "U6-*, usw-lite-?-poe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.MACs["aa:bb:cc:00:00:01"] || !p.MACs["aa:bb:cc:00:00:02"] || len(p.Models) != 2 {
+		t.Errorf("policy = %+v", p)
+	}
+	if p, _ := ParsePolicy("", " "); !p.IsZero() {
+		t.Error("empty policy is not zero")
+	}
+	if _, err := ParsePolicy("not-a-mac", ""); err == nil {
+		t.Error("accepted a bad MAC")
+	}
+	if _, err := ParsePolicy("", "U6-["); err == nil {
+		t.Error("accepted a bad pattern")
+	}
+	for _, broad := range []string{"*", "u*", "U6*", "?6-Pro", "[uU]6-*"} {
+		if _, err := ParsePolicy("", broad); err == nil {
+			t.Errorf("accepted the catch-all pattern %q", broad)
+		}
+	}
+	if _, err := ParsePolicy("", "UAP-AC-Pro"); err != nil {
+		t.Errorf("rejected an exact model: %v", err)
+	}
+}
+
+func TestDecide(t *testing.T) {
+	p, err := ParsePolicy("aa:bb:cc:00:00:01", "U6-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending := []unifi.PendingDevice{
+		{MAC: "AA:BB:CC:00:00:01", Model: "USW-Flex"},
+		{MAC: "aa:bb:cc:00:00:02", Model: "U6-Lite"},
+		{MAC: "aa:bb:cc:00:00:03", Model: "UAP-AC-Pro"},
+		{MAC: "aa:bb:cc:00:00:04"},
+	}
+	want := []bool{true, true, false, false}
+	for i, d := range p.Decide(pending) {
+		if d.Adopt != want[i] || d.Reason == "" {
+			t.Errorf("%s: adopt = %v (%s), want %v", d.MAC, d.Adopt, d.Reason, want[i])
+		}
+	}
+}
+
+func TestPoll(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	adopt := func(context.Context) ([]Decision, error) {
+		calls++
+		if calls == 8 {
+			cancel()
+		}
+		if calls == 2 {
+			return nil, errors.New("controller unreachable")
+		}
+		decisions := []Decision{{MAC: "aa:bb:cc:00:00:01", Adopt: true, Error: "adoption rejected"}}
+		if calls != 5 {
+			decisions = append(decisions, Decision{MAC: "aa:bb:cc:00:00:09", Reason: "not allowlisted"})
+		}
+		if calls == 6 {
+			decisions = append(decisions, Decision{MAC: "aa:bb:cc:00:00:02", Adopt: true, Adopted: true, DeviceID: "dev-2"})
+		}
+		return decisions, nil
+	}
+	Poll(ctx, time.Millisecond, adopt, logger)
+
+	log := buf.String()
+	// The failing device fails on calls 1, 3, 4, 5, 6, 7 and 8; a failed
+	// poll does not reset its count, so attempts 1, 2 and 4 are logged.
+	if n := strings.Count(log, "adoption failed"); n != 3 {
+		t.Errorf("logged %d adoption failures, want 3:\n%s", n, log)
+	}
+	// The rogue device is alerted on call 1 and again on call 6, after it
+	// was gone from the pending list on call 5.
+	if n := strings.Count(log, "rogue device alert"); n != 2 {
+		t.Errorf("logged %d rogue alerts, want 2:\n%s", n, log)
+	}
+	if n := strings.Count(log, "adopted device"); n != 1 {
+		t.Errorf("logged %d adoptions, want 1:\n%s", n, log)
+	}
+	if !strings.Contains(log, "controller unreachable") {
+		t.Errorf("poll error not logged:\n%s", log)
+	}
+}
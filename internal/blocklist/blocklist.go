@@ -0,0 +1,270 @@
+// Package blocklist parses IP threat-intel feeds: plain text (one address,
+// CIDR or range per line), CSV, Spamhaus DROP and FireHOL netset files.
+package blocklist
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gordcurrie/unifi-mcp/internal/matchlist"
+)
+
+// Format names a feed format.
+type Format string
+
+// Supported formats. Text, DROP and netset share one line parser: the first
+// field of each line is the entry, and "#", ";" and "//" start comments.
+const (
+	FormatAuto   Format = "auto"
+	FormatText   Format = "text"
+	FormatCSV    Format = "csv"
+	FormatDROP   Format = "drop"
+	FormatNetset Format = "netset"
+)
+
+// Entries broader than these prefix lengths are rejected: a feed line like
+// 0.0.0.0/1 would block half the internet, and is far more likely a mistake
+// than intent.
+const (
+	MinIPv4Bits = 8
+	MinIPv6Bits = 16
+)
+
+// maxSkipped caps how many skipped lines a Result keeps for reporting.
+const maxSkipped = 20
+
+// Skip is a line that did not yield a usable entry.
+type Skip struct {
+	Line   int    `json:"line"`
+	Text   string `json:"text"`
+	Reason string `json:"reason"`
+}
+
+// Result is what a feed yielded.
+type Result struct {
+	Format Format `json:"format"`
+	// Entries are the valid entries in file order, not yet aggregated.
+	Entries []string `json:"-"`
+	Lines   int      `json:"lines"`
+	// Skipped holds the first maxSkipped problems; SkippedCount counts all.
+	Skipped      []Skip `json:"skipped,omitempty"`
+	SkippedCount int    `json:"skippedCount"`
+}
+
+func (r *Result) skip(line int, text, reason string) {
+	r.SkippedCount++
+	if len(r.Skipped) < maxSkipped {
+		r.Skipped = append(r.Skipped, Skip{Line: line, Text: text, Reason: reason})
+	}
+}
+
+// DetectFormat picks a format from a file name: .csv is CSV, .netset and
+// .ipset are netset, anything else is parsed as text (which also reads DROP).
+func DetectFormat(name string) Format {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return FormatCSV
+	case ".netset", ".ipset":
+		return FormatNetset
+	default:
+		return FormatText
+	}
+}
+
+// ParseFormat validates a user-supplied format name; empty means auto.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case "":
+		return FormatAuto, nil
+	case FormatAuto, FormatText, FormatCSV, FormatDROP, FormatNetset:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown format %q (use auto, text, csv, drop or netset)", s)
+	}
+}
+
+// Parse reads a feed. csvColumn selects the CSV column by header name or
+// 1-based index; empty picks the first column holding an address. It is
+// ignored for other formats.
+func Parse(r io.Reader, format Format, csvColumn string) (Result, error) {
+	switch format {
+	case FormatText, FormatDROP, FormatNetset:
+		return parseLines(r, format)
+	case FormatCSV:
+		return parseCSV(r, csvColumn)
+	default:
+		return Result{}, fmt.Errorf("format %q must be resolved before parsing", format)
+	}
+}
+
+func parseLines(r io.Reader, format Format) (Result, error) {
+	res := Result{Format: format}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		res.Lines++
+		line := sc.Text()
+		if i := strings.IndexAny(line, "#;"); i >= 0 {
+			line = line[:i]
+		}
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		res.add(res.Lines, fields[0])
+	}
+	if err := sc.Err(); err != nil {
+		return res, fmt.Errorf("line %d: %w", res.Lines+1, err)
+	}
+	return res, nil
+}
+
+func parseCSV(r io.Reader, column string) (Result, error) {
+	res := Result{Format: FormatCSV}
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.Comment = '#'
+	cr.LazyQuotes = true
+	cr.TrimLeadingSpace = true
+
+	col := -1
+	if n, err := strconv.Atoi(column); err == nil {
+		if n < 1 {
+			return res, fmt.Errorf("csv column %d: columns are numbered from 1", n)
+		}
+		col = n - 1
+	}
+	for first := true; ; first = false {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				res.Lines = perr.Line
+				res.skip(perr.Line, "", perr.Err.Error())
+				continue
+			}
+			return res, err
+		}
+		line, _ := cr.FieldPos(0)
+		res.Lines = line
+		if col < 0 {
+			switch {
+			case column != "" && first:
+				for i, h := range rec {
+					if strings.EqualFold(strings.TrimSpace(h), column) {
+						col = i
+					}
+				}
+				if col < 0 {
+					return res, fmt.Errorf("csv has no column named %q (header: %s)", column, strings.Join(rec, ","))
+				}
+				continue
+			case column == "":
+				for i, f := range rec {
+					if _, err := parseEntry(f); err == nil {
+						col = i
+						break
+					}
+				}
+				if col < 0 {
+					if first {
+						continue // header row
+					}
+					res.skip(line, strings.Join(rec, ","), "no column holds an IP address")
+					continue
+				}
+			}
+		}
+		if col >= len(rec) {
+			res.skip(line, strings.Join(rec, ","), fmt.Sprintf("row has no column %d", col+1))
+			continue
+		}
+		if first && column != "" {
+			if _, err := parseEntry(rec[col]); err != nil {
+				continue // header row of a numbered column
+			}
+		}
+		res.add(line, rec[col])
+	}
+	return res, nil
+}
+
+// add validates entry and records it or the reason it was skipped.
+func (r *Result) add(line int, entry string) {
+	entry = strings.TrimSpace(entry)
+	norm, err := parseEntry(entry)
+	if err != nil {
+		r.skip(line, entry, err.Error())
+		return
+	}
+	r.Entries = append(r.Entries, norm)
+}
+
+// parseEntry checks one address, CIDR or range and rejects overly broad ones.
+func parseEntry(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", errors.New("empty")
+	}
+	if _, err := matchlist.Normalize(matchlist.IP, []string{s}); err != nil {
+		return "", errors.New("not an IP address, CIDR or range")
+	}
+	bits, v4 := breadth(s)
+	minBits := MinIPv6Bits
+	if v4 {
+		minBits = MinIPv4Bits
+	}
+	if bits < minBits {
+		return "", fmt.Errorf("covers more than a /%d", minBits)
+	}
+	return s, nil
+}
+
+// breadth returns the length of the shortest prefix that contains every
+// address s covers, and whether s is IPv4. s must already be valid.
+func breadth(s string) (bits int, v4 bool) {
+	if lo, hi, ok := strings.Cut(s, "-"); ok {
+		a, _ := netip.ParseAddr(strings.TrimSpace(lo))
+		b, _ := netip.ParseAddr(strings.TrimSpace(hi))
+		a, b = a.Unmap(), b.Unmap()
+		as, bs := a.AsSlice(), b.AsSlice()
+		for i := range as {
+			x := as[i] ^ bs[i]
+			if x != 0 {
+				return i*8 + bits8(x), a.Is4()
+			}
+		}
+		return len(as) * 8, a.Is4()
+	}
+	if p, err := netip.ParsePrefix(s); err == nil {
+		if p.Addr().Is4In6() {
+			return p.Bits() - 96, true
+		}
+		return p.Bits(), p.Addr().Is4()
+	}
+	a, _ := netip.ParseAddr(s)
+	return a.Unmap().BitLen(), a.Unmap().Is4()
+}
+
+// bits8 returns the number of leading zero bits in a non-zero byte.
+func bits8(x byte) int {
+	n := 0
+	for x&0x80 == 0 {
+		x <<= 1
+		n++
+	}
+	return n
+}
@@ -0,0 +1,139 @@
+package blocklist
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseLines(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      Format
+		in          string
+		want        []string
+		wantSkipped int
+	}{
+		{
+			name:   "spamhaus drop",
+			format: FormatDROP,
+			in: `; Spamhaus DROP List 2024/01/01 - (c) 2024 The Spamhaus Project
+; Last-Modified: Mon, 01 Jan 2024 00:00:00 GMT
+1.10.16.0/20 ; SBL256894
+1.19.0.0/16 ; SBL434604
+`,
+			want: []string{"1.10.16.0/20", "1.19.0.0/16"},
+		},
+		{
+			name:   "firehol netset",
+			format: FormatNetset,
+			in: `#
+# firehol_level1
+#
+0.0.0.0/8
+1.2.3.4
+5.6.7.0/24
+`,
+			want: []string{"0.0.0.0/8", "1.2.3.4", "5.6.7.0/24"},
+		},
+		{
+			name:   "plain text with junk",
+			format: FormatText,
+			in: `10.0.0.1   first host
+2001:db8::/32 // v6
+10.0.0.5-10.0.0.9
+not-an-ip
+0.0.0.0/0
+10.0.0.0-20.0.0.0
+`,
+			want:        []string{"10.0.0.1", "2001:db8::/32", "10.0.0.5-10.0.0.9"},
+			wantSkipped: 3,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := Parse(strings.NewReader(tc.in), tc.format, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(res.Entries, tc.want) {
+				t.Errorf("Entries = %v, want %v", res.Entries, tc.want)
+			}
+			if res.SkippedCount != tc.wantSkipped {
+				t.Errorf("SkippedCount = %d, want %d (%+v)", res.SkippedCount, tc.wantSkipped, res.Skipped)
+			}
+		})
+	}
+}
+
+func TestParseSkipReasons(t *testing.T) {
+	res, err := Parse(strings.NewReader("1.2.3.4\n0.0.0.0/1\nbogus\n"), FormatText, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Skipped) != 2 {
+		t.Fatalf("Skipped = %+v", res.Skipped)
+	}
+	if s := res.Skipped[0]; s.Line != 2 || !strings.Contains(s.Reason, "more than a /8") {
+		t.Errorf("Skipped[0] = %+v", s)
+	}
+	if s := res.Skipped[1]; s.Line != 3 || s.Text != "bogus" {
+		t.Errorf("Skipped[1] = %+v", s)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	const feed = `# exported feed
+first_seen,ip,reason
+2024-01-01,192.0.2.1,scanner
+2024-01-02,198.51.100.0/24,botnet
+2024-01-03,,missing
+`
+	tests := []struct {
+		name        string
+		column      string
+		want        []string
+		wantSkipped int
+		wantErr     string
+	}{
+		{name: "auto column", want: []string{"192.0.2.1", "198.51.100.0/24"}, wantSkipped: 1},
+		{name: "named column", column: "IP", want: []string{"192.0.2.1", "198.51.100.0/24"}, wantSkipped: 1},
+		{name: "numbered column", column: "2", want: []string{"192.0.2.1", "198.51.100.0/24"}, wantSkipped: 1},
+		{name: "unknown column", column: "addr", wantErr: `no column named "addr"`},
+		{name: "column zero", column: "0", wantErr: "numbered from 1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := Parse(strings.NewReader(feed), FormatCSV, tc.column)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("got %v, want error containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(res.Entries, tc.want) {
+				t.Errorf("Entries = %v, want %v", res.Entries, tc.want)
+			}
+			if res.SkippedCount != tc.wantSkipped {
+				t.Errorf("SkippedCount = %d, want %d (%+v)", res.SkippedCount, tc.wantSkipped, res.Skipped)
+			}
+		})
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	for name, want := range map[string]Format{
+		"drop.txt":              FormatText,
+		"feeds/abuse.CSV":       FormatCSV,
+		"firehol_level1.netset": FormatNetset,
+		"tor.ipset":             FormatNetset,
+		"list":                  FormatText,
+	} {
+		if got := DetectFormat(name); got != want {
+			t.Errorf("DetectFormat(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
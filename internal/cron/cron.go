@@ -0,0 +1,182 @@
+// Package cron parses standard five-field cron expressions and finds the
+// times they fire at in a given time zone.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	expr                          string
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny record a "*" day-of-month or day-of-week field. When
+	// both day fields are restricted, a day matching either fires (as in
+	// Vixie cron); otherwise both must match.
+	domAny, dowAny bool
+}
+
+// macros are the shorthands Parse accepts.
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type field struct {
+	name     string
+	min, max int
+	names    []string // names[i] is value min+i
+}
+
+var fields = [5]field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	// 7 is accepted for Sunday and folded onto 0.
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// Parse parses a five-field expression "minute hour day-of-month month
+// day-of-week", e.g. "0 22 * * *" or "30 8 * * mon-fri". Fields take *,
+// values, ranges a-b, lists a,b and steps */n or a-b/n; months and weekdays
+// may be given by their three-letter English names. The macros @hourly,
+// @daily, @weekly, @monthly and @yearly are accepted too.
+func Parse(expr string) (Schedule, error) {
+	s := Schedule{expr: strings.TrimSpace(expr)}
+	spec := strings.ToLower(s.expr)
+	if m, ok := macros[spec]; ok {
+		spec = m
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != 5 {
+		return Schedule{}, fmt.Errorf("cron expression %q must have 5 fields (minute hour day-of-month month day-of-week)", expr)
+	}
+	dst := [5]*uint64{&s.minute, &s.hour, &s.dom, &s.month, &s.dow}
+	for i, p := range parts {
+		set, err := parseField(p, fields[i])
+		if err != nil {
+			return Schedule{}, fmt.Errorf("cron expression %q: %w", expr, err)
+		}
+		*dst[i] = set
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	s.domAny, s.dowAny = parts[2] == "*", parts[4] == "*"
+	return s, nil
+}
+
+func parseField(s string, f field) (uint64, error) {
+	var set uint64
+	for item := range strings.SplitSeq(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n < 1 || n > f.max {
+				return 0, fmt.Errorf("%s: invalid step %q", f.name, stepStr)
+			}
+			step = n
+		}
+		lo, hi := f.min, f.max
+		if f.max == 7 {
+			hi = 6 // "*" for weekdays means 0-6, not 0-7
+		}
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = value(a, f); err != nil {
+				return 0, err
+			}
+			switch {
+			case isRange:
+				if hi, err = value(b, f); err != nil {
+					return 0, err
+				}
+			case hasStep:
+				hi = f.max
+			default:
+				hi = lo
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("%s: range %q is backwards", f.name, rng)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func value(s string, f field) (int, error) {
+	for i, n := range f.names {
+		if s == n {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s: %q is not a value from %d to %d", f.name, s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// String returns the expression as given to Parse.
+func (s Schedule) String() string { return s.expr }
+
+// searchYears bounds how far ahead Next looks; an expression such as
+// "0 0 30 2 *" never fires.
+const searchYears = 5
+
+// Next returns the first time after t at which s fires, in t's location, or
+// the zero time if it never does. Times are wall-clock times: a time skipped
+// by a daylight-saving change does not fire, and one that occurs twice fires
+// the first time.
+func (s Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+	limit := t.Year() + searchYears
+	for t.Year() <= limit {
+		y, mo, d := t.Date()
+		h := t.Hour()
+		var next time.Time
+		switch {
+		case s.month&(1<<uint(mo)) == 0:
+			next = time.Date(y, mo+1, 1, 0, 0, 0, 0, loc)
+		case !s.dayMatches(t):
+			next = time.Date(y, mo, d+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<uint(h)) == 0:
+			next = time.Date(y, mo, d, h+1, 0, 0, 0, loc)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			next = t.Add(time.Minute)
+		default:
+			return t
+		}
+		if !next.After(t) {
+			// Normalization across a daylight-saving change can land on or
+			// before t; step forward instead.
+			next = t.Add(time.Minute)
+		}
+		t = next
+	}
+	return time.Time{}
+}
+
+func (s Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if !s.domAny && !s.dowAny {
+		return dom || dow
+	}
+	return dom && dow
+}
@@ -0,0 +1,59 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8",
+		"5-1 * * * *", "*/0 * * * *", "* * * foo *", "@reboot",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) succeeded", expr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	toronto, err := time.LoadLocation("America/Toronto")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	at := func(s string) time.Time {
+		t.Helper()
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, toronto)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	tests := []struct {
+		expr, from, want string
+	}{
+		{"0 22 * * *", "2026-03-02 21:59", "2026-03-02 22:00"},
+		{"0 22 * * *", "2026-03-02 22:00", "2026-03-03 22:00"},
+		{"30 8 * * mon-fri", "2026-03-06 09:00", "2026-03-09 08:30"}, // Friday to Monday
+		{"*/15 * * * *", "2026-03-02 10:07", "2026-03-02 10:15"},
+		{"0 0 1 jan,jul *", "2026-03-02 00:00", "2026-07-01 00:00"},
+		{"0 12 13 * fri", "2026-03-02 00:00", "2026-03-06 12:00"}, // either day field matches
+		{"0 9 * * 7", "2026-03-02 00:00", "2026-03-08 09:00"},     // 7 is Sunday
+		{"@weekly", "2026-03-02 00:00", "2026-03-08 00:00"},
+		{"30 2 * * *", "2026-03-08 00:00", "2026-03-09 02:30"}, // 02:30 does not exist on 8 March
+		{"0 0 29 2 *", "2026-03-01 00:00", "2028-02-29 00:00"},
+	}
+	for _, tc := range tests {
+		s, err := Parse(tc.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.expr, err)
+		}
+		if got, want := s.Next(at(tc.from)), at(tc.want); !got.Equal(want) {
+			t.Errorf("%q after %s = %s, want %s", tc.expr, tc.from, got.Format("2006-01-02 15:04 MST"), tc.want)
+		}
+	}
+	never, _ := Parse("0 0 30 2 *")
+	if got := never.Next(at("2026-01-01 00:00")); !got.IsZero() {
+		t.Errorf("30 February fired at %s", got)
+	}
+}
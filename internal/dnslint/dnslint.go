@@ -0,0 +1,413 @@
+// Package dnslint finds mistakes in a site's local DNS policies: names with
+// clashing answers, records that hide public DNS, addresses outside the site's
+// networks, references to disabled records, overlapping wildcards and unusual
+// TTLs.
+package dnslint
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+// Severity ranks a finding.
+type Severity string
+
+// Severities, most serious first.
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+	Info    Severity = "info"
+)
+
+func (s Severity) rank() int {
+	switch s {
+	case Error:
+		return 0
+	case Warning:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Names of the checks, as reported in Finding.Check.
+const (
+	CheckDuplicate          = "duplicate"
+	CheckConflict           = "conflict"
+	CheckShadowsPublic      = "shadows_public"
+	CheckOutsideSubnets     = "outside_subnets"
+	CheckDisabledReferenced = "disabled_referenced"
+	CheckWildcardOverlap    = "wildcard_overlap"
+	CheckSuspiciousTTL      = "suspicious_ttl"
+)
+
+// TTLs outside [ShortTTL, LongTTL] seconds are reported. Zero, the server
+// default, is not.
+const (
+	ShortTTL = 30
+	LongTTL  = 86400
+)
+
+// privateSuffixes are names that never resolve in public DNS (RFC 6761,
+// RFC 6762, RFC 8375 and the suffixes home routers conventionally use).
+var privateSuffixes = []string{
+	"lan", "local", "localdomain", "localhost", "home", "home.arpa", "internal",
+	"intranet", "private", "corp", "test", "example", "invalid",
+	"in-addr.arpa", "ip6.arpa",
+}
+
+// Finding is one problem found.
+type Finding struct {
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Domain   string   `json:"domain"`
+	// PolicyIDs are the policies involved, the one the finding is about first.
+	PolicyIDs []string `json:"policyIds"`
+	Message   string   `json:"message"`
+}
+
+// Options configures Lint.
+type Options struct {
+	// Subnets are the site's IPv4 network subnets. When empty, A records are
+	// not checked against them.
+	Subnets []netip.Prefix
+	// LocalDomains are extra suffixes the site answers for on purpose (e.g.
+	// a split-horizon company domain); records under them do not shadow
+	// public DNS.
+	LocalDomains []string
+}
+
+// policy is a DNS policy with its domain normalized.
+type policy struct {
+	*unifi.DNSPolicy
+	name string
+}
+
+// Lint checks policies and returns the findings, most serious first. Only
+// enabled policies answer queries, so disabled ones are only looked at as
+// the targets of references.
+func Lint(policies []unifi.DNSPolicy, opts Options) []Finding {
+	var enabled, disabled []policy
+	for i := range policies {
+		p := policy{DNSPolicy: &policies[i], name: normalize(policies[i].Domain)}
+		if p.Enabled {
+			enabled = append(enabled, p)
+		} else {
+			disabled = append(disabled, p)
+		}
+	}
+	byName := make(map[string][]policy)
+	for _, p := range enabled {
+		byName[p.name] = append(byName[p.name], p)
+	}
+
+	var out []Finding
+	for _, n := range slices.Sorted(maps.Keys(byName)) {
+		out = append(out, lintName(n, byName[n])...)
+	}
+	local := append(slices.Clone(privateSuffixes), opts.LocalDomains...)
+	for _, p := range enabled {
+		if f, ok := shadowsPublic(p, local); ok {
+			out = append(out, f)
+		}
+		if f, ok := outsideSubnets(p, opts.Subnets); ok {
+			out = append(out, f)
+		}
+		if f, ok := suspiciousTTL(p); ok {
+			out = append(out, f)
+		}
+	}
+	out = append(out, disabledReferenced(enabled, disabled)...)
+	out = append(out, wildcardOverlaps(enabled)...)
+
+	slices.SortStableFunc(out, func(a, b Finding) int {
+		return cmp.Or(
+			cmp.Compare(a.Severity.rank(), b.Severity.rank()),
+			cmp.Compare(a.Domain, b.Domain),
+			cmp.Compare(a.Check, b.Check),
+		)
+	})
+	return out
+}
+
+// lintName reports duplicate and conflicting policies for one name.
+func lintName(name string, ps []policy) []Finding {
+	var out []Finding
+	byValue := make(map[string][]string)
+	var values []string
+	types := make(map[string][]policy)
+	for _, p := range ps {
+		v := p.Type + " " + value(p.DNSPolicy)
+		if _, ok := byValue[v]; !ok {
+			values = append(values, v)
+		}
+		byValue[v] = append(byValue[v], p.ID)
+		types[p.Type] = append(types[p.Type], p)
+	}
+	for _, v := range values {
+		if ids := byValue[v]; len(ids) > 1 {
+			out = append(out, Finding{
+				Check: CheckDuplicate, Severity: Warning, Domain: name, PolicyIDs: ids,
+				Message: fmt.Sprintf("%d policies give the same %s answer; all but one can be deleted", len(ids), typeName(strings.Fields(v)[0])),
+			})
+		}
+	}
+
+	if cnames := types[unifi.DNSPolicyTypeCNAME]; len(cnames) > 0 && len(types) > 1 {
+		ids := ids(cnames)
+		for _, p := range ps {
+			if p.Type != unifi.DNSPolicyTypeCNAME {
+				ids = append(ids, p.ID)
+			}
+		}
+		out = append(out, Finding{
+			Check: CheckConflict, Severity: Error, Domain: name, PolicyIDs: ids,
+			Message: "a CNAME record cannot share its name with other records; which answer clients get is undefined",
+		})
+	}
+	if fwd := types[unifi.DNSPolicyTypeForwardDomain]; len(fwd) > 0 && len(types) > 1 {
+		out = append(out, Finding{
+			Check: CheckConflict, Severity: Warning, Domain: name, PolicyIDs: ids(ps),
+			Message: "the name is both forwarded to another server and answered locally",
+		})
+	}
+	// Several A or AAAA answers can be deliberate round robin; several CNAME
+	// targets or forwarding servers cannot.
+	for _, t := range []string{unifi.DNSPolicyTypeA, unifi.DNSPolicyTypeAAAA, unifi.DNSPolicyTypeCNAME, unifi.DNSPolicyTypeForwardDomain} {
+		distinct := map[string]bool{}
+		for _, p := range types[t] {
+			distinct[value(p.DNSPolicy)] = true
+		}
+		if len(distinct) < 2 {
+			continue
+		}
+		sev, msg := Warning, "answers differ: %s; clients get all of them in turn, which is rarely wanted for a local name"
+		if t == unifi.DNSPolicyTypeCNAME || t == unifi.DNSPolicyTypeForwardDomain {
+			sev, msg = Error, "answers differ: %s; only one can take effect"
+		}
+		out = append(out, Finding{
+			Check: CheckConflict, Severity: sev, Domain: name, PolicyIDs: ids(types[t]),
+			Message: fmt.Sprintf("%d %s policies "+msg, len(types[t]), typeName(t), strings.Join(slices.Sorted(maps.Keys(distinct)), ", ")),
+		})
+	}
+	return out
+}
+
+// shadowsPublic reports a record answering for a name outside every local
+// suffix, which hides whatever public DNS has for it.
+func shadowsPublic(p policy, local []string) (Finding, bool) {
+	name := strings.TrimPrefix(p.name, "*.")
+	for _, s := range local {
+		if inDomain(name, normalize(s)) {
+			return Finding{}, false
+		}
+	}
+	if !strings.Contains(name, ".") && p.Type != unifi.DNSPolicyTypeForwardDomain {
+		// A single label such as "nas" never reaches public DNS.
+		return Finding{}, false
+	}
+	msg := "answers locally for a name in public DNS; clients on this site never see the public record"
+	if p.Type == unifi.DNSPolicyTypeForwardDomain {
+		msg = "sends queries for a public domain to " + p.IPAddress + " instead of the normal resolvers"
+	}
+	return Finding{Check: CheckShadowsPublic, Severity: Warning, Domain: p.name, PolicyIDs: []string{p.ID}, Message: msg}, true
+}
+
+// outsideSubnets reports an A record whose address is in none of subnets.
+// Private addresses there usually belong to a removed or renumbered network;
+// public ones may be deliberate overrides. Loopback and unspecified
+// addresses are sinkholes and are left alone.
+func outsideSubnets(p policy, subnets []netip.Prefix) (Finding, bool) {
+	if p.Type != unifi.DNSPolicyTypeA || len(subnets) == 0 {
+		return Finding{}, false
+	}
+	ip, err := netip.ParseAddr(p.IPv4Address)
+	if err != nil || ip.IsLoopback() || ip.IsUnspecified() {
+		return Finding{}, false
+	}
+	for _, s := range subnets {
+		if s.Contains(ip) {
+			return Finding{}, false
+		}
+	}
+	f := Finding{Check: CheckOutsideSubnets, Severity: Info, Domain: p.name, PolicyIDs: []string{p.ID},
+		Message: ip.String() + " is a public address outside the site's networks"}
+	if ip.IsPrivate() || sharedAddressSpace.Contains(ip) {
+		f.Severity = Warning
+		f.Message = ip.String() + " is in none of the site's network subnets; the network may have been removed or renumbered"
+	}
+	return f, true
+}
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+func suspiciousTTL(p policy) (Finding, bool) {
+	t := p.TTLSeconds
+	f := Finding{Check: CheckSuspiciousTTL, Domain: p.name, PolicyIDs: []string{p.ID}}
+	switch {
+	case t > 0 && t < ShortTTL:
+		f.Severity = Info
+		f.Message = fmt.Sprintf("TTL of %ds makes clients query again almost every time", t)
+	case t > LongTTL:
+		f.Severity = Warning
+		f.Message = fmt.Sprintf("TTL of %ds lets clients keep an old answer for more than a day after a change", t)
+	default:
+		return Finding{}, false
+	}
+	return f, true
+}
+
+// disabledReferenced reports enabled CNAME, MX and SRV records whose target
+// only has disabled records, so the reference no longer resolves locally.
+func disabledReferenced(enabled, disabled []policy) []Finding {
+	if len(disabled) == 0 {
+		return nil
+	}
+	var wildcards []string
+	answered := map[string]bool{}
+	for _, p := range enabled {
+		if s, ok := strings.CutPrefix(p.name, "*."); ok {
+			wildcards = append(wildcards, s)
+		}
+		answered[p.name] = true
+	}
+	off := map[string][]string{}
+	for _, p := range disabled {
+		off[p.name] = append(off[p.name], p.ID)
+	}
+	var out []Finding
+	for _, p := range enabled {
+		var target string
+		switch p.Type {
+		case unifi.DNSPolicyTypeCNAME:
+			target = p.TargetDomain
+		case unifi.DNSPolicyTypeMX:
+			target = p.MailServerDomain
+		case unifi.DNSPolicyTypeSRV:
+			target = p.ServerDomain
+		default:
+			continue
+		}
+		target = normalize(target)
+		if answered[target] || len(off[target]) == 0 || slices.ContainsFunc(wildcards, func(s string) bool { return strings.HasSuffix(target, "."+s) }) {
+			continue
+		}
+		out = append(out, Finding{
+			Check: CheckDisabledReferenced, Severity: Warning, Domain: p.name,
+			PolicyIDs: append([]string{p.ID}, off[target]...),
+			Message:   fmt.Sprintf("%s target %s only has disabled records", typeName(p.Type), target),
+		})
+	}
+	return out
+}
+
+// wildcardOverlaps reports, for each enabled wildcard, the names under it
+// that have records of their own or a narrower wildcard. Those take
+// precedence, which is easy to forget when the wildcard changes.
+func wildcardOverlaps(enabled []policy) []Finding {
+	var out []Finding
+	seen := map[string]bool{}
+	for _, w := range enabled {
+		scope, ok := strings.CutPrefix(w.name, "*.")
+		if !ok || seen[w.name] {
+			continue
+		}
+		seen[w.name] = true
+		ids := []string{}
+		for _, p := range enabled {
+			if p.name == w.name {
+				ids = append(ids, p.ID)
+			}
+		}
+		var names []string
+		under := map[string]bool{}
+		for _, p := range enabled {
+			if p.name != w.name && strings.HasSuffix(p.name, "."+scope) {
+				ids = append(ids, p.ID)
+				if !under[p.name] {
+					under[p.name] = true
+					names = append(names, p.name)
+				}
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		slices.Sort(names)
+		out = append(out, Finding{
+			Check: CheckWildcardOverlap, Severity: Info, Domain: w.name, PolicyIDs: ids,
+			Message: fmt.Sprintf("%d names under the wildcard have their own records and do not get its answer: %s",
+				len(names), summarize(names, 10)),
+		})
+	}
+	return out
+}
+
+// value renders the type-specific answer of p for comparison.
+func value(p *unifi.DNSPolicy) string {
+	switch p.Type {
+	case unifi.DNSPolicyTypeA:
+		return p.IPv4Address
+	case unifi.DNSPolicyTypeAAAA:
+		if ip, err := netip.ParseAddr(p.IPv6Address); err == nil {
+			return ip.String()
+		}
+		return p.IPv6Address
+	case unifi.DNSPolicyTypeCNAME:
+		return normalize(p.TargetDomain)
+	case unifi.DNSPolicyTypeMX:
+		return optInt(p.Priority) + " " + normalize(p.MailServerDomain)
+	case unifi.DNSPolicyTypeTXT:
+		return strconv.Quote(p.Text)
+	case unifi.DNSPolicyTypeSRV:
+		return strings.Join([]string{p.Service, p.Protocol, optInt(p.Priority), optInt(p.Weight), strconv.Itoa(p.Port), normalize(p.ServerDomain)}, " ")
+	case unifi.DNSPolicyTypeForwardDomain:
+		return p.IPAddress
+	}
+	return ""
+}
+
+// typeName turns a policy type such as "CNAME_RECORD" into "CNAME".
+func typeName(t string) string {
+	return strings.TrimSuffix(t, "_RECORD")
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(s), "."))
+}
+
+// inDomain reports whether name is domain or lies under it.
+func inDomain(name, domain string) bool {
+	return domain != "" && (name == domain || strings.HasSuffix(name, "."+domain))
+}
+
+func optInt(p *int) string {
+	if p == nil {
+		return "-"
+	}
+	return strconv.Itoa(*p)
+}
+
+func ids(ps []policy) []string {
+	out := make([]string, len(ps))
+	for i, p := range ps {
+		out[i] = p.ID
+	}
+	return out
+}
+
+// summarize joins up to limit names and counts the rest.
+func summarize(names []string, limit int) string {
+	if len(names) <= limit {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:limit], ", "), len(names)-limit)
+}
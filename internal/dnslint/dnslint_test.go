@@ -0,0 +1,96 @@
+package dnslint
+
+import (
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+func a(id, domain, ip string) unifi.DNSPolicy {
+	return unifi.DNSPolicy{ID: id, Type: unifi.DNSPolicyTypeA, Domain: domain, IPv4Address: ip, Enabled: true}
+}
+
+func cname(id, domain, target string) unifi.DNSPolicy {
+	return unifi.DNSPolicy{ID: id, Type: unifi.DNSPolicyTypeCNAME, Domain: domain, TargetDomain: target, Enabled: true}
+}
+
+func TestLint(t *testing.T) {
+	subnets := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/24")}
+	disabled := a("off", "old.lan", "10.0.0.9")
+	disabled.Enabled = false
+	longTTL := a("ttl", "slow.lan", "10.0.0.8")
+	longTTL.TTLSeconds = 7 * 86400
+
+	for _, tc := range []struct {
+		name     string
+		policies []unifi.DNSPolicy
+		opts     Options
+		// want lists check/severity/domain/ids of each finding in order.
+		want []string
+	}{
+		{
+			name:     "clean",
+			policies: []unifi.DNSPolicy{a("1", "nas.lan", "10.0.0.5"), cname("2", "files.lan", "nas.lan"), a("3", "ads.example.com", "0.0.0.0")},
+			opts:     Options{Subnets: subnets, LocalDomains: []string{"example.com"}},
+		},
+		{
+			name:     "duplicate",
+			policies: []unifi.DNSPolicy{a("1", "nas.lan", "10.0.0.5"), a("2", "NAS.lan.", "10.0.0.5")},
+			want:     []string{"duplicate/warning/nas.lan/1,2"},
+		},
+		{
+			name:     "differing A answers",
+			policies: []unifi.DNSPolicy{a("1", "nas.lan", "10.0.0.5"), a("2", "nas.lan", "10.0.0.6")},
+			want:     []string{"conflict/warning/nas.lan/1,2"},
+		},
+		{
+			name:     "CNAME beside an A record",
+			policies: []unifi.DNSPolicy{a("1", "nas.lan", "10.0.0.5"), cname("2", "nas.lan", "files.lan")},
+			want:     []string{"conflict/error/nas.lan/2,1"},
+		},
+		{
+			name:     "public name",
+			policies: []unifi.DNSPolicy{a("1", "www.google.com", "10.0.0.5"), a("2", "nas", "10.0.0.6")},
+			want:     []string{"shadows_public/warning/www.google.com/1"},
+		},
+		{
+			name:     "outside subnets",
+			policies: []unifi.DNSPolicy{a("1", "old.lan", "192.168.9.1"), a("2", "cloud.lan", "203.0.113.7"), a("3", "nas.lan", "10.0.0.5")},
+			opts:     Options{Subnets: subnets},
+			want:     []string{"outside_subnets/warning/old.lan/1", "outside_subnets/info/cloud.lan/2"},
+		},
+		{
+			name:     "disabled target",
+			policies: []unifi.DNSPolicy{cname("1", "www.lan", "old.lan"), disabled},
+			want:     []string{"disabled_referenced/warning/www.lan/1,off"},
+		},
+		{
+			name:     "disabled target covered by a wildcard",
+			policies: []unifi.DNSPolicy{cname("1", "www.lan", "old.lan"), disabled, a("w", "*.lan", "10.0.0.1")},
+			want:     []string{"wildcard_overlap/info/*.lan/w,1"},
+		},
+		{
+			name:     "wildcard overlap",
+			policies: []unifi.DNSPolicy{a("w", "*.apps.lan", "10.0.0.1"), a("1", "db.apps.lan", "10.0.0.2"), a("2", "apps.lan", "10.0.0.3")},
+			want:     []string{"wildcard_overlap/info/*.apps.lan/w,1"},
+		},
+		{
+			name:     "long TTL",
+			policies: []unifi.DNSPolicy{longTTL},
+			want:     []string{"suspicious_ttl/warning/slow.lan/ttl"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, f := range Lint(tc.policies, tc.opts) {
+				got = append(got, strings.Join([]string{f.Check, string(f.Severity), f.Domain, strings.Join(f.PolicyIDs, ",")}, "/"))
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Package dnsrecords reads and writes local DNS records as /etc/hosts, BIND
+// zone-file and CSV text, and reconciles a set of records against the ones a
+// controller already has.
+package dnsrecords
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Type is a record type.
+type Type string
+
+// Supported record types.
+const (
+	A     Type = "A"
+	AAAA  Type = "AAAA"
+	CNAME Type = "CNAME"
+)
+
+// MaxTTL is the largest TTL a record may carry (RFC 2181 §8).
+const MaxTTL = 1<<31 - 1
+
+// Record is one DNS record. Names are lower case without a trailing dot.
+type Record struct {
+	// ID is the controller's ID for the record; empty for parsed records.
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+	Type Type   `json:"type"`
+	// Value is the address for A and AAAA records and the target name for
+	// CNAME records.
+	Value string `json:"value"`
+	// TTL is in seconds; 0 means unspecified.
+	TTL int `json:"ttl,omitempty"`
+}
+
+// New validates and normalizes a record.
+func New(name string, t Type, value string, ttl int) (Record, error) {
+	n, err := normalizeName(name, true)
+	if err != nil {
+		return Record{}, fmt.Errorf("name %q: %w", name, err)
+	}
+	r := Record{Name: n, Type: t, TTL: ttl}
+	if ttl < 0 || ttl > MaxTTL {
+		return Record{}, fmt.Errorf("%s: TTL %d is out of range", n, ttl)
+	}
+	value = strings.TrimSpace(value)
+	switch t {
+	case A, AAAA:
+		a, err := netip.ParseAddr(value)
+		if err != nil || a.Zone() != "" {
+			return Record{}, fmt.Errorf("%s: %q is not an IP address", n, value)
+		}
+		if t == A && !a.Is4() {
+			return Record{}, fmt.Errorf("%s: A record address %s is not IPv4", n, value)
+		}
+		if t == AAAA && (!a.Is6() || a.Is4In6()) {
+			return Record{}, fmt.Errorf("%s: AAAA record address %s is not IPv6", n, value)
+		}
+		r.Value = a.String()
+	case CNAME:
+		target, err := normalizeName(value, false)
+		if err != nil {
+			return Record{}, fmt.Errorf("%s: CNAME target %q: %w", n, value, err)
+		}
+		if target == n {
+			return Record{}, fmt.Errorf("%s: CNAME points at itself", n)
+		}
+		r.Value = target
+	default:
+		return Record{}, fmt.Errorf("%s: record type %q is not supported (use A, AAAA or CNAME)", n, t)
+	}
+	return r, nil
+}
+
+// ParseType reads a record type name, case-insensitively.
+func ParseType(s string) (Type, error) {
+	switch t := Type(strings.ToUpper(strings.TrimSpace(s))); t {
+	case A, AAAA, CNAME:
+		return t, nil
+	default:
+		return "", fmt.Errorf("record type %q is not supported (use A, AAAA or CNAME)", s)
+	}
+}
+
+// normalizeName lower-cases a domain name, drops a trailing dot and checks
+// its syntax. A leading "*" label is allowed when wildcard is true.
+func normalizeName(s string, wildcard bool) (string, error) {
+	s = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(s), "."))
+	if s == "" {
+		return "", errors.New("empty name")
+	}
+	if len(s) > 253 {
+		return "", errors.New("longer than 253 characters")
+	}
+	labels := strings.Split(s, ".")
+	for i, l := range labels {
+		if l == "*" && i == 0 && wildcard && len(labels) > 1 {
+			continue
+		}
+		if l == "" {
+			return "", errors.New("empty label")
+		}
+		if len(l) > 63 {
+			return "", fmt.Errorf("label %q is longer than 63 characters", l)
+		}
+		if l[0] == '-' || l[len(l)-1] == '-' {
+			return "", fmt.Errorf("label %q starts or ends with a hyphen", l)
+		}
+		for _, c := range l {
+			if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' && c != '_' {
+				return "", fmt.Errorf("label %q contains %q", l, c)
+			}
+		}
+	}
+	return s, nil
+}
+
+// InDomain reports whether name is domain or a name under it. An empty
+// domain contains every name.
+func InDomain(name, domain string) bool {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	return domain == "" || name == domain || strings.HasSuffix(name, "."+domain)
+}
+
+// qualify appends origin to a single-label name, as the hosts and CSV readers
+// do when an origin is given.
+func qualify(name, origin string) string {
+	name = strings.TrimSpace(name)
+	if origin == "" || strings.Contains(strings.TrimSuffix(name, "."), ".") {
+		return name
+	}
+	return strings.TrimSuffix(name, ".") + "." + origin
+}
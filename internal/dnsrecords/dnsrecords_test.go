@@ -0,0 +1,318 @@
+package dnsrecords
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		rname   string
+		typ     Type
+		value   string
+		want    Record
+		wantErr string
+	}{
+		{name: "a", rname: "NAS.Home.", typ: A, value: "192.168.1.10", want: Record{Name: "nas.home", Type: A, Value: "192.168.1.10"}},
+		{name: "aaaa compressed", rname: "nas.home", typ: AAAA, value: "2001:DB8:0::1", want: Record{Name: "nas.home", Type: AAAA, Value: "2001:db8::1"}},
+		{name: "cname", rname: "files.home", typ: CNAME, value: "NAS.home.", want: Record{Name: "files.home", Type: CNAME, Value: "nas.home"}},
+		{name: "wildcard", rname: "*.apps.home", typ: A, value: "10.0.0.1", want: Record{Name: "*.apps.home", Type: A, Value: "10.0.0.1"}},
+		{name: "a with ipv6", rname: "x.home", typ: A, value: "::1", wantErr: "not IPv4"},
+		{name: "aaaa with ipv4", rname: "x.home", typ: AAAA, value: "10.0.0.1", wantErr: "not IPv6"},
+		{name: "bad label", rname: "bad_-.home", typ: A, value: "10.0.0.1", wantErr: "hyphen"},
+		{name: "space in name", rname: "my nas.home", typ: A, value: "10.0.0.1", wantErr: "contains"},
+		{name: "cname to itself", rname: "x.home", typ: CNAME, value: "x.home", wantErr: "itself"},
+		{name: "wildcard cname target", rname: "x.home", typ: CNAME, value: "*.home", wantErr: "contains"},
+		{name: "mx", rname: "x.home", typ: "MX", value: "mail.home", wantErr: "not supported"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := New(tc.rname, tc.typ, tc.value, 0)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("got %v, want error containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseHosts(t *testing.T) {
+	const in = `127.0.0.1	localhost
+::1		localhost ip6-localhost
+ff02::1		ip6-allnodes
+0.0.0.0		ads.example.com
+192.168.1.10	nas nas.home   # storage
+2001:db8::10	nas
+192.168.1.11
+bogus		host
+`
+	res, err := Parse(strings.NewReader(in), FormatHosts, "home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Record{
+		{Name: "nas.home", Type: A, Value: "192.168.1.10"},
+		{Name: "nas.home", Type: A, Value: "192.168.1.10"},
+		{Name: "nas.home", Type: AAAA, Value: "2001:db8::10"},
+	}
+	if !reflect.DeepEqual(res.Records, want) {
+		t.Errorf("Records = %+v, want %+v", res.Records, want)
+	}
+	if len(res.Skipped) != 2 || res.Skipped[0].Line != 7 || res.Skipped[1].Line != 8 {
+		t.Errorf("Skipped = %+v", res.Skipped)
+	}
+}
+
+func TestParseZone(t *testing.T) {
+	const in = `$ORIGIN home.
+$TTL 1h
+@	IN SOA ns1 admin (
+		2024010101 ; serial
+		3600 600 86400 300 )
+	IN NS	ns1
+ns1		IN A	192.168.1.1
+nas	300	IN A	192.168.1.10
+	IN AAAA	2001:db8::10
+files	IN CNAME nas
+web.example.com.	60 IN CNAME nas.home.
+mail	IN MX	10 nas
+$ORIGIN lab.home.
+pi	A	10.0.0.2
+`
+	res, err := Parse(strings.NewReader(in), FormatZone, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Record{
+		{Name: "ns1.home", Type: A, Value: "192.168.1.1", TTL: 3600},
+		{Name: "nas.home", Type: A, Value: "192.168.1.10", TTL: 300},
+		{Name: "nas.home", Type: AAAA, Value: "2001:db8::10", TTL: 3600},
+		{Name: "files.home", Type: CNAME, Value: "nas.home", TTL: 3600},
+		{Name: "web.example.com", Type: CNAME, Value: "nas.home", TTL: 60},
+		{Name: "pi.lab.home", Type: A, Value: "10.0.0.2", TTL: 3600},
+	}
+	if !reflect.DeepEqual(res.Records, want) {
+		t.Errorf("Records =\n%+v\nwant\n%+v", res.Records, want)
+	}
+	if len(res.Skipped) != 1 || res.Skipped[0].Line != 12 || !strings.Contains(res.Skipped[0].Reason, "MX") {
+		t.Errorf("Skipped = %+v", res.Skipped)
+	}
+}
+
+func TestParseZoneRelativeWithoutOrigin(t *testing.T) {
+	res, err := Parse(strings.NewReader("nas IN A 10.0.0.1\n"), FormatZone, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Records) != 0 || len(res.Skipped) != 1 || !strings.Contains(res.Skipped[0].Reason, "no $ORIGIN") {
+		t.Errorf("got %+v", res)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []Record
+		skipped int
+		wantErr string
+	}{
+		{
+			name: "typed",
+			in:   "domain,type,address,ttl\nnas.home,A,192.168.1.10,300\nfiles.home,cname,nas.home,\nx.home,TXT,hello,\n",
+			want: []Record{
+				{Name: "nas.home", Type: A, Value: "192.168.1.10", TTL: 300},
+				{Name: "files.home", Type: CNAME, Value: "nas.home"},
+			},
+			skipped: 1,
+		},
+		{
+			name: "inferred types and origin",
+			in:   "name,value\nnas,192.168.1.10\nnas,2001:db8::10\nfiles,nas\n",
+			want: []Record{
+				{Name: "nas.home", Type: A, Value: "192.168.1.10"},
+				{Name: "nas.home", Type: AAAA, Value: "2001:db8::10"},
+				{Name: "files.home", Type: CNAME, Value: "nas.home"},
+			},
+		},
+		{name: "no value column", in: "name,comment\nnas,x\n", wantErr: "no value column"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := Parse(strings.NewReader(tc.in), FormatCSV, "home")
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("got %v, want error containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(res.Records, tc.want) {
+				t.Errorf("Records = %+v, want %+v", res.Records, tc.want)
+			}
+			if len(res.Skipped) != tc.skipped {
+				t.Errorf("Skipped = %+v, want %d", res.Skipped, tc.skipped)
+			}
+		})
+	}
+}
+
+func TestParseTTL(t *testing.T) {
+	for in, want := range map[string]int{"300": 300, "1h": 3600, "1h30m": 5400, "1W": 604800, "2d1": 172801} {
+		if got, ok := parseTTL(in); !ok || got != want {
+			t.Errorf("parseTTL(%q) = %d, %v; want %d", in, got, ok, want)
+		}
+	}
+	for _, in := range []string{"", "h", "1x", "99999999999"} {
+		if _, ok := parseTTL(in); ok {
+			t.Errorf("parseTTL(%q) succeeded", in)
+		}
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	records := []Record{
+		{Name: "home", Type: A, Value: "192.168.1.1"},
+		{Name: "nas.home", Type: A, Value: "192.168.1.10", TTL: 300},
+		{Name: "nas.home", Type: AAAA, Value: "2001:db8::10"},
+		{Name: "files.home", Type: CNAME, Value: "nas.home", TTL: 60},
+		{Name: "web.example.com", Type: A, Value: "192.168.1.20"},
+	}
+	for _, format := range []Format{FormatZone, FormatCSV} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if _, err := Write(&buf, format, records, "home"); err != nil {
+				t.Fatal(err)
+			}
+			res, err := Parse(&buf, format, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(res.Records, records) {
+				t.Errorf("round trip =\n%+v\nwant\n%+v", res.Records, records)
+			}
+		})
+	}
+
+	t.Run("hosts", func(t *testing.T) {
+		var buf bytes.Buffer
+		omitted, err := Write(&buf, FormatHosts, records, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if omitted != 1 {
+			t.Errorf("omitted = %d, want 1", omitted)
+		}
+		if !strings.Contains(buf.String(), "192.168.1.10 nas.home\n") {
+			t.Errorf("hosts output:\n%s", buf.String())
+		}
+	})
+}
+
+func TestDiff(t *testing.T) {
+	existing := []Record{
+		{ID: "1", Name: "nas.home", Type: A, Value: "192.168.1.10", TTL: 300},
+		{ID: "2", Name: "printer.home", Type: A, Value: "192.168.1.20"},
+		{ID: "3", Name: "old.home", Type: A, Value: "192.168.1.30"},
+		{ID: "4", Name: "files.home", Type: CNAME, Value: "nas.home", TTL: 60},
+		{ID: "5", Name: "keep.home", Type: A, Value: "192.168.1.40"},
+	}
+	desired := []Record{
+		{Name: "nas.home", Type: A, Value: "192.168.1.10"},
+		{Name: "printer.home", Type: A, Value: "192.168.1.21"},
+		{Name: "files.home", Type: CNAME, Value: "nas.home", TTL: 120},
+		{Name: "new.home", Type: AAAA, Value: "2001:db8::1"},
+		{Name: "new.home", Type: AAAA, Value: "2001:db8::1"},
+	}
+	p, err := Diff(desired, existing, func(r Record) bool { return r.Name != "keep.home" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Plan{
+		Create: []Record{{Name: "new.home", Type: AAAA, Value: "2001:db8::1"}},
+		Update: []Update{
+			{From: existing[3], To: Record{ID: "4", Name: "files.home", Type: CNAME, Value: "nas.home", TTL: 120}},
+			{From: existing[1], To: Record{ID: "2", Name: "printer.home", Type: A, Value: "192.168.1.21"}},
+		},
+		Unchanged: []Record{existing[0]},
+		Extra:     []Record{existing[2]},
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("Diff =\n%+v\nwant\n%+v", p, want)
+	}
+}
+
+func TestDiffCNAMEConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		desired  []Record
+		existing []Record
+		delete   bool
+		wantErr  string
+	}{
+		{
+			name: "cname and a",
+			desired: []Record{
+				{Name: "x.home", Type: CNAME, Value: "nas.home"},
+				{Name: "x.home", Type: A, Value: "10.0.0.1"},
+			},
+			wantErr: "cannot share its name",
+		},
+		{
+			name: "two cnames",
+			desired: []Record{
+				{Name: "x.home", Type: CNAME, Value: "nas.home"},
+				{Name: "x.home", Type: CNAME, Value: "web.home"},
+			},
+			wantErr: "two CNAME records",
+		},
+		{
+			name:     "cname over a kept a record",
+			desired:  []Record{{Name: "x.home", Type: CNAME, Value: "nas.home"}},
+			existing: []Record{{ID: "1", Name: "x.home", Type: A, Value: "10.0.0.1"}},
+			wantErr:  "cannot share its name",
+		},
+		{
+			name:     "cname over a deleted a record",
+			desired:  []Record{{Name: "x.home", Type: CNAME, Value: "nas.home"}},
+			existing: []Record{{ID: "1", Name: "x.home", Type: A, Value: "10.0.0.1"}},
+			delete:   true,
+		},
+		{
+			name:    "existing conflicts elsewhere are not ours",
+			desired: []Record{{Name: "y.home", Type: A, Value: "10.0.0.2"}},
+			existing: []Record{
+				{ID: "1", Name: "x.home", Type: A, Value: "10.0.0.1"},
+				{ID: "2", Name: "x.home", Type: CNAME, Value: "nas.home"},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var deletable func(Record) bool
+			if tc.delete {
+				deletable = func(Record) bool { return true }
+			}
+			_, err := Diff(tc.desired, tc.existing, deletable)
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("got %v, want no error", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("got %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,450 @@
+package dnsrecords
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// Format names a record file format.
+type Format string
+
+// Supported formats.
+const (
+	FormatHosts Format = "hosts"
+	FormatZone  Format = "zone"
+	FormatCSV   Format = "csv"
+)
+
+// ParseFormat validates a user-supplied format name.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case FormatHosts, FormatZone, FormatCSV:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown format %q (use hosts, zone or csv)", s)
+	}
+}
+
+// Skip is a line that did not yield a record.
+type Skip struct {
+	Line   int    `json:"line"`
+	Text   string `json:"text"`
+	Reason string `json:"reason"`
+}
+
+// Result is what a file yielded.
+type Result struct {
+	Records []Record `json:"records"`
+	Lines   int      `json:"lines"`
+	Skipped []Skip   `json:"skipped,omitempty"`
+}
+
+func (r *Result) skip(line int, text, reason string) {
+	r.Skipped = append(r.Skipped, Skip{Line: line, Text: strings.TrimSpace(text), Reason: reason})
+}
+
+// Parse reads records in the given format. origin (a domain name) qualifies
+// relative names: in zone files it is the initial $ORIGIN, and in hosts and
+// CSV files it is appended to single-label names such as "nas". Records of
+// other types and lines that cannot be read are returned in Skipped rather
+// than failing the whole file.
+func Parse(r io.Reader, format Format, origin string) (Result, error) {
+	if origin != "" {
+		o, err := normalizeName(origin, false)
+		if err != nil {
+			return Result{}, fmt.Errorf("origin %q: %w", origin, err)
+		}
+		origin = o
+	}
+	switch format {
+	case FormatHosts:
+		return parseHosts(r, origin)
+	case FormatZone:
+		return parseZone(r, origin)
+	case FormatCSV:
+		return parseCSV(r, origin)
+	default:
+		return Result{}, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// parseHosts reads "address name [alias...]" lines. Loopback, unspecified
+// and multicast addresses (localhost, ip6-allnodes, 0.0.0.0 sinkholes) are
+// skipped.
+func parseHosts(r io.Reader, origin string) (Result, error) {
+	var res Result
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		res.Lines++
+		text := sc.Text()
+		line, _, _ := strings.Cut(text, "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		addr, err := netip.ParseAddr(fields[0])
+		if err != nil {
+			res.skip(res.Lines, text, fmt.Sprintf("%q is not an IP address", fields[0]))
+			continue
+		}
+		addr = addr.Unmap()
+		if addr.IsLoopback() || addr.IsUnspecified() || addr.IsMulticast() {
+			continue
+		}
+		if len(fields) == 1 {
+			res.skip(res.Lines, text, "no host names")
+			continue
+		}
+		t := A
+		if addr.Is6() {
+			t = AAAA
+		}
+		for _, name := range fields[1:] {
+			rec, err := New(qualify(name, origin), t, addr.String(), 0)
+			if err != nil {
+				res.skip(res.Lines, text, err.Error())
+				continue
+			}
+			res.Records = append(res.Records, rec)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return res, fmt.Errorf("line %d: %w", res.Lines+1, err)
+	}
+	return res, nil
+}
+
+// parseZone reads a BIND master file: $ORIGIN and $TTL directives, "@",
+// relative and inherited owner names, optional TTL and class fields, and
+// parenthesized multi-line records. SOA and NS records are ignored; other
+// types than A, AAAA and CNAME are skipped.
+func parseZone(r io.Reader, origin string) (Result, error) {
+	var (
+		res        Result
+		defaultTTL int
+		owner      string
+		pending    []string // fields of a record still inside parentheses
+		start      int      // line the pending record started on
+		startText  string
+		inherited  bool
+	)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		res.Lines++
+		text := sc.Text()
+		line := stripZoneComment(text)
+		if pending == nil {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			start, startText = res.Lines, text
+			inherited = line[0] == ' ' || line[0] == '\t'
+		}
+		pending = append(pending, strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(line))...)
+		if depth := parenDepth(pending); depth > 0 {
+			continue
+		} else if depth < 0 {
+			res.skip(start, startText, "unbalanced parentheses")
+			pending = nil
+			continue
+		}
+		fields := make([]string, 0, len(pending))
+		for _, f := range pending {
+			if f != "(" && f != ")" {
+				fields = append(fields, f)
+			}
+		}
+		pending = nil
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch strings.ToUpper(fields[0]) {
+		case "$ORIGIN":
+			if len(fields) < 2 {
+				res.skip(start, startText, "$ORIGIN needs a name")
+				continue
+			}
+			o, err := absName(fields[1], origin)
+			if err != nil {
+				res.skip(start, startText, err.Error())
+				continue
+			}
+			origin = o
+			continue
+		case "$TTL":
+			ttl, ok := parseTTL(fieldAt(fields, 1))
+			if !ok {
+				res.skip(start, startText, "$TTL needs a TTL")
+				continue
+			}
+			defaultTTL = ttl
+			continue
+		case "$INCLUDE", "$GENERATE":
+			res.skip(start, startText, fields[0]+" is not supported")
+			continue
+		}
+
+		if !inherited {
+			o, err := absName(fields[0], origin)
+			if err != nil {
+				res.skip(start, startText, err.Error())
+				owner = ""
+				continue
+			}
+			owner, fields = o, fields[1:]
+		}
+		if owner == "" {
+			res.skip(start, startText, "no owner name")
+			continue
+		}
+		ttl := defaultTTL
+		for len(fields) > 0 {
+			if isClass(fields[0]) {
+				fields = fields[1:]
+			} else if t, ok := parseTTL(fields[0]); ok {
+				ttl, fields = t, fields[1:]
+			} else {
+				break
+			}
+		}
+		if len(fields) == 0 {
+			res.skip(start, startText, "no record type")
+			continue
+		}
+		typ := strings.ToUpper(fields[0])
+		switch typ {
+		case "SOA", "NS":
+			continue
+		case string(A), string(AAAA), string(CNAME):
+		default:
+			res.skip(start, startText, fmt.Sprintf("record type %s is not supported", typ))
+			continue
+		}
+		if len(fields) != 2 {
+			res.skip(start, startText, fmt.Sprintf("%s record needs exactly one value", typ))
+			continue
+		}
+		value := fields[1]
+		if Type(typ) == CNAME {
+			v, err := absName(value, origin)
+			if err != nil {
+				res.skip(start, startText, err.Error())
+				continue
+			}
+			value = v
+		}
+		rec, err := New(owner, Type(typ), value, ttl)
+		if err != nil {
+			res.skip(start, startText, err.Error())
+			continue
+		}
+		res.Records = append(res.Records, rec)
+	}
+	if err := sc.Err(); err != nil {
+		return res, fmt.Errorf("line %d: %w", res.Lines+1, err)
+	}
+	if pending != nil {
+		res.skip(start, startText, "unterminated parentheses")
+	}
+	return res, nil
+}
+
+// stripZoneComment drops a ";" comment, ignoring semicolons in quoted strings.
+func stripZoneComment(line string) string {
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			quoted = !quoted
+		case ';':
+			if !quoted {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+func parenDepth(fields []string) int {
+	depth := 0
+	for _, f := range fields {
+		switch f {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+	}
+	return depth
+}
+
+func fieldAt(fields []string, i int) string {
+	if i < len(fields) {
+		return fields[i]
+	}
+	return ""
+}
+
+// absName resolves a zone-file name against origin: "@" is the origin, names
+// ending in "." are absolute and anything else is relative.
+func absName(name, origin string) (string, error) {
+	switch {
+	case name == "@":
+		if origin == "" {
+			return "", errors.New(`"@" used with no $ORIGIN`)
+		}
+		return origin, nil
+	case strings.HasSuffix(name, "."):
+		return strings.ToLower(strings.TrimSuffix(name, ".")), nil
+	case origin == "":
+		return "", fmt.Errorf("relative name %q with no $ORIGIN", name)
+	default:
+		return strings.ToLower(name) + "." + origin, nil
+	}
+}
+
+func isClass(s string) bool {
+	switch strings.ToUpper(s) {
+	case "IN", "CH", "HS", "CS":
+		return true
+	}
+	return false
+}
+
+// parseTTL reads a TTL in seconds or BIND's unit form, e.g. "1h30m".
+func parseTTL(s string) (int, bool) {
+	if s == "" || s[0] < '0' || s[0] > '9' {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, n <= MaxTTL
+	}
+	total, n := 0, -1
+	for _, c := range strings.ToLower(s) {
+		if c >= '0' && c <= '9' {
+			if n < 0 {
+				n = 0
+			}
+			n = n*10 + int(c-'0')
+			if n > MaxTTL {
+				return 0, false
+			}
+			continue
+		}
+		unit := map[rune]int{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 604800}[c]
+		if unit == 0 || n < 0 {
+			return 0, false
+		}
+		total += n * unit
+		n = -1
+		if total > MaxTTL {
+			return 0, false
+		}
+	}
+	if n >= 0 {
+		total += n
+	}
+	return total, total <= MaxTTL
+}
+
+// csvColumns maps accepted CSV header names to the column they fill.
+var csvColumns = map[string]string{
+	"name": "name", "domain": "name", "hostname": "name", "host": "name",
+	"type":  "type",
+	"value": "value", "address": "value", "ip": "value", "ip_address": "value", "target": "value",
+	"ttl": "ttl", "ttl_seconds": "ttl",
+}
+
+// parseCSV reads a CSV file whose header names the name, value and
+// optionally type and ttl columns. Without a type column, the type follows
+// from the value: IPv4 is A, IPv6 is AAAA and anything else is CNAME.
+func parseCSV(r io.Reader, origin string) (Result, error) {
+	var res Result
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.Comment = '#'
+	cr.TrimLeadingSpace = true
+
+	cols := map[string]int{}
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				res.Lines = perr.Line
+				res.skip(perr.Line, "", perr.Err.Error())
+				continue
+			}
+			return res, err
+		}
+		line, _ := cr.FieldPos(0)
+		res.Lines = line
+		if len(cols) == 0 {
+			for i, h := range row {
+				if c, ok := csvColumns[strings.ToLower(strings.TrimSpace(h))]; ok {
+					if _, dup := cols[c]; !dup {
+						cols[c] = i
+					}
+				}
+			}
+			if _, ok := cols["name"]; !ok {
+				return res, fmt.Errorf("csv header %q has no name column (name, domain or hostname)", strings.Join(row, ","))
+			}
+			if _, ok := cols["value"]; !ok {
+				return res, fmt.Errorf("csv header %q has no value column (value, address, ip or target)", strings.Join(row, ","))
+			}
+			continue
+		}
+		get := func(col string) string {
+			if i, ok := cols[col]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		text := strings.Join(row, ",")
+		value := get("value")
+		var t Type
+		if s := get("type"); s != "" {
+			if t, err = ParseType(s); err != nil {
+				res.skip(line, text, err.Error())
+				continue
+			}
+		} else if a, err := netip.ParseAddr(value); err == nil && a.Is4() {
+			t = A
+		} else if err == nil {
+			t = AAAA
+		} else {
+			t = CNAME
+		}
+		ttl := 0
+		if s := get("ttl"); s != "" {
+			var ok bool
+			if ttl, ok = parseTTL(s); !ok {
+				res.skip(line, text, fmt.Sprintf("TTL %q is not valid", s))
+				continue
+			}
+		}
+		if t == CNAME {
+			value = qualify(value, origin)
+		}
+		rec, err := New(qualify(get("name"), origin), t, value, ttl)
+		if err != nil {
+			res.skip(line, text, err.Error())
+			continue
+		}
+		res.Records = append(res.Records, rec)
+	}
+	return res, nil
+}
@@ -0,0 +1,129 @@
+package dnsrecords
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Update is a change to an existing record.
+type Update struct {
+	From Record `json:"from"`
+	To   Record `json:"to"`
+}
+
+// Plan is what it takes to turn the existing records into the desired ones.
+type Plan struct {
+	Create    []Record `json:"create"`
+	Update    []Update `json:"update"`
+	Unchanged []Record `json:"unchanged"`
+	// Extra are existing records that are not desired and are deleted by
+	// the plan.
+	Extra []Record `json:"extra"`
+}
+
+// Diff plans the changes that make existing match desired.
+//
+// Records are matched on name, type and value first, so a name may carry
+// several A or AAAA records. A desired record with no exact match takes over
+// an unmatched existing record of the same name and type, which becomes an
+// update of its value. TTLs are compared only when the desired record gives
+// one; an update keeps the existing TTL otherwise. Updates keep the existing
+// record's ID. Existing records left unmatched become Extra when deletable
+// reports true for them and are kept otherwise; a nil deletable keeps them all.
+//
+// Diff refuses plans whose result a resolver could not serve: a CNAME next to
+// any other record of the same name, or two CNAMEs for one name. The desired
+// records are checked together with the existing records the plan keeps, so a
+// name cannot become a CNAME while its old records stay.
+func Diff(desired, existing []Record, deletable func(Record) bool) (Plan, error) {
+	used := make([]bool, len(existing))
+	match := func(want Record, exact bool) int {
+		for i, e := range existing {
+			if !used[i] && e.Name == want.Name && e.Type == want.Type && (!exact || e.Value == want.Value) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	p := Plan{Create: []Record{}, Update: []Update{}, Unchanged: []Record{}, Extra: []Record{}}
+	seen := map[Record]bool{}
+	var rest []Record
+	for _, d := range desired {
+		k := Record{Name: d.Name, Type: d.Type, Value: d.Value}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		i := match(d, true)
+		if i < 0 {
+			rest = append(rest, d)
+			continue
+		}
+		used[i] = true
+		e := existing[i]
+		if d.TTL != 0 && d.TTL != e.TTL {
+			d.ID = e.ID
+			p.Update = append(p.Update, Update{From: e, To: d})
+		} else {
+			p.Unchanged = append(p.Unchanged, e)
+		}
+	}
+	for _, d := range rest {
+		i := match(d, false)
+		if i < 0 {
+			p.Create = append(p.Create, d)
+			continue
+		}
+		used[i] = true
+		e := existing[i]
+		d.ID = e.ID
+		if d.TTL == 0 {
+			d.TTL = e.TTL
+		}
+		p.Update = append(p.Update, Update{From: e, To: d})
+	}
+	result := slices.Clone(desired)
+	for i, e := range existing {
+		switch {
+		case used[i]:
+		case deletable != nil && deletable(e):
+			p.Extra = append(p.Extra, e)
+		default:
+			result = append(result, e)
+		}
+	}
+	if err := checkCNAMEs(result, desired); err != nil {
+		return Plan{}, err
+	}
+	return p, nil
+}
+
+// checkCNAMEs reports names of the desired records that have a CNAME
+// alongside other records in records.
+func checkCNAMEs(records, desired []Record) error {
+	names := map[string]bool{}
+	for _, r := range desired {
+		names[r.Name] = true
+	}
+	records = slices.DeleteFunc(slices.Clone(records), func(r Record) bool { return !names[r.Name] })
+	cnames := map[string]string{}
+	for _, r := range records {
+		if r.Type == CNAME {
+			if prev, ok := cnames[r.Name]; ok && prev != r.Value {
+				return fmt.Errorf("%s has two CNAME records (%s and %s)", r.Name, prev, r.Value)
+			}
+			cnames[r.Name] = r.Value
+		}
+	}
+	var errs []error
+	reported := map[string]bool{}
+	for _, r := range records {
+		if _, ok := cnames[r.Name]; ok && r.Type != CNAME && !reported[r.Name] {
+			reported[r.Name] = true
+			errs = append(errs, fmt.Errorf("%s has a CNAME record and a %s record; a CNAME cannot share its name", r.Name, r.Type))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,114 @@
+package dnsrecords
+
+import (
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Sort orders records by name, type and value.
+func Sort(records []Record) {
+	slices.SortFunc(records, func(a, b Record) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Type, b.Type), cmp.Compare(a.Value, b.Value))
+	})
+}
+
+// Write renders records in the given format, in the order given. In zone
+// files, names under origin are written relative to it. Hosts files cannot
+// hold CNAME or wildcard records; those are left out and counted in omitted.
+func Write(w io.Writer, format Format, records []Record, origin string) (omitted int, err error) {
+	origin = strings.ToLower(strings.TrimSuffix(origin, "."))
+	switch format {
+	case FormatHosts:
+		return writeHosts(w, records)
+	case FormatZone:
+		return 0, writeZone(w, records, origin)
+	case FormatCSV:
+		return 0, writeCSV(w, records)
+	default:
+		return 0, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// writeHosts writes one line per address, listing its names in order.
+func writeHosts(w io.Writer, records []Record) (int, error) {
+	var (
+		order   []string
+		names   = map[string][]string{}
+		omitted int
+	)
+	for _, r := range records {
+		if r.Type == CNAME || strings.HasPrefix(r.Name, "*.") {
+			omitted++
+			continue
+		}
+		if _, ok := names[r.Value]; !ok {
+			order = append(order, r.Value)
+		}
+		names[r.Value] = append(names[r.Value], r.Name)
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	for _, addr := range order {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\n", addr, strings.Join(names[addr], " ")); err != nil {
+			return omitted, err
+		}
+	}
+	return omitted, tw.Flush()
+}
+
+func writeZone(w io.Writer, records []Record, origin string) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	if origin != "" {
+		if _, err := fmt.Fprintf(tw, "$ORIGIN %s.\n", origin); err != nil {
+			return err
+		}
+	}
+	for _, r := range records {
+		ttl := ""
+		if r.TTL > 0 {
+			ttl = strconv.Itoa(r.TTL)
+		}
+		value := r.Value
+		if r.Type == CNAME {
+			value += "."
+		}
+		if _, err := fmt.Fprintf(tw, "%s\t%s\tIN\t%s\t%s\n", relName(r.Name, origin), ttl, r.Type, value); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// relName writes name relative to origin when it is under it, and absolute
+// (with a trailing dot) otherwise.
+func relName(name, origin string) string {
+	switch {
+	case origin == "":
+		return name + "."
+	case name == origin:
+		return "@"
+	case strings.HasSuffix(name, "."+origin):
+		return strings.TrimSuffix(name, "."+origin)
+	default:
+		return name + "."
+	}
+}
+
+func writeCSV(w io.Writer, records []Record) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"name", "type", "value", "ttl"})
+	for _, r := range records {
+		ttl := ""
+		if r.TTL > 0 {
+			ttl = strconv.Itoa(r.TTL)
+		}
+		_ = cw.Write([]string{r.Name, string(r.Type), r.Value, ttl})
+	}
+	cw.Flush()
+	return cw.Error()
+}
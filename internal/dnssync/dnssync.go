@@ -0,0 +1,129 @@
+// Package dnssync keeps local DNS A records in step with the names of the
+// clients on the network. Records it creates are listed in a file-backed
+// ownership registry; records missing from the registry were made by someone
+// else and are never changed or deleted.
+package dnssync
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/statefile"
+)
+
+// DefaultRemoveAfter is how long a record outlives its client's last sighting
+// before it is deleted. Clients come and go (phones sleep, laptops travel), so
+// records are not removed the moment a client disconnects.
+const DefaultRemoveAfter = 7 * 24 * time.Hour
+
+// Record is one DNS policy owned by the sync.
+type Record struct {
+	PolicyID string `json:"policyId"`
+	// SiteID is the effective site ID. Records saved before it was resolved
+	// have it empty for the default site.
+	SiteID     string    `json:"siteId,omitempty"`
+	MAC        string    `json:"macAddress"`
+	ClientName string    `json:"clientName,omitempty"`
+	Domain     string    `json:"domain"`
+	IP         string    `json:"ipv4Address"`
+	LastSeen   time.Time `json:"lastSeen"`
+}
+
+// Store is the ownership registry, keyed by policy ID. All methods are safe
+// for concurrent use; every mutation is written to disk before it returns.
+type Store struct {
+	path string
+	// run serializes whole syncs; see Lock.
+	run sync.Mutex
+
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+// Open loads the registry at path, or starts an empty one if the file does
+// not exist yet.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, records: make(map[string]Record)}
+	if _, err := statefile.Load(path, &s.records); err != nil {
+		return nil, fmt.Errorf("open dns sync registry: %w", err)
+	}
+	if s.records == nil {
+		s.records = make(map[string]Record)
+	}
+	return s, nil
+}
+
+// Lock reserves the registry for one sync, from planning against it to
+// saving the result, so the background sync and sync_client_dns never act on
+// the same records at once. List and Update stay usable while it is held.
+func (s *Store) Lock() { s.run.Lock() }
+
+// Unlock releases the registry reserved by Lock.
+func (s *Store) Unlock() { s.run.Unlock() }
+
+// List returns the records for siteID, sorted by domain.
+func (s *Store) List(siteID string) []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Record
+	for _, r := range s.records {
+		if r.SiteID == siteID {
+			out = append(out, r)
+		}
+	}
+	slices.SortFunc(out, func(a, b Record) int {
+		return cmp.Or(cmp.Compare(a.Domain, b.Domain), cmp.Compare(a.PolicyID, b.PolicyID))
+	})
+	return out
+}
+
+// Update adds or replaces put and drops the records with the remove policy
+// IDs, then saves once.
+func (s *Store) Update(put []Record, remove []string) error {
+	if len(put) == 0 && len(remove) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev := maps.Clone(s.records)
+	for _, r := range put {
+		s.records[r.PolicyID] = r
+	}
+	for _, id := range remove {
+		delete(s.records, id)
+	}
+	if err := statefile.Save(s.path, s.records); err != nil {
+		s.records = prev
+		return fmt.Errorf("save dns sync registry: %w", err)
+	}
+	return nil
+}
+
+// Poll runs sync immediately and then every interval until ctx is done,
+// logging what each run changed.
+func Poll(ctx context.Context, interval time.Duration, sync func(ctx context.Context) (Report, error), logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		rep, err := sync(ctx)
+		switch {
+		case err != nil:
+			logger.Warn("dns sync", "err", err)
+		case len(rep.Errors) > 0:
+			logger.Warn("dns sync", "created", len(rep.Create), "updated", len(rep.Update), "deleted", len(rep.Delete), "errors", rep.Errors)
+		case len(rep.Create)+len(rep.Update)+len(rep.Delete) > 0:
+			logger.Info("dns sync", "created", len(rep.Create), "updated", len(rep.Update), "deleted", len(rep.Delete))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
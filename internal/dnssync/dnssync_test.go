@@ -0,0 +1,150 @@
+package dnssync
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+func TestHostname(t *testing.T) {
+	for in, want := range map[string]string{
+		"Gord's iPhone (2)":     "gords-iphone-2",
+		"  Living Room TV  ":    "living-room-tv",
+		"printer_01.office":     "printer-01-office",
+		"---":                   "",
+		"日本":                    "",
+		strings.Repeat("a", 70): strings.Repeat("a", 63),
+	} {
+		if got := Hostname(in); got != want {
+			t.Errorf("Hostname(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func a(id, domain, ip string) unifi.DNSPolicy {
+	return unifi.DNSPolicy{ID: id, Type: unifi.DNSPolicyTypeA, Domain: domain, IPv4Address: ip, Enabled: true}
+}
+
+func TestCompute(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	opts := Options{Domain: "lan.", Now: now}
+	clients := []Client{
+		{MAC: "aa:00:00:00:00:01", Name: "NAS", IP: "10.0.0.10"},                 // owned, unchanged
+		{MAC: "aa:00:00:00:00:02", Name: "Printer", IP: "10.0.0.21"},             // owned, new IP
+		{MAC: "aa:00:00:00:00:03", Name: "Laptop", IP: "10.0.0.30"},              // new
+		{MAC: "aa:00:00:00:00:04", Name: "Router", IP: "10.0.0.1"},               // manual record holds the name
+		{MAC: "aa:00:00:00:00:05", Name: "Phone", IP: "10.0.0.50"},               // two phones
+		{MAC: "aa:00:00:00:00:06", Name: "phone", IP: "10.0.0.51"},               //
+		{MAC: "aa:00:00:00:00:07", Name: "Camera", IP: "fd00::7"},                // no IPv4
+		{MAC: "AA-00-00-00-00-01", Name: "NAS duplicate listing", IP: "1.1.1.1"}, // same MAC again: ignored
+	}
+	policies := []unifi.DNSPolicy{
+		a("p-nas", "nas.lan", "10.0.0.10"),
+		a("p-printer", "printer.lan", "10.0.0.20"),
+		a("p-router", "router.lan", "10.0.0.1"),
+		a("p-old", "old.lan", "10.0.0.99"),
+		a("p-recent", "tablet.lan", "10.0.0.98"),
+	}
+	owned := []Record{
+		{PolicyID: "p-nas", MAC: "aa:00:00:00:00:01", Domain: "nas.lan", IP: "10.0.0.10", LastSeen: now.Add(-time.Hour)},
+		{PolicyID: "p-printer", MAC: "aa:00:00:00:00:02", Domain: "printer.lan", IP: "10.0.0.20", LastSeen: now.Add(-time.Hour)},
+		{PolicyID: "p-old", MAC: "aa:00:00:00:00:08", Domain: "old.lan", IP: "10.0.0.99", LastSeen: now.Add(-8 * 24 * time.Hour)},
+		{PolicyID: "p-recent", MAC: "aa:00:00:00:00:09", Domain: "tablet.lan", IP: "10.0.0.98", LastSeen: now.Add(-24 * time.Hour)},
+		{PolicyID: "p-gone", MAC: "aa:00:00:00:00:0a", Domain: "gone.lan", IP: "10.0.0.97", LastSeen: now},
+	}
+	p := Compute(clients, policies, owned, opts)
+
+	domains := func(cs []Change) string {
+		var out []string
+		for _, c := range cs {
+			out = append(out, c.Domain)
+		}
+		return strings.Join(out, " ")
+	}
+	for _, tc := range []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"create", domains(p.Create), "laptop.lan phone-0005.lan phone-0006.lan"},
+		{"update", domains(p.Update), "printer.lan"},
+		{"unchanged", domains(p.Unchanged), "nas.lan"},
+		{"delete", domains(p.Delete), "old.lan"},
+		{"forget", domains(p.Forget), "gone.lan"},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s = %q, want %q", tc.name, tc.got, tc.want)
+		}
+	}
+	if u := p.Update[0]; u.PolicyID != "p-printer" || u.FromIP != "10.0.0.20" || u.IP != "10.0.0.21" {
+		t.Errorf("update = %+v", u)
+	}
+	if len(p.Skipped) != 2 || !strings.Contains(p.Skipped[0].Reason, "no IPv4") || !strings.Contains(p.Skipped[1].Reason, "does not own (p-router)") {
+		t.Errorf("skipped = %+v", p.Skipped)
+	}
+	if strings.Join(p.Seen, " ") != "aa:00:00:00:00:01 aa:00:00:00:00:02" {
+		t.Errorf("seen = %v", p.Seen)
+	}
+}
+
+func TestComputeRecreatesDeletedRecord(t *testing.T) {
+	owned := []Record{{PolicyID: "p-1", MAC: "aa:00:00:00:00:01", Domain: "nas.lan", IP: "10.0.0.10"}}
+	p := Compute([]Client{{MAC: "aa:00:00:00:00:01", Name: "nas", IP: "10.0.0.10"}}, nil, owned, Options{Domain: "lan"})
+	if len(p.Create) != 1 || p.Create[0].Domain != "nas.lan" || len(p.Forget) != 1 || p.Forget[0].PolicyID != "p-1" {
+		t.Errorf("got %+v", p)
+	}
+}
+
+func TestComputeRenamedClientLosesName(t *testing.T) {
+	owned := []Record{{PolicyID: "p-1", MAC: "aa:00:00:00:00:01", Domain: "nas.lan", IP: "10.0.0.10"}}
+	p := Compute([]Client{{MAC: "aa:00:00:00:00:01", Name: "???", IP: "10.0.0.10"}}, []unifi.DNSPolicy{a("p-1", "nas.lan", "10.0.0.10")}, owned, Options{Domain: "lan"})
+	if len(p.Delete) != 1 || p.Delete[0].PolicyID != "p-1" {
+		t.Errorf("got %+v", p)
+	}
+}
+
+func TestStoreUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dnssync.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Update([]Record{
+		{PolicyID: "p-2", SiteID: "", MAC: "aa:00:00:00:00:02", Domain: "b.lan"},
+		{PolicyID: "p-1", SiteID: "", MAC: "aa:00:00:00:00:01", Domain: "a.lan"},
+		{PolicyID: "p-3", SiteID: "other", MAC: "aa:00:00:00:00:03", Domain: "c.lan"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Update(nil, []string{"p-2"}); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := reopened.List("")
+	if len(got) != 1 || got[0].PolicyID != "p-1" {
+		t.Errorf("List(\"\") = %+v", got)
+	}
+	if got := reopened.List("other"); len(got) != 1 || got[0].Domain != "c.lan" {
+		t.Errorf("List(other) = %+v", got)
+	}
+}
+
+func TestParseDomain(t *testing.T) {
+	for in, want := range map[string]string{"lan": "lan", ".Home.Arpa.": "home.arpa"} {
+		if got, err := ParseDomain(in); err != nil || got != want {
+			t.Errorf("ParseDomain(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "a..b", "my_lan", "-lan"} {
+		if _, err := ParseDomain(in); err == nil {
+			t.Errorf("ParseDomain(%q) succeeded", in)
+		}
+	}
+}
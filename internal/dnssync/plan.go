@@ -0,0 +1,267 @@
+package dnssync
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/oui"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+// Client is a connected client as the sync sees it.
+type Client struct {
+	MAC  string
+	Name string
+	IP   string
+}
+
+// Options configures a sync.
+type Options struct {
+	// Domain is the suffix every client name is placed under, e.g. "lan".
+	Domain string
+	// RemoveAfter is how long after its client was last seen an owned record
+	// is deleted; zero means DefaultRemoveAfter.
+	RemoveAfter time.Duration
+	// Now is the time of the sync.
+	Now time.Time
+}
+
+// Change is one record the sync creates, updates, deletes or leaves alone.
+type Change struct {
+	MAC        string `json:"macAddress"`
+	ClientName string `json:"clientName,omitempty"`
+	PolicyID   string `json:"policyId,omitempty"`
+	Domain     string `json:"domain"`
+	IP         string `json:"ipv4Address"`
+	// FromDomain and FromIP are the current values an update replaces.
+	FromDomain string `json:"fromDomain,omitempty"`
+	FromIP     string `json:"fromIpv4Address,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// Skip is a client that gets no record, or an owned record left as it is
+// although its client would want a change.
+type Skip struct {
+	MAC        string `json:"macAddress"`
+	ClientName string `json:"clientName,omitempty"`
+	Domain     string `json:"domain,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+// Plan is what a sync will do. Forget lists owned records whose policy has
+// already gone; they only leave the registry.
+type Plan struct {
+	Create    []Change `json:"create"`
+	Update    []Change `json:"update"`
+	Delete    []Change `json:"delete"`
+	Unchanged []Change `json:"unchanged"`
+	Forget    []Change `json:"forget,omitempty"`
+	Skipped   []Skip   `json:"skipped,omitempty"`
+	// Seen are the MACs of owned records whose client is connected, whose
+	// last-seen time should move to Options.Now.
+	Seen []string `json:"-"`
+}
+
+// Report is the outcome of a sync: its plan, and whether and how well it was
+// applied.
+type Report struct {
+	Plan
+	Applied bool     `json:"applied"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// Hostname turns a client name into a DNS label: lower case, apostrophes
+// dropped and every other run of characters outside a-z and 0-9 replaced by
+// one hyphen, so "Gord's iPhone (2)" becomes "gords-iphone-2". It returns ""
+// when nothing usable is left.
+func Hostname(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			b.WriteRune(r)
+			hyphen = false
+		case r == '\'' || r == '’':
+		case b.Len() > 0 && !hyphen:
+			b.WriteByte('-')
+			hyphen = true
+		}
+	}
+	s := strings.TrimRight(b.String(), "-")
+	if len(s) > 63 {
+		s = strings.TrimRight(s[:63], "-")
+	}
+	return s
+}
+
+// ParseDomain checks a domain suffix for client names, e.g. "lan" or
+// "home.arpa", and returns it lower case without surrounding dots.
+func ParseDomain(s string) (string, error) {
+	d := strings.ToLower(strings.Trim(strings.TrimSpace(s), "."))
+	if d == "" {
+		return "", errors.New("domain suffix is empty")
+	}
+	// Leave room for a 63-character client label and its dot.
+	if len(d) > 189 {
+		return "", errors.New("domain suffix is longer than 189 characters")
+	}
+	for _, l := range strings.Split(d, ".") {
+		if l == "" || Hostname(l) != l {
+			return "", fmt.Errorf("domain suffix %q: label %q must be letters, digits and inner hyphens", s, l)
+		}
+	}
+	return d, nil
+}
+
+// want is the record one client should have.
+type want struct {
+	mac, name, label, ip string
+}
+
+// Compute plans a sync of the records owned for the clients against the
+// site's policies. Names two clients share get the last four hex digits of
+// each MAC appended. A name already taken by a policy the sync does not own
+// is skipped, as is a client without a name or an IPv4 address.
+func Compute(clients []Client, policies []unifi.DNSPolicy, owned []Record, opts Options) Plan {
+	removeAfter := cmp.Or(opts.RemoveAfter, DefaultRemoveAfter)
+	suffix := strings.ToLower(strings.Trim(opts.Domain, "."))
+	p := Plan{Create: []Change{}, Update: []Change{}, Delete: []Change{}, Unchanged: []Change{}}
+
+	byID := make(map[string]*unifi.DNSPolicy, len(policies))
+	byDomain := make(map[string][]string)
+	for i := range policies {
+		pol := &policies[i]
+		byID[pol.ID] = pol
+		d := strings.ToLower(strings.TrimSuffix(pol.Domain, "."))
+		byDomain[d] = append(byDomain[d], pol.ID)
+	}
+	owned = slices.Clone(owned)
+	slices.SortFunc(owned, func(a, b Record) int { return cmp.Compare(a.PolicyID, b.PolicyID) })
+	ownedIDs := make(map[string]bool, len(owned))
+	ownedByMAC := make(map[string]Record, len(owned))
+	for _, r := range owned {
+		ownedIDs[r.PolicyID] = true
+		if _, dup := ownedByMAC[r.MAC]; dup {
+			p.Delete = append(p.Delete, change(r, "a second record for the same client"))
+			continue
+		}
+		ownedByMAC[r.MAC] = r
+	}
+
+	// Work out every client's label before looking for clashes between them.
+	var wants []want
+	seen := map[string]bool{}
+	for _, c := range clients {
+		hw, err := oui.ParseMAC(c.MAC)
+		if err != nil || seen[hw.String()] {
+			continue
+		}
+		mac := hw.String()
+		seen[mac] = true
+		label := Hostname(c.Name)
+		ip, err := netip.ParseAddr(c.IP)
+		switch {
+		case label == "":
+			if rec, ok := ownedByMAC[mac]; ok {
+				p.Delete = append(p.Delete, change(rec, "client no longer has a usable name"))
+				delete(ownedByMAC, mac)
+			} else if c.Name != "" {
+				p.Skipped = append(p.Skipped, Skip{MAC: mac, ClientName: c.Name, Reason: "name has no characters usable in a host name"})
+			}
+			continue
+		case err != nil || !ip.Is4():
+			// Keep whatever record the client has until it gets an address.
+			if _, ok := ownedByMAC[mac]; ok {
+				p.Seen = append(p.Seen, mac)
+				delete(ownedByMAC, mac)
+			}
+			p.Skipped = append(p.Skipped, Skip{MAC: mac, ClientName: c.Name, Reason: "no IPv4 address"})
+			continue
+		}
+		wants = append(wants, want{mac: mac, name: c.Name, label: label, ip: ip.String()})
+	}
+	labels := map[string]int{}
+	for _, w := range wants {
+		labels[w.label]++
+	}
+	slices.SortFunc(wants, func(a, b want) int { return cmp.Compare(a.mac, b.mac) })
+
+	for _, w := range wants {
+		label := w.label
+		if labels[label] > 1 {
+			hex := strings.ReplaceAll(w.mac, ":", "")
+			label = strings.TrimRight(label[:min(len(label), 58)], "-") + "-" + hex[len(hex)-4:]
+		}
+		domain := label + "." + suffix
+		rec, isOwned := ownedByMAC[w.mac]
+		delete(ownedByMAC, w.mac)
+		c := Change{MAC: w.mac, ClientName: w.name, Domain: domain, IP: w.ip}
+
+		if holder := foreignHolder(byDomain[domain], rec.PolicyID, ownedIDs); holder != "" {
+			p.Skipped = append(p.Skipped, Skip{MAC: w.mac, ClientName: w.name, Domain: domain, Reason: holder})
+			if isOwned {
+				p.Seen = append(p.Seen, w.mac)
+			}
+			continue
+		}
+		if !isOwned {
+			p.Create = append(p.Create, c)
+			continue
+		}
+		p.Seen = append(p.Seen, w.mac)
+		c.PolicyID = rec.PolicyID
+		pol, exists := byID[rec.PolicyID]
+		switch {
+		case !exists:
+			c.PolicyID = ""
+			c.Reason = "the owned record was deleted outside the sync"
+			p.Forget = append(p.Forget, change(rec, "policy no longer exists"))
+			p.Create = append(p.Create, c)
+		case pol.Type != unifi.DNSPolicyTypeA || !strings.EqualFold(strings.TrimSuffix(pol.Domain, "."), domain) || pol.IPv4Address != w.ip:
+			c.FromDomain, c.FromIP = pol.Domain, pol.IPv4Address
+			p.Update = append(p.Update, c)
+		default:
+			p.Unchanged = append(p.Unchanged, c)
+		}
+	}
+
+	// What is left in ownedByMAC belongs to clients that are not connected.
+	for _, rec := range ownedByMAC {
+		_, exists := byID[rec.PolicyID]
+		switch {
+		case !exists:
+			p.Forget = append(p.Forget, change(rec, "policy no longer exists"))
+		case opts.Now.Sub(rec.LastSeen) >= removeAfter:
+			p.Delete = append(p.Delete, change(rec, "client not seen since "+rec.LastSeen.UTC().Format(time.RFC3339)))
+		}
+	}
+	for _, l := range [][]Change{p.Delete, p.Forget} {
+		slices.SortFunc(l, func(a, b Change) int { return cmp.Compare(a.Domain, b.Domain) })
+	}
+	return p
+}
+
+// foreignHolder describes why domain cannot be used when a policy other than
+// ownID already answers for it, or returns "".
+func foreignHolder(holders []string, ownID string, ownedIDs map[string]bool) string {
+	for _, id := range holders {
+		if id == ownID {
+			continue
+		}
+		if ownedIDs[id] {
+			return "name is held by another client's record (" + id + ")"
+		}
+		return "name is taken by a record the sync does not own (" + id + ")"
+	}
+	return ""
+}
+
+func change(r Record, reason string) Change {
+	return Change{MAC: r.MAC, ClientName: r.ClientName, PolicyID: r.PolicyID, Domain: r.Domain, IP: r.IP, Reason: reason}
+}
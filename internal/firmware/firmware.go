@@ -0,0 +1,139 @@
+// Package firmware summarizes the firmware running on a site's devices and
+// plans staged upgrades.
+package firmware
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+// Compare orders firmware versions such as "6.6.77.15402": dot- and
+// dash-separated parts are compared numerically where both are numbers and
+// as text otherwise. It returns -1, 0 or +1.
+func Compare(a, b string) int {
+	split := func(s string) []string {
+		return strings.FieldsFunc(s, func(r rune) bool { return r == '.' || r == '-' || r == '+' })
+	}
+	pa, pb := split(a), split(b)
+	for i := range min(len(pa), len(pb)) {
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		var c int
+		if errA == nil && errB == nil {
+			c = cmp.Compare(na, nb)
+		} else {
+			c = cmp.Compare(pa[i], pb[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(pa), len(pb))
+}
+
+// Device is one device in a Version.
+type Device struct {
+	ID        string `json:"id"`
+	Name      string `json:"name,omitempty"`
+	State     string `json:"state"`
+	Updatable bool   `json:"updatable"`
+}
+
+// Version groups the devices of one model running one firmware version.
+type Version struct {
+	Version   string `json:"version"`
+	Devices   int    `json:"devices"`
+	Updatable int    `json:"updatable"`
+	// Behind is set when another device of the model runs a newer version.
+	Behind bool     `json:"behind,omitempty"`
+	List   []Device `json:"list"`
+}
+
+// Model summarizes one device model.
+type Model struct {
+	Model     string `json:"model"`
+	Devices   int    `json:"devices"`
+	Updatable int    `json:"updatable"`
+	// Newest is the newest version any device of the model runs.
+	Newest string `json:"newest"`
+	// Consistent is set when every device of the model runs the same version.
+	Consistent bool      `json:"consistent"`
+	Versions   []Version `json:"versions"`
+}
+
+// Report is the firmware state of a set of devices.
+type Report struct {
+	Devices   int     `json:"devices"`
+	Updatable int     `json:"updatable"`
+	Offline   int     `json:"offline"`
+	Models    []Model `json:"models"`
+}
+
+// Summarize groups devices by model and firmware version. Models are sorted
+// by name and versions newest first.
+func Summarize(devices []unifi.Device) Report {
+	r := Report{Devices: len(devices), Models: []Model{}}
+	byModel := map[string]map[string]*Version{}
+	for i := range devices {
+		d := &devices[i]
+		if d.FirmwareUpdatable {
+			r.Updatable++
+		}
+		if d.State != "ONLINE" {
+			r.Offline++
+		}
+		versions := byModel[d.Model]
+		if versions == nil {
+			versions = map[string]*Version{}
+			byModel[d.Model] = versions
+		}
+		v := versions[d.FirmwareVersion]
+		if v == nil {
+			v = &Version{Version: d.FirmwareVersion}
+			versions[d.FirmwareVersion] = v
+		}
+		v.Devices++
+		if d.FirmwareUpdatable {
+			v.Updatable++
+		}
+		v.List = append(v.List, Device{ID: d.ID, Name: d.Name, State: d.State, Updatable: d.FirmwareUpdatable})
+	}
+	for model, versions := range byModel {
+		m := Model{Model: model, Consistent: len(versions) == 1}
+		for _, v := range versions {
+			slices.SortFunc(v.List, func(a, b Device) int { return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID)) })
+			m.Devices += v.Devices
+			m.Updatable += v.Updatable
+			m.Versions = append(m.Versions, *v)
+		}
+		slices.SortFunc(m.Versions, func(a, b Version) int { return Compare(b.Version, a.Version) })
+		m.Newest = m.Versions[0].Version
+		for i := range m.Versions {
+			m.Versions[i].Behind = Compare(m.Versions[i].Version, m.Newest) < 0
+		}
+		r.Models = append(r.Models, m)
+	}
+	slices.SortFunc(r.Models, func(a, b Model) int { return cmp.Compare(a.Model, b.Model) })
+	return r
+}
+
+// Waves splits n devices into upgrade waves: a canary wave of the first
+// canary devices, then waves of at most size. canary and size are at least 1.
+// It returns the index ranges [start, end) of each wave.
+func Waves(n, canary, size int) [][2]int {
+	canary, size = max(canary, 1), max(size, 1)
+	var waves [][2]int
+	for start := 0; start < n; {
+		end := min(start+size, n)
+		if start == 0 {
+			end = min(canary, n)
+		}
+		waves = append(waves, [2]int{start, end})
+		start = end
+	}
+	return waves
+}
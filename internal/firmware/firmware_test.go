@@ -0,0 +1,72 @@
+package firmware
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"6.6.77", "6.6.77", 0},
+		{"6.6.77", "6.6.9", 1},
+		{"6.6.77.15402", "6.6.77", 1},
+		{"7.0.1", "6.99.99", 1},
+		{"4.0.66-beta", "4.0.66-rc", -1},
+		{"", "1.0", -1},
+	}
+	for _, tc := range tests {
+		if got := Compare(tc.a, tc.b); got != tc.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	devices := []unifi.Device{
+		{ID: "ap-1", Name: "Hall", Model: "U6-Pro", State: "ONLINE", FirmwareVersion: "6.6.77", FirmwareUpdatable: true},
+		{ID: "ap-2", Name: "Attic", Model: "U6-Pro", State: "ONLINE", FirmwareVersion: "6.7.10"},
+		{ID: "ap-3", Name: "Den", Model: "U6-Pro", State: "OFFLINE", FirmwareVersion: "6.6.77", FirmwareUpdatable: true},
+		{ID: "sw-1", Name: "Core", Model: "USW-24", State: "ONLINE", FirmwareVersion: "7.1.26"},
+	}
+	r := Summarize(devices)
+	if r.Devices != 4 || r.Updatable != 2 || r.Offline != 1 || len(r.Models) != 2 {
+		t.Fatalf("report = %+v", r)
+	}
+	ap := r.Models[0]
+	if ap.Model != "U6-Pro" || ap.Newest != "6.7.10" || ap.Consistent || ap.Devices != 3 || ap.Updatable != 2 {
+		t.Errorf("U6-Pro = %+v", ap)
+	}
+	if len(ap.Versions) != 2 || ap.Versions[0].Behind || !ap.Versions[1].Behind || ap.Versions[1].Devices != 2 {
+		t.Errorf("U6-Pro versions = %+v", ap.Versions)
+	}
+	if names := []string{ap.Versions[1].List[0].Name, ap.Versions[1].List[1].Name}; names[0] != "Den" || names[1] != "Hall" {
+		t.Errorf("devices not sorted by name: %v", names)
+	}
+	if sw := r.Models[1]; !sw.Consistent || sw.Newest != "7.1.26" {
+		t.Errorf("USW-24 = %+v", sw)
+	}
+}
+
+func TestWaves(t *testing.T) {
+	tests := []struct {
+		n, canary, size int
+		want            [][2]int
+	}{
+		{0, 1, 5, nil},
+		{1, 1, 5, [][2]int{{0, 1}}},
+		{7, 1, 3, [][2]int{{0, 1}, {1, 4}, {4, 7}}},
+		{5, 2, 10, [][2]int{{0, 2}, {2, 5}}},
+		{3, 5, 2, [][2]int{{0, 3}}},
+		{3, 0, 0, [][2]int{{0, 1}, {1, 2}, {2, 3}}},
+	}
+	for _, tc := range tests {
+		if got := Waves(tc.n, tc.canary, tc.size); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Waves(%d, %d, %d) = %v, want %v", tc.n, tc.canary, tc.size, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+// Package inventory keeps a persistent, file-backed record of every client MAC
+// address seen on the network, whether the owner has marked it as known, and
+// when it was first and last observed.
+package inventory
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/oui"
+	"github.com/gordcurrie/unifi-mcp/internal/statefile"
+)
+
+// maxEvents caps the number of first-seen events retained in the file; the
+// oldest are dropped first.
+const maxEvents = 1000
+
+// EventFirstSeen is the Event.Type recorded the first time a MAC is observed.
+const EventFirstSeen = "FIRST_SEEN"
+
+// ErrNotFound is returned when a MAC address is not in the inventory.
+var ErrNotFound = errors.New("client not in inventory")
+
+// Client is one inventory record, keyed by normalised MAC address.
+type Client struct {
+	MAC           string    `json:"macAddress"`
+	Known         bool      `json:"known"`
+	Owner         string    `json:"owner,omitempty"`
+	Label         string    `json:"label,omitempty"`
+	FirstSeen     time.Time `json:"firstSeen,omitzero"`
+	LastSeen      time.Time `json:"lastSeen,omitzero"`
+	LastName      string    `json:"lastName,omitempty"`
+	LastIP        string    `json:"lastIp,omitempty"`
+	MarkedKnownAt time.Time `json:"markedKnownAt,omitzero"`
+}
+
+// Event records a notable inventory change, currently only first sightings.
+type Event struct {
+	Type string    `json:"type"`
+	MAC  string    `json:"macAddress"`
+	Name string    `json:"name,omitempty"`
+	IP   string    `json:"ipAddress,omitempty"`
+	At   time.Time `json:"at"`
+}
+
+// Observation is a single client seen on the network during a poll.
+type Observation struct {
+	MAC  string
+	Name string
+	IP   string
+}
+
+// state is the on-disk JSON document.
+type state struct {
+	Clients map[string]*Client `json:"clients"`
+	Events  []Event            `json:"events"`
+	// BaselineAt is when the first batch of observations was recorded
+	// without first-seen events; see Record.
+	BaselineAt time.Time `json:"baselineAt,omitzero"`
+}
+
+// Store is a file-backed inventory. All methods are safe for concurrent use;
+// every mutation is written to disk before it returns.
+type Store struct {
+	path string
+	now  func() time.Time
+
+	mu    sync.Mutex
+	state state
+}
+
+// Open loads the inventory at path, or starts an empty one if the file does
+// not exist yet. The file and its directory are created on the first write.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, now: time.Now, state: state{Clients: make(map[string]*Client)}}
+	if _, err := statefile.Load(path, &s.state); err != nil {
+		return nil, fmt.Errorf("open inventory: %w", err)
+	}
+	if s.state.Clients == nil {
+		s.state.Clients = make(map[string]*Client)
+	}
+	return s, nil
+}
+
+// Path returns the file the store persists to.
+func (s *Store) Path() string { return s.path }
+
+// MarkKnown records mac as a known client with the given owner and label,
+// creating the record if the MAC has never been observed. Empty owner or label
+// leave any existing value unchanged.
+func (s *Store) MarkKnown(mac, owner, label string) (Client, error) {
+	key, err := normalize(mac)
+	if err != nil {
+		return Client{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := s.state.clone()
+	c := next.Clients[key]
+	if c == nil {
+		c = &Client{MAC: key}
+		next.Clients[key] = c
+	}
+	c.Known = true
+	c.MarkedKnownAt = s.now().UTC()
+	if owner != "" {
+		c.Owner = owner
+	}
+	if label != "" {
+		c.Label = label
+	}
+	if err := s.commitLocked(next); err != nil {
+		return Client{}, err
+	}
+	return *c, nil
+}
+
+// MarkUnknown clears the known flag on mac, keeping its sighting history and
+// owner/label so a mistaken mark can be undone without losing first-seen data.
+func (s *Store) MarkUnknown(mac string) (Client, error) {
+	key, err := normalize(mac)
+	if err != nil {
+		return Client{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.state.Clients[key]; !ok {
+		return Client{}, fmt.Errorf("mark unknown %s: %w", key, ErrNotFound)
+	}
+	next := s.state.clone()
+	c := next.Clients[key]
+	c.Known = false
+	c.MarkedKnownAt = time.Time{}
+	if err := s.commitLocked(next); err != nil {
+		return Client{}, err
+	}
+	return *c, nil
+}
+
+// Observe records a batch of sightings, updating last-seen details for every
+// MAC and creating a FIRST_SEEN event for each MAC not already in the
+// inventory. It returns the new events. Observations with an invalid MAC are
+// skipped. Nothing changes unless the batch is saved, so a failed save
+// reports the same clients as new on the next call.
+func (s *Store) Observe(obs []Observation) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := s.state.clone()
+	events := next.observe(obs, s.now().UTC())
+	if err := s.commitLocked(next); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// observe applies obs to st at now and returns the first-seen events.
+func (st *state) observe(obs []Observation, now time.Time) []Event {
+	var events []Event
+	for _, o := range obs {
+		key, err := normalize(o.MAC)
+		if err != nil {
+			continue
+		}
+		c := st.Clients[key]
+		if c == nil {
+			c = &Client{MAC: key}
+			st.Clients[key] = c
+		}
+		if c.FirstSeen.IsZero() {
+			c.FirstSeen = now
+			events = append(events, Event{Type: EventFirstSeen, MAC: key, Name: o.Name, IP: o.IP, At: now})
+		}
+		c.LastSeen = now
+		if o.Name != "" {
+			c.LastName = o.Name
+		}
+		if o.IP != "" {
+			c.LastIP = o.IP
+		}
+	}
+	st.Events = append(st.Events, events...)
+	if n := len(st.Events); n > maxEvents {
+		st.Events = slices.Clone(st.Events[n-maxEvents:])
+	}
+	return events
+}
+
+// Record is Observe for the poller and list_unknown_clients, except that the
+// first batch recorded into an inventory that has never observed a client is
+// taken as the baseline: the clients already on the network get their
+// first/last-seen times but no FIRST_SEEN event, so starting the inventory on
+// a busy network does not report every client as new.
+func (s *Store) Record(obs []Observation) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	baseline := s.state.BaselineAt.IsZero()
+	for _, c := range s.state.Clients {
+		if !c.FirstSeen.IsZero() {
+			baseline = false
+			break
+		}
+	}
+	next := s.state.clone()
+	now := s.now().UTC()
+	events := next.observe(obs, now)
+	if baseline {
+		next.BaselineAt = now
+		next.Events = slices.Clone(s.state.Events)
+		events = nil
+	}
+	if err := s.commitLocked(next); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Get returns the record for mac.
+func (s *Store) Get(mac string) (Client, bool) {
+	key, err := normalize(mac)
+	if err != nil {
+		return Client{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.state.Clients[key]
+	if !ok {
+		return Client{}, false
+	}
+	return *c, true
+}
+
+// List returns every record sorted by MAC address.
+func (s *Store) List() []Client {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Client, 0, len(s.state.Clients))
+	for _, c := range s.state.Clients {
+		out = append(out, *c)
+	}
+	slices.SortFunc(out, func(a, b Client) int { return cmp.Compare(a.MAC, b.MAC) })
+	return out
+}
+
+// Events returns the recorded events at or after since, oldest first.
+func (s *Store) Events(since time.Time) []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Event
+	for _, e := range s.state.Events {
+		if !e.At.Before(since) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Poll calls fetch every interval and records the result via Record until ctx
+// is cancelled. Each new client is logged at info level; fetch and write
+// failures are logged and retried on the next tick.
+func (s *Store) Poll(ctx context.Context, interval time.Duration, fetch func(ctx context.Context) ([]Observation, error), logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		obs, err := fetch(ctx)
+		if err != nil {
+			logger.Warn("inventory poll: fetch clients", "err", err)
+		} else {
+			events, err := s.Record(obs)
+			if err != nil {
+				logger.Warn("inventory poll: record observations", "err", err)
+			}
+			for _, e := range events {
+				logger.Info("new client seen", "mac", e.MAC, "name", e.Name, "ip", e.IP)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// commitLocked saves next and makes it the store's state; on failure the
+// state is left as it was. The caller must hold s.mu.
+func (s *Store) commitLocked(next state) error {
+	if err := statefile.Save(s.path, next); err != nil {
+		return fmt.Errorf("save inventory: %w", err)
+	}
+	s.state = next
+	return nil
+}
+
+// clone returns a deep copy of st that can be changed without touching st.
+func (st state) clone() state {
+	out := state{Clients: make(map[string]*Client, len(st.Clients)), Events: slices.Clone(st.Events), BaselineAt: st.BaselineAt}
+	for k, c := range st.Clients {
+		cc := *c
+		out.Clients[k] = &cc
+	}
+	return out
+}
+
+func normalize(mac string) (string, error) {
+	hw, err := oui.ParseMAC(mac)
+	if err != nil {
+		return "", fmt.Errorf("%q: %w", mac, err)
+	}
+	return hw.String(), nil
+}
@@ -0,0 +1,307 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "sub", "inventory.json"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return s
+}
+
+func TestObserve(t *testing.T) {
+	t.Run("first sighting records event once", func(t *testing.T) {
+		s := newTestStore(t)
+		t0 := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		s.now = func() time.Time { return t0 }
+
+		events, err := s.Observe([]Observation{{MAC: "AA-BB-CC-00-00-01", Name: "phone", IP: "10.0.0.5"}})
+		if err != nil {
+			t.Fatalf("Observe: %v", err)
+		}
+		if len(events) != 1 || events[0].MAC != "aa:bb:cc:00:00:01" || events[0].Type != EventFirstSeen {
+			t.Fatalf("got events %+v, want one FIRST_SEEN for aa:bb:cc:00:00:01", events)
+		}
+
+		t1 := t0.Add(time.Hour)
+		s.now = func() time.Time { return t1 }
+		events, err = s.Observe([]Observation{{MAC: "aa:bb:cc:00:00:01", IP: "10.0.0.6"}})
+		if err != nil {
+			t.Fatalf("Observe: %v", err)
+		}
+		if len(events) != 0 {
+			t.Errorf("got %d events on second sighting, want 0", len(events))
+		}
+		c, ok := s.Get("aabbcc000001")
+		if !ok {
+			t.Fatal("client missing after Observe")
+		}
+		if !c.FirstSeen.Equal(t0) || !c.LastSeen.Equal(t1) {
+			t.Errorf("got firstSeen %v lastSeen %v, want %v / %v", c.FirstSeen, c.LastSeen, t0, t1)
+		}
+		if c.LastName != "phone" || c.LastIP != "10.0.0.6" {
+			t.Errorf("got lastName %q lastIp %q", c.LastName, c.LastIP)
+		}
+		if c.Known {
+			t.Error("observed client should not be known")
+		}
+	})
+
+	t.Run("invalid MAC is skipped", func(t *testing.T) {
+		s := newTestStore(t)
+		events, err := s.Observe([]Observation{{MAC: "nope"}})
+		if err != nil {
+			t.Fatalf("Observe: %v", err)
+		}
+		if len(events) != 0 || len(s.List()) != 0 {
+			t.Errorf("expected nothing recorded, got events=%d clients=%d", len(events), len(s.List()))
+		}
+	})
+
+	t.Run("event log is capped", func(t *testing.T) {
+		s := newTestStore(t)
+		obs := make([]Observation, maxEvents+5)
+		for i := range obs {
+			obs[i] = Observation{MAC: macN(i)}
+		}
+		if _, err := s.Observe(obs); err != nil {
+			t.Fatalf("Observe: %v", err)
+		}
+		events := s.Events(time.Time{})
+		if len(events) != maxEvents {
+			t.Fatalf("got %d events, want %d", len(events), maxEvents)
+		}
+		if events[0].MAC != macN(5) {
+			t.Errorf("oldest retained event %s, want %s", events[0].MAC, macN(5))
+		}
+	})
+
+	t.Run("a failed save keeps the sightings new", func(t *testing.T) {
+		s := newTestStore(t)
+		good := s.path
+		blocker := filepath.Join(t.TempDir(), "file")
+		if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		s.path = filepath.Join(blocker, "inventory.json")
+		obs := []Observation{{MAC: "aa:bb:cc:00:00:01"}}
+		if _, err := s.Observe(obs); err == nil {
+			t.Fatal("Observe saved under a regular file")
+		}
+		if _, ok := s.Get("aa:bb:cc:00:00:01"); ok || len(s.Events(time.Time{})) != 0 {
+			t.Error("a failed save changed the inventory")
+		}
+		s.path = good
+		events, err := s.Observe(obs)
+		if err != nil {
+			t.Fatalf("Observe: %v", err)
+		}
+		if len(events) != 1 {
+			t.Errorf("got %d events after the retry, want 1", len(events))
+		}
+	})
+}
+
+func TestMarkKnown(t *testing.T) {
+	t.Run("marks and persists", func(t *testing.T) {
+		s := newTestStore(t)
+		if _, err := s.Observe([]Observation{{MAC: "aa:bb:cc:00:00:01"}}); err != nil {
+			t.Fatalf("Observe: %v", err)
+		}
+		c, err := s.MarkKnown("AABB.CC00.0001", "alice", "laptop")
+		if err != nil {
+			t.Fatalf("MarkKnown: %v", err)
+		}
+		if !c.Known || c.Owner != "alice" || c.Label != "laptop" || c.FirstSeen.IsZero() {
+			t.Errorf("got %+v", c)
+		}
+
+		reopened, err := Open(s.Path())
+		if err != nil {
+			t.Fatalf("reopen: %v", err)
+		}
+		got, ok := reopened.Get("aa:bb:cc:00:00:01")
+		if !ok || !got.Known || got.Owner != "alice" {
+			t.Errorf("after reopen got %+v, %v", got, ok)
+		}
+		if len(reopened.Events(time.Time{})) != 1 {
+			t.Errorf("events not persisted")
+		}
+	})
+
+	t.Run("empty owner keeps existing value", func(t *testing.T) {
+		s := newTestStore(t)
+		if _, err := s.MarkKnown("aa:bb:cc:00:00:01", "alice", "laptop"); err != nil {
+			t.Fatalf("MarkKnown: %v", err)
+		}
+		c, err := s.MarkKnown("aa:bb:cc:00:00:01", "", "work laptop")
+		if err != nil {
+			t.Fatalf("MarkKnown: %v", err)
+		}
+		if c.Owner != "alice" || c.Label != "work laptop" {
+			t.Errorf("got owner %q label %q", c.Owner, c.Label)
+		}
+	})
+
+	t.Run("marking before sighting still records first-seen later", func(t *testing.T) {
+		s := newTestStore(t)
+		if _, err := s.MarkKnown("aa:bb:cc:00:00:01", "alice", ""); err != nil {
+			t.Fatalf("MarkKnown: %v", err)
+		}
+		events, err := s.Observe([]Observation{{MAC: "aa:bb:cc:00:00:01"}})
+		if err != nil {
+			t.Fatalf("Observe: %v", err)
+		}
+		if len(events) != 1 {
+			t.Errorf("got %d events, want 1", len(events))
+		}
+	})
+
+	t.Run("invalid MAC", func(t *testing.T) {
+		s := newTestStore(t)
+		if _, err := s.MarkKnown("zz", "", ""); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestMarkUnknown(t *testing.T) {
+	s := newTestStore(t)
+	if _, err := s.MarkKnown("aa:bb:cc:00:00:01", "alice", ""); err != nil {
+		t.Fatalf("MarkKnown: %v", err)
+	}
+	c, err := s.MarkUnknown("aa:bb:cc:00:00:01")
+	if err != nil {
+		t.Fatalf("MarkUnknown: %v", err)
+	}
+	if c.Known || !c.MarkedKnownAt.IsZero() {
+		t.Errorf("got %+v, want known=false", c)
+	}
+	if c.Owner != "alice" {
+		t.Errorf("owner not preserved: %q", c.Owner)
+	}
+	if _, err := s.MarkUnknown("aa:bb:cc:00:00:02"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got %v, want ErrNotFound", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	t.Run("corrupt file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "inventory.json")
+		if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Open(path); err == nil {
+			t.Error("expected decode error")
+		}
+	})
+
+	t.Run("file permissions are 0600", func(t *testing.T) {
+		s := newTestStore(t)
+		if _, err := s.MarkKnown("aa:bb:cc:00:00:01", "", ""); err != nil {
+			t.Fatalf("MarkKnown: %v", err)
+		}
+		fi, err := os.Stat(s.Path())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := fi.Mode().Perm(); perm != 0o600 {
+			t.Errorf("got mode %o, want 600", perm)
+		}
+	})
+}
+
+func TestPoll(t *testing.T) {
+	s := newTestStore(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		s.Poll(ctx, time.Millisecond, func(context.Context) ([]Observation, error) {
+			n := calls.Add(1)
+			if n >= 3 {
+				cancel()
+			}
+			if n == 1 {
+				return []Observation{{MAC: "aa:bb:cc:00:00:01"}}, nil
+			}
+			return []Observation{{MAC: "aa:bb:cc:00:00:01"}, {MAC: "aa:bb:cc:00:00:02"}}, nil
+		}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Poll did not return after cancel")
+	}
+	if events := s.Events(time.Time{}); len(events) != 1 || events[0].MAC != "aa:bb:cc:00:00:02" {
+		t.Errorf("got events %+v, want one for the client that joined after the baseline", events)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	t.Run("first batch is a silent baseline", func(t *testing.T) {
+		s := newTestStore(t)
+		events, err := s.Record([]Observation{{MAC: "aa:bb:cc:00:00:01"}, {MAC: "aa:bb:cc:00:00:02"}})
+		if err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+		if len(events) != 0 || len(s.Events(time.Time{})) != 0 {
+			t.Fatalf("baseline recorded events %+v", events)
+		}
+		if c, ok := s.Get("aa:bb:cc:00:00:01"); !ok || c.FirstSeen.IsZero() {
+			t.Errorf("baseline client = %+v, %v; want first-seen set", c, ok)
+		}
+
+		events, err = s.Record([]Observation{{MAC: "aa:bb:cc:00:00:01"}, {MAC: "aa:bb:cc:00:00:03"}})
+		if err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+		if len(events) != 1 || events[0].MAC != "aa:bb:cc:00:00:03" {
+			t.Errorf("got events %+v, want one for aa:bb:cc:00:00:03", events)
+		}
+	})
+
+	t.Run("baseline survives reopen", func(t *testing.T) {
+		s := newTestStore(t)
+		if _, err := s.Record(nil); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+		s2, err := Open(s.Path())
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		events, err := s2.Record([]Observation{{MAC: "aa:bb:cc:00:00:01"}})
+		if err != nil || len(events) != 1 {
+			t.Errorf("got %+v, %v; want one event after an empty baseline", events, err)
+		}
+	})
+
+	t.Run("inventory with observed clients is not re-baselined", func(t *testing.T) {
+		s := newTestStore(t)
+		if _, err := s.Observe([]Observation{{MAC: "aa:bb:cc:00:00:01"}}); err != nil {
+			t.Fatal(err)
+		}
+		events, err := s.Record([]Observation{{MAC: "aa:bb:cc:00:00:02"}})
+		if err != nil || len(events) != 1 {
+			t.Errorf("got %+v, %v; want one event", events, err)
+		}
+	})
+}
+
+func macN(i int) string {
+	return fmt.Sprintf("02:00:00:00:%02x:%02x", (i>>8)&0xff, i&0xff)
+}
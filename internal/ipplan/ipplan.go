@@ -0,0 +1,154 @@
+// Package ipplan checks a site's IPv4 addressing plan: that each network's
+// gateway and DHCP range fit its subnet, and that networks do not overlap or
+// share a VLAN ID.
+package ipplan
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Network is one network of the plan. Subnet is invalid for networks without
+// an IPv4 configuration (e.g. switch-managed VLANs), which are only checked for
+// VLAN ID clashes.
+type Network struct {
+	ID     string
+	Name   string
+	VLANID int
+	// Gateway is the gateway's address, within Subnet.
+	Gateway netip.Addr
+	Subnet  netip.Prefix
+	// DHCPStart and DHCPStop bound the DHCP pool; both are invalid when the
+	// network runs no DHCP server.
+	DHCPStart netip.Addr
+	DHCPStop  netip.Addr
+}
+
+// Parse builds a Network from the controller's string fields. Empty
+// gateway or DHCP bounds are left invalid.
+func Parse(id, name string, vlanID int, gateway string, prefixLength int, dhcpStart, dhcpStop string) (Network, error) {
+	n := Network{ID: id, Name: name, VLANID: vlanID}
+	if gateway != "" {
+		gw, err := netip.ParseAddr(gateway)
+		if err != nil || !gw.Is4() {
+			return n, fmt.Errorf("gateway %q is not an IPv4 address", gateway)
+		}
+		if prefixLength < 1 || prefixLength > 32 {
+			return n, fmt.Errorf("prefix length %d is out of range", prefixLength)
+		}
+		n.Gateway = gw
+		n.Subnet = netip.PrefixFrom(gw, prefixLength).Masked()
+	}
+	for _, b := range []struct {
+		s   string
+		dst *netip.Addr
+	}{{dhcpStart, &n.DHCPStart}, {dhcpStop, &n.DHCPStop}} {
+		if b.s == "" {
+			continue
+		}
+		a, err := netip.ParseAddr(b.s)
+		if err != nil || !a.Is4() {
+			return n, fmt.Errorf("DHCP range bound %q is not an IPv4 address", b.s)
+		}
+		*b.dst = a
+	}
+	return n, nil
+}
+
+// label names n in error messages.
+func (n *Network) label() string {
+	if n.Name != "" {
+		return fmt.Sprintf("%q", n.Name)
+	}
+	return n.ID
+}
+
+// vlan returns n's effective VLAN ID: untagged networks are on VLAN 1.
+func (n *Network) vlan() int {
+	if n.VLANID == 0 {
+		return 1
+	}
+	return n.VLANID
+}
+
+// Check validates candidate on its own and against others, which should be
+// every other network on the site. A network in others with candidate's ID is
+// the one being replaced and is skipped. All problems found are returned
+// together.
+func Check(candidate Network, others []Network) error {
+	var errs []error
+	if v := candidate.VLANID; v != 0 && (v < 2 || v > 4094) {
+		errs = append(errs, fmt.Errorf("VLAN ID %d is out of range (2-4094)", v))
+	}
+	if candidate.Subnet.IsValid() {
+		errs = append(errs, checkSubnet(&candidate)...)
+	} else if candidate.DHCPStart.IsValid() || candidate.DHCPStop.IsValid() {
+		errs = append(errs, errors.New("a DHCP range needs a gateway address and prefix length"))
+	}
+	for i := range others {
+		o := &others[i]
+		if candidate.ID != "" && o.ID == candidate.ID {
+			continue
+		}
+		if o.vlan() == candidate.vlan() {
+			errs = append(errs, fmt.Errorf("VLAN ID %d is already used by network %s", candidate.vlan(), o.label()))
+		}
+		if candidate.Subnet.IsValid() && o.Subnet.IsValid() && candidate.Subnet.Overlaps(o.Subnet) {
+			errs = append(errs, fmt.Errorf("subnet %s overlaps %s of network %s", candidate.Subnet, o.Subnet, o.label()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkSubnet validates the gateway and DHCP range against n.Subnet.
+func checkSubnet(n *Network) []error {
+	var errs []error
+	bits := n.Subnet.Bits()
+	if bits < 8 || bits > 30 {
+		errs = append(errs, fmt.Errorf("prefix length /%d is out of range (/8-/30)", bits))
+		return errs
+	}
+	network, broadcast := bounds(n.Subnet)
+	if n.Gateway == network || n.Gateway == broadcast {
+		errs = append(errs, fmt.Errorf("gateway %s is the network or broadcast address of %s", n.Gateway, n.Subnet))
+	}
+	start, stop := n.DHCPStart, n.DHCPStop
+	switch {
+	case !start.IsValid() && !stop.IsValid():
+		return errs
+	case !start.IsValid() || !stop.IsValid():
+		errs = append(errs, errors.New("a DHCP range needs both a start and a stop address"))
+		return errs
+	}
+	var outside []string
+	for _, a := range []netip.Addr{start, stop} {
+		if !n.Subnet.Contains(a) || a == network || a == broadcast {
+			outside = append(outside, a.String())
+		}
+	}
+	if len(outside) > 0 {
+		errs = append(errs, fmt.Errorf("DHCP range %s-%s is not within the usable addresses of %s (%s)",
+			start, stop, n.Subnet, strings.Join(outside, ", ")))
+		return errs
+	}
+	if stop.Less(start) {
+		errs = append(errs, fmt.Errorf("DHCP range start %s is after stop %s", start, stop))
+		return errs
+	}
+	if n.Gateway.Compare(start) >= 0 && n.Gateway.Compare(stop) <= 0 {
+		errs = append(errs, fmt.Errorf("gateway %s is inside the DHCP range %s-%s", n.Gateway, start, stop))
+	}
+	return errs
+}
+
+// bounds returns the network and broadcast addresses of an IPv4 prefix.
+func bounds(p netip.Prefix) (network, broadcast netip.Addr) {
+	network = p.Masked().Addr()
+	b := network.As4()
+	host := uint32(1)<<(32-p.Bits()) - 1
+	v := uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+	v |= host
+	return network, netip.AddrFrom4([4]byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)})
+}
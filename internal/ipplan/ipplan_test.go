@@ -0,0 +1,138 @@
+package ipplan
+
+import (
+	"strings"
+	"testing"
+)
+
+func mustParse(t *testing.T, id, name string, vlan int, gw string, prefix int, start, stop string) Network {
+	t.Helper()
+	n, err := Parse(id, name, vlan, gw, prefix, start, stop)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return n
+}
+
+func TestParse(t *testing.T) {
+	n := mustParse(t, "net-1", "IoT", 10, "192.168.10.1", 24, "192.168.10.100", "192.168.10.200")
+	if n.Subnet.String() != "192.168.10.0/24" {
+		t.Errorf("Subnet = %s", n.Subnet)
+	}
+	for _, tc := range []struct {
+		gw     string
+		prefix int
+		start  string
+	}{
+		{"not-an-ip", 24, ""},
+		{"fe80::1", 64, ""},
+		{"10.0.0.1", 40, ""},
+		{"10.0.0.1", 24, "10.0.0"},
+	} {
+		if _, err := Parse("x", "x", 0, tc.gw, tc.prefix, tc.start, ""); err == nil {
+			t.Errorf("Parse(%q, %d, %q): expected error", tc.gw, tc.prefix, tc.start)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	existing := []Network{
+		mustParse(t, "net-default", "Default", 0, "192.168.1.1", 24, "192.168.1.6", "192.168.1.254"),
+		mustParse(t, "net-iot", "IoT", 10, "192.168.10.1", 24, "", ""),
+		mustParse(t, "net-sw", "Switch VLAN", 40, "", 0, "", ""),
+	}
+
+	tests := []struct {
+		name      string
+		candidate Network
+		want      []string // substrings; nil means valid
+	}{
+		{
+			name:      "valid new network",
+			candidate: mustParse(t, "", "Guest", 30, "10.30.0.1", 24, "10.30.0.10", "10.30.0.250"),
+		},
+		{
+			name:      "update keeps own VLAN and subnet",
+			candidate: mustParse(t, "net-iot", "IoT", 10, "192.168.10.1", 24, "192.168.10.50", "192.168.10.99"),
+		},
+		{
+			name:      "duplicate VLAN",
+			candidate: mustParse(t, "", "Cams", 10, "10.50.0.1", 24, "", ""),
+			want:      []string{`VLAN ID 10 is already used by network "IoT"`},
+		},
+		{
+			name:      "untagged clashes with default",
+			candidate: mustParse(t, "", "Flat", 1, "10.60.0.1", 24, "", ""),
+			want:      []string{`VLAN ID 1 is already used by network "Default"`},
+		},
+		{
+			name:      "duplicate VLAN of switch-managed network",
+			candidate: mustParse(t, "", "Lab", 40, "10.40.0.1", 24, "", ""),
+			want:      []string{`"Switch VLAN"`},
+		},
+		{
+			name:      "overlapping subnet",
+			candidate: mustParse(t, "", "Big", 20, "192.168.0.1", 16, "", ""),
+			want:      []string{"overlaps 192.168.1.0/24", "overlaps 192.168.10.0/24"},
+		},
+		{
+			name:      "DHCP range outside subnet",
+			candidate: mustParse(t, "", "Guest", 30, "10.30.0.1", 24, "10.30.0.10", "10.30.1.10"),
+			want:      []string{"not within the usable addresses", "10.30.1.10"},
+		},
+		{
+			name:      "DHCP range includes broadcast",
+			candidate: mustParse(t, "", "Guest", 30, "10.30.0.1", 24, "10.30.0.10", "10.30.0.255"),
+			want:      []string{"not within the usable addresses"},
+		},
+		{
+			name:      "DHCP range reversed",
+			candidate: mustParse(t, "", "Guest", 30, "10.30.0.1", 24, "10.30.0.200", "10.30.0.10"),
+			want:      []string{"is after stop"},
+		},
+		{
+			name:      "gateway inside DHCP range",
+			candidate: mustParse(t, "", "Guest", 30, "10.30.0.100", 24, "10.30.0.10", "10.30.0.200"),
+			want:      []string{"gateway 10.30.0.100 is inside the DHCP range"},
+		},
+		{
+			name:      "gateway is network address",
+			candidate: mustParse(t, "", "Guest", 30, "10.30.0.0", 24, "", ""),
+			want:      []string{"network or broadcast address"},
+		},
+		{
+			name:      "half DHCP range",
+			candidate: mustParse(t, "", "Guest", 30, "10.30.0.1", 24, "10.30.0.10", ""),
+			want:      []string{"both a start and a stop"},
+		},
+		{
+			name:      "VLAN out of range",
+			candidate: mustParse(t, "", "Guest", 4095, "10.30.0.1", 24, "", ""),
+			want:      []string{"out of range (2-4094)"},
+		},
+		{
+			name:      "prefix too long",
+			candidate: mustParse(t, "", "P2P", 31, "10.31.0.1", 31, "", ""),
+			want:      []string{"/31 is out of range"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Check(tc.candidate, existing)
+			if tc.want == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error containing %q", tc.want)
+			}
+			for _, w := range tc.want {
+				if !strings.Contains(err.Error(), w) {
+					t.Errorf("error %q does not contain %q", err, w)
+				}
+			}
+		})
+	}
+}
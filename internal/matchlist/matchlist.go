@@ -0,0 +1,315 @@
+// Package matchlist parses, normalizes and edits the entries of traffic
+// matching lists: IP lists of IPv4/IPv6 addresses, CIDRs and ranges, and port
+// lists of single ports and ranges.
+//
+// Every operation works on the set of values the entries cover, so the result
+// is canonical: duplicates disappear, overlapping and adjacent entries merge,
+// and each merged span is written as the most compact form available — an
+// address or port, a CIDR, or an a-b range.
+package matchlist
+
+import (
+	"cmp"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Kind selects how entries are parsed.
+type Kind int
+
+// Supported kinds.
+const (
+	IP Kind = iota
+	Port
+)
+
+// Normalize parses entries and returns them in canonical form: IPv4 spans
+// first, then IPv6, each sorted.
+func Normalize(kind Kind, entries []string) ([]string, error) {
+	switch kind {
+	case IP:
+		spans, err := parseIPs(entries)
+		if err != nil {
+			return nil, err
+		}
+		return formatIPs(mergeIPs(spans)), nil
+	case Port:
+		spans, err := parsePorts(entries)
+		if err != nil {
+			return nil, err
+		}
+		return formatPorts(mergePorts(spans)), nil
+	default:
+		return nil, fmt.Errorf("unknown kind %d", kind)
+	}
+}
+
+// Add returns current with add merged in, normalized.
+func Add(kind Kind, current, add []string) ([]string, error) {
+	if _, err := Normalize(kind, add); err != nil {
+		return nil, err
+	}
+	return Normalize(kind, append(slices.Clone(current), add...))
+}
+
+// Remove returns current without any value covered by remove, normalized.
+// Removing part of a CIDR or range splits it.
+func Remove(kind Kind, current, remove []string) ([]string, error) {
+	switch kind {
+	case IP:
+		cur, err := parseIPs(current)
+		if err != nil {
+			return nil, fmt.Errorf("current entries: %w", err)
+		}
+		rm, err := parseIPs(remove)
+		if err != nil {
+			return nil, err
+		}
+		return formatIPs(subtractIPs(mergeIPs(cur), mergeIPs(rm))), nil
+	case Port:
+		cur, err := parsePorts(current)
+		if err != nil {
+			return nil, fmt.Errorf("current entries: %w", err)
+		}
+		rm, err := parsePorts(remove)
+		if err != nil {
+			return nil, err
+		}
+		return formatPorts(subtractPorts(mergePorts(cur), mergePorts(rm))), nil
+	default:
+		return nil, fmt.Errorf("unknown kind %d", kind)
+	}
+}
+
+// ── IP spans ────────────────────────────────────────────────────────────────
+
+// ipSpan is an inclusive range of addresses of one family.
+type ipSpan struct{ lo, hi netip.Addr }
+
+func parseIPs(entries []string) ([]ipSpan, error) {
+	spans := make([]ipSpan, 0, len(entries))
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		s, err := parseIP(e)
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, s)
+	}
+	return spans, nil
+}
+
+// Span parses one IP entry — an address, CIDR or a-b range — and returns the
+// inclusive range of addresses it covers.
+func Span(entry string) (lo, hi netip.Addr, err error) {
+	s, err := parseIP(strings.TrimSpace(entry))
+	return s.lo, s.hi, err
+}
+
+func parseIP(e string) (ipSpan, error) {
+	if lo, hi, ok := strings.Cut(e, "-"); ok {
+		a, err1 := netip.ParseAddr(strings.TrimSpace(lo))
+		b, err2 := netip.ParseAddr(strings.TrimSpace(hi))
+		if err1 != nil || err2 != nil {
+			return ipSpan{}, fmt.Errorf("%q is not an address range", e)
+		}
+		a, b = a.Unmap().WithZone(""), b.Unmap().WithZone("")
+		if a.Is4() != b.Is4() {
+			return ipSpan{}, fmt.Errorf("range %q mixes IPv4 and IPv6", e)
+		}
+		if b.Less(a) {
+			return ipSpan{}, fmt.Errorf("range %q ends before it starts", e)
+		}
+		return ipSpan{a, b}, nil
+	}
+	if strings.Contains(e, "/") {
+		p, err := netip.ParsePrefix(e)
+		if err != nil {
+			return ipSpan{}, fmt.Errorf("%q is not a CIDR", e)
+		}
+		if p.Addr().Is4In6() && p.Bits() >= 96 {
+			p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+		}
+		p = p.Masked()
+		return ipSpan{p.Addr(), lastAddr(p)}, nil
+	}
+	a, err := netip.ParseAddr(e)
+	if err != nil {
+		return ipSpan{}, fmt.Errorf("%q is not an IP address, CIDR or range", e)
+	}
+	a = a.Unmap().WithZone("")
+	return ipSpan{a, a}, nil
+}
+
+// lastAddr returns the highest address in p.
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Masked().Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+	a, _ := netip.AddrFromSlice(b)
+	return a
+}
+
+// mergeIPs sorts spans and merges overlapping and adjacent ones.
+func mergeIPs(spans []ipSpan) []ipSpan {
+	slices.SortFunc(spans, func(a, b ipSpan) int {
+		return cmp.Or(a.lo.Compare(b.lo), a.hi.Compare(b.hi))
+	})
+	var out []ipSpan
+	for _, s := range spans {
+		if n := len(out); n > 0 && out[n-1].lo.Is4() == s.lo.Is4() {
+			last := &out[n-1]
+			next := last.hi.Next()
+			if !next.IsValid() || !next.Less(s.lo) {
+				if last.hi.Less(s.hi) {
+					last.hi = s.hi
+				}
+				continue
+			}
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// subtractIPs returns the parts of cur (merged) not covered by rm (merged).
+func subtractIPs(cur, rm []ipSpan) []ipSpan {
+	var out []ipSpan
+	for _, c := range cur {
+		pieces := []ipSpan{c}
+		for _, r := range rm {
+			var next []ipSpan
+			for _, p := range pieces {
+				if p.lo.Is4() != r.lo.Is4() || r.hi.Less(p.lo) || p.hi.Less(r.lo) {
+					next = append(next, p)
+					continue
+				}
+				if p.lo.Less(r.lo) {
+					next = append(next, ipSpan{p.lo, r.lo.Prev()})
+				}
+				if r.hi.Less(p.hi) {
+					next = append(next, ipSpan{r.hi.Next(), p.hi})
+				}
+			}
+			pieces = next
+		}
+		out = append(out, pieces...)
+	}
+	return out
+}
+
+func formatIPs(spans []ipSpan) []string {
+	// IPv4 sorts before IPv6 in netip order already.
+	out := make([]string, 0, len(spans))
+	for _, s := range spans {
+		out = append(out, formatIP(s))
+	}
+	return out
+}
+
+// formatIP writes s as an address, a CIDR when it is exactly one, or a range.
+func formatIP(s ipSpan) string {
+	if s.lo == s.hi {
+		return s.lo.String()
+	}
+	for bits := 0; bits <= s.lo.BitLen(); bits++ {
+		p := netip.PrefixFrom(s.lo, bits)
+		if p.Masked().Addr() == s.lo && lastAddr(p) == s.hi {
+			return p.Masked().String()
+		}
+	}
+	return s.lo.String() + "-" + s.hi.String()
+}
+
+// ── Port spans ──────────────────────────────────────────────────────────────
+
+type portSpan struct{ lo, hi int }
+
+func parsePorts(entries []string) ([]portSpan, error) {
+	spans := make([]portSpan, 0, len(entries))
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(e, "-")
+		if !isRange {
+			hi = lo
+		}
+		a, err1 := parsePort(lo)
+		b, err2 := parsePort(hi)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("%q is not a port (1-65535) or port range", e)
+		}
+		if b < a {
+			return nil, fmt.Errorf("port range %q ends before it starts", e)
+		}
+		spans = append(spans, portSpan{a, b})
+	}
+	return spans, nil
+}
+
+func parsePort(s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 1 || n > 65535 {
+		return 0, fmt.Errorf("invalid port %q", s)
+	}
+	return n, nil
+}
+
+func mergePorts(spans []portSpan) []portSpan {
+	slices.SortFunc(spans, func(a, b portSpan) int { return cmp.Or(a.lo-b.lo, a.hi-b.hi) })
+	var out []portSpan
+	for _, s := range spans {
+		if n := len(out); n > 0 && s.lo <= out[n-1].hi+1 {
+			out[n-1].hi = max(out[n-1].hi, s.hi)
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func subtractPorts(cur, rm []portSpan) []portSpan {
+	var out []portSpan
+	for _, c := range cur {
+		pieces := []portSpan{c}
+		for _, r := range rm {
+			var next []portSpan
+			for _, p := range pieces {
+				if r.hi < p.lo || p.hi < r.lo {
+					next = append(next, p)
+					continue
+				}
+				if p.lo < r.lo {
+					next = append(next, portSpan{p.lo, r.lo - 1})
+				}
+				if r.hi < p.hi {
+					next = append(next, portSpan{r.hi + 1, p.hi})
+				}
+			}
+			pieces = next
+		}
+		out = append(out, pieces...)
+	}
+	return out
+}
+
+func formatPorts(spans []portSpan) []string {
+	out := make([]string, 0, len(spans))
+	for _, s := range spans {
+		if s.lo == s.hi {
+			out = append(out, strconv.Itoa(s.lo))
+		} else {
+			out = append(out, fmt.Sprintf("%d-%d", s.lo, s.hi))
+		}
+	}
+	return out
+}
@@ -0,0 +1,172 @@
+package matchlist
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    Kind
+		entries []string
+		want    []string
+	}{
+		{
+			name:    "dedupe and sort",
+			kind:    IP,
+			entries: []string{"10.0.0.2", " 10.0.0.1 ", "10.0.0.2", ""},
+			want:    []string{"10.0.0.1-10.0.0.2"},
+		},
+		{
+			name:    "host bits masked",
+			kind:    IP,
+			entries: []string{"192.168.1.77/24"},
+			want:    []string{"192.168.1.0/24"},
+		},
+		{
+			name:    "contained CIDR collapses",
+			kind:    IP,
+			entries: []string{"10.1.2.0/24", "10.0.0.0/8", "10.9.9.9"},
+			want:    []string{"10.0.0.0/8"},
+		},
+		{
+			name:    "adjacent CIDRs merge to supernet",
+			kind:    IP,
+			entries: []string{"192.168.0.0/24", "192.168.1.0/24"},
+			want:    []string{"192.168.0.0/23"},
+		},
+		{
+			name:    "range equal to CIDR",
+			kind:    IP,
+			entries: []string{"172.16.0.0-172.16.0.255"},
+			want:    []string{"172.16.0.0/24"},
+		},
+		{
+			name:    "range overlapping CIDR",
+			kind:    IP,
+			entries: []string{"10.0.0.200-10.0.1.10", "10.0.0.0/24"},
+			want:    []string{"10.0.0.0-10.0.1.10"},
+		},
+		{
+			name:    "ipv6 and mapped ipv4",
+			kind:    IP,
+			entries: []string{"2001:DB8::/32", "2001:db8:1::1", "::ffff:10.0.0.1"},
+			want:    []string{"10.0.0.1", "2001:db8::/32"},
+		},
+		{
+			name:    "ports",
+			kind:    Port,
+			entries: []string{"443", "80", "8000-8080", "8080-8090", "81", "443"},
+			want:    []string{"80-81", "443", "8000-8090"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Normalize(tc.kind, tc.entries)
+			if err != nil {
+				t.Fatalf("Normalize: %v", err)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		kind  Kind
+		entry string
+		want  string
+	}{
+		{"bad address", IP, "10.0.0.256", "not an IP address"},
+		{"bad CIDR", IP, "10.0.0.0/33", "not a CIDR"},
+		{"reversed range", IP, "10.0.0.9-10.0.0.1", "ends before it starts"},
+		{"mixed family range", IP, "10.0.0.1-::1", "mixes IPv4 and IPv6"},
+		{"port zero", Port, "0", "not a port"},
+		{"port too big", Port, "65536", "not a port"},
+		{"reversed port range", Port, "90-80", "ends before it starts"},
+		{"port word", Port, "http", "not a port"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Normalize(tc.kind, []string{tc.entry})
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got %v, want error containing %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestAddRemove(t *testing.T) {
+	got, err := Add(IP, []string{"10.0.0.0/25"}, []string{"10.0.0.128/25", "192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"10.0.0.0/24", "192.0.2.1"}; !slices.Equal(got, want) {
+		t.Errorf("Add = %v, want %v", got, want)
+	}
+
+	if _, err := Add(IP, []string{"10.0.0.0/24"}, []string{"nope"}); err == nil {
+		t.Error("Add with invalid entry: expected error")
+	}
+
+	got, err = Remove(IP, []string{"10.0.0.0/24", "192.0.2.1"}, []string{"10.0.0.0/25", "192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"10.0.0.128/25"}; !slices.Equal(got, want) {
+		t.Errorf("Remove = %v, want %v", got, want)
+	}
+
+	got, err = Remove(IP, []string{"10.0.0.0/24"}, []string{"10.0.0.10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"10.0.0.0-10.0.0.9", "10.0.0.11-10.0.0.255"}; !slices.Equal(got, want) {
+		t.Errorf("Remove split = %v, want %v", got, want)
+	}
+
+	got, err = Remove(Port, []string{"1-1024"}, []string{"22", "1000-2000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"1-21", "23-999"}; !slices.Equal(got, want) {
+		t.Errorf("Remove ports = %v, want %v", got, want)
+	}
+
+	got, err = Remove(IP, []string{"::/0"}, []string{"0.0.0.0/0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"::/0"}; !slices.Equal(got, want) {
+		t.Errorf("Remove other family = %v, want %v", got, want)
+	}
+}
+
+func TestSpan(t *testing.T) {
+	tests := []struct {
+		entry  string
+		lo, hi string
+	}{
+		{"10.0.0.5", "10.0.0.5", "10.0.0.5"},
+		{"10.0.0.77/24", "10.0.0.0", "10.0.0.255"},
+		{"::ffff:10.0.0.1", "10.0.0.1", "10.0.0.1"},
+		{" 10.0.0.1 - 10.0.0.9 ", "10.0.0.1", "10.0.0.9"},
+		{"2001:db8::/127", "2001:db8::", "2001:db8::1"},
+	}
+	for _, tc := range tests {
+		lo, hi, err := Span(tc.entry)
+		if err != nil || lo.String() != tc.lo || hi.String() != tc.hi {
+			t.Errorf("Span(%q) = %v, %v, %v; want %s, %s", tc.entry, lo, hi, err, tc.lo, tc.hi)
+		}
+	}
+	for _, bad := range []string{"10.0.0.9-10.0.0.1", "10.0.0.1-::1", "nope", "10.0.0.0/33"} {
+		if _, _, err := Span(bad); err == nil {
+			t.Errorf("Span(%q): expected error", bad)
+		}
+	}
+}
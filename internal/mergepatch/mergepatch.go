@@ -0,0 +1,133 @@
+// Package mergepatch implements JSON Merge Patch (RFC 7396) and a structural
+// diff of two JSON documents. Both work on raw JSON so that members and
+// numbers pass through byte for byte.
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Apply returns target with patch merged in per RFC 7396: object members in
+// patch replace those in target recursively, null members delete, and any
+// non-object patch replaces target outright.
+func Apply(target, patch []byte) ([]byte, error) {
+	if !json.Valid(patch) {
+		return nil, fmt.Errorf("patch is not valid JSON")
+	}
+	if len(target) > 0 && !json.Valid(target) {
+		return nil, fmt.Errorf("target is not valid JSON")
+	}
+	return apply(target, patch)
+}
+
+func apply(target, patch []byte) ([]byte, error) {
+	p, ok := asObject(patch)
+	if !ok {
+		return compact(patch), nil
+	}
+	t, ok := asObject(target)
+	if !ok {
+		t = make(map[string]json.RawMessage)
+	}
+	for k, v := range p {
+		if isNull(v) {
+			delete(t, k)
+			continue
+		}
+		merged, err := apply(t[k], v)
+		if err != nil {
+			return nil, err
+		}
+		t[k] = merged
+	}
+	return json.Marshal(t)
+}
+
+// Change is one difference between two documents. Path is a JSON Pointer
+// (RFC 6901); Old is absent for added members and New for removed ones.
+type Change struct {
+	Path string          `json:"path"`
+	Old  json.RawMessage `json:"old,omitempty"`
+	New  json.RawMessage `json:"new,omitempty"`
+}
+
+// Diff returns the changes that turn before into after, sorted by path.
+// Objects are compared member by member; arrays and scalars are compared as
+// whole values.
+func Diff(before, after []byte) ([]Change, error) {
+	if !json.Valid(before) || !json.Valid(after) {
+		return nil, fmt.Errorf("diff: invalid JSON")
+	}
+	var out []Change
+	diff("", before, after, &out)
+	slices.SortFunc(out, func(a, b Change) int { return strings.Compare(a.Path, b.Path) })
+	return out, nil
+}
+
+func diff(path string, before, after []byte, out *[]Change) {
+	b, bok := asObject(before)
+	a, aok := asObject(after)
+	if !bok || !aok {
+		if !bytes.Equal(compact(before), compact(after)) {
+			*out = append(*out, Change{Path: path, Old: nonEmpty(before), New: nonEmpty(after)})
+		}
+		return
+	}
+	for k, bv := range b {
+		p := path + "/" + escape(k)
+		if av, ok := a[k]; ok {
+			diff(p, bv, av, out)
+		} else {
+			*out = append(*out, Change{Path: p, Old: compact(bv)})
+		}
+	}
+	for k, av := range a {
+		if _, ok := b[k]; !ok {
+			*out = append(*out, Change{Path: path + "/" + escape(k), New: compact(av)})
+		}
+	}
+}
+
+// TopLevel returns the first segment of a JSON Pointer produced by Diff,
+// unescaped, or "" for the document root.
+func TopLevel(path string) string {
+	seg, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(seg)
+}
+
+func asObject(data []byte) (map[string]json.RawMessage, bool) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, false
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &m); err != nil {
+		return nil, false
+	}
+	return m, true
+}
+
+func isNull(data []byte) bool { return string(bytes.TrimSpace(data)) == "null" }
+
+func compact(data []byte) json.RawMessage {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return json.RawMessage(bytes.TrimSpace(data))
+	}
+	return buf.Bytes()
+}
+
+func nonEmpty(data []byte) json.RawMessage {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	return compact(data)
+}
+
+func escape(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
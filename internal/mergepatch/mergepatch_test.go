@@ -0,0 +1,114 @@
+package mergepatch
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestApply runs the examples from RFC 7396 Appendix A.
+func TestApply(t *testing.T) {
+	cases := []struct {
+		target, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.target+"+"+tc.patch, func(t *testing.T) {
+			got, err := Apply([]byte(tc.target), []byte(tc.patch))
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			assertJSONEqual(t, got, tc.want)
+		})
+	}
+
+	t.Run("preserves large numbers", func(t *testing.T) {
+		got, err := Apply([]byte(`{"n":9007199254740993,"m":1}`), []byte(`{"m":2}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(got, &m); err != nil {
+			t.Fatal(err)
+		}
+		if string(m["n"]) != "9007199254740993" {
+			t.Errorf("n = %s", m["n"])
+		}
+	})
+
+	t.Run("invalid patch", func(t *testing.T) {
+		if _, err := Apply([]byte(`{}`), []byte(`{`)); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestDiff(t *testing.T) {
+	before := `{"name":"a","keep":1,"nested":{"x":1,"y":[1,2]},"gone":true,"a/b":0}`
+	after := `{"name":"b","keep":1,"nested":{"x":1,"y":[1,3],"z":"new"},"a/b":1}`
+	got, err := Diff([]byte(before), []byte(after))
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	want := []Change{
+		{Path: "/a~1b", Old: json.RawMessage(`0`), New: json.RawMessage(`1`)},
+		{Path: "/gone", Old: json.RawMessage(`true`)},
+		{Path: "/name", Old: json.RawMessage(`"a"`), New: json.RawMessage(`"b"`)},
+		{Path: "/nested/y", Old: json.RawMessage(`[1,2]`), New: json.RawMessage(`[1,3]`)},
+		{Path: "/nested/z", New: json.RawMessage(`"new"`)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+
+	t.Run("identical", func(t *testing.T) {
+		got, err := Diff([]byte(`{"a":{"b":1}}`), []byte(`{ "a": { "b": 1 } }`))
+		if err != nil || len(got) != 0 {
+			t.Errorf("got %v, %v; want no changes", got, err)
+		}
+	})
+}
+
+func TestTopLevel(t *testing.T) {
+	cases := map[string]string{
+		"/name":     "name",
+		"/nested/y": "nested",
+		"/a~1b/c":   "a/b",
+		"/t~0ilde":  "t~ilde",
+		"":          "",
+	}
+	for in, want := range cases {
+		if got := TopLevel(in); got != want {
+			t.Errorf("TopLevel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func assertJSONEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("result %s is not JSON: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
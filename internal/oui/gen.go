@@ -0,0 +1,72 @@
+//go:build ignore
+
+// gen.go regenerates table.go from oui.csv. Run it via go generate ./internal/oui.
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	f, err := os.Open("oui.csv")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	if _, err := r.Read(); err != nil { // header
+		log.Fatalf("read header: %v", err)
+	}
+
+	vendors := make(map[uint32]string)
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("read oui.csv: %v", err)
+		}
+		if len(rec) < 3 || rec[0] != "MA-L" {
+			continue
+		}
+		p, err := strconv.ParseUint(strings.TrimSpace(rec[1]), 16, 24)
+		if err != nil {
+			log.Fatalf("bad assignment %q: %v", rec[1], err)
+		}
+		vendors[uint32(p)] = strings.Join(strings.Fields(rec[2]), " ")
+	}
+
+	prefixes := make([]uint32, 0, len(vendors))
+	for p := range vendors {
+		prefixes = append(prefixes, p)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return prefixes[i] < prefixes[j] })
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen.go from oui.csv; DO NOT EDIT.\n\npackage oui\n\n")
+	buf.WriteString("// table is sorted by prefix for binary search.\nvar table = [...]entry{\n")
+	for _, p := range prefixes {
+		fmt.Fprintf(&buf, "\t{0x%06X, %q},\n", p, vendors[p])
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("format: %v", err)
+	}
+	if err := os.WriteFile("table.go", src, 0o600); err != nil {
+		log.Fatal(err)
+	}
+}
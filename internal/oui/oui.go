@@ -0,0 +1,100 @@
+// Package oui maps MAC addresses to the organisation that registered their
+// IEEE Organizationally Unique Identifier (OUI). The registry is compiled into
+// the binary, so lookups work offline.
+//
+// oui.csv is the full IEEE MA-L registry as published on 24 October 2025,
+// in the layout of https://standards-oui.ieee.org/oui/oui.csv with the
+// organisation addresses left out. MA-M and MA-S (28- and 36-bit) blocks are
+// not split out: an address in one reports the MA-L holder, usually "IEEE
+// Registration Authority". To refresh the table, replace oui.csv with a new
+// download and run go generate ./internal/oui.
+package oui
+
+//go:generate go run gen.go
+
+import (
+	"encoding/hex"
+	"errors"
+	"net"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidMAC is returned by ParseMAC when the input is not a 48-bit MAC address.
+var ErrInvalidMAC = errors.New("invalid MAC address")
+
+// Info describes a MAC address: its normalised form, the registered vendor (if
+// any), and the flags carried in the first octet.
+type Info struct {
+	MAC    string `json:"macAddress"`
+	OUI    string `json:"oui"`
+	Vendor string `json:"vendor,omitempty"`
+	// LocallyAdministered is set when the U/L bit is 1: the address was assigned
+	// by software rather than burned in by a manufacturer, so no vendor applies.
+	LocallyAdministered bool `json:"locallyAdministered"`
+	Multicast           bool `json:"multicast"`
+	// Randomized is set for locally administered unicast addresses, which is how
+	// iOS, Android, Windows and macOS private-address features present themselves.
+	Randomized bool `json:"randomized"`
+}
+
+// entry is one row of the generated registry table.
+type entry struct {
+	prefix uint32 // 24-bit OUI
+	vendor string
+}
+
+// ParseMAC parses a 48-bit MAC address written with colons, hyphens, Cisco
+// dots, or no separators at all.
+func ParseMAC(s string) (net.HardwareAddr, error) {
+	s = strings.TrimSpace(s)
+	if hw, err := net.ParseMAC(s); err == nil && len(hw) == 6 {
+		return hw, nil
+	}
+	if len(s) == 12 {
+		if b, err := hex.DecodeString(s); err == nil {
+			return net.HardwareAddr(b), nil
+		}
+	}
+	return nil, ErrInvalidMAC
+}
+
+// Lookup returns the vendor registered for the OUI of mac. ok is false when the
+// address is invalid, locally administered, or not in the registry.
+func Lookup(mac string) (vendor string, ok bool) {
+	hw, err := ParseMAC(mac)
+	if err != nil || hw[0]&0x02 != 0 {
+		return "", false
+	}
+	return lookupPrefix(uint32(hw[0])<<16 | uint32(hw[1])<<8 | uint32(hw[2]))
+}
+
+// Describe returns the full Info for mac.
+func Describe(mac string) (Info, error) {
+	hw, err := ParseMAC(mac)
+	if err != nil {
+		return Info{}, err
+	}
+	info := Info{
+		MAC:                 hw.String(),
+		OUI:                 strings.ToUpper(hex.EncodeToString(hw[:3])),
+		LocallyAdministered: hw[0]&0x02 != 0,
+		Multicast:           hw[0]&0x01 != 0,
+	}
+	info.Randomized = info.LocallyAdministered && !info.Multicast
+	if !info.LocallyAdministered {
+		info.Vendor, _ = lookupPrefix(uint32(hw[0])<<16 | uint32(hw[1])<<8 | uint32(hw[2]))
+	}
+	return info, nil
+}
+
+// Len returns the number of OUIs in the compiled registry.
+func Len() int { return len(table) }
+
+func lookupPrefix(p uint32) (string, bool) {
+	i := sort.Search(len(table), func(i int) bool { return table[i].prefix >= p })
+	if i < len(table) && table[i].prefix == p {
+		return table[i].vendor, true
+	}
+	return "", false
+}
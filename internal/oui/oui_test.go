@@ -0,0 +1,142 @@
+package oui
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestParseMAC(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"B8:27:EB:12:34:56", "b8:27:eb:12:34:56", true},
+		{"b8-27-eb-12-34-56", "b8:27:eb:12:34:56", true},
+		{"b827.eb12.3456", "b8:27:eb:12:34:56", true},
+		{"B827EB123456", "b8:27:eb:12:34:56", true},
+		{"  b8:27:eb:12:34:56 ", "b8:27:eb:12:34:56", true},
+		{"b8:27:eb:12:34", "", false},
+		{"not-a-mac", "", false},
+		{"00:00:00:00:fe:80:00:00:00:00:00:00:02:00:5e:10:00:00:00:01", "", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			hw, err := ParseMAC(tc.in)
+			if !tc.ok {
+				if !errors.Is(err, ErrInvalidMAC) {
+					t.Errorf("got err %v, want ErrInvalidMAC", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMAC: %v", err)
+			}
+			if hw.String() != tc.want {
+				t.Errorf("got %q, want %q", hw.String(), tc.want)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	t.Run("known vendor", func(t *testing.T) {
+		vendor, ok := Lookup("b8:27:eb:00:00:01")
+		if !ok || vendor != "Raspberry Pi Foundation" {
+			t.Errorf("got %q, %v; want Raspberry Pi Foundation, true", vendor, ok)
+		}
+	})
+
+	t.Run("first and last table entries", func(t *testing.T) {
+		for _, e := range []entry{table[0], table[len(table)-1]} {
+			mac := net.HardwareAddr{byte(e.prefix >> 16), byte(e.prefix >> 8), byte(e.prefix), 0, 0, 1}
+			got, ok := Lookup(mac.String())
+			if !ok || got != e.vendor {
+				t.Errorf("prefix %06X: got %q, %v; want %q", e.prefix, got, ok, e.vendor)
+			}
+		}
+	})
+
+	t.Run("unknown OUI", func(t *testing.T) {
+		// 00:08:33 is not assigned in the registry snapshot.
+		if _, ok := Lookup("00:08:33:00:00:00"); ok {
+			t.Error("expected no match")
+		}
+	})
+
+	t.Run("table holds the full MA-L registry", func(t *testing.T) {
+		// The registry had over 38,000 MA-L assignments in 2025; a much
+		// smaller table means oui.csv was replaced by a subset.
+		if len(table) < 35000 {
+			t.Errorf("table has %d OUIs, want the full registry", len(table))
+		}
+	})
+
+	t.Run("locally administered address has no vendor", func(t *testing.T) {
+		// 0xBA has the U/L bit set; the same bytes with 0xB8 are Raspberry Pi.
+		if v, ok := Lookup("ba:27:eb:00:00:01"); ok {
+			t.Errorf("got vendor %q for locally administered MAC", v)
+		}
+	})
+
+	t.Run("table is sorted", func(t *testing.T) {
+		for i := 1; i < len(table); i++ {
+			if table[i-1].prefix >= table[i].prefix {
+				t.Fatalf("table not strictly sorted at %d: %06X >= %06X", i, table[i-1].prefix, table[i].prefix)
+			}
+		}
+	})
+}
+
+func TestDescribe(t *testing.T) {
+	cases := []struct {
+		name       string
+		mac        string
+		vendor     string
+		local      bool
+		multicast  bool
+		randomized bool
+	}{
+		{"universal unicast", "F0:9F:C2:11:22:33", "Ubiquiti Inc", false, false, false},
+		{"randomized private address", "da:a1:19:00:11:22", "", true, false, true},
+		{"multicast", "01:00:5e:00:00:fb", "", false, true, false},
+		{"locally administered multicast", "33:33:00:00:00:01", "", true, true, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			info, err := Describe(tc.mac)
+			if err != nil {
+				t.Fatalf("Describe: %v", err)
+			}
+			if info.Vendor != tc.vendor {
+				t.Errorf("Vendor: got %q, want %q", info.Vendor, tc.vendor)
+			}
+			if info.LocallyAdministered != tc.local {
+				t.Errorf("LocallyAdministered: got %v, want %v", info.LocallyAdministered, tc.local)
+			}
+			if info.Multicast != tc.multicast {
+				t.Errorf("Multicast: got %v, want %v", info.Multicast, tc.multicast)
+			}
+			if info.Randomized != tc.randomized {
+				t.Errorf("Randomized: got %v, want %v", info.Randomized, tc.randomized)
+			}
+		})
+	}
+
+	t.Run("normalises and reports OUI", func(t *testing.T) {
+		info, err := Describe("F09F.C211.2233")
+		if err != nil {
+			t.Fatalf("Describe: %v", err)
+		}
+		if info.MAC != "f0:9f:c2:11:22:33" || info.OUI != "F09FC2" {
+			t.Errorf("got MAC %q OUI %q", info.MAC, info.OUI)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := Describe("zz"); !errors.Is(err, ErrInvalidMAC) {
+			t.Errorf("got %v, want ErrInvalidMAC", err)
+		}
+	})
+}
@@ -0,0 +1,198 @@
+// Package passphrase generates WPA personal passphrases from crypto/rand,
+// either as random characters or as words drawn from a wordlist.
+package passphrase
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Character-style defaults and bounds. WPA passphrases are 8-63 characters;
+// the lower bound here is higher because a guest SSID is exposed to anyone in
+// range for a month at a time.
+const (
+	DefaultChars = 20
+	MinChars     = 12
+	MaxChars     = 63
+)
+
+// MinEntropyBits is the least strength either style may have: that of a
+// MinChars-character passphrase, rounded down.
+const MinEntropyBits = 69
+
+// Word-style bounds. The default word count is the smallest that reaches
+// MinEntropyBits with the wordlist in use: 9 words from the built-in 256.
+const (
+	MaxWords = 12
+	// MinWordlist is the smallest custom wordlist accepted; fewer words make
+	// each one worth too little entropy.
+	MinWordlist = 64
+	// maxAttempts bounds how often a word passphrase longer than MaxChars is
+	// drawn again before giving up.
+	maxAttempts = 100
+)
+
+// charset is the alphabet for character-style passphrases. Look-alike
+// characters (0/O, 1/l/I) are left out so a passphrase read off a printout or
+// screen can still be typed.
+const charset = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+//go:embed words.txt
+var builtinWords string
+
+// Style selects how a passphrase is built.
+type Style string
+
+// Supported styles.
+const (
+	StyleChars Style = "chars"
+	StyleWords Style = "words"
+)
+
+// Options configures Generate. The zero value yields a DefaultChars-character
+// passphrase.
+type Options struct {
+	Style Style
+	// Length is the number of characters (StyleChars) or words (StyleWords).
+	// Zero selects the style's default.
+	Length int
+	// Wordlist replaces the built-in list for StyleWords.
+	Wordlist []string
+	// Separator joins words; empty means "-".
+	Separator string
+}
+
+// Result is a generated passphrase and its strength.
+type Result struct {
+	Passphrase string
+	// EntropyBits is the strength of the generation method, assuming an
+	// attacker knows the style, length and wordlist.
+	EntropyBits float64
+}
+
+// Generate returns a new passphrase built as opts describes.
+func Generate(opts Options) (Result, error) {
+	switch opts.Style {
+	case "", StyleChars:
+		return generateChars(opts.Length)
+	case StyleWords:
+		return generateWords(opts)
+	default:
+		return Result{}, fmt.Errorf("unknown style %q (use %s or %s)", opts.Style, StyleChars, StyleWords)
+	}
+}
+
+func generateChars(n int) (Result, error) {
+	if n == 0 {
+		n = DefaultChars
+	}
+	if n < MinChars || n > MaxChars {
+		return Result{}, fmt.Errorf("length must be %d-%d characters", MinChars, MaxChars)
+	}
+	b := make([]byte, n)
+	for i := range b {
+		j, err := randIndex(len(charset))
+		if err != nil {
+			return Result{}, err
+		}
+		b[i] = charset[j]
+	}
+	return Result{
+		Passphrase:  string(b),
+		EntropyBits: float64(n) * math.Log2(float64(len(charset))),
+	}, nil
+}
+
+func generateWords(opts Options) (Result, error) {
+	sep := opts.Separator
+	if sep == "" {
+		sep = "-"
+	}
+	for _, r := range sep {
+		if r < ' ' || r > '~' {
+			return Result{}, fmt.Errorf("separator %q must be printable ASCII", sep)
+		}
+	}
+	words := opts.Wordlist
+	if len(words) == 0 {
+		words = strings.Fields(builtinWords)
+	} else {
+		var err error
+		if words, err = cleanWordlist(words); err != nil {
+			return Result{}, err
+		}
+	}
+	bitsPerWord := math.Log2(float64(len(words)))
+	n := opts.Length
+	if n == 0 {
+		n = int(math.Ceil(MinEntropyBits / bitsPerWord))
+	}
+	if n > MaxWords {
+		return Result{}, fmt.Errorf("length must be at most %d words", MaxWords)
+	}
+	if bits := float64(n) * bitsPerWord; bits < MinEntropyBits {
+		return Result{}, fmt.Errorf("%d words from a %d-word list give %.0f bits of entropy; at least %d words are required",
+			n, len(words), bits, int(math.Ceil(MinEntropyBits/bitsPerWord)))
+	}
+	// A draw longer than WPA allows is discarded and drawn again. Rejecting
+	// the rare long combinations costs a negligible fraction of a bit.
+	picked := make([]string, n)
+	for range maxAttempts {
+		for i := range picked {
+			j, err := randIndex(len(words))
+			if err != nil {
+				return Result{}, err
+			}
+			picked[i] = words[j]
+		}
+		if p := strings.Join(picked, sep); len(p) <= MaxChars {
+			return Result{
+				Passphrase:  p,
+				EntropyBits: float64(n) * bitsPerWord,
+			}, nil
+		}
+	}
+	return Result{}, fmt.Errorf("%d words joined by %q rarely fit in %d characters; use fewer words, shorter words or a shorter separator",
+		n, sep, MaxChars)
+}
+
+// cleanWordlist trims and de-duplicates words and checks that enough remain
+// and that each is printable ASCII without spaces.
+func cleanWordlist(words []string) ([]string, error) {
+	seen := make(map[string]bool, len(words))
+	out := make([]string, 0, len(words))
+	for _, w := range words {
+		w = strings.TrimSpace(w)
+		if w == "" || seen[w] {
+			continue
+		}
+		for _, r := range w {
+			if r <= ' ' || r > '~' {
+				return nil, fmt.Errorf("wordlist entry %q must be printable ASCII without spaces", w)
+			}
+		}
+		seen[w] = true
+		out = append(out, w)
+	}
+	if len(out) < MinWordlist {
+		return nil, fmt.Errorf("wordlist has %d distinct words; at least %d are required", len(out), MinWordlist)
+	}
+	return out, nil
+}
+
+// randIndex returns a uniformly random integer in [0, n).
+func randIndex(n int) (int, error) {
+	if n <= 0 {
+		return 0, errors.New("empty alphabet")
+	}
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("read random: %w", err)
+	}
+	return int(v.Int64()), nil
+}
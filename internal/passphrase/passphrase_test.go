@@ -0,0 +1,127 @@
+package passphrase
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Run("chars default", func(t *testing.T) {
+		r, err := Generate(Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(r.Passphrase) != DefaultChars {
+			t.Errorf("len = %d, want %d", len(r.Passphrase), DefaultChars)
+		}
+		for _, c := range r.Passphrase {
+			if !strings.ContainsRune(charset, c) {
+				t.Errorf("unexpected character %q", c)
+			}
+		}
+		if r.EntropyBits < 100 {
+			t.Errorf("EntropyBits = %.1f, want >= 100", r.EntropyBits)
+		}
+	})
+
+	t.Run("chars differ between calls", func(t *testing.T) {
+		a, _ := Generate(Options{Length: 32})
+		b, _ := Generate(Options{Length: 32})
+		if a.Passphrase == b.Passphrase {
+			t.Error("two generated passphrases are equal")
+		}
+	})
+
+	t.Run("words builtin", func(t *testing.T) {
+		r, err := Generate(Options{Style: StyleWords, Length: 10, Separator: "."})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(strings.Split(r.Passphrase, ".")); n != 10 {
+			t.Errorf("got %d words in %q, want 10", n, r.Passphrase)
+		}
+		if r.EntropyBits != 80 {
+			t.Errorf("EntropyBits = %v, want 80 (10 words from 256)", r.EntropyBits)
+		}
+	})
+
+	t.Run("words default reaches the minimum", func(t *testing.T) {
+		for range 200 {
+			r, err := Generate(Options{Style: StyleWords})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := len(strings.Split(r.Passphrase, "-")); n != 9 || r.EntropyBits < MinEntropyBits {
+				t.Fatalf("%q: %d words, %.1f bits", r.Passphrase, n, r.EntropyBits)
+			}
+			if len(r.Passphrase) > MaxChars {
+				t.Fatalf("%q is longer than %d", r.Passphrase, MaxChars)
+			}
+		}
+	})
+
+	t.Run("words space separator", func(t *testing.T) {
+		r, err := Generate(Options{Style: StyleWords, Separator: " "})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(strings.Fields(r.Passphrase)); n != 9 {
+			t.Errorf("got %d words in %q, want 9", n, r.Passphrase)
+		}
+	})
+
+	t.Run("words custom list", func(t *testing.T) {
+		list := make([]string, MinWordlist)
+		for i := range list {
+			list[i] = fmt.Sprintf("w%d", i)
+		}
+		r, err := Generate(Options{Style: StyleWords, Wordlist: list})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.EntropyBits != 72 {
+			t.Errorf("EntropyBits = %v, want 72 (12 words from 64)", r.EntropyBits)
+		}
+		for _, w := range strings.Split(r.Passphrase, "-") {
+			if !strings.HasPrefix(w, "w") {
+				t.Errorf("word %q not from custom list", w)
+			}
+		}
+	})
+
+	errCases := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"chars too short", Options{Length: 8}, "length must be"},
+		{"chars too long", Options{Length: 64}, "length must be"},
+		{"too few words", Options{Style: StyleWords, Length: 6}, "at least 9 words are required"},
+		{"too many words", Options{Style: StyleWords, Length: 13}, "at most 12 words"},
+		{"control separator", Options{Style: StyleWords, Separator: "\n"}, "printable ASCII"},
+		{"non-ASCII separator", Options{Style: StyleWords, Separator: "·"}, "printable ASCII"},
+		{"unknown style", Options{Style: "emoji"}, "unknown style"},
+		{"small wordlist", Options{Style: StyleWords, Wordlist: []string{"a", "b", "a"}}, "2 distinct words"},
+		{"bad word", Options{Style: StyleWords, Wordlist: []string{"two words"}}, "printable ASCII"},
+		{"over 63 chars", Options{Style: StyleWords, Length: 12, Separator: "----"}, "rarely fit in 63"},
+	}
+	for _, tc := range errCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Generate(tc.opts)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got %v, want error containing %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuiltinWordlist(t *testing.T) {
+	words := strings.Fields(builtinWords)
+	if len(words) != 256 {
+		t.Errorf("builtin wordlist has %d words, want 256", len(words))
+	}
+	if _, err := cleanWordlist(words); err != nil {
+		t.Errorf("builtin wordlist: %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+// Package quarantine records which clients have been quarantined with a
+// blocking ACL rule, and the ACL ordering that was in force beforehand so the
+// release can put it back exactly.
+package quarantine
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/oui"
+	"github.com/gordcurrie/unifi-mcp/internal/statefile"
+)
+
+// RulePrefix starts the name of every ACL rule created by quarantine, so the
+// rules are recognisable in the controller UI and in list_acl_rules output.
+const RulePrefix = "[quarantine] "
+
+// ErrNotFound is returned when a MAC address is not quarantined on a site.
+var ErrNotFound = errors.New("client not quarantined")
+
+// ErrAlreadyQuarantined is returned by Add when the MAC already has a record
+// on the same site.
+var ErrAlreadyQuarantined = errors.New("client already quarantined")
+
+// Record is one quarantined client.
+type Record struct {
+	MAC        string `json:"macAddress"`
+	SiteID     string `json:"siteId,omitempty"`
+	ClientID   string `json:"clientId,omitempty"`
+	ClientName string `json:"clientName,omitempty"`
+	RuleID     string `json:"ruleId"`
+	Reason     string `json:"reason,omitempty"`
+	// OriginalOrdering is the ACL rule ordering immediately before the
+	// quarantine rule was inserted.
+	OriginalOrdering []string  `json:"originalOrdering"`
+	QuarantinedAt    time.Time `json:"quarantinedAt"`
+}
+
+// Store is a file-backed set of quarantine records keyed by site and
+// normalised MAC, so the same client quarantined on two sites has two
+// records. All methods are safe for concurrent use; every mutation is
+// written to disk before it returns.
+type Store struct {
+	path string
+
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+// Open loads the records at path, or starts an empty store if the file does
+// not exist yet. Records are re-keyed from their own site and MAC, so files
+// written when records were keyed by MAC alone still load.
+func Open(path string) (*Store, error) {
+	var loaded map[string]Record
+	if _, err := statefile.Load(path, &loaded); err != nil {
+		return nil, fmt.Errorf("open quarantine: %w", err)
+	}
+	s := &Store{path: path, records: make(map[string]Record, len(loaded))}
+	for _, rec := range loaded {
+		mac, err := normalize(rec.MAC)
+		if err != nil {
+			return nil, fmt.Errorf("open quarantine: %w", err)
+		}
+		rec.MAC = mac
+		s.records[recordKey(rec.SiteID, mac)] = rec
+	}
+	return s, nil
+}
+
+// Add stores rec, normalising its MAC address. It fails with
+// ErrAlreadyQuarantined if the MAC already has a record on rec.SiteID.
+func (s *Store) Add(rec Record) (Record, error) {
+	mac, err := normalize(rec.MAC)
+	if err != nil {
+		return Record{}, err
+	}
+	rec.MAC = mac
+	rec.OriginalOrdering = slices.Clone(rec.OriginalOrdering)
+	key := recordKey(rec.SiteID, mac)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.records[key]; ok {
+		return Record{}, fmt.Errorf("%s: %w", mac, ErrAlreadyQuarantined)
+	}
+	s.records[key] = rec
+	if err := s.saveLocked(); err != nil {
+		delete(s.records, key)
+		return Record{}, err
+	}
+	return rec, nil
+}
+
+// Get returns the record for mac on siteID.
+func (s *Store) Get(siteID, mac string) (Record, error) {
+	mac, err := normalize(mac)
+	if err != nil {
+		return Record{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[recordKey(siteID, mac)]
+	if !ok {
+		return Record{}, fmt.Errorf("%s: %w", mac, ErrNotFound)
+	}
+	return rec, nil
+}
+
+// Remove deletes the record for mac on siteID.
+func (s *Store) Remove(siteID, mac string) error {
+	mac, err := normalize(mac)
+	if err != nil {
+		return err
+	}
+	key := recordKey(siteID, mac)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[key]
+	if !ok {
+		return fmt.Errorf("%s: %w", mac, ErrNotFound)
+	}
+	delete(s.records, key)
+	if err := s.saveLocked(); err != nil {
+		s.records[key] = rec
+		return err
+	}
+	return nil
+}
+
+// List returns every record sorted by quarantine time, oldest first.
+func (s *Store) List() []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Record, 0, len(s.records))
+	for _, rec := range s.records {
+		out = append(out, rec)
+	}
+	slices.SortFunc(out, func(a, b Record) int {
+		return cmp.Or(a.QuarantinedAt.Compare(b.QuarantinedAt), cmp.Compare(a.MAC, b.MAC), cmp.Compare(a.SiteID, b.SiteID))
+	})
+	return out
+}
+
+// Placement says where release puts ACL rules that were created while a
+// client was quarantined. There is no safe default: a new rule's position
+// changes what traffic it sees, so the caller must choose.
+type Placement string
+
+// Placements accepted by RestoreOrdering.
+const (
+	// PlacementNone refuses to restore when rules were created since.
+	PlacementNone Placement = ""
+	// PlacementFirst puts the new rules, in their current order, before the
+	// restored ones.
+	PlacementFirst Placement = "first"
+	// PlacementLast puts the new rules, in their current order, after the
+	// restored ones.
+	PlacementLast Placement = "last"
+)
+
+// NewRulesError is returned by RestoreOrdering when rules were created since
+// the quarantine and no placement was given for them.
+type NewRulesError struct {
+	IDs []string
+}
+
+// Error implements the error interface.
+func (e *NewRulesError) Error() string {
+	return fmt.Sprintf("%d ACL rules were created during the quarantine (%s); choose whether they go first or last",
+		len(e.IDs), strings.Join(e.IDs, ", "))
+}
+
+// RestoreOrdering computes the ordering to apply on release from the ordering
+// saved at quarantine time and the current one (without the quarantine rule).
+// Rules in original that still exist keep exactly their original order, and
+// rules deleted since are dropped. Rules created since (in current but not
+// original) are placed as place says; with PlacementNone their presence is a
+// *NewRulesError.
+func RestoreOrdering(original, current []string, place Placement) ([]string, error) {
+	present := make(map[string]bool, len(current))
+	for _, id := range current {
+		present[id] = true
+	}
+	seen := make(map[string]bool, len(original))
+	var kept, added []string
+	for _, id := range original {
+		if present[id] && !seen[id] {
+			kept = append(kept, id)
+			seen[id] = true
+		}
+	}
+	for _, id := range current {
+		if !seen[id] {
+			added = append(added, id)
+			seen[id] = true
+		}
+	}
+	if len(added) == 0 {
+		return append([]string{}, kept...), nil
+	}
+	switch place {
+	case PlacementFirst:
+		return append(added, kept...), nil
+	case PlacementLast:
+		return append(kept, added...), nil
+	case PlacementNone:
+		return nil, &NewRulesError{IDs: added}
+	default:
+		return nil, fmt.Errorf("unknown placement %q (use first or last)", place)
+	}
+}
+
+// saveLocked persists the records. The caller must hold s.mu.
+func (s *Store) saveLocked() error {
+	if err := statefile.Save(s.path, s.records); err != nil {
+		return fmt.Errorf("save quarantine: %w", err)
+	}
+	return nil
+}
+
+// recordKey is the map and file key of a record.
+func recordKey(siteID, mac string) string { return siteID + "/" + mac }
+
+func normalize(mac string) (string, error) {
+	hw, err := oui.ParseMAC(mac)
+	if err != nil {
+		return "", fmt.Errorf("%q: %w", mac, err)
+	}
+	return hw.String(), nil
+}
@@ -0,0 +1,167 @@
+package quarantine
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/oui"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "quarantine.json"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return s
+}
+
+func TestStore(t *testing.T) {
+	t.Run("add get remove", func(t *testing.T) {
+		s := newTestStore(t)
+		rec, err := s.Add(Record{MAC: "AA-BB-CC-00-00-01", SiteID: "s-1", RuleID: "ar-1", OriginalOrdering: []string{"ar-0"}})
+		if err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+		if rec.MAC != "aa:bb:cc:00:00:01" {
+			t.Errorf("MAC not normalised: %q", rec.MAC)
+		}
+		got, err := s.Get("s-1", "aabb.cc00.0001")
+		if err != nil || got.RuleID != "ar-1" {
+			t.Fatalf("Get: %+v, %v", got, err)
+		}
+		if err := s.Remove("s-1", "aa:bb:cc:00:00:01"); err != nil {
+			t.Fatalf("Remove: %v", err)
+		}
+		if _, err := s.Get("s-1", "aa:bb:cc:00:00:01"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get after Remove: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("duplicate add", func(t *testing.T) {
+		s := newTestStore(t)
+		if _, err := s.Add(Record{MAC: "aa:bb:cc:00:00:01", SiteID: "s-1", RuleID: "ar-1"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Add(Record{MAC: "aa:bb:cc:00:00:01", SiteID: "s-1", RuleID: "ar-2"}); !errors.Is(err, ErrAlreadyQuarantined) {
+			t.Errorf("got %v, want ErrAlreadyQuarantined", err)
+		}
+	})
+
+	t.Run("same MAC on two sites", func(t *testing.T) {
+		s := newTestStore(t)
+		for _, site := range []string{"s-1", "s-2"} {
+			if _, err := s.Add(Record{MAC: "aa:bb:cc:00:00:01", SiteID: site, RuleID: "ar-" + site}); err != nil {
+				t.Fatalf("Add on %s: %v", site, err)
+			}
+		}
+		if got, err := s.Get("s-2", "aa:bb:cc:00:00:01"); err != nil || got.RuleID != "ar-s-2" {
+			t.Fatalf("Get s-2: %+v, %v", got, err)
+		}
+		if err := s.Remove("s-1", "aa:bb:cc:00:00:01"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Get("s-2", "aa:bb:cc:00:00:01"); err != nil {
+			t.Errorf("removing the s-1 record removed s-2's: %v", err)
+		}
+	})
+
+	t.Run("remove unknown", func(t *testing.T) {
+		s := newTestStore(t)
+		if err := s.Remove("s-1", "aa:bb:cc:00:00:01"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("invalid MAC", func(t *testing.T) {
+		s := newTestStore(t)
+		if _, err := s.Add(Record{MAC: "nope"}); !errors.Is(err, oui.ErrInvalidMAC) {
+			t.Errorf("got %v, want ErrInvalidMAC", err)
+		}
+	})
+
+	t.Run("persists across reopen", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "quarantine.json")
+		s, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		if _, err := s.Add(Record{MAC: "aa:bb:cc:00:00:02", RuleID: "ar-2", OriginalOrdering: []string{"a", "b"}, QuarantinedAt: at}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Add(Record{MAC: "aa:bb:cc:00:00:01", RuleID: "ar-1", QuarantinedAt: at.Add(time.Hour)}); err != nil {
+			t.Fatal(err)
+		}
+		s2, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		list := s2.List()
+		if len(list) != 2 || list[0].RuleID != "ar-2" || list[1].RuleID != "ar-1" {
+			t.Fatalf("List: %+v", list)
+		}
+		if !slices.Equal(list[0].OriginalOrdering, []string{"a", "b"}) || !list[0].QuarantinedAt.Equal(at) {
+			t.Errorf("record not round-tripped: %+v", list[0])
+		}
+	})
+
+	t.Run("loads records keyed by MAC alone", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "quarantine.json")
+		legacy := `{"aa:bb:cc:00:00:01":{"macAddress":"aa:bb:cc:00:00:01","siteId":"s-1","ruleId":"ar-1","originalOrdering":[]}}`
+		if err := os.WriteFile(path, []byte(legacy), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		s, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := s.Get("s-1", "aa:bb:cc:00:00:01"); err != nil || got.RuleID != "ar-1" {
+			t.Errorf("Get: %+v, %v", got, err)
+		}
+	})
+}
+
+func TestRestoreOrdering(t *testing.T) {
+	cases := []struct {
+		name     string
+		original []string
+		current  []string
+		place    Placement
+		want     []string
+		wantNew  []string
+	}{
+		{name: "unchanged", original: []string{"a", "b", "c"}, current: []string{"a", "b", "c"}, want: []string{"a", "b", "c"}},
+		{name: "reordered since", original: []string{"a", "b", "c"}, current: []string{"c", "a", "b"}, want: []string{"a", "b", "c"}},
+		{name: "rule deleted since", original: []string{"a", "b", "c"}, current: []string{"c", "a"}, want: []string{"a", "c"}},
+		{name: "rule added since needs a placement", original: []string{"a", "b"}, current: []string{"b", "n", "a", "m"}, wantNew: []string{"n", "m"}},
+		{name: "rule added since goes first", original: []string{"a", "b"}, current: []string{"b", "n", "a"}, place: PlacementFirst, want: []string{"n", "a", "b"}},
+		{name: "rule added since goes last", original: []string{"a", "b"}, current: []string{"b", "n", "a"}, place: PlacementLast, want: []string{"a", "b", "n"}},
+		{name: "placement unused without new rules", original: []string{"a", "b"}, current: []string{"b", "a"}, place: PlacementFirst, want: []string{"a", "b"}},
+		{name: "empty original", current: []string{"x"}, place: PlacementLast, want: []string{"x"}},
+		{name: "empty", want: []string{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RestoreOrdering(tc.original, tc.current, tc.place)
+			if tc.wantNew != nil {
+				var nre *NewRulesError
+				if !errors.As(err, &nre) || !slices.Equal(nre.IDs, tc.wantNew) {
+					t.Fatalf("got %v, %v; want NewRulesError for %v", got, err, tc.wantNew)
+				}
+				return
+			}
+			if err != nil || !slices.Equal(got, tc.want) {
+				t.Errorf("got %v, %v; want %v", got, err, tc.want)
+			}
+		})
+	}
+
+	if _, err := RestoreOrdering([]string{"a"}, []string{"a", "n"}, "middle"); err == nil {
+		t.Error("unknown placement: expected error")
+	}
+}
@@ -0,0 +1,402 @@
+// Package schedule keeps one-off jobs to run at a future time — guest
+// authorizations and voucher batches — in a file-backed store, and runs them
+// when they fall due. Jobs survive restarts: a job that came due while the
+// server was down runs as soon as it is back, unless its window has closed.
+// A voucher batch is never created twice: a failed or interrupted run fails
+// the job instead of running it again.
+package schedule
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/statefile"
+)
+
+// Kind is what a job does.
+type Kind string
+
+// Job kinds.
+const (
+	// KindGuestAccess authorizes a client for guest access from Start to End.
+	KindGuestAccess Kind = "guest_access"
+	// KindVouchers generates a batch of vouchers at Start.
+	KindVouchers Kind = "vouchers"
+)
+
+// Status is where a job is in its life.
+type Status string
+
+// Job statuses. Pending is the only one that still runs.
+const (
+	Pending   Status = "pending"
+	Done      Status = "done"
+	Failed    Status = "failed"
+	Cancelled Status = "cancelled"
+	// Missed jobs had their window close before they could run.
+	Missed Status = "missed"
+)
+
+// MaxAttempts is how many times a guest_access job is tried before it fails,
+// not counting attempts that end in ErrNotReady. Authorizing a guest again is
+// harmless; a vouchers job fails on its first error, because the batch may
+// have been created even though the call failed.
+const MaxAttempts = 5
+
+// retryable reports whether a failed run of a k job may simply run again.
+func (k Kind) retryable() bool {
+	return k == KindGuestAccess
+}
+
+// ErrNotReady is returned by an Executor when a job cannot run yet, e.g.
+// because the guest's device is not connected. The job stays pending and is
+// tried again on the next check, until its window closes.
+var ErrNotReady = errors.New("not ready")
+
+// ErrNotFound is returned for an unknown job ID.
+var ErrNotFound = errors.New("scheduled job not found")
+
+// GuestAccess is the payload of a KindGuestAccess job.
+type GuestAccess struct {
+	// MAC identifies the guest's device, which need not be connected when the
+	// job is scheduled.
+	MAC                   string `json:"macAddress"`
+	ClientName            string `json:"clientName,omitempty"`
+	DataLimitMb           int    `json:"dataLimitMb,omitempty"`
+	DownloadBandwidthKbps int    `json:"downloadBandwidthKbps,omitempty"`
+	UploadBandwidthKbps   int    `json:"uploadBandwidthKbps,omitempty"`
+}
+
+// Vouchers is the payload of a KindVouchers job.
+type Vouchers struct {
+	Count            int    `json:"count"`
+	Name             string `json:"name,omitempty"`
+	TimeLimitMinutes int    `json:"timeLimitMinutes,omitempty"`
+	DataLimitMb      int    `json:"dataLimitMb,omitempty"`
+}
+
+// Job is one scheduled job.
+type Job struct {
+	ID     string `json:"id"`
+	Kind   Kind   `json:"kind"`
+	SiteID string `json:"siteId,omitempty"`
+	// Start is when the job runs. End, when set, closes its window: a job not
+	// run by then is missed.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end,omitzero"`
+	// TimeZone is the IANA zone the times were given in, for display.
+	TimeZone    string       `json:"timeZone,omitempty"`
+	GuestAccess *GuestAccess `json:"guestAccess,omitempty"`
+	Vouchers    *Vouchers    `json:"vouchers,omitempty"`
+
+	Status    Status    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Attempts counts failed runs; runs that were not ready do not count.
+	Attempts    int       `json:"attempts,omitempty"`
+	LastAttempt time.Time `json:"lastAttempt,omitzero"`
+	// InFlight is set, and saved, before the job runs and cleared with its
+	// outcome; a job still marked on the next check was interrupted.
+	InFlight   time.Time `json:"inFlight,omitzero"`
+	FinishedAt time.Time `json:"finishedAt,omitzero"`
+	// Result describes what a done job did; Error is the latest failure or
+	// reason the job is still waiting.
+	Result string `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// ResourceIDs are the IDs of what the job created, e.g. voucher IDs.
+	ResourceIDs []string `json:"resourceIds,omitempty"`
+}
+
+// Validate checks a job before it is added.
+func (j *Job) Validate() error {
+	if j.Start.IsZero() {
+		return errors.New("start time is required")
+	}
+	if !j.End.IsZero() && !j.End.After(j.Start) {
+		return errors.New("end must be after start")
+	}
+	switch j.Kind {
+	case KindGuestAccess:
+		if j.GuestAccess == nil || j.GuestAccess.MAC == "" || j.Vouchers != nil {
+			return errors.New("a guest_access job needs exactly the guest's MAC address")
+		}
+		if j.End.IsZero() {
+			return errors.New("a guest_access job needs an end time")
+		}
+	case KindVouchers:
+		if j.Vouchers == nil || j.GuestAccess != nil {
+			return errors.New("a vouchers job needs exactly the voucher batch")
+		}
+		if j.Vouchers.Count < 1 || j.Vouchers.Count > 100 {
+			return errors.New("voucher count must be 1-100")
+		}
+	default:
+		return fmt.Errorf("unknown job kind %q", j.Kind)
+	}
+	return nil
+}
+
+// timeLayouts are the forms ParseTime accepts besides RFC 3339.
+var timeLayouts = []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02T15:04:05"}
+
+// ParseTime parses s as an RFC 3339 time, or as a wall-clock time such as
+// "2026-03-10 09:00" in loc.
+func ParseTime(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use e.g. 2026-03-10 09:00 or RFC 3339)", s)
+}
+
+// Executor runs a due job and describes the outcome. It returns the IDs of
+// anything it created; ErrNotReady leaves the job pending.
+type Executor func(ctx context.Context, job Job) (result string, ids []string, err error)
+
+// Store is the file-backed set of jobs, keyed by ID. All methods are safe for
+// concurrent use; every mutation is written to disk before it returns.
+type Store struct {
+	path string
+	wake chan struct{}
+
+	mu   sync.Mutex
+	jobs map[string]Job
+}
+
+// Open loads the jobs at path, or starts an empty store if the file does not
+// exist yet.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, wake: make(chan struct{}, 1), jobs: make(map[string]Job)}
+	if _, err := statefile.Load(path, &s.jobs); err != nil {
+		return nil, fmt.Errorf("open schedule: %w", err)
+	}
+	if s.jobs == nil {
+		s.jobs = make(map[string]Job)
+	}
+	return s, nil
+}
+
+// Add validates job, gives it an ID and stores it as pending. A vouchers job
+// must start in the future: one whose start has passed would run at once.
+func (s *Store) Add(job Job, now time.Time) (Job, error) {
+	if err := job.Validate(); err != nil {
+		return Job{}, err
+	}
+	if job.Kind == KindVouchers && job.Start.Before(now) {
+		return Job{}, errors.New("start is in the past; create the vouchers now instead")
+	}
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return Job{}, fmt.Errorf("job ID: %w", err)
+	}
+	job.ID = hex.EncodeToString(b[:])
+	job.Status = Pending
+	job.CreatedAt = now
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job
+	if err := s.saveLocked(); err != nil {
+		delete(s.jobs, job.ID)
+		return Job{}, err
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Get returns the job with id.
+func (s *Store) Get(id string) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, fmt.Errorf("%s: %w", id, ErrNotFound)
+	}
+	return job, nil
+}
+
+// List returns the jobs, pending first and each group by start time. Finished
+// jobs are only included when all is set.
+func (s *Store) List(all bool) []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		if all || j.Status == Pending {
+			out = append(out, j)
+		}
+	}
+	slices.SortFunc(out, func(a, b Job) int {
+		return cmp.Or(
+			cmp.Compare(boolRank(a.Status != Pending), boolRank(b.Status != Pending)),
+			a.Start.Compare(b.Start),
+			cmp.Compare(a.ID, b.ID),
+		)
+	})
+	return out
+}
+
+// Cancel marks a pending job cancelled and returns it.
+func (s *Store) Cancel(id string, now time.Time) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, fmt.Errorf("%s: %w", id, ErrNotFound)
+	}
+	if job.Status != Pending {
+		return Job{}, fmt.Errorf("job %s is already %s", id, job.Status)
+	}
+	if !job.InFlight.IsZero() {
+		return Job{}, fmt.Errorf("job %s is running", id)
+	}
+	prev := job
+	job.Status, job.FinishedAt = Cancelled, now
+	s.jobs[id] = job
+	if err := s.saveLocked(); err != nil {
+		s.jobs[id] = prev
+		return Job{}, err
+	}
+	return job, nil
+}
+
+// RunDue runs every pending job whose start has passed, one at a time, and
+// records the outcomes. It returns the jobs it ran or closed.
+func (s *Store) RunDue(ctx context.Context, now time.Time, exec Executor) ([]Job, error) {
+	var due []Job
+	for _, j := range s.List(false) {
+		if !j.Start.After(now) {
+			due = append(due, j)
+		}
+	}
+	var errs []error
+	for i := range due {
+		j := &due[i]
+		switch {
+		case !j.End.IsZero() && !now.Before(j.End):
+			j.Status, j.FinishedAt, j.InFlight = Missed, now, time.Time{}
+		case !j.InFlight.IsZero() && !j.Kind.retryable():
+			j.Status, j.FinishedAt = Failed, now
+			j.Error = fmt.Sprintf("interrupted while running at %s; check whether it took effect before scheduling it again",
+				j.InFlight.Format(time.RFC3339))
+			j.InFlight = time.Time{}
+		default:
+			if ok, err := s.markInFlight(j, now); !ok {
+				if err != nil {
+					errs = append(errs, err)
+				}
+				continue
+			}
+			result, ids, err := exec(ctx, *j)
+			j.LastAttempt, j.InFlight = now, time.Time{}
+			switch {
+			case err == nil:
+				j.Status, j.FinishedAt, j.Result, j.ResourceIDs, j.Error = Done, now, result, ids, ""
+			case errors.Is(err, ErrNotReady):
+				j.Error = err.Error()
+			default:
+				j.Attempts++
+				j.Error = err.Error()
+				if j.Attempts >= MaxAttempts || !j.Kind.retryable() {
+					j.Status, j.FinishedAt = Failed, now
+				}
+			}
+		}
+		if err := s.update(*j); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return due, errors.Join(errs...)
+}
+
+// markInFlight records that job is about to run. It reports false, and the
+// job must not run, if the job was cancelled meanwhile or the marker could
+// not be saved.
+func (s *Store) markInFlight(job *Job, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, ok := s.jobs[job.ID]
+	if !ok || prev.Status != Pending {
+		return false, nil
+	}
+	job.InFlight = now
+	s.jobs[job.ID] = *job
+	if err := s.saveLocked(); err != nil {
+		s.jobs[job.ID] = prev
+		job.InFlight = time.Time{}
+		return false, err
+	}
+	return true, nil
+}
+
+// update stores the outcome of a run, unless the job was cancelled meanwhile.
+func (s *Store) update(job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, ok := s.jobs[job.ID]
+	if !ok || prev.Status != Pending {
+		return nil
+	}
+	s.jobs[job.ID] = job
+	if err := s.saveLocked(); err != nil {
+		s.jobs[job.ID] = prev
+		return err
+	}
+	return nil
+}
+
+// Run checks for due jobs every interval, and right away whenever a job is
+// added, until ctx is done. Retries of jobs that are not ready or failed
+// happen on these checks.
+func (s *Store) Run(ctx context.Context, interval time.Duration, exec Executor, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		ran, err := s.RunDue(ctx, time.Now(), exec)
+		if err != nil {
+			logger.Warn("scheduler", "err", err)
+		}
+		for _, j := range ran {
+			switch j.Status {
+			case Done:
+				logger.Info("scheduled job done", "id", j.ID, "kind", j.Kind, "result", j.Result)
+			case Failed, Missed:
+				logger.Warn("scheduled job "+string(j.Status), "id", j.ID, "kind", j.Kind, "err", j.Error)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// saveLocked persists the jobs. The caller must hold s.mu.
+func (s *Store) saveLocked() error {
+	if err := statefile.Save(s.path, s.jobs); err != nil {
+		return fmt.Errorf("save schedule: %w", err)
+	}
+	return nil
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
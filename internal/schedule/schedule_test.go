@@ -0,0 +1,246 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var t0 = time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+func guestJob(start, end time.Time) Job {
+	return Job{Kind: KindGuestAccess, Start: start, End: end, GuestAccess: &GuestAccess{MAC: "aa:bb:cc:00:00:01"}}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		job  Job
+		ok   bool
+	}{
+		{"guest", guestJob(t0, t0.Add(8*time.Hour)), true},
+		{"guest without end", guestJob(t0, time.Time{}), false},
+		{"end before start", guestJob(t0, t0.Add(-time.Hour)), false},
+		{"vouchers", Job{Kind: KindVouchers, Start: t0, Vouchers: &Vouchers{Count: 5}}, true},
+		{"too many vouchers", Job{Kind: KindVouchers, Start: t0, Vouchers: &Vouchers{Count: 101}}, false},
+		{"no start", Job{Kind: KindVouchers, Vouchers: &Vouchers{Count: 1}}, false},
+		{"unknown kind", Job{Kind: "reboot", Start: t0}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.job.Validate(); (err == nil) != tc.ok {
+				t.Errorf("Validate = %v, want ok=%v", err, tc.ok)
+			}
+		})
+	}
+}
+
+func TestRunDue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	add := func(j Job) string {
+		t.Helper()
+		j, err := s.Add(j, t0.Add(-24*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return j.ID
+	}
+	waiting := add(guestJob(t0, t0.Add(8*time.Hour)))
+	missed := add(guestJob(t0.Add(-10*time.Hour), t0.Add(-2*time.Hour)))
+	later := add(guestJob(t0.Add(time.Hour), t0.Add(2*time.Hour)))
+	vouchers := add(Job{Kind: KindVouchers, Start: t0.Add(-time.Minute), Vouchers: &Vouchers{Count: 2}})
+	cancelled := add(Job{Kind: KindVouchers, Start: t0, Vouchers: &Vouchers{Count: 1}})
+	if _, err := s.Cancel(cancelled, t0); err != nil {
+		t.Fatal(err)
+	}
+
+	guestConnected := false
+	exec := func(_ context.Context, j Job) (string, []string, error) {
+		switch j.Kind {
+		case KindGuestAccess:
+			if !guestConnected {
+				return "", nil, fmt.Errorf("client is not connected: %w", ErrNotReady)
+			}
+			return "authorized", nil, nil
+		default:
+			return "created 2 vouchers", []string{"v1", "v2"}, nil
+		}
+	}
+	ran, err := s.RunDue(context.Background(), t0, exec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ran) != 3 {
+		t.Errorf("ran %d jobs, want 3", len(ran))
+	}
+
+	// Reopen to check that outcomes were persisted.
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Status{waiting: Pending, missed: Missed, later: Pending, vouchers: Done, cancelled: Cancelled}
+	for id, status := range want {
+		if j, _ := s.Get(id); j.Status != status {
+			t.Errorf("job %s status = %s, want %s", id, j.Status, status)
+		}
+	}
+	if j, _ := s.Get(vouchers); len(j.ResourceIDs) != 2 {
+		t.Errorf("voucher job resource IDs = %v", j.ResourceIDs)
+	}
+	if j, _ := s.Get(waiting); j.Attempts != 0 || j.Error == "" {
+		t.Errorf("waiting job = %+v", j)
+	}
+
+	guestConnected = true
+	if _, err := s.RunDue(context.Background(), t0.Add(30*time.Minute), exec); err != nil {
+		t.Fatal(err)
+	}
+	if j, _ := s.Get(waiting); j.Status != Done || j.Result != "authorized" {
+		t.Errorf("waiting job after connect = %+v", j)
+	}
+	if got := s.List(false); len(got) != 1 || got[0].ID != later {
+		t.Errorf("pending = %+v", got)
+	}
+}
+
+func TestRunDueFailsAfterMaxAttempts(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "schedule.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := s.Add(guestJob(t0, t0.Add(8*time.Hour)), t0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := func(context.Context, Job) (string, []string, error) { return "", nil, errors.New("boom") }
+	for i := range MaxAttempts {
+		if _, err := s.RunDue(context.Background(), t0.Add(time.Duration(i)*time.Minute), exec); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := s.Get(j.ID); i < MaxAttempts-1 && got.Status != Pending {
+			t.Fatalf("after %d attempts: job = %+v", i+1, got)
+		}
+	}
+	if got, _ := s.Get(j.ID); got.Status != Failed || got.Attempts != MaxAttempts || got.Error != "boom" {
+		t.Errorf("job = %+v", got)
+	}
+}
+
+func TestVoucherJobsRunOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	add := func() string {
+		t.Helper()
+		j, err := s.Add(Job{Kind: KindVouchers, Start: t0, Vouchers: &Vouchers{Count: 1}}, t0.Add(-time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return j.ID
+	}
+
+	t.Run("a failure is not retried", func(t *testing.T) {
+		id := add()
+		calls := 0
+		exec := func(context.Context, Job) (string, []string, error) {
+			calls++
+			return "", nil, errors.New("timeout")
+		}
+		for i := range 3 {
+			if _, err := s.RunDue(context.Background(), t0.Add(time.Duration(i)*time.Minute), exec); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got, _ := s.Get(id); calls != 1 || got.Status != Failed || got.Error != "timeout" {
+			t.Errorf("%d calls, job = %+v", calls, got)
+		}
+	})
+
+	t.Run("the in-flight marker is saved before running", func(t *testing.T) {
+		id := add()
+		exec := func(context.Context, Job) (string, []string, error) {
+			onDisk, err := Open(path)
+			if err != nil {
+				return "", nil, err
+			}
+			if j, _ := onDisk.Get(id); j.InFlight.IsZero() {
+				t.Error("job ran before its in-flight marker was saved")
+			}
+			if _, err := s.Cancel(id, t0); err == nil {
+				t.Error("a running job was cancelled")
+			}
+			return "created 1 voucher", []string{"v1"}, nil
+		}
+		if _, err := s.RunDue(context.Background(), t0, exec); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := s.Get(id); got.Status != Done || !got.InFlight.IsZero() {
+			t.Errorf("job = %+v", got)
+		}
+	})
+
+	t.Run("an interrupted run fails instead of running again", func(t *testing.T) {
+		id := add()
+		// Simulate a crash mid-run: the marker is saved, the outcome never is.
+		s.mu.Lock()
+		j := s.jobs[id]
+		j.InFlight = t0
+		s.jobs[id] = j
+		if err := s.saveLocked(); err != nil {
+			t.Fatal(err)
+		}
+		s.mu.Unlock()
+		s, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exec := func(context.Context, Job) (string, []string, error) {
+			t.Error("an interrupted voucher job ran again")
+			return "", nil, nil
+		}
+		if _, err := s.RunDue(context.Background(), t0.Add(time.Minute), exec); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := s.Get(id); got.Status != Failed || !strings.Contains(got.Error, "interrupted") {
+			t.Errorf("job = %+v", got)
+		}
+	})
+
+	t.Run("start must be in the future", func(t *testing.T) {
+		_, err := s.Add(Job{Kind: KindVouchers, Start: t0, Vouchers: &Vouchers{Count: 1}}, t0.Add(time.Second))
+		if err == nil || !strings.Contains(err.Error(), "in the past") {
+			t.Errorf("got %v, want a past-start error", err)
+		}
+	})
+}
+
+func TestParseTime(t *testing.T) {
+	loc, err := time.LoadLocation("America/Toronto")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	for in, want := range map[string]time.Time{
+		"2026-03-10 09:00":          time.Date(2026, 3, 10, 13, 0, 0, 0, time.UTC),
+		"2026-03-10T09:00:30":       time.Date(2026, 3, 10, 13, 0, 30, 0, time.UTC),
+		"2026-03-10T09:00:00+01:00": time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC),
+	} {
+		got, err := ParseTime(in, loc)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("ParseTime(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseTime("next tuesday", loc); err == nil {
+		t.Error("ParseTime accepted free text")
+	}
+}
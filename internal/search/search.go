@@ -0,0 +1,149 @@
+// Package search parses search terms and scores how well a resource field
+// matches one. A term is a MAC address, an IP address, a CIDR, or free text;
+// address-shaped terms and values are compared structurally, so an IP query
+// also finds the CIDRs and ranges that contain it.
+package search
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/gordcurrie/unifi-mcp/internal/matchlist"
+	"github.com/gordcurrie/unifi-mcp/internal/oui"
+)
+
+// Match scores, highest first. An exact match on an identifier (MAC, IP, code,
+// name) outranks a containing range, which outranks a textual prefix or substring.
+const (
+	scoreExact     = 100
+	scoreContains  = 80
+	scoreInPrefix  = 70
+	scoreOverlap   = 60
+	scorePrefix    = 50
+	scoreSubstring = 30
+)
+
+// Query is a parsed search term. At most one of mac, addr or prefix is set;
+// text is always the lower-cased term.
+type Query struct {
+	text   string
+	mac    string
+	addr   netip.Addr
+	prefix netip.Prefix
+}
+
+// Parse interprets raw as a MAC address, IP address, CIDR or free text.
+func Parse(raw string) Query {
+	raw = strings.TrimSpace(raw)
+	q := Query{text: strings.ToLower(raw)}
+	if m, ok := parseMAC(raw); ok {
+		q.mac = m
+		return q
+	}
+	if a, err := netip.ParseAddr(raw); err == nil {
+		q.addr = a.Unmap().WithZone("")
+		return q
+	}
+	if p, err := netip.ParsePrefix(raw); err == nil {
+		q.prefix = p.Masked()
+	}
+	return q
+}
+
+// Kind reports how the query was interpreted: "mac", "ip", "cidr" or "text".
+func (q Query) Kind() string {
+	switch {
+	case q.mac != "":
+		return "mac"
+	case q.addr.IsValid():
+		return "ip"
+	case q.prefix.IsValid():
+		return "cidr"
+	default:
+		return "text"
+	}
+}
+
+// Score rates how well value matches the query; 0 means no match.
+func (q Query) Score(value string) int {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	// Address-shaped values are compared structurally only, so "10.0.20.1"
+	// never matches "10.0.20.15" as a text prefix.
+	if q.mac != "" {
+		if m, ok := parseMAC(value); ok {
+			if m == q.mac {
+				return scoreExact
+			}
+			return 0
+		}
+	}
+	if q.addr.IsValid() || q.prefix.IsValid() {
+		if lo, hi, isRange, ok := parseAddress(value); ok {
+			return q.scoreAddress(lo, hi, isRange)
+		}
+	}
+	v := strings.ToLower(value)
+	switch {
+	case v == q.text:
+		return scoreExact
+	case strings.HasPrefix(v, q.text):
+		return scorePrefix
+	case strings.Contains(v, q.text):
+		return scoreSubstring
+	}
+	return 0
+}
+
+// scoreAddress matches an IP or CIDR query against the inclusive range
+// [lo, hi]; isRange is false when the value was a single address.
+func (q Query) scoreAddress(lo, hi netip.Addr, isRange bool) int {
+	if q.addr.IsValid() {
+		switch {
+		case q.addr.Is4() != lo.Is4():
+			return 0
+		case !isRange && lo == q.addr:
+			return scoreExact
+		case q.addr.Compare(lo) >= 0 && q.addr.Compare(hi) <= 0:
+			return scoreContains
+		}
+		return 0
+	}
+	pLo, pHi, err := matchlist.Span(q.prefix.String())
+	if err != nil || pLo.Is4() != lo.Is4() {
+		return 0
+	}
+	if !isRange && q.prefix.Contains(lo) {
+		return scoreInPrefix
+	}
+	if lo.Compare(pHi) <= 0 && hi.Compare(pLo) >= 0 {
+		return scoreOverlap
+	}
+	return 0
+}
+
+// parseAddress parses "addr", "addr/bits" or "lo-hi" into an inclusive range.
+// isRange is false only for a single address; backwards ranges are rejected.
+func parseAddress(value string) (lo, hi netip.Addr, isRange, ok bool) {
+	lo, hi, err := matchlist.Span(value)
+	if err != nil {
+		return netip.Addr{}, netip.Addr{}, false, false
+	}
+	return lo, hi, strings.ContainsAny(value, "/-"), true
+}
+
+// parseMAC normalizes a MAC address to lower-case colon form. A term without
+// separators counts only if it has a hex letter, so a 12-digit number such as
+// a voucher code or phone number is searched as text.
+func parseMAC(s string) (string, bool) {
+	hw, err := oui.ParseMAC(s)
+	if err != nil {
+		return "", false
+	}
+	if len(s) == 12 && !strings.ContainsAny(strings.ToLower(s), "abcdef") {
+		return "", false
+	}
+	return hw.String(), true
+}
@@ -0,0 +1,72 @@
+package search
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw  string
+		kind string
+	}{
+		{"aa:bb:cc:dd:ee:ff", "mac"},
+		{"AABB.CCDD.EEFF", "mac"},
+		{"aabbccddeeff", "mac"},
+		{"00-11-22-33-44-55", "mac"},
+		{"001122334455", "text"},
+		{"10.0.20.1", "ip"},
+		{"::ffff:10.0.20.1", "ip"},
+		{"2001:db8::1", "ip"},
+		{"10.0.20.7/24", "cidr"},
+		{"living room", "text"},
+	}
+	for _, tc := range tests {
+		if got := Parse(tc.raw).Kind(); got != tc.kind {
+			t.Errorf("Parse(%q).Kind() = %q, want %q", tc.raw, got, tc.kind)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		query string
+		value string
+		want  int
+	}{
+		// MAC queries compare MAC-shaped values structurally.
+		{"AA-BB-CC-DD-EE-FF", "aa:bb:cc:dd:ee:ff", scoreExact},
+		{"aa:bb:cc:dd:ee:ff", "aa:bb:cc:dd:ee:00", 0},
+		{"aa:bb:cc", "aa:bb:cc:dd:ee:ff", scorePrefix},
+
+		// A 12-digit number is text, not a MAC.
+		{"001122334455", "001122334455", scoreExact},
+		{"001122334455", "00:11:22:33:44:55", 0},
+
+		// IP queries.
+		{"10.0.20.1", "10.0.20.1", scoreExact},
+		{"10.0.20.1", "10.0.20.15", 0},
+		{"10.0.20.1", "10.0.20.0/24", scoreContains},
+		{"10.0.20.1", "10.0.20.0-10.0.20.9", scoreContains},
+		{"10.0.20.1", "10.0.20.9-10.0.20.0", 0},
+		{"10.0.20.1", "::ffff:10.0.20.1", scoreExact},
+		{"::1", "0.0.0.0/0", 0},
+
+		// CIDR queries.
+		{"10.0.20.0/24", "10.0.20.44", scoreInPrefix},
+		{"10.0.20.0/24", "10.0.21.1", 0},
+		{"10.0.20.0/24", "10.0.0.0/16", scoreOverlap},
+		{"10.0.20.0/24", "10.0.20.250-10.0.21.5", scoreOverlap},
+		{"10.0.20.0/24", "10.0.21.5-10.0.20.250", 0},
+		{"10.0.0.0/8", "2001:db8::/32", 0},
+
+		// Free text.
+		{"office", "Office", scoreExact},
+		{"office", "Office AP", scorePrefix},
+		{"ap", "Office AP", scoreSubstring},
+		{"garage", "Office AP", 0},
+		{"office", "  ", 0},
+	}
+	for _, tc := range tests {
+		if got := Parse(tc.query).Score(tc.value); got != tc.want {
+			t.Errorf("Parse(%q).Score(%q) = %d, want %d", tc.query, tc.value, got, tc.want)
+		}
+	}
+}
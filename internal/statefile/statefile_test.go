@@ -0,0 +1,76 @@
+package statefile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	type doc struct {
+		Name  string   `json:"name"`
+		Items []string `json:"items"`
+	}
+
+	t.Run("round trip creates directory", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "nested", "state.json")
+		if err := Save(path, doc{Name: "a", Items: []string{"x", "y"}}); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		var got doc
+		ok, err := Load(path, &got)
+		if err != nil || !ok {
+			t.Fatalf("Load: ok=%v err=%v", ok, err)
+		}
+		if got.Name != "a" || len(got.Items) != 2 {
+			t.Errorf("got %+v", got)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		got := doc{Name: "unchanged"}
+		ok, err := Load(filepath.Join(t.TempDir(), "absent.json"), &got)
+		if err != nil || ok {
+			t.Fatalf("Load: ok=%v err=%v, want false, nil", ok, err)
+		}
+		if got.Name != "unchanged" {
+			t.Errorf("v modified: %+v", got)
+		}
+	})
+
+	t.Run("corrupt file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "bad.json")
+		if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		var got doc
+		if _, err := Load(path, &got); err == nil {
+			t.Error("expected decode error")
+		}
+	})
+
+	t.Run("permissions and no temp files left", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "state.json")
+		if err := Save(path, doc{}); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		if err := Save(path, doc{Name: "again"}); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		fi, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := fi.Mode().Perm(); perm != 0o600 {
+			t.Errorf("got mode %o, want 600", perm)
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 {
+			t.Errorf("got %d directory entries, want 1", len(entries))
+		}
+	})
+}
@@ -0,0 +1,406 @@
+// Package toggles keeps recurring rules that switch a WiFi broadcast or
+// firewall policy on or off on a cron schedule, e.g. "kids SSID off at 22:00",
+// in a file-backed store together with a history of their runs.
+//
+// Each check runs the rules that have come due. A rule that came due several
+// times while the server was down runs once, for its latest occurrence, and
+// when several rules come due for the same resource only the one with the
+// latest occurrence runs; the others are recorded as superseded. A failed run
+// is retried with backoff until it succeeds, MaxAttempts is reached or the
+// rule's next occurrence takes over.
+package toggles
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/cron"
+	"github.com/gordcurrie/unifi-mcp/internal/statefile"
+)
+
+// Target is the kind of resource a rule toggles.
+type Target string
+
+// Targets.
+const (
+	WiFiBroadcast  Target = "wifi_broadcast"
+	FirewallPolicy Target = "firewall_policy"
+)
+
+// Outcome is how a run ended.
+type Outcome string
+
+// Run outcomes.
+const (
+	OK Outcome = "ok"
+	// Retrying runs failed and will be tried again.
+	Retrying Outcome = "retrying"
+	// Failed runs failed for the last time; the rule waits for its next
+	// occurrence.
+	Failed Outcome = "failed"
+	// Superseded runs were skipped because another rule for the same resource
+	// came due later.
+	Superseded Outcome = "superseded"
+)
+
+// MaxAttempts is how many times one occurrence of a rule is tried.
+const MaxAttempts = 5
+
+// RetryBackoff is the wait before the first retry; it doubles each time.
+const RetryBackoff = time.Minute
+
+// HistoryLimit is how many runs are kept; older ones are dropped.
+const HistoryLimit = 500
+
+// ErrNotFound is returned for an unknown rule ID.
+var ErrNotFound = errors.New("toggle schedule not found")
+
+// Rule is one recurring toggle.
+type Rule struct {
+	ID     string `json:"id"`
+	Name   string `json:"name,omitempty"`
+	SiteID string `json:"siteId,omitempty"`
+	Target Target `json:"target"`
+	// ResourceID is the broadcast or policy ID; ResourceName is its name when
+	// the rule was made, for display.
+	ResourceID   string `json:"resourceId"`
+	ResourceName string `json:"resourceName,omitempty"`
+	// Enable is the state the rule sets.
+	Enable bool `json:"enable"`
+	// Cron is a five-field cron expression evaluated in TimeZone, an IANA
+	// zone name.
+	Cron      string    `json:"cron"`
+	TimeZone  string    `json:"timeZone"`
+	CreatedAt time.Time `json:"createdAt"`
+	NextRun   time.Time `json:"nextRun,omitzero"`
+
+	// RetryOf is the occurrence a failed run is being retried for, and
+	// RetryAt when the next attempt is due; both are zero otherwise.
+	RetryOf  time.Time `json:"retryOf,omitzero"`
+	RetryAt  time.Time `json:"retryAt,omitzero"`
+	Attempts int       `json:"attempts,omitempty"`
+
+	LastRun     time.Time `json:"lastRun,omitzero"`
+	LastOutcome Outcome   `json:"lastOutcome,omitempty"`
+	LastError   string    `json:"lastError,omitempty"`
+}
+
+// Run is one entry in the history.
+type Run struct {
+	RuleID     string `json:"ruleId"`
+	RuleName   string `json:"ruleName,omitempty"`
+	Target     Target `json:"target"`
+	ResourceID string `json:"resourceId"`
+	Enable     bool   `json:"enable"`
+	// Scheduled is the occurrence the run was for; At is when it happened.
+	Scheduled time.Time `json:"scheduled"`
+	At        time.Time `json:"at"`
+	Attempt   int       `json:"attempt,omitempty"`
+	Outcome   Outcome   `json:"outcome"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Validate checks a rule before it is added and returns its parsed schedule
+// and time zone.
+func (r *Rule) Validate() (cron.Schedule, *time.Location, error) {
+	switch r.Target {
+	case WiFiBroadcast, FirewallPolicy:
+	default:
+		return cron.Schedule{}, nil, fmt.Errorf("unknown target %q (use %s or %s)", r.Target, WiFiBroadcast, FirewallPolicy)
+	}
+	if r.ResourceID == "" {
+		return cron.Schedule{}, nil, errors.New("resource ID is required")
+	}
+	sched, err := cron.Parse(r.Cron)
+	if err != nil {
+		return cron.Schedule{}, nil, err
+	}
+	loc, err := time.LoadLocation(r.TimeZone)
+	if err != nil {
+		return cron.Schedule{}, nil, fmt.Errorf("time zone: %w", err)
+	}
+	return sched, loc, nil
+}
+
+// Executor applies a rule to its resource.
+type Executor func(ctx context.Context, rule Rule) error
+
+type state struct {
+	Rules   map[string]Rule `json:"rules"`
+	History []Run           `json:"history"`
+}
+
+// Store is the file-backed set of rules and their history. All methods are
+// safe for concurrent use; every mutation is written to disk before it
+// returns.
+type Store struct {
+	path string
+	wake chan struct{}
+
+	mu sync.Mutex
+	st state
+}
+
+// Open loads the rules at path, or starts an empty store if the file does not
+// exist yet.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, wake: make(chan struct{}, 1)}
+	if _, err := statefile.Load(path, &s.st); err != nil {
+		return nil, fmt.Errorf("open toggle schedules: %w", err)
+	}
+	if s.st.Rules == nil {
+		s.st.Rules = make(map[string]Rule)
+	}
+	return s, nil
+}
+
+// Add validates rule, gives it an ID and its first run after now, and stores
+// it.
+func (s *Store) Add(rule Rule, now time.Time) (Rule, error) {
+	sched, loc, err := rule.Validate()
+	if err != nil {
+		return Rule{}, err
+	}
+	rule.NextRun = sched.Next(now.In(loc))
+	if rule.NextRun.IsZero() {
+		return Rule{}, fmt.Errorf("cron expression %q never fires", rule.Cron)
+	}
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return Rule{}, fmt.Errorf("rule ID: %w", err)
+	}
+	rule.ID = hex.EncodeToString(b[:])
+	rule.TimeZone = loc.String()
+	rule.CreatedAt = now
+	rule.RetryOf, rule.RetryAt, rule.Attempts = time.Time{}, time.Time{}, 0
+	rule.LastRun, rule.LastOutcome, rule.LastError = time.Time{}, "", ""
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.st.Rules[rule.ID] = rule
+	if err := s.saveLocked(); err != nil {
+		delete(s.st.Rules, rule.ID)
+		return Rule{}, err
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return rule, nil
+}
+
+// Get returns the rule with id.
+func (s *Store) Get(id string) (Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rule, ok := s.st.Rules[id]
+	if !ok {
+		return Rule{}, fmt.Errorf("%s: %w", id, ErrNotFound)
+	}
+	return rule, nil
+}
+
+// List returns the rules ordered by when they next run.
+func (s *Store) List() []Rule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Rule, 0, len(s.st.Rules))
+	for _, r := range s.st.Rules {
+		out = append(out, r)
+	}
+	slices.SortFunc(out, func(a, b Rule) int {
+		return cmp.Or(a.due().Compare(b.due()), cmp.Compare(a.ID, b.ID))
+	})
+	return out
+}
+
+// Delete removes the rule with id and returns it. Its history is kept.
+func (s *Store) Delete(id string) (Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rule, ok := s.st.Rules[id]
+	if !ok {
+		return Rule{}, fmt.Errorf("%s: %w", id, ErrNotFound)
+	}
+	delete(s.st.Rules, id)
+	if err := s.saveLocked(); err != nil {
+		s.st.Rules[id] = rule
+		return Rule{}, err
+	}
+	return rule, nil
+}
+
+// History returns up to limit runs, newest first, of the rule with ruleID or
+// of all rules when it is empty. A limit of zero or less returns them all.
+func (s *Store) History(ruleID string, limit int) []Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Run
+	for i := len(s.st.History) - 1; i >= 0; i-- {
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		if r := s.st.History[i]; ruleID == "" || r.RuleID == ruleID {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// due returns when r next needs attention: its retry if one is pending and
+// comes first, its next occurrence otherwise.
+func (r *Rule) due() time.Time {
+	if !r.RetryAt.IsZero() && r.RetryAt.Before(r.NextRun) {
+		return r.RetryAt
+	}
+	return r.NextRun
+}
+
+// Check runs the rules due at now and records the outcomes. It returns the
+// runs it recorded.
+func (s *Store) Check(ctx context.Context, now time.Time, exec Executor) ([]Run, error) {
+	type dueRule struct {
+		rule       Rule
+		occurrence time.Time
+		attempt    int
+	}
+	// Collect what is due: a new occurrence takes over any retry still
+	// pending for an older one.
+	groups := map[string][]dueRule{}
+	for _, r := range s.List() {
+		var d dueRule
+		switch {
+		case !r.NextRun.IsZero() && !r.NextRun.After(now):
+			sched, loc, err := r.Validate()
+			if err != nil {
+				continue
+			}
+			occ := r.NextRun.In(loc)
+			for next := sched.Next(occ); !next.IsZero() && !next.After(now); next = sched.Next(occ) {
+				occ = next
+			}
+			d = dueRule{rule: r, occurrence: occ, attempt: 1}
+			r.NextRun = sched.Next(now.In(loc))
+		case !r.RetryAt.IsZero() && !r.RetryAt.After(now):
+			d = dueRule{rule: r, occurrence: r.RetryOf, attempt: r.Attempts + 1}
+		default:
+			continue
+		}
+		d.rule.NextRun = r.NextRun
+		key := r.SiteID + "/" + string(r.Target) + "/" + r.ResourceID
+		groups[key] = append(groups[key], d)
+	}
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var (
+		runs []Run
+		errs []error
+	)
+	for _, k := range keys {
+		group := groups[k]
+		// The latest occurrence wins; among equals, the newest rule.
+		slices.SortFunc(group, func(a, b dueRule) int {
+			return cmp.Or(b.occurrence.Compare(a.occurrence), b.rule.CreatedAt.Compare(a.rule.CreatedAt), cmp.Compare(a.rule.ID, b.rule.ID))
+		})
+		for i, d := range group {
+			r := d.rule
+			run := Run{
+				RuleID: r.ID, RuleName: r.Name, Target: r.Target, ResourceID: r.ResourceID, Enable: r.Enable,
+				Scheduled: d.occurrence, At: now, Attempt: d.attempt,
+			}
+			r.RetryOf, r.RetryAt, r.Attempts = time.Time{}, time.Time{}, 0
+			if i > 0 {
+				run.Outcome, run.Attempt = Superseded, 0
+				run.Error = "superseded by rule " + group[0].rule.ID
+			} else if err := exec(ctx, r); err != nil {
+				run.Error = err.Error()
+				retryAt := now.Add(RetryBackoff << (d.attempt - 1))
+				if d.attempt < MaxAttempts && (r.NextRun.IsZero() || retryAt.Before(r.NextRun)) {
+					run.Outcome = Retrying
+					r.RetryOf, r.RetryAt, r.Attempts = d.occurrence, retryAt, d.attempt
+				} else {
+					run.Outcome = Failed
+				}
+			} else {
+				run.Outcome = OK
+			}
+			r.LastRun, r.LastOutcome, r.LastError = now, run.Outcome, run.Error
+			runs = append(runs, run)
+			if err := s.record(r, run); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return runs, errors.Join(errs...)
+}
+
+// record stores a run and the rule's new state. A rule deleted while it ran
+// is not brought back, but the run is still recorded.
+func (s *Store) record(rule Rule, run Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, ok := s.st.Rules[rule.ID]
+	prevHistory := s.st.History
+	if ok {
+		s.st.Rules[rule.ID] = rule
+	}
+	s.st.History = append(s.st.History, run)
+	if n := len(s.st.History) - HistoryLimit; n > 0 {
+		s.st.History = slices.Clone(s.st.History[n:])
+	}
+	if err := s.saveLocked(); err != nil {
+		if ok {
+			s.st.Rules[rule.ID] = prev
+		}
+		s.st.History = prevHistory
+		return err
+	}
+	return nil
+}
+
+// Run checks for due rules every interval, and right away whenever a rule is
+// added, until ctx is done.
+func (s *Store) Run(ctx context.Context, interval time.Duration, exec Executor, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		runs, err := s.Check(ctx, time.Now(), exec)
+		if err != nil {
+			logger.Warn("toggle schedules", "err", err)
+		}
+		for _, r := range runs {
+			switch r.Outcome {
+			case OK:
+				logger.Info("toggle schedule ran", "rule", r.RuleID, "target", r.Target, "resource", r.ResourceID, "enable", r.Enable)
+			case Retrying, Failed:
+				logger.Warn("toggle schedule "+string(r.Outcome), "rule", r.RuleID, "target", r.Target, "resource", r.ResourceID, "err", r.Error)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// saveLocked persists the rules and history. The caller must hold s.mu.
+func (s *Store) saveLocked() error {
+	if err := statefile.Save(s.path, s.st); err != nil {
+		return fmt.Errorf("save toggle schedules: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package toggles
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var t0 = time.Date(2026, 3, 10, 21, 0, 0, 0, time.UTC)
+
+func openStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "toggles.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, path
+}
+
+func add(t *testing.T, s *Store, r Rule, now time.Time) Rule {
+	t.Helper()
+	if r.Target == "" {
+		r.Target = WiFiBroadcast
+	}
+	if r.ResourceID == "" {
+		r.ResourceID = "bc-1"
+	}
+	if r.TimeZone == "" {
+		r.TimeZone = "UTC"
+	}
+	r, err := s.Add(r, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		ok   bool
+	}{
+		{"ok", Rule{Target: FirewallPolicy, ResourceID: "fp-1", Cron: "0 22 * * *", TimeZone: "UTC"}, true},
+		{"unknown target", Rule{Target: "network", ResourceID: "n-1", Cron: "0 22 * * *", TimeZone: "UTC"}, false},
+		{"no resource", Rule{Target: WiFiBroadcast, Cron: "0 22 * * *", TimeZone: "UTC"}, false},
+		{"bad cron", Rule{Target: WiFiBroadcast, ResourceID: "bc-1", Cron: "22:00", TimeZone: "UTC"}, false},
+		{"bad zone", Rule{Target: WiFiBroadcast, ResourceID: "bc-1", Cron: "0 22 * * *", TimeZone: "Mars/Olympus"}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := tc.rule.Validate(); (err == nil) != tc.ok {
+				t.Errorf("Validate = %v, want ok=%v", err, tc.ok)
+			}
+		})
+	}
+}
+
+func TestCheckRunsLatestMissedOccurrenceOnce(t *testing.T) {
+	s, path := openStore(t)
+	r := add(t, s, Rule{Cron: "0 * * * *"}, t0) // hourly, first at 22:00
+	var calls int
+	exec := func(context.Context, Rule) error { calls++; return nil }
+
+	// Down from 21:00 until 01:30: four occurrences were missed.
+	runs, err := s.Check(context.Background(), t0.Add(4*time.Hour+30*time.Minute), exec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 || len(runs) != 1 || runs[0].Outcome != OK {
+		t.Fatalf("calls = %d, runs = %+v", calls, runs)
+	}
+	if want := t0.Add(4 * time.Hour); !runs[0].Scheduled.Equal(want) {
+		t.Errorf("scheduled = %s, want %s", runs[0].Scheduled, want)
+	}
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.Get(r.ID)
+	if want := t0.Add(5 * time.Hour); !got.NextRun.Equal(want) || got.LastOutcome != OK {
+		t.Errorf("rule after run = %+v, want next run %s", got, want)
+	}
+	if h := s.History("", 0); len(h) != 1 {
+		t.Errorf("history = %+v", h)
+	}
+}
+
+func TestCheckSupersedes(t *testing.T) {
+	s, _ := openStore(t)
+	off := add(t, s, Rule{Cron: "0 22 * * *", Enable: false}, t0)
+	on := add(t, s, Rule{Cron: "30 22 * * *", Enable: true}, t0)
+	other := add(t, s, Rule{Cron: "0 22 * * *", ResourceID: "bc-2"}, t0)
+
+	var applied []string
+	exec := func(_ context.Context, r Rule) error { applied = append(applied, r.ID); return nil }
+	runs, err := s.Check(context.Background(), t0.Add(2*time.Hour), exec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 3 || len(applied) != 2 {
+		t.Fatalf("runs = %+v, applied = %v", runs, applied)
+	}
+	outcomes := map[string]Outcome{}
+	for _, r := range runs {
+		outcomes[r.RuleID] = r.Outcome
+	}
+	want := map[string]Outcome{off.ID: Superseded, on.ID: OK, other.ID: OK}
+	for id, o := range want {
+		if outcomes[id] != o {
+			t.Errorf("rule %s outcome = %s, want %s", id, outcomes[id], o)
+		}
+	}
+}
+
+func TestCheckRetries(t *testing.T) {
+	s, _ := openStore(t)
+	r := add(t, s, Rule{Cron: "0 22 * * *"}, t0)
+	fail := true
+	exec := func(context.Context, Rule) error {
+		if fail {
+			return errors.New("controller unreachable")
+		}
+		return nil
+	}
+	due := t0.Add(time.Hour)
+	if runs, _ := s.Check(context.Background(), due, exec); len(runs) != 1 || runs[0].Outcome != Retrying {
+		t.Fatalf("first run = %+v", runs)
+	}
+	got, _ := s.Get(r.ID)
+	if !got.RetryAt.Equal(due.Add(RetryBackoff)) || !got.RetryOf.Equal(due) || got.Attempts != 1 {
+		t.Fatalf("rule after failure = %+v", got)
+	}
+	// Not due again before the backoff has passed.
+	if runs, _ := s.Check(context.Background(), due.Add(30*time.Second), exec); len(runs) != 0 {
+		t.Fatalf("retried early: %+v", runs)
+	}
+	if runs, _ := s.Check(context.Background(), due.Add(RetryBackoff), exec); len(runs) != 1 || runs[0].Attempt != 2 {
+		t.Fatalf("second run = %+v", runs)
+	}
+	if got, _ := s.Get(r.ID); !got.RetryAt.Equal(due.Add(3 * RetryBackoff)) {
+		t.Fatalf("backoff did not double: retry at %s", got.RetryAt)
+	}
+	fail = false
+	runs, _ := s.Check(context.Background(), due.Add(3*RetryBackoff), exec)
+	if len(runs) != 1 || runs[0].Outcome != OK || !runs[0].Scheduled.Equal(due) {
+		t.Fatalf("third run = %+v", runs)
+	}
+	if got, _ := s.Get(r.ID); !got.RetryAt.IsZero() || got.Attempts != 0 {
+		t.Errorf("retry state not cleared: %+v", got)
+	}
+}
+
+func TestCheckGivesUp(t *testing.T) {
+	s, _ := openStore(t)
+	r := add(t, s, Rule{Cron: "0 22 * * *"}, t0)
+	exec := func(context.Context, Rule) error { return errors.New("boom") }
+	now := t0.Add(time.Hour)
+	for range MaxAttempts {
+		if _, err := s.Check(context.Background(), now, exec); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := s.Get(r.ID); !got.RetryAt.IsZero() {
+			now = got.RetryAt
+		}
+	}
+	got, _ := s.Get(r.ID)
+	if got.LastOutcome != Failed || !got.RetryAt.IsZero() {
+		t.Errorf("rule = %+v", got)
+	}
+	if h := s.History(r.ID, 0); len(h) != MaxAttempts || h[0].Outcome != Failed {
+		t.Errorf("history = %+v", h)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	s, _ := openStore(t)
+	r := add(t, s, Rule{Cron: "@daily"}, t0)
+	if _, err := s.Delete(r.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Delete(r.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete = %v", err)
+	}
+	if len(s.List()) != 0 {
+		t.Error("rule still listed")
+	}
+}
@@ -15,6 +15,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/mergepatch"
 )
 
 // maxResponseBytes caps how much data we read from any single API response.
@@ -106,6 +108,11 @@ func (c *Client) site(siteID string) string {
 	return c.siteID
 }
 
+// SiteID returns the site a call with siteID acts on: siteID itself, or the
+// default site when it is empty. Local state that is kept per site is keyed
+// by it, so "" and the default site's ID name the same site.
+func (c *Client) SiteID(siteID string) string { return c.site(siteID) }
+
 // get performs a GET request to the given path (relative to baseURL).
 func (c *Client) get(ctx context.Context, path string) ([]byte, error) {
 	return c.do(ctx, http.MethodGet, path, nil)
@@ -153,6 +160,51 @@ func (c *Client) put(ctx context.Context, path string, body any) ([]byte, error)
 	return c.do(ctx, http.MethodPut, path, body)
 }
 
+// patchV1 fetches the JSON object at path, drops the read-only id and metadata
+// members, merges patch into it as an RFC 7396 merge patch, and PUTs the
+// result back. Every member the patch does not mention is sent exactly as
+// received — including ones the Go types do not model, nested settings, and
+// numbers too large for float64 — so a toggle cannot reset settings such as a
+// WiFi passphrase or schedule. It returns the PUT response body.
+func (c *Client) patchV1(ctx context.Context, path string, patch any) ([]byte, error) {
+	return c.patchV1Func(ctx, path, func(map[string]json.RawMessage) any { return patch })
+}
+
+// patchV1Func is patchV1 for patches that depend on the current object:
+// build receives its members (without id and metadata) and returns the patch.
+func (c *Client) patchV1Func(ctx context.Context, path string, build func(current map[string]json.RawMessage) any) ([]byte, error) {
+	raw, err := c.get(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("get: %w", err)
+	}
+	body, err := decodeV1[map[string]json.RawMessage](raw)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	if body == nil {
+		return nil, fmt.Errorf("decode: response is not a JSON object")
+	}
+	delete(body, "id")
+	delete(body, "metadata")
+	current, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+	p, err := json.Marshal(build(body))
+	if err != nil {
+		return nil, fmt.Errorf("encode patch: %w", err)
+	}
+	merged, err := mergepatch.Apply(current, p)
+	if err != nil {
+		return nil, fmt.Errorf("merge: %w", err)
+	}
+	updated, err := c.put(ctx, path, json.RawMessage(merged))
+	if err != nil {
+		return nil, fmt.Errorf("put: %w", err)
+	}
+	return updated, nil
+}
+
 // delete performs a DELETE request to the given path.
 func (c *Client) delete(ctx context.Context, path string) error {
 	_, err := c.do(ctx, http.MethodDelete, path, nil)
@@ -184,6 +184,48 @@ func TestGetWithQuery(t *testing.T) {
 	}
 }
 
+func TestPatchV1(t *testing.T) {
+	cases := []struct {
+		name    string
+		getBody string
+		wantErr bool
+	}{
+		{"object", `{"id":"x","enabled":false,"keep":{"n":1}}`, false},
+		{"null body", `null`, true},
+		{"array body", `[]`, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var put map[string]json.RawMessage
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if r.Method == http.MethodPut {
+					_ = json.NewDecoder(r.Body).Decode(&put)
+					_, _ = w.Write([]byte(`{}`))
+					return
+				}
+				_, _ = w.Write([]byte(tc.getBody))
+			})
+			_, err := client.patchV1(context.Background(), "/integration/v1/x", map[string]any{"enabled": true, "added": "v"})
+			if tc.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("patchV1: %v", err)
+			}
+			if string(put["enabled"]) != "true" || string(put["added"]) != `"v"` || string(put["keep"]) != `{"n":1}` {
+				t.Errorf("PUT body = %v", put)
+			}
+			if _, ok := put["id"]; ok {
+				t.Error("PUT body should not contain id")
+			}
+		})
+	}
+}
+
 func TestPathTraversalRejection(t *testing.T) {
 	cases := []struct {
 		name    string
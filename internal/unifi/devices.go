@@ -50,6 +50,21 @@ func (c *Client) RestartDevice(ctx context.Context, siteID, deviceID string) err
 	return nil
 }
 
+// UpgradeDevice sends an UPGRADE action via POST /integration/v1/sites/{siteID}/devices/{deviceID}/actions,
+// which starts a firmware upgrade to the latest release the controller offers for the device.
+// Pass an empty siteID to use the client default.
+func (c *Client) UpgradeDevice(ctx context.Context, siteID, deviceID string) error {
+	id := c.site(siteID)
+	_, err := c.postWithBody(ctx,
+		fmt.Sprintf("/integration/v1/sites/%s/devices/%s/actions", url.PathEscape(id), url.PathEscape(deviceID)),
+		deviceActionRequest{Action: "UPGRADE"},
+	)
+	if err != nil {
+		return fmt.Errorf("UpgradeDevice %s %s: %w", id, deviceID, err)
+	}
+	return nil
+}
+
 // PowerCyclePort power-cycles a single PoE port on a switch via
 // POST /integration/v1/sites/{siteID}/devices/{deviceID}/interfaces/ports/{portIdx}/actions.
 // Pass an empty siteID to use the client default.
@@ -65,6 +80,21 @@ func (c *Client) PowerCyclePort(ctx context.Context, siteID, deviceID string, po
 	return nil
 }
 
+// AdoptDevice adopts a pending device into a site via POST /integration/v1/sites/{siteID}/devices
+// and returns the adopted device. Pass an empty siteID to use the client default.
+func (c *Client) AdoptDevice(ctx context.Context, siteID, mac string) (Device, error) {
+	id := c.site(siteID)
+	data, err := c.postWithBody(ctx, fmt.Sprintf("/integration/v1/sites/%s/devices", url.PathEscape(id)), adoptDeviceRequest{MAC: mac})
+	if err != nil {
+		return Device{}, fmt.Errorf("AdoptDevice %s %s: %w", id, mac, err)
+	}
+	dev, err := decodeV1[Device](data)
+	if err != nil {
+		return Device{}, fmt.Errorf("AdoptDevice %s %s: %w", id, mac, err)
+	}
+	return dev, nil
+}
+
 // ListPendingDevices returns devices visible on the network but not yet adopted from
 // GET /integration/v1/pending-devices. This endpoint is not site-scoped.
 // offset and limit control pagination; 0 means use the API default.
@@ -38,6 +38,25 @@ func TestListDevices(t *testing.T) {
 		}
 	})
 
+	t.Run("accepts the interfaces kind list", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"data": []map[string]any{
+					{"id": "dev-1", "macAddress": "aa:bb:cc:dd:ee:01", "state": "ONLINE", "interfaces": []string{"ports", "radios"}},
+				},
+				"totalCount": 1,
+			})
+		})
+		devices, err := client.ListDevices(context.Background(), "", 0, 0)
+		if err != nil {
+			t.Fatalf("ListDevices: %v", err)
+		}
+		if len(devices.Data) != 1 || devices.Data[0].ID != "dev-1" || devices.Data[0].Interfaces != nil {
+			t.Errorf("got %+v, want dev-1 without interface details", devices.Data)
+		}
+	})
+
 	t.Run("returns error on non-2xx", func(t *testing.T) {
 		client := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
 			http.Error(w, "error", http.StatusInternalServerError)
@@ -70,6 +89,32 @@ func TestGetDevice(t *testing.T) {
 		}
 	})
 
+	t.Run("decodes ports", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id": "sw-1", "macAddress": "aa:bb:cc:00:00:10", "state": "ONLINE",
+				"interfaces": {"ports": [
+					{"idx": 1, "state": "UP", "connector": "RJ45", "speedMbps": 1000, "maxSpeedMbps": 1000,
+					 "poe": {"standard": "802.3at", "type": 2, "enabled": true, "state": "UP", "powerW": 6.5}},
+					{"idx": 9, "state": "DOWN", "connector": "SFP", "maxSpeedMbps": 1000}
+				], "radios": []}}`))
+		})
+		dev, err := client.GetDevice(context.Background(), "", "sw-1")
+		if err != nil {
+			t.Fatalf("GetDevice: %v", err)
+		}
+		if dev.Interfaces == nil || len(dev.Interfaces.Ports) != 2 {
+			t.Fatalf("interfaces = %+v", dev.Interfaces)
+		}
+		p := dev.Interfaces.Ports[0]
+		if p.Idx != 1 || p.SpeedMbps != 1000 || p.PoE == nil || !p.PoE.Enabled || p.PoE.PowerW == nil || *p.PoE.PowerW != 6.5 {
+			t.Errorf("port 1 = %+v, poe %+v", p, p.PoE)
+		}
+		if dev.Interfaces.Ports[1].PoE != nil {
+			t.Errorf("SFP port has PoE: %+v", dev.Interfaces.Ports[1].PoE)
+		}
+	})
+
 	t.Run("returns error on non-2xx", func(t *testing.T) {
 		client := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
 			http.Error(w, "error", http.StatusInternalServerError)
@@ -115,6 +160,76 @@ func TestRestartDevice(t *testing.T) {
 	})
 }
 
+func TestUpgradeDevice(t *testing.T) {
+	t.Run("posts upgrade action", func(t *testing.T) {
+		var gotAction string
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/integration/v1/sites/test-site-id/devices/dev-1/actions" || r.Method != http.MethodPost {
+				http.Error(w, "unexpected", http.StatusBadRequest)
+				return
+			}
+			var req deviceActionRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "bad body", http.StatusBadRequest)
+				return
+			}
+			gotAction = req.Action
+			w.WriteHeader(http.StatusNoContent)
+		})
+		if err := client.UpgradeDevice(context.Background(), "", "dev-1"); err != nil {
+			t.Fatalf("UpgradeDevice: %v", err)
+		}
+		if gotAction != "UPGRADE" {
+			t.Errorf("got action %q, want UPGRADE", gotAction)
+		}
+	})
+
+	t.Run("returns error on non-2xx", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "error", http.StatusBadRequest)
+		})
+		if err := client.UpgradeDevice(context.Background(), "", "dev-1"); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+func TestAdoptDevice(t *testing.T) {
+	t.Run("posts MAC and decodes device", func(t *testing.T) {
+		var got adoptDeviceRequest
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/integration/v1/sites/test-site-id/devices" || r.Method != http.MethodPost {
+				http.Error(w, "unexpected", http.StatusBadRequest)
+				return
+			}
+			if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+				http.Error(w, "bad body", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id": "dev-7", "macAddress": got.MAC, "model": "U6-Lite", "state": "ADOPTING",
+			})
+		})
+		dev, err := client.AdoptDevice(context.Background(), "", "aa:bb:cc:00:00:07")
+		if err != nil {
+			t.Fatalf("AdoptDevice: %v", err)
+		}
+		if got.MAC != "aa:bb:cc:00:00:07" || dev.ID != "dev-7" || dev.State != "ADOPTING" {
+			t.Errorf("sent %+v, got %+v", got, dev)
+		}
+	})
+
+	t.Run("returns error on non-2xx", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "error", http.StatusBadRequest)
+		})
+		if _, err := client.AdoptDevice(context.Background(), "", "aa:bb:cc:00:00:07"); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
 func TestGetDeviceStats(t *testing.T) {
 	t.Run("decodes stats", func(t *testing.T) {
 		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
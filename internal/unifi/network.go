@@ -102,28 +102,14 @@ func (c *Client) GetFirewallPolicy(ctx context.Context, siteID, policyID string)
 
 // SetFirewallPolicyEnabled enables or disables a firewall policy via
 // GET then PUT /integration/v1/sites/{siteID}/firewall/policies/{policyID}.
-// It fetches the current resource as a raw map to preserve all API fields,
-// sets the enabled flag, and PUTs the full object back.
+// Only the enabled member changes; every other member is sent back as received.
 // Pass an empty siteID to use the client default.
 func (c *Client) SetFirewallPolicyEnabled(ctx context.Context, siteID, policyID string, enabled bool) (FirewallPolicy, error) {
 	id := c.site(siteID)
 	path := fmt.Sprintf("/integration/v1/sites/%s/firewall/policies/%s", url.PathEscape(id), url.PathEscape(policyID))
-
-	raw, err := c.get(ctx, path)
-	if err != nil {
-		return FirewallPolicy{}, fmt.Errorf("SetFirewallPolicyEnabled %s %s: get: %w", id, policyID, err)
-	}
-	body, err := decodeV1[map[string]any](raw)
-	if err != nil {
-		return FirewallPolicy{}, fmt.Errorf("SetFirewallPolicyEnabled %s %s: decode: %w", id, policyID, err)
-	}
-	delete(body, "id")
-	delete(body, "metadata")
-	body["enabled"] = enabled
-
-	updated, err := c.put(ctx, path, body)
+	updated, err := c.patchV1(ctx, path, map[string]any{"enabled": enabled})
 	if err != nil {
-		return FirewallPolicy{}, fmt.Errorf("SetFirewallPolicyEnabled %s %s: put: %w", id, policyID, err)
+		return FirewallPolicy{}, fmt.Errorf("SetFirewallPolicyEnabled %s %s: %w", id, policyID, err)
 	}
 	policy, err := decodeV1[FirewallPolicy](updated)
 	if err != nil {
@@ -276,24 +262,20 @@ func (c *Client) DeleteACLRule(ctx context.Context, siteID, ruleID string) error
 	return nil
 }
 
-// SetACLRuleEnabled enables or disables an ACL rule via GET then PUT.
-// It fetches the current rule, flips the enabled flag, and PUTs back.
+// SetACLRuleEnabled enables or disables an ACL rule via
+// GET then PUT /integration/v1/sites/{siteID}/acl-rules/{ruleID}.
+// Only the enabled member changes; every other member is sent back as received.
 // Pass an empty siteID to use the client default.
 func (c *Client) SetACLRuleEnabled(ctx context.Context, siteID, ruleID string, enabled bool) (ACLRule, error) {
 	id := c.site(siteID)
-	existing, err := c.GetACLRule(ctx, siteID, ruleID)
+	path := fmt.Sprintf("/integration/v1/sites/%s/acl-rules/%s", url.PathEscape(id), url.PathEscape(ruleID))
+	updated, err := c.patchV1(ctx, path, map[string]any{"enabled": enabled})
 	if err != nil {
-		return ACLRule{}, fmt.Errorf("SetACLRuleEnabled %s %s: get: %w", id, ruleID, err)
+		return ACLRule{}, fmt.Errorf("SetACLRuleEnabled %s %s: %w", id, ruleID, err)
 	}
-	req := ACLRuleRequest{
-		Type:    existing.Type,
-		Name:    existing.Name,
-		Action:  existing.Action,
-		Enabled: enabled,
-	}
-	rule, err := c.UpdateACLRule(ctx, siteID, ruleID, req)
+	rule, err := decodeV1[ACLRule](updated)
 	if err != nil {
-		return ACLRule{}, fmt.Errorf("SetACLRuleEnabled %s %s: put: %w", id, ruleID, err)
+		return ACLRule{}, fmt.Errorf("SetACLRuleEnabled %s %s: decode response: %w", id, ruleID, err)
 	}
 	return rule, nil
 }
@@ -331,32 +313,54 @@ func (c *Client) ReorderACLRules(ctx context.Context, siteID string, orderedIDs
 	return ordering, nil
 }
 
-// SetWiFiBroadcastEnabled enables or disables a WiFi broadcast via
+// CreateWiFiBroadcast creates a WiFi broadcast (SSID) via
+// POST /integration/v1/sites/{siteID}/wifi/broadcasts.
+// Pass an empty siteID to use the client default.
+func (c *Client) CreateWiFiBroadcast(ctx context.Context, siteID string, req WiFiBroadcastRequest) (WiFiBroadcast, error) {
+	id := c.site(siteID)
+	data, err := c.postWithBody(ctx, fmt.Sprintf("/integration/v1/sites/%s/wifi/broadcasts", url.PathEscape(id)), req)
+	if err != nil {
+		return WiFiBroadcast{}, fmt.Errorf("CreateWiFiBroadcast %s: %w", id, err)
+	}
+	bc, err := decodeV1[WiFiBroadcast](data)
+	if err != nil {
+		return WiFiBroadcast{}, fmt.Errorf("CreateWiFiBroadcast %s: %w", id, err)
+	}
+	return bc, nil
+}
+
+// UpdateWiFiBroadcast changes the settings named in update via
 // GET then PUT /integration/v1/sites/{siteID}/wifi/broadcasts/{broadcastID}.
-// It fetches the current resource as a raw map to preserve all API fields,
-// sets the enabled flag, and PUTs the full object back.
+// Settings update does not mention, including the passphrase and any the Go
+// types do not model, are sent back as received; switching to OPEN security
+// clears the passphrase.
 // Pass an empty siteID to use the client default.
-func (c *Client) SetWiFiBroadcastEnabled(ctx context.Context, siteID, broadcastID string, enabled bool) (WiFiBroadcast, error) {
+func (c *Client) UpdateWiFiBroadcast(ctx context.Context, siteID, broadcastID string, update WiFiBroadcastUpdate) (WiFiBroadcast, error) {
 	id := c.site(siteID)
 	path := fmt.Sprintf("/integration/v1/sites/%s/wifi/broadcasts/%s", url.PathEscape(id), url.PathEscape(broadcastID))
-
-	// GET current state as raw map for the round-trip PUT.
-	raw, err := c.get(ctx, path)
+	updated, err := c.patchV1Func(ctx, path, func(current map[string]json.RawMessage) any {
+		return update.mergePatch(current)
+	})
 	if err != nil {
-		return WiFiBroadcast{}, fmt.Errorf("SetWiFiBroadcastEnabled %s %s: get: %w", id, broadcastID, err)
+		return WiFiBroadcast{}, fmt.Errorf("UpdateWiFiBroadcast %s %s: %w", id, broadcastID, err)
 	}
-	body, err := decodeV1[map[string]any](raw)
+	bc, err := decodeV1[WiFiBroadcast](updated)
 	if err != nil {
-		return WiFiBroadcast{}, fmt.Errorf("SetWiFiBroadcastEnabled %s %s: decode: %w", id, broadcastID, err)
+		return WiFiBroadcast{}, fmt.Errorf("UpdateWiFiBroadcast %s %s: decode response: %w", id, broadcastID, err)
 	}
-	// Strip read-only fields the API rejects in PUT bodies.
-	delete(body, "id")
-	delete(body, "metadata")
-	body["enabled"] = enabled
+	return bc, nil
+}
 
-	updated, err := c.put(ctx, path, body)
+// SetWiFiBroadcastEnabled enables or disables a WiFi broadcast via
+// GET then PUT /integration/v1/sites/{siteID}/wifi/broadcasts/{broadcastID}.
+// Only the enabled member changes; every other member is sent back as received.
+// Pass an empty siteID to use the client default.
+func (c *Client) SetWiFiBroadcastEnabled(ctx context.Context, siteID, broadcastID string, enabled bool) (WiFiBroadcast, error) {
+	id := c.site(siteID)
+	path := fmt.Sprintf("/integration/v1/sites/%s/wifi/broadcasts/%s", url.PathEscape(id), url.PathEscape(broadcastID))
+	updated, err := c.patchV1(ctx, path, map[string]any{"enabled": enabled})
 	if err != nil {
-		return WiFiBroadcast{}, fmt.Errorf("SetWiFiBroadcastEnabled %s %s: put: %w", id, broadcastID, err)
+		return WiFiBroadcast{}, fmt.Errorf("SetWiFiBroadcastEnabled %s %s: %w", id, broadcastID, err)
 	}
 	bc, err := decodeV1[WiFiBroadcast](updated)
 	if err != nil {
@@ -397,6 +401,51 @@ func (c *Client) GetTrafficMatchingList(ctx context.Context, siteID, listID stri
 	return list, nil
 }
 
+// CreateTrafficMatchingList creates a traffic matching list via
+// POST /integration/v1/sites/{siteID}/traffic-matching-lists.
+// Pass an empty siteID to use the client default.
+func (c *Client) CreateTrafficMatchingList(ctx context.Context, siteID string, req TrafficMatchingListRequest) (TrafficMatchingList, error) {
+	id := c.site(siteID)
+	data, err := c.postWithBody(ctx, fmt.Sprintf("/integration/v1/sites/%s/traffic-matching-lists", url.PathEscape(id)), req)
+	if err != nil {
+		return TrafficMatchingList{}, fmt.Errorf("CreateTrafficMatchingList %s: %w", id, err)
+	}
+	list, err := decodeV1[TrafficMatchingList](data)
+	if err != nil {
+		return TrafficMatchingList{}, fmt.Errorf("CreateTrafficMatchingList %s: %w", id, err)
+	}
+	return list, nil
+}
+
+// UpdateTrafficMatchingList changes the settings named in update via
+// GET then PUT /integration/v1/sites/{siteID}/traffic-matching-lists/{listID}.
+// Settings update does not mention are sent back as received.
+// Pass an empty siteID to use the client default.
+func (c *Client) UpdateTrafficMatchingList(ctx context.Context, siteID, listID string, update TrafficMatchingListUpdate) (TrafficMatchingList, error) {
+	id := c.site(siteID)
+	path := fmt.Sprintf("/integration/v1/sites/%s/traffic-matching-lists/%s", url.PathEscape(id), url.PathEscape(listID))
+	updated, err := c.patchV1(ctx, path, update.mergePatch())
+	if err != nil {
+		return TrafficMatchingList{}, fmt.Errorf("UpdateTrafficMatchingList %s %s: %w", id, listID, err)
+	}
+	list, err := decodeV1[TrafficMatchingList](updated)
+	if err != nil {
+		return TrafficMatchingList{}, fmt.Errorf("UpdateTrafficMatchingList %s %s: decode response: %w", id, listID, err)
+	}
+	return list, nil
+}
+
+// DeleteTrafficMatchingList deletes a traffic matching list via
+// DELETE /integration/v1/sites/{siteID}/traffic-matching-lists/{listID}.
+// Pass an empty siteID to use the client default.
+func (c *Client) DeleteTrafficMatchingList(ctx context.Context, siteID, listID string) error {
+	id := c.site(siteID)
+	if err := c.delete(ctx, fmt.Sprintf("/integration/v1/sites/%s/traffic-matching-lists/%s", url.PathEscape(id), url.PathEscape(listID))); err != nil {
+		return fmt.Errorf("DeleteTrafficMatchingList %s %s: %w", id, listID, err)
+	}
+	return nil
+}
+
 // ListWANs returns one page of WAN interface definitions from
 // GET /integration/v1/sites/{siteID}/wans.
 // Pass an empty siteID to use the client default. offset and limit control pagination; 0 means use the API default.
@@ -645,3 +694,64 @@ func (c *Client) ListRADIUSProfiles(ctx context.Context, siteID string, offset,
 	}
 	return page, nil
 }
+
+// GetNetwork returns a single network, including its IPv4 configuration, from
+// GET /integration/v1/sites/{siteID}/networks/{networkID}.
+// Pass an empty siteID to use the client default.
+func (c *Client) GetNetwork(ctx context.Context, siteID, networkID string) (NetworkConf, error) {
+	id := c.site(siteID)
+	data, err := c.get(ctx, fmt.Sprintf("/integration/v1/sites/%s/networks/%s", url.PathEscape(id), url.PathEscape(networkID)))
+	if err != nil {
+		return NetworkConf{}, fmt.Errorf("GetNetwork %s %s: %w", id, networkID, err)
+	}
+	network, err := decodeV1[NetworkConf](data)
+	if err != nil {
+		return NetworkConf{}, fmt.Errorf("GetNetwork %s %s: %w", id, networkID, err)
+	}
+	return network, nil
+}
+
+// CreateNetwork creates a network (VLAN) via
+// POST /integration/v1/sites/{siteID}/networks.
+// Pass an empty siteID to use the client default.
+func (c *Client) CreateNetwork(ctx context.Context, siteID string, req NetworkRequest) (NetworkConf, error) {
+	id := c.site(siteID)
+	data, err := c.postWithBody(ctx, fmt.Sprintf("/integration/v1/sites/%s/networks", url.PathEscape(id)), req)
+	if err != nil {
+		return NetworkConf{}, fmt.Errorf("CreateNetwork %s: %w", id, err)
+	}
+	network, err := decodeV1[NetworkConf](data)
+	if err != nil {
+		return NetworkConf{}, fmt.Errorf("CreateNetwork %s: %w", id, err)
+	}
+	return network, nil
+}
+
+// UpdateNetwork changes the settings named in update via
+// GET then PUT /integration/v1/sites/{siteID}/networks/{networkID}.
+// Settings update does not mention are sent back as received.
+// Pass an empty siteID to use the client default.
+func (c *Client) UpdateNetwork(ctx context.Context, siteID, networkID string, update NetworkUpdate) (NetworkConf, error) {
+	id := c.site(siteID)
+	path := fmt.Sprintf("/integration/v1/sites/%s/networks/%s", url.PathEscape(id), url.PathEscape(networkID))
+	updated, err := c.patchV1(ctx, path, update.mergePatch())
+	if err != nil {
+		return NetworkConf{}, fmt.Errorf("UpdateNetwork %s %s: %w", id, networkID, err)
+	}
+	network, err := decodeV1[NetworkConf](updated)
+	if err != nil {
+		return NetworkConf{}, fmt.Errorf("UpdateNetwork %s %s: decode response: %w", id, networkID, err)
+	}
+	return network, nil
+}
+
+// DeleteNetwork deletes a network via
+// DELETE /integration/v1/sites/{siteID}/networks/{networkID}.
+// Pass an empty siteID to use the client default.
+func (c *Client) DeleteNetwork(ctx context.Context, siteID, networkID string) error {
+	id := c.site(siteID)
+	if err := c.delete(ctx, fmt.Sprintf("/integration/v1/sites/%s/networks/%s", url.PathEscape(id), url.PathEscape(networkID))); err != nil {
+		return fmt.Errorf("DeleteNetwork %s %s: %w", id, networkID, err)
+	}
+	return nil
+}
@@ -1,10 +1,13 @@
 package unifi
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -262,6 +265,24 @@ func TestGetWiFiBroadcast(t *testing.T) {
 }
 
 func TestSetWiFiBroadcastEnabled(t *testing.T) {
+	t.Run("preserves unmodeled fields", func(t *testing.T) {
+		const get = `{
+			"id": "bc-9", "name": "HomeWiFi", "type": "STANDARD", "enabled": true,
+			"securityConfiguration": {"type": "WPA2_PERSONAL", "passphrase": "hunter2hunter2", "pmfMode": "OPTIONAL"},
+			"bandSteering": {"mode": "PREFER_5GHZ"},
+			"broadcastingFrequenciesGHz": [2.4, 5],
+			"schedule": {"mode": "CUSTOM", "days": ["MON", "TUE"], "startMinute": 420},
+			"dtimPeriod": 9007199254740993,
+			"hideName": false,
+			"metadata": {"origin": "USER_DEFINED"}
+		}`
+		client, put := newRoundTripClient(t, "/integration/v1/sites/test-site-id/wifi/broadcasts/bc-9", get)
+		if _, err := client.SetWiFiBroadcastEnabled(context.Background(), "", "bc-9", false); err != nil {
+			t.Fatalf("SetWiFiBroadcastEnabled: %v", err)
+		}
+		assertOnlyEnabledChanged(t, get, *put, false)
+	})
+
 	t.Run("disables a broadcast", func(t *testing.T) {
 		var putEnabled any
 		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
@@ -427,6 +448,70 @@ func TestGetTrafficMatchingList(t *testing.T) {
 	})
 }
 
+func TestCreateTrafficMatchingList(t *testing.T) {
+	var body TrafficMatchingListRequest
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/integration/v1/sites/test-site-id/traffic-matching-lists" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "decode error", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "tml-new", "name": body.Name, "type": body.Type, "entries": body.Entries})
+	})
+	list, err := client.CreateTrafficMatchingList(context.Background(), "", TrafficMatchingListRequest{
+		Name: "Blocked", Type: TrafficMatchingListTypeIP, Entries: []string{"10.0.0.0/8"},
+	})
+	if err != nil {
+		t.Fatalf("CreateTrafficMatchingList: %v", err)
+	}
+	if list.ID != "tml-new" || body.Type != "IP" || len(body.Entries) != 1 {
+		t.Errorf("got %+v, sent %+v", list, body)
+	}
+}
+
+func TestUpdateTrafficMatchingList(t *testing.T) {
+	const get = `{"id": "tml-1", "name": "Blocked", "type": "IP", "entries": ["10.0.0.1"], "description": "kept"}`
+	client, put := newRoundTripClient(t, "/integration/v1/sites/test-site-id/traffic-matching-lists/tml-1", get)
+	list, err := client.UpdateTrafficMatchingList(context.Background(), "", "tml-1", TrafficMatchingListUpdate{
+		Entries: []string{"10.0.0.0/24", "192.0.2.1"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateTrafficMatchingList: %v", err)
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(*put, &m); err != nil {
+		t.Fatalf("decode PUT body: %v", err)
+	}
+	if string(m["entries"]) != `["10.0.0.0/24","192.0.2.1"]` || string(m["name"]) != `"Blocked"` || string(m["description"]) != `"kept"` {
+		t.Errorf("PUT body = %s", *put)
+	}
+	if len(list.Entries) != 2 {
+		t.Errorf("got %+v", list)
+	}
+}
+
+func TestDeleteTrafficMatchingList(t *testing.T) {
+	var gotMethod string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/integration/v1/sites/test-site-id/traffic-matching-lists/tml-1" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		gotMethod = r.Method
+		w.WriteHeader(http.StatusNoContent)
+	})
+	if err := client.DeleteTrafficMatchingList(context.Background(), "", "tml-1"); err != nil {
+		t.Fatalf("DeleteTrafficMatchingList: %v", err)
+	}
+	if gotMethod != http.MethodDelete {
+		t.Errorf("method = %s, want DELETE", gotMethod)
+	}
+}
+
 func TestListWANs(t *testing.T) {
 	t.Run("decodes WAN list", func(t *testing.T) {
 		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
@@ -804,6 +889,26 @@ func TestGetFirewallPolicy(t *testing.T) {
 }
 
 func TestSetFirewallPolicyEnabled(t *testing.T) {
+	t.Run("preserves unmodeled fields", func(t *testing.T) {
+		const get = `{
+			"id": "fp-9", "name": "Block IoT", "enabled": false, "index": 10000,
+			"action": {"type": "BLOCK", "allowReturnTraffic": true},
+			"source": {"zoneId": "zone-a", "trafficFilter": {"type": "NETWORK", "networkFilter": {"networkIds": ["n1"], "matchOpposite": false}}},
+			"destination": {"zoneId": "zone-b"},
+			"ipProtocolScope": {"ipVersion": "IPV4", "protocolFilter": {"type": "NAMED_PROTOCOL", "protocol": {"name": "TCP"}}},
+			"schedule": {"mode": "EVERY_DAY", "timeFilter": {"startTime": "22:00", "stopTime": "06:00"}},
+			"ipsecFilter": "MATCH_ENCRYPTED",
+			"counter": 12345678901234567890,
+			"loggingEnabled": true,
+			"metadata": {"origin": "USER_DEFINED", "configurable": true}
+		}`
+		client, put := newRoundTripClient(t, "/integration/v1/sites/test-site-id/firewall/policies/fp-9", get)
+		if _, err := client.SetFirewallPolicyEnabled(context.Background(), "", "fp-9", true); err != nil {
+			t.Fatalf("SetFirewallPolicyEnabled: %v", err)
+		}
+		assertOnlyEnabledChanged(t, get, *put, true)
+	})
+
 	t.Run("gets then puts with enabled flag set", func(t *testing.T) {
 		var putBody map[string]any
 		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
@@ -1151,6 +1256,33 @@ func TestCreateACLRule(t *testing.T) {
 		}
 	})
 
+	t.Run("sends MAC source filter", func(t *testing.T) {
+		var raw map[string]any
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+				http.Error(w, "decode error", http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "ar-new", "type": "MAC", "sourceFilter": raw["sourceFilter"]})
+		})
+		req := ACLRuleRequest{
+			Type: "MAC", Name: "block", Action: "BLOCK", Enabled: true,
+			SourceFilter: &ACLRuleFilter{Type: "MAC_ADDRESSES", MACAddresses: []string{"aa:bb:cc:dd:ee:ff"}},
+		}
+		rule, err := client.CreateACLRule(context.Background(), "", req)
+		if err != nil {
+			t.Fatalf("CreateACLRule: %v", err)
+		}
+		sf, ok := raw["sourceFilter"].(map[string]any)
+		if !ok || sf["type"] != "MAC_ADDRESSES" {
+			t.Fatalf("sent sourceFilter %v", raw["sourceFilter"])
+		}
+		if rule.SourceFilter == nil || len(rule.SourceFilter.MACAddresses) != 1 {
+			t.Errorf("decoded SourceFilter %+v", rule.SourceFilter)
+		}
+	})
+
 	t.Run("returns error on non-2xx", func(t *testing.T) {
 		client := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
 			http.Error(w, "server error", http.StatusInternalServerError)
@@ -1190,6 +1322,25 @@ func TestUpdateACLRule(t *testing.T) {
 		}
 	})
 
+	t.Run("sends an empty description to clear it", func(t *testing.T) {
+		var raw map[string]any
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+				http.Error(w, "decode error", http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "ar-1", "type": "IPV4"})
+		})
+		req := ACLRuleRequest{Type: "IPV4", Name: "r", Action: "ALLOW"}
+		if _, err := client.UpdateACLRule(context.Background(), "", "ar-1", req); err != nil {
+			t.Fatalf("UpdateACLRule: %v", err)
+		}
+		if d, ok := raw["description"]; !ok || d != "" {
+			t.Errorf("sent description %v (present %v), want \"\"", d, ok)
+		}
+	})
+
 	t.Run("returns error on non-2xx", func(t *testing.T) {
 		client := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
 			http.Error(w, "server error", http.StatusInternalServerError)
@@ -1231,6 +1382,23 @@ func TestDeleteACLRule(t *testing.T) {
 }
 
 func TestSetACLRuleEnabled(t *testing.T) {
+	t.Run("preserves unmodeled fields", func(t *testing.T) {
+		const get = `{
+			"id": "ar-9", "type": "MAC", "name": "[quarantine] laptop", "description": "suspicious", "action": "BLOCK", "enabled": true, "index": 0,
+			"sourceFilter": {"type": "MAC_ADDRESSES", "macAddresses": ["aa:bb:cc:dd:ee:ff"], "prefixLength": 48},
+			"networkIdFilter": "net-1",
+			"enforcingDeviceFilter": {"type": "DEVICES", "deviceIds": ["sw-1"]},
+			"loggingEnabled": true,
+			"hitCount": 9007199254740993,
+			"metadata": {"origin": "USER_DEFINED"}
+		}`
+		client, put := newRoundTripClient(t, "/integration/v1/sites/test-site-id/acl-rules/ar-9", get)
+		if _, err := client.SetACLRuleEnabled(context.Background(), "", "ar-9", false); err != nil {
+			t.Fatalf("SetACLRuleEnabled: %v", err)
+		}
+		assertOnlyEnabledChanged(t, get, *put, false)
+	})
+
 	t.Run("gets then puts with enabled flag set", func(t *testing.T) {
 		callCount := 0
 		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
@@ -1543,6 +1711,25 @@ func TestListDeviceTags(t *testing.T) {
 		}
 	})
 
+	t.Run("decodes tagged device IDs", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"data": []map[string]any{
+					{"id": "dt-1", "name": "cameras", "deviceIds": []string{"dev-1", "dev-2"}},
+				},
+				"totalCount": 1,
+			})
+		})
+		tags, err := client.ListDeviceTags(context.Background(), "", 0, 0)
+		if err != nil {
+			t.Fatalf("ListDeviceTags: %v", err)
+		}
+		if len(tags.Data) != 1 || strings.Join(tags.Data[0].DeviceIDs, ",") != "dev-1,dev-2" {
+			t.Errorf("got %+v, want DeviceIDs [dev-1 dev-2]", tags.Data)
+		}
+	})
+
 	t.Run("returns error on non-2xx", func(t *testing.T) {
 		client := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
 			http.Error(w, "error", http.StatusInternalServerError)
@@ -1663,3 +1850,332 @@ func TestListRADIUSProfiles(t *testing.T) {
 		}
 	})
 }
+
+// newRoundTripClient serves getBody for GET on path and records the raw body of
+// the PUT that follows, echoing it back as the response.
+func newRoundTripClient(t *testing.T, path, getBody string) (client *Client, putBody *[]byte) {
+	t.Helper()
+	putBody = new([]byte)
+	client = newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = io.WriteString(w, getBody)
+		case http.MethodPut:
+			b, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, "read error", http.StatusBadRequest)
+				return
+			}
+			*putBody = b
+			_, _ = w.Write(b)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	return client, putBody
+}
+
+// assertOnlyEnabledChanged checks that put carries every member of get byte for
+// byte, except id and metadata (dropped) and enabled (set to want).
+func assertOnlyEnabledChanged(t *testing.T, get string, put []byte, want bool) {
+	t.Helper()
+	var before, after map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(get), &before); err != nil {
+		t.Fatalf("decode GET body: %v", err)
+	}
+	if err := json.Unmarshal(put, &after); err != nil {
+		t.Fatalf("decode PUT body %q: %v", put, err)
+	}
+	for k, v := range before {
+		switch k {
+		case "id", "metadata":
+			if _, ok := after[k]; ok {
+				t.Errorf("PUT body contains read-only member %q", k)
+			}
+			continue
+		case "enabled":
+			continue
+		}
+		var wantBuf, gotBuf bytes.Buffer
+		_ = json.Compact(&wantBuf, v)
+		_ = json.Compact(&gotBuf, after[k])
+		if !bytes.Equal(wantBuf.Bytes(), gotBuf.Bytes()) {
+			t.Errorf("member %q: PUT %s, want %s", k, gotBuf.Bytes(), wantBuf.Bytes())
+		}
+	}
+	if got := string(after["enabled"]); got != fmt.Sprint(want) {
+		t.Errorf("PUT enabled = %s, want %v", got, want)
+	}
+	if len(after) != len(before)-2 {
+		t.Errorf("PUT has %d members, want %d: %s", len(after), len(before)-2, put)
+	}
+}
+
+func TestCreateWiFiBroadcast(t *testing.T) {
+	t.Run("sends passphrase in body only", func(t *testing.T) {
+		var body map[string]any
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/integration/v1/sites/test-site-id/wifi/broadcasts" || r.Method != http.MethodPost {
+				http.Error(w, "not found", http.StatusNotFound)
+				return
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, "decode error", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id": "bc-new", "type": "STANDARD", "name": body["name"], "enabled": true,
+				"securityConfiguration": body["securityConfiguration"],
+			})
+		})
+		hide := true
+		req := WiFiBroadcastRequest{
+			Type: "STANDARD", Name: "Lab", Enabled: true, HideName: &hide,
+			Network:               &WiFiBroadcastNetwork{Type: "SPECIFIC", NetworkID: "net-30"},
+			SecurityConfiguration: &WiFiSecurityRequest{Type: "WPA2_PERSONAL", Passphrase: "correct horse battery", PMFMode: "OPTIONAL"},
+		}
+		if s := fmt.Sprintf("%+v", *req.SecurityConfiguration); strings.Contains(s, "correct horse") {
+			t.Errorf("fmt leaked passphrase: %s", s)
+		}
+		bc, err := client.CreateWiFiBroadcast(context.Background(), "", req)
+		if err != nil {
+			t.Fatalf("CreateWiFiBroadcast: %v", err)
+		}
+		sec, _ := body["securityConfiguration"].(map[string]any)
+		if sec["passphrase"] != "correct horse battery" || sec["type"] != "WPA2_PERSONAL" {
+			t.Errorf("sent securityConfiguration %v", sec)
+		}
+		if bc.ID != "bc-new" || bc.SecurityConfiguration == nil || bc.SecurityConfiguration.Type != "WPA2_PERSONAL" {
+			t.Errorf("got %+v", bc)
+		}
+		if out, _ := json.Marshal(bc); strings.Contains(string(out), "correct horse") {
+			t.Errorf("decoded broadcast carries passphrase: %s", out)
+		}
+	})
+
+	t.Run("returns error on non-2xx", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "bad request", http.StatusBadRequest)
+		})
+		if _, err := client.CreateWiFiBroadcast(context.Background(), "", WiFiBroadcastRequest{Type: "STANDARD", Name: "x"}); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+func TestUpdateWiFiBroadcast(t *testing.T) {
+	const get = `{
+		"id": "bc-1", "type": "STANDARD", "name": "Home", "enabled": true, "hideName": false,
+		"network": {"type": "SPECIFIC", "networkId": "net-10"},
+		"securityConfiguration": {"type": "WPA2_PERSONAL", "passphrase": "existing-secret", "pmfMode": "OPTIONAL", "saeConfiguration": {"anticloggingThresholdSeconds": 5}},
+		"bandSteering": {"mode": "PREFER_5GHZ"},
+		"metadata": {"origin": "USER_DEFINED"}
+	}`
+
+	decodePut := func(t *testing.T, put []byte) map[string]json.RawMessage {
+		t.Helper()
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(put, &m); err != nil {
+			t.Fatalf("decode PUT body: %v", err)
+		}
+		return m
+	}
+
+	t.Run("changes PMF mode without touching passphrase", func(t *testing.T) {
+		client, put := newRoundTripClient(t, "/integration/v1/sites/test-site-id/wifi/broadcasts/bc-1", get)
+		_, err := client.UpdateWiFiBroadcast(context.Background(), "", "bc-1", WiFiBroadcastUpdate{
+			Security: &WiFiSecurityRequest{PMFMode: "REQUIRED"},
+		})
+		if err != nil {
+			t.Fatalf("UpdateWiFiBroadcast: %v", err)
+		}
+		m := decodePut(t, *put)
+		var sec map[string]any
+		_ = json.Unmarshal(m["securityConfiguration"], &sec)
+		if sec["pmfMode"] != "REQUIRED" || sec["passphrase"] != "existing-secret" || sec["type"] != "WPA2_PERSONAL" || sec["saeConfiguration"] == nil {
+			t.Errorf("securityConfiguration = %s", m["securityConfiguration"])
+		}
+		if string(m["bandSteering"]) != `{"mode":"PREFER_5GHZ"}` {
+			t.Errorf("bandSteering = %s", m["bandSteering"])
+		}
+		if _, ok := m["id"]; ok {
+			t.Error("PUT body contains id")
+		}
+	})
+
+	t.Run("sets new passphrase and native network", func(t *testing.T) {
+		client, put := newRoundTripClient(t, "/integration/v1/sites/test-site-id/wifi/broadcasts/bc-1", get)
+		hide, iso := true, true
+		_, err := client.UpdateWiFiBroadcast(context.Background(), "", "bc-1", WiFiBroadcastUpdate{
+			HideName:               &hide,
+			ClientIsolationEnabled: &iso,
+			Network:                &WiFiBroadcastNetwork{Type: "NATIVE"},
+			Security:               &WiFiSecurityRequest{Type: "WPA3_PERSONAL", Passphrase: "brand-new-secret"},
+		})
+		if err != nil {
+			t.Fatalf("UpdateWiFiBroadcast: %v", err)
+		}
+		m := decodePut(t, *put)
+		if string(m["network"]) != `{"type":"NATIVE"}` {
+			t.Errorf("network = %s", m["network"])
+		}
+		if string(m["hideName"]) != "true" || string(m["clientIsolationEnabled"]) != "true" {
+			t.Errorf("hideName = %s, clientIsolationEnabled = %s", m["hideName"], m["clientIsolationEnabled"])
+		}
+		var sec map[string]any
+		_ = json.Unmarshal(m["securityConfiguration"], &sec)
+		if sec["passphrase"] != "brand-new-secret" || sec["type"] != "WPA3_PERSONAL" || sec["pmfMode"] != "OPTIONAL" {
+			t.Errorf("securityConfiguration = %s", m["securityConfiguration"])
+		}
+	})
+
+	t.Run("switching to OPEN clears the passphrase and PSKs", func(t *testing.T) {
+		const withPSKs = `{
+			"id": "bc-1", "name": "Home", "enabled": true,
+			"securityConfiguration": {"type": "WPA2_PERSONAL", "passphrase": "existing-secret", "pmfMode": "OPTIONAL",
+				"ppskKeys": [{"passphrase": "vlan-secret", "networkId": "net-20"}]}
+		}`
+		client, put := newRoundTripClient(t, "/integration/v1/sites/test-site-id/wifi/broadcasts/bc-1", withPSKs)
+		_, err := client.UpdateWiFiBroadcast(context.Background(), "", "bc-1", WiFiBroadcastUpdate{
+			Security: &WiFiSecurityRequest{Type: "OPEN"},
+		})
+		if err != nil {
+			t.Fatalf("UpdateWiFiBroadcast: %v", err)
+		}
+		m := decodePut(t, *put)
+		if strings.Contains(string(*put), "secret") {
+			t.Errorf("PUT body still carries a key: %s", *put)
+		}
+		var sec map[string]any
+		_ = json.Unmarshal(m["securityConfiguration"], &sec)
+		if len(sec) != 2 || sec["type"] != "OPEN" || sec["pmfMode"] != "OPTIONAL" {
+			t.Errorf("securityConfiguration = %s", m["securityConfiguration"])
+		}
+	})
+}
+
+func TestGetNetwork(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/integration/v1/sites/test-site-id/networks/net-10" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{
+			"id": "net-10", "name": "IoT", "enabled": true, "vlanId": 10, "management": "GATEWAY",
+			"ipv4Configuration": {
+				"hostIpAddress": "192.168.10.1", "prefixLength": 24,
+				"dhcpConfiguration": {"mode": "SERVER", "ipAddressRange": {"start": "192.168.10.100", "stop": "192.168.10.200"}}
+			}
+		}`)
+	})
+	n, err := client.GetNetwork(context.Background(), "", "net-10")
+	if err != nil {
+		t.Fatalf("GetNetwork: %v", err)
+	}
+	ip := n.IPv4Configuration
+	if n.VLANID != 10 || ip == nil || ip.HostIPAddress != "192.168.10.1" || ip.PrefixLength != 24 ||
+		ip.DHCPConfiguration == nil || ip.DHCPConfiguration.IPAddressRange.Stop != "192.168.10.200" {
+		t.Errorf("got %+v", n)
+	}
+	if _, err := client.GetNetwork(context.Background(), "", "missing"); err == nil {
+		t.Error("expected error for unknown network")
+	}
+}
+
+func TestCreateNetwork(t *testing.T) {
+	var body map[string]any
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/integration/v1/sites/test-site-id/networks" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "decode error", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		body["id"] = "net-new"
+		_ = json.NewEncoder(w).Encode(body)
+	})
+	n, err := client.CreateNetwork(context.Background(), "", NetworkRequest{
+		Management: "GATEWAY", Name: "Guest", Enabled: true, VLANID: 30,
+		IPv4Configuration: &NetworkIPv4Configuration{
+			HostIPAddress: "10.30.0.1", PrefixLength: 24,
+			DHCPConfiguration: &NetworkDHCPConfiguration{Mode: "SERVER", IPAddressRange: &IPAddressRange{Start: "10.30.0.10", Stop: "10.30.0.250"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateNetwork: %v", err)
+	}
+	if n.ID != "net-new" || n.VLANID != 30 {
+		t.Errorf("got %+v", n)
+	}
+	if body["vlanId"] != float64(30) || body["management"] != "GATEWAY" {
+		t.Errorf("POST body = %v", body)
+	}
+}
+
+func TestUpdateNetwork(t *testing.T) {
+	const get = `{
+		"id": "net-10", "name": "IoT", "enabled": true, "vlanId": 10, "management": "GATEWAY",
+		"isolationEnabled": true,
+		"ipv4Configuration": {
+			"hostIpAddress": "192.168.10.1", "prefixLength": 24,
+			"dhcpConfiguration": {"mode": "SERVER", "leaseTimeSeconds": 86400, "ipAddressRange": {"start": "192.168.10.100", "stop": "192.168.10.200"}}
+		},
+		"metadata": {"origin": "USER_DEFINED"}
+	}`
+	client, put := newRoundTripClient(t, "/integration/v1/sites/test-site-id/networks/net-10", get)
+	vlan := 11
+	_, err := client.UpdateNetwork(context.Background(), "", "net-10", NetworkUpdate{
+		VLANID: &vlan,
+		IPv4Configuration: &NetworkIPv4Configuration{
+			DHCPConfiguration: &NetworkDHCPConfiguration{IPAddressRange: &IPAddressRange{Start: "192.168.10.50", Stop: "192.168.10.99"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("UpdateNetwork: %v", err)
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(*put, &m); err != nil {
+		t.Fatalf("decode PUT body: %v", err)
+	}
+	if string(m["vlanId"]) != "11" || string(m["isolationEnabled"]) != "true" {
+		t.Errorf("vlanId = %s, isolationEnabled = %s", m["vlanId"], m["isolationEnabled"])
+	}
+	var ip NetworkIPv4Configuration
+	_ = json.Unmarshal(m["ipv4Configuration"], &ip)
+	if ip.HostIPAddress != "192.168.10.1" || ip.DHCPConfiguration == nil || ip.DHCPConfiguration.Mode != "SERVER" ||
+		ip.DHCPConfiguration.LeaseTimeSeconds != 86400 || ip.DHCPConfiguration.IPAddressRange.Start != "192.168.10.50" {
+		t.Errorf("ipv4Configuration = %s", m["ipv4Configuration"])
+	}
+	if _, ok := m["metadata"]; ok {
+		t.Error("PUT body contains metadata")
+	}
+}
+
+func TestDeleteNetwork(t *testing.T) {
+	var gotMethod string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/integration/v1/sites/test-site-id/networks/net-10" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		gotMethod = r.Method
+		w.WriteHeader(http.StatusNoContent)
+	})
+	if err := client.DeleteNetwork(context.Background(), "", "net-10"); err != nil {
+		t.Fatalf("DeleteNetwork: %v", err)
+	}
+	if gotMethod != http.MethodDelete {
+		t.Errorf("method = %s, want DELETE", gotMethod)
+	}
+}
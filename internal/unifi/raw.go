@@ -0,0 +1,81 @@
+package unifi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// Resource collections accepted by GetRaw and PutRaw, as they appear in
+// /integration/v1/sites/{siteID}/{collection}/{id}.
+const (
+	CollectionWiFiBroadcasts   = "wifi/broadcasts"
+	CollectionNetworks         = "networks"
+	CollectionDNSPolicies      = "dns/policies"
+	CollectionFirewallZones    = "firewall/zones"
+	CollectionFirewallPolicies = "firewall/policies"
+	CollectionACLRules         = "acl-rules"
+)
+
+// rawCollections is the set of collections GetRaw and PutRaw will address.
+// Collections contain slashes and so cannot be path-escaped; limiting them to
+// known values keeps caller input out of the URL structure.
+var rawCollections = map[string]bool{
+	CollectionWiFiBroadcasts:   true,
+	CollectionNetworks:         true,
+	CollectionDNSPolicies:      true,
+	CollectionFirewallZones:    true,
+	CollectionFirewallPolicies: true,
+	CollectionACLRules:         true,
+}
+
+func (c *Client) rawPath(siteID, collection, resourceID string) (string, error) {
+	if !rawCollections[collection] {
+		return "", fmt.Errorf("unsupported collection %q", collection)
+	}
+	return fmt.Sprintf("/integration/v1/sites/%s/%s/%s", url.PathEscape(c.site(siteID)), collection, url.PathEscape(resourceID)), nil
+}
+
+// GetRaw returns a single resource as the controller sent it via
+// GET /integration/v1/sites/{siteID}/{collection}/{resourceID}, for callers
+// that must see members the typed structs do not model.
+// Pass an empty siteID to use the client default.
+func (c *Client) GetRaw(ctx context.Context, siteID, collection, resourceID string) (json.RawMessage, error) {
+	id := c.site(siteID)
+	path, err := c.rawPath(siteID, collection, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("GetRaw %s %s: %w", id, resourceID, err)
+	}
+	data, err := c.get(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("GetRaw %s %s/%s: %w", id, collection, resourceID, err)
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("GetRaw %s %s/%s: response is not valid JSON", id, collection, resourceID)
+	}
+	return data, nil
+}
+
+// PutRaw replaces a resource via PUT /integration/v1/sites/{siteID}/{collection}/{resourceID}
+// and returns the response as sent. body must be a JSON object; its read-only
+// id and metadata members are dropped before sending.
+// Pass an empty siteID to use the client default.
+func (c *Client) PutRaw(ctx context.Context, siteID, collection, resourceID string, body json.RawMessage) (json.RawMessage, error) {
+	id := c.site(siteID)
+	path, err := c.rawPath(siteID, collection, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("PutRaw %s %s: %w", id, resourceID, err)
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(body, &obj); err != nil || obj == nil {
+		return nil, fmt.Errorf("PutRaw %s %s/%s: body must be a JSON object", id, collection, resourceID)
+	}
+	delete(obj, "id")
+	delete(obj, "metadata")
+	data, err := c.put(ctx, path, obj)
+	if err != nil {
+		return nil, fmt.Errorf("PutRaw %s %s/%s: %w", id, collection, resourceID, err)
+	}
+	return data, nil
+}
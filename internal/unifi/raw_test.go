@@ -0,0 +1,94 @@
+package unifi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestGetRaw(t *testing.T) {
+	t.Run("returns body verbatim", func(t *testing.T) {
+		const body = `{"id":"n-1","name":"IoT","vlanId":30,"dhcpGuarding":{"enabled":true}}`
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/integration/v1/sites/test-site-id/networks/n-1" || r.Method != http.MethodGet {
+				http.Error(w, "not found", http.StatusNotFound)
+				return
+			}
+			_, _ = io.WriteString(w, body)
+		})
+		got, err := client.GetRaw(context.Background(), "", CollectionNetworks, "n-1")
+		if err != nil {
+			t.Fatalf("GetRaw: %v", err)
+		}
+		if string(got) != body {
+			t.Errorf("got %s, want %s", got, body)
+		}
+	})
+
+	t.Run("rejects unknown collection", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			t.Error("unexpected request")
+			w.WriteHeader(http.StatusOK)
+		})
+		if _, err := client.GetRaw(context.Background(), "", "../devices", "x"); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+
+	t.Run("escapes resource ID", func(t *testing.T) {
+		var gotPath string
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			gotPath = r.URL.EscapedPath()
+			_, _ = io.WriteString(w, `{}`)
+		})
+		if _, err := client.GetRaw(context.Background(), "", CollectionACLRules, "a/b"); err != nil {
+			t.Fatalf("GetRaw: %v", err)
+		}
+		if gotPath != "/integration/v1/sites/test-site-id/acl-rules/a%2Fb" {
+			t.Errorf("path = %q", gotPath)
+		}
+	})
+}
+
+func TestPutRaw(t *testing.T) {
+	t.Run("strips read-only members", func(t *testing.T) {
+		var put map[string]json.RawMessage
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/integration/v1/sites/s2/firewall/zones/z-1" || r.Method != http.MethodPut {
+				http.Error(w, "not found", http.StatusNotFound)
+				return
+			}
+			_ = json.NewDecoder(r.Body).Decode(&put)
+			_, _ = io.WriteString(w, `{"id":"z-1","name":"new"}`)
+		})
+		got, err := client.PutRaw(context.Background(), "s2", CollectionFirewallZones, "z-1",
+			json.RawMessage(`{"id":"z-1","name":"new","networkIds":["n1"],"metadata":{"origin":"USER_DEFINED"}}`))
+		if err != nil {
+			t.Fatalf("PutRaw: %v", err)
+		}
+		if string(got) != `{"id":"z-1","name":"new"}` {
+			t.Errorf("response = %s", got)
+		}
+		if _, ok := put["id"]; ok {
+			t.Error("PUT body contains id")
+		}
+		if _, ok := put["metadata"]; ok {
+			t.Error("PUT body contains metadata")
+		}
+		if string(put["networkIds"]) != `["n1"]` {
+			t.Errorf("networkIds = %s", put["networkIds"])
+		}
+	})
+
+	t.Run("rejects non-object body", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+			t.Error("unexpected request")
+			w.WriteHeader(http.StatusOK)
+		})
+		if _, err := client.PutRaw(context.Background(), "", CollectionNetworks, "n-1", json.RawMessage(`[1]`)); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
@@ -2,8 +2,16 @@ package unifi
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"net"
+	"net/netip"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
 )
 
 const sensitiveStringRedacted = "[REDACTED]"
@@ -65,6 +73,67 @@ type Device struct {
 	FirmwareUpdatable bool   `json:"firmwareUpdatable"`
 	AdoptedAt         string `json:"adoptedAt,omitempty"`
 	ProvisionedAt     string `json:"provisionedAt,omitempty"`
+	// Interfaces is only filled by GetDevice; the device list names the
+	// interface kinds a device has without their details.
+	Interfaces *DeviceInterfaces `json:"interfaces,omitempty"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The device list sends
+// interfaces as a list of kinds, e.g. ["ports", "radios"], which leaves
+// Interfaces nil.
+func (d *Device) UnmarshalJSON(data []byte) error {
+	type plain Device
+	var aux struct {
+		plain
+		Interfaces json.RawMessage `json:"interfaces"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*d = Device(aux.plain)
+	if len(aux.Interfaces) > 0 && aux.Interfaces[0] == '{' {
+		d.Interfaces = new(DeviceInterfaces)
+		if err := json.Unmarshal(aux.Interfaces, d.Interfaces); err != nil {
+			return fmt.Errorf("interfaces: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeviceInterfaces is the interfaces object of
+// GET /integration/v1/sites/{siteId}/devices/{deviceId}.
+type DeviceInterfaces struct {
+	Ports []DevicePort `json:"ports,omitempty"`
+}
+
+// DevicePort is one physical port of a device.
+type DevicePort struct {
+	Idx  int    `json:"idx"`
+	Name string `json:"name,omitempty"`
+	// State is UP, DOWN or UNKNOWN.
+	State     string `json:"state,omitempty"`
+	Connector string `json:"connector,omitempty"`
+	// SpeedMbps is the negotiated link speed; zero when the link is down.
+	SpeedMbps    int      `json:"speedMbps,omitempty"`
+	MaxSpeedMbps int      `json:"maxSpeedMbps,omitempty"`
+	PoE          *PortPoE `json:"poe,omitempty"`
+	// ConnectedMAC is the MAC address of what is plugged into the port. It is
+	// best-effort: the Integration API does not document it, and many
+	// firmware versions leave it out, so an empty value says nothing about
+	// the port.
+	ConnectedMAC string `json:"connectedMacAddress,omitempty"`
+}
+
+// PortPoE is the PoE state of a port that can supply power.
+type PortPoE struct {
+	Standard string `json:"standard,omitempty"`
+	Type     int    `json:"type,omitempty"`
+	Enabled  bool   `json:"enabled"`
+	// State is UP while the port is delivering power.
+	State string `json:"state,omitempty"`
+	// PowerW is the power drawn. It is best-effort: it is not documented by
+	// the Integration API and is nil when the controller leaves it out.
+	PowerW *float64 `json:"powerW,omitempty"`
 }
 
 // DeviceStats is returned by GET /integration/v1/sites/{siteId}/devices/{deviceId}/statistics/latest.
@@ -84,6 +153,12 @@ type deviceActionRequest struct {
 	Action string `json:"action"`
 }
 
+// adoptDeviceRequest is the body sent to POST /integration/v1/sites/{siteId}/devices.
+type adoptDeviceRequest struct {
+	MAC               string `json:"macAddress"`
+	IgnoreDeviceLimit bool   `json:"ignoreDeviceLimit"`
+}
+
 // NetworkClient is returned by GET /integration/v1/sites/{siteId}/clients.
 type NetworkClient struct {
 	ID             string `json:"id"`
@@ -135,6 +210,112 @@ type WiFiSecurityConfiguration struct {
 	PMFMode            string `json:"pmfMode,omitempty"`
 }
 
+// WiFiBroadcastRequest is the body for POST /integration/v1/sites/{siteId}/wifi/broadcasts.
+// Known Type values: "STANDARD".
+type WiFiBroadcastRequest struct {
+	Type                   string                `json:"type"`
+	Name                   string                `json:"name"`
+	Enabled                bool                  `json:"enabled"`
+	HideName               *bool                 `json:"hideName,omitempty"`
+	ClientIsolationEnabled *bool                 `json:"clientIsolationEnabled,omitempty"`
+	Network                *WiFiBroadcastNetwork `json:"network,omitempty"`
+	SecurityConfiguration  *WiFiSecurityRequest  `json:"securityConfiguration,omitempty"`
+}
+
+// WiFiSecurityRequest is the security block of a WiFi broadcast create or
+// update. Passphrase is write-only: it is sent to the controller in the request
+// body but redacted everywhere else (fmt, logs), and no response type models it.
+type WiFiSecurityRequest struct {
+	Type               string          `json:"type,omitempty"`
+	Passphrase         SensitiveString `json:"-"`
+	FastRoamingEnabled *bool           `json:"fastRoamingEnabled,omitempty"`
+	PMFMode            string          `json:"pmfMode,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, writing the real passphrase into the
+// wire body. SensitiveString's own MarshalJSON would redact it.
+func (r WiFiSecurityRequest) MarshalJSON() ([]byte, error) {
+	type plain WiFiSecurityRequest
+	return json.Marshal(struct {
+		plain
+		Passphrase string `json:"passphrase,omitempty"`
+	}{plain(r), string(r.Passphrase)})
+}
+
+// WiFiBroadcastUpdate lists the WiFi broadcast settings to change; nil fields
+// are left as they are. Network replaces the VLAN binding as a whole.
+type WiFiBroadcastUpdate struct {
+	Name                   *string
+	Enabled                *bool
+	HideName               *bool
+	ClientIsolationEnabled *bool
+	Network                *WiFiBroadcastNetwork
+	Security               *WiFiSecurityRequest
+}
+
+// wifiSecretMembers are substrings of securityConfiguration member names that
+// hold a key; they are removed when a broadcast switches to OPEN.
+var wifiSecretMembers = []string{"passphrase", "psk", "password", "secret"}
+
+// mergePatch returns u as an RFC 7396 merge patch over current, the
+// controller object.
+func (u *WiFiBroadcastUpdate) mergePatch(current map[string]json.RawMessage) map[string]any {
+	patch := make(map[string]any)
+	if u.Name != nil {
+		patch["name"] = *u.Name
+	}
+	if u.Enabled != nil {
+		patch["enabled"] = *u.Enabled
+	}
+	if u.HideName != nil {
+		patch["hideName"] = *u.HideName
+	}
+	if u.ClientIsolationEnabled != nil {
+		patch["clientIsolationEnabled"] = *u.ClientIsolationEnabled
+	}
+	if u.Network != nil {
+		network := map[string]any{"type": u.Network.Type, "networkId": nil}
+		if u.Network.NetworkID != "" {
+			network["networkId"] = u.Network.NetworkID
+		}
+		patch["network"] = network
+	}
+	if u.Security != nil {
+		// Marshal through WiFiSecurityRequest so only the set members (and the
+		// real passphrase) appear; unset ones stay untouched by the merge.
+		patch["securityConfiguration"] = *u.Security
+		if u.Security.Type == "OPEN" {
+			patch["securityConfiguration"] = openSecurityPatch(u.Security, current["securityConfiguration"])
+		}
+	}
+	return patch
+}
+
+// openSecurityPatch returns sec as a merge patch that also removes every key
+// member of current, so an OPEN broadcast keeps no stale passphrase or PSK.
+func openSecurityPatch(sec *WiFiSecurityRequest, current json.RawMessage) map[string]any {
+	patch := map[string]any{"passphrase": nil}
+	var members map[string]json.RawMessage
+	_ = json.Unmarshal(current, &members)
+	for name := range members {
+		lower := strings.ToLower(name)
+		for _, s := range wifiSecretMembers {
+			if strings.Contains(lower, s) {
+				patch[name] = nil
+				break
+			}
+		}
+	}
+	patch["type"] = sec.Type
+	if sec.FastRoamingEnabled != nil {
+		patch["fastRoamingEnabled"] = *sec.FastRoamingEnabled
+	}
+	if sec.PMFMode != "" {
+		patch["pmfMode"] = sec.PMFMode
+	}
+	return patch
+}
+
 // WiFiHotspotConfiguration describes any captive-portal / guest hotspot settings.
 type WiFiHotspotConfiguration struct {
 	// Type is the portal variety, e.g. CAPTIVE_PORTAL.
@@ -149,6 +330,69 @@ type NetworkConf struct {
 	VLANID     int    `json:"vlanId,omitempty"`
 	Management string `json:"management,omitempty"`
 	Default    bool   `json:"default,omitempty"`
+	// IPv4Configuration is returned by GET .../networks/{networkId}; list
+	// responses may omit it.
+	IPv4Configuration *NetworkIPv4Configuration `json:"ipv4Configuration,omitempty"`
+}
+
+// NetworkIPv4Configuration is the IPv4 plan of a gateway-managed network.
+// Every member is omitempty so that a partial value can serve as a merge patch.
+type NetworkIPv4Configuration struct {
+	// HostIPAddress is the gateway's address on the network.
+	HostIPAddress     string                    `json:"hostIpAddress,omitempty"`
+	PrefixLength      int                       `json:"prefixLength,omitempty"`
+	DHCPConfiguration *NetworkDHCPConfiguration `json:"dhcpConfiguration,omitempty"`
+}
+
+// NetworkDHCPConfiguration describes the DHCP service on a network.
+type NetworkDHCPConfiguration struct {
+	// Mode is "SERVER", "RELAY" or "NONE".
+	Mode             string          `json:"mode,omitempty"`
+	IPAddressRange   *IPAddressRange `json:"ipAddressRange,omitempty"`
+	LeaseTimeSeconds int             `json:"leaseTimeSeconds,omitempty"`
+}
+
+// IPAddressRange is an inclusive range of IPv4 addresses.
+type IPAddressRange struct {
+	Start string `json:"start"`
+	Stop  string `json:"stop"`
+}
+
+// NetworkRequest is the body for POST /integration/v1/sites/{siteId}/networks.
+// Known Management values: "GATEWAY".
+type NetworkRequest struct {
+	Management        string                    `json:"management"`
+	Name              string                    `json:"name"`
+	Enabled           bool                      `json:"enabled"`
+	VLANID            int                       `json:"vlanId"`
+	IPv4Configuration *NetworkIPv4Configuration `json:"ipv4Configuration,omitempty"`
+}
+
+// NetworkUpdate names the network settings to change; nil fields, and
+// IPv4Configuration members left at their zero value, are kept as they are.
+type NetworkUpdate struct {
+	Name              *string
+	Enabled           *bool
+	VLANID            *int
+	IPv4Configuration *NetworkIPv4Configuration
+}
+
+// mergePatch returns u as an RFC 7396 merge patch over the controller object.
+func (u *NetworkUpdate) mergePatch() map[string]any {
+	patch := make(map[string]any)
+	if u.Name != nil {
+		patch["name"] = *u.Name
+	}
+	if u.Enabled != nil {
+		patch["enabled"] = *u.Enabled
+	}
+	if u.VLANID != nil {
+		patch["vlanId"] = *u.VLANID
+	}
+	if u.IPv4Configuration != nil {
+		patch["ipv4Configuration"] = *u.IPv4Configuration
+	}
+	return patch
 }
 
 // FirewallResourceMetadata is the read-only metadata returned on firewall policies and zones.
@@ -182,6 +426,9 @@ type FirewallPolicyIPAddressFilter struct {
 	Type          string                       `json:"type"`
 	MatchOpposite bool                         `json:"matchOpposite"`
 	Items         []FirewallPolicyIPFilterItem `json:"items,omitempty"`
+	// TrafficMatchingListID is set instead of Items when Type is
+	// "TRAFFIC_MATCHING_LIST".
+	TrafficMatchingListID string `json:"trafficMatchingListId,omitempty"`
 }
 
 // FirewallPolicyPortFilter holds the list of ports to match.
@@ -189,6 +436,9 @@ type FirewallPolicyPortFilter struct {
 	Type          string                         `json:"type"`
 	MatchOpposite bool                           `json:"matchOpposite"`
 	Items         []FirewallPolicyPortFilterItem `json:"items,omitempty"`
+	// TrafficMatchingListID is set instead of Items when Type is
+	// "TRAFFIC_MATCHING_LIST".
+	TrafficMatchingListID string `json:"trafficMatchingListId,omitempty"`
 }
 
 // FirewallPolicyIPFilterItem is a single IP address entry in a filter list.
@@ -237,6 +487,30 @@ type FirewallPolicy struct {
 	Metadata              *FirewallResourceMetadata `json:"metadata,omitempty"`
 }
 
+// TrafficMatchingListIDs returns the IDs of the traffic matching lists p's
+// source and destination filters reference, without duplicates.
+func (p *FirewallPolicy) TrafficMatchingListIDs() []string {
+	var ids []string
+	add := func(id string) {
+		if id != "" && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	for _, ref := range []FirewallPolicyZoneRef{p.Source, p.Destination} {
+		f := ref.TrafficFilter
+		if f == nil {
+			continue
+		}
+		if f.IPAddressFilter != nil {
+			add(f.IPAddressFilter.TrafficMatchingListID)
+		}
+		if f.PortFilter != nil {
+			add(f.PortFilter.TrafficMatchingListID)
+		}
+	}
+	return ids
+}
+
 // FirewallZone is returned by GET /integration/v1/sites/{siteId}/firewall/zones.
 type FirewallZone struct {
 	ID         string                    `json:"id"`
@@ -251,24 +525,250 @@ type FirewallZoneRequest struct {
 	NetworkIDs []string `json:"networkIds"`
 }
 
+// Known ACLRule.Type values.
+const (
+	ACLRuleTypeIPv4 = "IPV4"
+	ACLRuleTypeMAC  = "MAC"
+)
+
+// Known ACLRuleFilter.Type values.
+const (
+	ACLFilterIPAddresses  = "IP_ADDRESSES_OR_SUBNETS"
+	ACLFilterMACAddresses = "MAC_ADDRESSES"
+)
+
 // ACLRule is returned by GET /integration/v1/sites/{siteId}/acl-rules.
+// Members the struct does not model are kept in Extra and sent back by
+// Request, so a read-modify-write does not drop them.
 type ACLRule struct {
-	ID       string                   `json:"id"`
-	Type     string                   `json:"type"`
-	Name     string                   `json:"name"`
-	Enabled  bool                     `json:"enabled"`
-	Action   string                   `json:"action"`
-	Index    int                      `json:"index"`
-	Metadata FirewallResourceMetadata `json:"metadata"`
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Enabled     bool   `json:"enabled"`
+	Action      string `json:"action"`
+	Index       int    `json:"index"`
+	// EnforcingDeviceFilter limits the switches that enforce the rule; nil means all.
+	EnforcingDeviceFilter *ACLDeviceFilter `json:"enforcingDeviceFilter,omitempty"`
+	SourceFilter          *ACLRuleFilter   `json:"sourceFilter,omitempty"`
+	DestinationFilter     *ACLRuleFilter   `json:"destinationFilter,omitempty"`
+	// ProtocolFilter restricts IPV4 rules to the listed protocols, e.g. "TCP", "UDP".
+	ProtocolFilter []string `json:"protocolFilter,omitempty"`
+	// NetworkIDFilter scopes a MAC rule to one network (VLAN).
+	NetworkIDFilter string                   `json:"networkIdFilter,omitempty"`
+	Metadata        FirewallResourceMetadata `json:"metadata"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, collecting unmodeled members in Extra.
+func (r *ACLRule) UnmarshalJSON(data []byte) error {
+	type plain ACLRule
+	extra, err := unmarshalWithExtra(data, (*plain)(r))
+	r.Extra = extra
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, writing Extra back alongside the modeled fields.
+func (r ACLRule) MarshalJSON() ([]byte, error) { //nolint:gocritic // value receiver so non-addressable values keep Extra
+	type plain ACLRule
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+// Request returns the rule as a create/update body, including its unmodeled
+// members, ready to be modified and sent back with UpdateACLRule.
+func (r *ACLRule) Request() ACLRuleRequest {
+	return ACLRuleRequest{
+		Type:                  r.Type,
+		Name:                  r.Name,
+		Description:           r.Description,
+		Action:                r.Action,
+		Enabled:               r.Enabled,
+		EnforcingDeviceFilter: r.EnforcingDeviceFilter.clone(),
+		SourceFilter:          r.SourceFilter.clone(),
+		DestinationFilter:     r.DestinationFilter.clone(),
+		ProtocolFilter:        slices.Clone(r.ProtocolFilter),
+		NetworkIDFilter:       r.NetworkIDFilter,
+		Extra:                 maps.Clone(r.Extra),
+	}
+}
+
+// ACLRuleFilter selects the source or destination traffic an ACL rule matches.
+// IPV4 rules use Type ACLFilterIPAddresses; MAC rules use ACLFilterMACAddresses.
+type ACLRuleFilter struct {
+	Type                 string   `json:"type"`
+	IPAddressesOrSubnets []string `json:"ipAddressesOrSubnets,omitempty"`
+	PortFilter           []int    `json:"portFilter,omitempty"`
+	MACAddresses         []string `json:"macAddresses,omitempty"`
+	NetworkIDs           []string `json:"networkIds,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, collecting unmodeled members in Extra.
+func (f *ACLRuleFilter) UnmarshalJSON(data []byte) error {
+	type plain ACLRuleFilter
+	extra, err := unmarshalWithExtra(data, (*plain)(f))
+	f.Extra = extra
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, writing Extra back alongside the modeled fields.
+func (f ACLRuleFilter) MarshalJSON() ([]byte, error) { //nolint:gocritic // value receiver so non-addressable values keep Extra
+	type plain ACLRuleFilter
+	return marshalWithExtra(plain(f), f.Extra)
+}
+
+func (f *ACLRuleFilter) clone() *ACLRuleFilter {
+	if f == nil {
+		return nil
+	}
+	c := *f
+	c.IPAddressesOrSubnets = slices.Clone(f.IPAddressesOrSubnets)
+	c.PortFilter = slices.Clone(f.PortFilter)
+	c.MACAddresses = slices.Clone(f.MACAddresses)
+	c.NetworkIDs = slices.Clone(f.NetworkIDs)
+	c.Extra = maps.Clone(f.Extra)
+	return &c
+}
+
+// ACLDeviceFilter lists the devices that enforce an ACL rule.
+// Known Type values: "DEVICES".
+type ACLDeviceFilter struct {
+	Type      string   `json:"type"`
+	DeviceIDs []string `json:"deviceIds"`
+}
+
+func (f *ACLDeviceFilter) clone() *ACLDeviceFilter {
+	if f == nil {
+		return nil
+	}
+	return &ACLDeviceFilter{Type: f.Type, DeviceIDs: slices.Clone(f.DeviceIDs)}
 }
 
 // ACLRuleRequest is the body for POST and PUT to /integration/v1/sites/{siteId}/acl-rules.
 // Known Type values: "IPV4", "MAC". Known Action values: "ALLOW", "BLOCK".
+// Extra members are sent as-is; ACLRule.Request fills them from a fetched rule.
 type ACLRuleRequest struct {
-	Type    string `json:"type"`
-	Name    string `json:"name"`
-	Action  string `json:"action"`
-	Enabled bool   `json:"enabled"`
+	Type                  string           `json:"type"`
+	Name                  string           `json:"name"`
+	Description           string           `json:"description"` // always sent, so "" clears it on update
+	Action                string           `json:"action"`
+	Enabled               bool             `json:"enabled"`
+	EnforcingDeviceFilter *ACLDeviceFilter `json:"enforcingDeviceFilter,omitempty"`
+	SourceFilter          *ACLRuleFilter   `json:"sourceFilter,omitempty"`
+	DestinationFilter     *ACLRuleFilter   `json:"destinationFilter,omitempty"`
+	ProtocolFilter        []string         `json:"protocolFilter,omitempty"`
+	NetworkIDFilter       string           `json:"networkIdFilter,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON implements json.Marshaler, writing Extra alongside the modeled fields.
+func (r ACLRuleRequest) MarshalJSON() ([]byte, error) { //nolint:gocritic // value receiver so non-addressable values keep Extra
+	type plain ACLRuleRequest
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+// Validate checks r against the controller's ACL rule schema: the type and
+// action are known, filters match the rule type, and every address, port and
+// protocol is well formed. It does not check that referenced IDs exist.
+func (r ACLRuleRequest) Validate() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	if r.Action != "ALLOW" && r.Action != "BLOCK" {
+		return fmt.Errorf("action %q must be ALLOW or BLOCK", r.Action)
+	}
+	if r.EnforcingDeviceFilter != nil && len(r.EnforcingDeviceFilter.DeviceIDs) == 0 {
+		return errors.New("enforcingDeviceFilter must list at least one device ID")
+	}
+	switch r.Type {
+	case ACLRuleTypeIPv4:
+		if r.NetworkIDFilter != "" {
+			return errors.New("networkIdFilter applies only to MAC rules")
+		}
+		for _, p := range r.ProtocolFilter {
+			if !slices.Contains(aclProtocols, p) {
+				return fmt.Errorf("protocol %q must be one of %s", p, strings.Join(aclProtocols, ", "))
+			}
+		}
+		for _, f := range []struct {
+			name string
+			f    *ACLRuleFilter
+		}{{"sourceFilter", r.SourceFilter}, {"destinationFilter", r.DestinationFilter}} {
+			if err := f.f.validateIP(); err != nil {
+				return fmt.Errorf("%s: %w", f.name, err)
+			}
+		}
+	case ACLRuleTypeMAC:
+		if len(r.ProtocolFilter) > 0 {
+			return errors.New("protocolFilter applies only to IPV4 rules")
+		}
+		for _, f := range []struct {
+			name string
+			f    *ACLRuleFilter
+		}{{"sourceFilter", r.SourceFilter}, {"destinationFilter", r.DestinationFilter}} {
+			if err := f.f.validateMAC(); err != nil {
+				return fmt.Errorf("%s: %w", f.name, err)
+			}
+		}
+	default:
+		return fmt.Errorf("type %q must be IPV4 or MAC", r.Type)
+	}
+	return nil
+}
+
+// aclProtocols are the protocolFilter values accepted by Validate.
+var aclProtocols = []string{"TCP", "UDP", "ICMP"}
+
+func (f *ACLRuleFilter) validateIP() error {
+	if f == nil {
+		return nil
+	}
+	if f.Type != ACLFilterIPAddresses {
+		return fmt.Errorf("type %q must be %s for IPV4 rules", f.Type, ACLFilterIPAddresses)
+	}
+	if len(f.MACAddresses) > 0 {
+		return errors.New("macAddresses are not allowed in IPV4 rules")
+	}
+	for _, a := range f.IPAddressesOrSubnets {
+		if addr, err := netip.ParseAddr(a); err == nil {
+			// Mapped forms such as ::ffff:10.0.0.1 are refused rather than
+			// sent on in a notation the controller may not accept.
+			if !addr.Is4() {
+				return fmt.Errorf("%q is not an IPv4 address", a)
+			}
+			continue
+		}
+		if p, err := netip.ParsePrefix(a); err != nil || !p.Addr().Is4() {
+			return fmt.Errorf("%q is not an IPv4 address or subnet", a)
+		}
+	}
+	for _, port := range f.PortFilter {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("port %d out of range 1-65535", port)
+		}
+	}
+	return nil
+}
+
+func (f *ACLRuleFilter) validateMAC() error {
+	if f == nil {
+		return nil
+	}
+	if f.Type != ACLFilterMACAddresses {
+		return fmt.Errorf("type %q must be %s for MAC rules", f.Type, ACLFilterMACAddresses)
+	}
+	if len(f.IPAddressesOrSubnets) > 0 || len(f.PortFilter) > 0 {
+		return errors.New("IP addresses and ports are not allowed in MAC rules")
+	}
+	for _, m := range f.MACAddresses {
+		if hw, err := net.ParseMAC(m); err != nil || len(hw) != 6 {
+			return fmt.Errorf("%q is not a MAC address", m)
+		}
+	}
+	return nil
 }
 
 // ACLRuleOrdering is returned by GET /integration/v1/sites/{siteId}/acl-rules/ordering.
@@ -276,6 +776,12 @@ type ACLRuleOrdering struct {
 	OrderedACLRuleIDs []string `json:"orderedAclRuleIds"`
 }
 
+// Known TrafficMatchingList.Type values.
+const (
+	TrafficMatchingListTypeIP   = "IP"
+	TrafficMatchingListTypePort = "PORT"
+)
+
 // TrafficMatchingList is returned by GET /integration/v1/sites/{siteId}/traffic-matching-lists.
 type TrafficMatchingList struct {
 	ID      string   `json:"id"`
@@ -284,6 +790,32 @@ type TrafficMatchingList struct {
 	Entries []string `json:"entries,omitempty"`
 }
 
+// TrafficMatchingListRequest is the body for POST /integration/v1/sites/{siteId}/traffic-matching-lists.
+type TrafficMatchingListRequest struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Entries []string `json:"entries"`
+}
+
+// TrafficMatchingListUpdate names the list settings to change; nil fields are
+// kept as they are. Entries replaces the whole list.
+type TrafficMatchingListUpdate struct {
+	Name    *string
+	Entries []string
+}
+
+// mergePatch returns u as an RFC 7396 merge patch over the controller object.
+func (u *TrafficMatchingListUpdate) mergePatch() map[string]any {
+	patch := make(map[string]any)
+	if u.Name != nil {
+		patch["name"] = *u.Name
+	}
+	if u.Entries != nil {
+		patch["entries"] = u.Entries
+	}
+	return patch
+}
+
 // WAN is returned by GET /integration/v1/sites/{siteId}/wans.
 type WAN struct {
 	ID        string   `json:"id"`
@@ -315,24 +847,295 @@ type VPNServer struct {
 	Enabled bool   `json:"enabled"`
 }
 
+// Known DNSPolicy.Type values.
+const (
+	DNSPolicyTypeA             = "A_RECORD"
+	DNSPolicyTypeAAAA          = "AAAA_RECORD"
+	DNSPolicyTypeCNAME         = "CNAME_RECORD"
+	DNSPolicyTypeMX            = "MX_RECORD"
+	DNSPolicyTypeTXT           = "TXT_RECORD"
+	DNSPolicyTypeSRV           = "SRV_RECORD"
+	DNSPolicyTypeForwardDomain = "FORWARD_DOMAIN"
+)
+
+// DNSPolicyTypes lists every DNSPolicy.Type value Validate accepts.
+var DNSPolicyTypes = []string{
+	DNSPolicyTypeA, DNSPolicyTypeAAAA, DNSPolicyTypeCNAME, DNSPolicyTypeMX,
+	DNSPolicyTypeTXT, DNSPolicyTypeSRV, DNSPolicyTypeForwardDomain,
+}
+
 // DNSPolicy is returned by GET /integration/v1/sites/{siteId}/dns/policies.
+// Which of the record fields are set depends on Type. Members the struct does
+// not model are kept in Extra and sent back by Request.
 type DNSPolicy struct {
-	ID          string `json:"id"`
-	Type        string `json:"type"`
-	Domain      string `json:"domain"`
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Domain string `json:"domain"`
+	// IPv4Address is the answer of an A_RECORD.
 	IPv4Address string `json:"ipv4Address,omitempty"`
-	TTLSeconds  int    `json:"ttlSeconds,omitempty"`
-	Enabled     bool   `json:"enabled"`
+	// IPv6Address is the answer of an AAAA_RECORD.
+	IPv6Address string `json:"ipv6Address,omitempty"`
+	// TargetDomain is the canonical name of a CNAME_RECORD.
+	TargetDomain string `json:"targetDomain,omitempty"`
+	// MailServerDomain is the exchange of an MX_RECORD.
+	MailServerDomain string `json:"mailServerDomain,omitempty"`
+	// Text is the content of a TXT_RECORD.
+	Text string `json:"text,omitempty"`
+	// ServerDomain, Service, Protocol, Port and Weight describe an SRV_RECORD,
+	// e.g. service "_sip", protocol "_tcp".
+	ServerDomain string `json:"serverDomain,omitempty"`
+	Service      string `json:"service,omitempty"`
+	Protocol     string `json:"protocol,omitempty"`
+	Port         int    `json:"port,omitempty"`
+	Weight       *int   `json:"weight,omitempty"`
+	// Priority applies to MX_RECORD and SRV_RECORD; lower is preferred.
+	Priority *int `json:"priority,omitempty"`
+	// IPAddress is the upstream server a FORWARD_DOMAIN sends queries to.
+	IPAddress  string `json:"ipAddress,omitempty"`
+	TTLSeconds int    `json:"ttlSeconds,omitempty"`
+	Enabled    bool   `json:"enabled"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, collecting unmodeled members in Extra.
+func (p *DNSPolicy) UnmarshalJSON(data []byte) error {
+	type plain DNSPolicy
+	extra, err := unmarshalWithExtra(data, (*plain)(p))
+	p.Extra = extra
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, writing Extra back alongside the modeled fields.
+func (p DNSPolicy) MarshalJSON() ([]byte, error) { //nolint:gocritic // value receiver so non-addressable values keep Extra
+	type plain DNSPolicy
+	return marshalWithExtra(plain(p), p.Extra)
+}
+
+// Request returns the policy as a create/update body, including its
+// unmodeled members, ready to be modified and sent back with UpdateDNSPolicy.
+func (p *DNSPolicy) Request() DNSPolicyRequest {
+	return DNSPolicyRequest{
+		Type:             p.Type,
+		Domain:           p.Domain,
+		IPv4Address:      p.IPv4Address,
+		IPv6Address:      p.IPv6Address,
+		TargetDomain:     p.TargetDomain,
+		MailServerDomain: p.MailServerDomain,
+		Text:             p.Text,
+		ServerDomain:     p.ServerDomain,
+		Service:          p.Service,
+		Protocol:         p.Protocol,
+		Port:             p.Port,
+		Weight:           cloneInt(p.Weight),
+		Priority:         cloneInt(p.Priority),
+		IPAddress:        p.IPAddress,
+		TTLSeconds:       p.TTLSeconds,
+		Enabled:          p.Enabled,
+		Extra:            maps.Clone(p.Extra),
+	}
+}
+
+func cloneInt(v *int) *int {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
 }
 
 // DNSPolicyRequest is the body for POST and PUT to /integration/v1/sites/{siteId}/dns/policies.
 // TTLSeconds is required by the API (must not be null); send 0 to use the server default.
+// Only the record fields of Type may be set; see Validate.
+// Extra members are sent as-is; DNSPolicy.Request fills them from a fetched policy.
 type DNSPolicyRequest struct {
-	Type        string `json:"type"`
-	Domain      string `json:"domain"`
+	Type   string `json:"type"`
+	Domain string `json:"domain"`
+	// IPv4Address is the answer of an A_RECORD.
 	IPv4Address string `json:"ipv4Address,omitempty"`
-	TTLSeconds  int    `json:"ttlSeconds"`
-	Enabled     bool   `json:"enabled"`
+	// IPv6Address is the answer of an AAAA_RECORD.
+	IPv6Address string `json:"ipv6Address,omitempty"`
+	// TargetDomain is the canonical name of a CNAME_RECORD.
+	TargetDomain string `json:"targetDomain,omitempty"`
+	// MailServerDomain is the exchange of an MX_RECORD.
+	MailServerDomain string `json:"mailServerDomain,omitempty"`
+	// Text is the content of a TXT_RECORD.
+	Text string `json:"text,omitempty"`
+	// ServerDomain, Service, Protocol, Port and Weight describe an SRV_RECORD,
+	// e.g. service "_sip", protocol "_tcp".
+	ServerDomain string `json:"serverDomain,omitempty"`
+	Service      string `json:"service,omitempty"`
+	Protocol     string `json:"protocol,omitempty"`
+	Port         int    `json:"port,omitempty"`
+	Weight       *int   `json:"weight,omitempty"`
+	// Priority applies to MX_RECORD and SRV_RECORD; lower is preferred.
+	Priority *int `json:"priority,omitempty"`
+	// IPAddress is the upstream server a FORWARD_DOMAIN sends queries to.
+	IPAddress  string `json:"ipAddress,omitempty"`
+	TTLSeconds int    `json:"ttlSeconds"`
+	Enabled    bool   `json:"enabled"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON implements json.Marshaler, writing Extra alongside the modeled fields.
+func (r DNSPolicyRequest) MarshalJSON() ([]byte, error) { //nolint:gocritic // value receiver so non-addressable values keep Extra
+	type plain DNSPolicyRequest
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+// maxDNSTTL is the largest TTL a record may carry (RFC 2181 §8).
+const maxDNSTTL = 1<<31 - 1
+
+// Validate checks r against the controller's DNS policy schema: the type is
+// known, the fields of that type are set and well formed, and no field of
+// another type is set.
+func (r DNSPolicyRequest) Validate() error {
+	if r.TTLSeconds < 0 || r.TTLSeconds > maxDNSTTL {
+		return fmt.Errorf("ttlSeconds %d is out of range", r.TTLSeconds)
+	}
+	// Fields of the type are cleared from d once checked, so any left over
+	// belong to another type.
+	d := r
+	d.Type, d.Domain, d.TTLSeconds, d.Enabled, d.Extra = "", "", 0, false, nil
+	switch r.Type {
+	case DNSPolicyTypeA, DNSPolicyTypeAAAA, DNSPolicyTypeCNAME, DNSPolicyTypeMX, DNSPolicyTypeTXT, DNSPolicyTypeSRV:
+		if err := checkDomainName(r.Domain, true); err != nil {
+			return fmt.Errorf("domain %q: %w", r.Domain, err)
+		}
+	case DNSPolicyTypeForwardDomain:
+		if err := checkDomainName(r.Domain, false); err != nil {
+			return fmt.Errorf("domain %q: %w", r.Domain, err)
+		}
+	default:
+		return fmt.Errorf("type %q must be one of %s", r.Type, strings.Join(DNSPolicyTypes, ", "))
+	}
+	switch r.Type {
+	case DNSPolicyTypeA:
+		if a, err := netip.ParseAddr(d.IPv4Address); err != nil || !a.Is4() {
+			return fmt.Errorf("ipv4Address %q is not an IPv4 address", d.IPv4Address)
+		}
+		d.IPv4Address = ""
+	case DNSPolicyTypeAAAA:
+		if a, err := netip.ParseAddr(d.IPv6Address); err != nil || !a.Is6() || a.Is4In6() || a.Zone() != "" {
+			return fmt.Errorf("ipv6Address %q is not an IPv6 address", d.IPv6Address)
+		}
+		d.IPv6Address = ""
+	case DNSPolicyTypeCNAME:
+		if err := checkDomainName(d.TargetDomain, false); err != nil {
+			return fmt.Errorf("targetDomain %q: %w", d.TargetDomain, err)
+		}
+		if strings.EqualFold(strings.TrimSuffix(d.TargetDomain, "."), strings.TrimSuffix(r.Domain, ".")) {
+			return errors.New("targetDomain must differ from domain")
+		}
+		d.TargetDomain = ""
+	case DNSPolicyTypeMX:
+		if err := checkDomainName(d.MailServerDomain, false); err != nil {
+			return fmt.Errorf("mailServerDomain %q: %w", d.MailServerDomain, err)
+		}
+		if err := checkUint16("priority", d.Priority); err != nil {
+			return err
+		}
+		d.MailServerDomain, d.Priority = "", nil
+	case DNSPolicyTypeTXT:
+		if d.Text == "" {
+			return errors.New("text is required")
+		}
+		for _, c := range d.Text {
+			if c < 0x20 || c > 0x7e {
+				return fmt.Errorf("text contains %q; only printable ASCII is allowed", c)
+			}
+		}
+		d.Text = ""
+	case DNSPolicyTypeSRV:
+		if err := checkDomainName(d.ServerDomain, false); err != nil {
+			return fmt.Errorf("serverDomain %q: %w", d.ServerDomain, err)
+		}
+		if !srvLabel.MatchString(d.Service) {
+			return fmt.Errorf("service %q must be a service label such as _sip", d.Service)
+		}
+		if !slices.Contains(srvProtocols, strings.ToLower(d.Protocol)) {
+			return fmt.Errorf("protocol %q must be one of %s", d.Protocol, strings.Join(srvProtocols, ", "))
+		}
+		if d.Port < 1 || d.Port > 65535 {
+			return fmt.Errorf("port %d out of range 1-65535", d.Port)
+		}
+		if err := checkUint16("priority", d.Priority); err != nil {
+			return err
+		}
+		if err := checkUint16("weight", d.Weight); err != nil {
+			return err
+		}
+		d.ServerDomain, d.Service, d.Protocol, d.Port, d.Priority, d.Weight = "", "", "", 0, nil, nil
+	case DNSPolicyTypeForwardDomain:
+		if a, err := netip.ParseAddr(d.IPAddress); err != nil || a.Zone() != "" {
+			return fmt.Errorf("ipAddress %q is not an IP address", d.IPAddress)
+		}
+		d.IPAddress = ""
+	}
+	if set := jsonSetFields(d); len(set) > 0 {
+		return fmt.Errorf("fields %s do not apply to %s policies", strings.Join(set, ", "), r.Type)
+	}
+	return nil
+}
+
+// srvProtocols are the SRV protocol labels accepted by Validate.
+var srvProtocols = []string{"_tcp", "_udp", "_tls"}
+
+// srvLabel matches an SRV service label such as "_sip" or "_xmpp-client".
+var srvLabel = regexp.MustCompile(`^_[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?$`)
+
+func checkUint16(name string, v *int) error {
+	if v == nil {
+		return fmt.Errorf("%s is required", name)
+	}
+	if *v < 0 || *v > 65535 {
+		return fmt.Errorf("%s %d out of range 0-65535", name, *v)
+	}
+	return nil
+}
+
+// checkDomainName checks the syntax of a DNS name, with or without a trailing
+// dot. A leading "*" label is allowed when wildcard is true.
+func checkDomainName(s string, wildcard bool) error {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" {
+		return errors.New("must not be empty")
+	}
+	if len(s) > 253 {
+		return errors.New("longer than 253 characters")
+	}
+	labels := strings.Split(s, ".")
+	for i, l := range labels {
+		if l == "*" && i == 0 && wildcard && len(labels) > 1 {
+			continue
+		}
+		if l == "" || len(l) > 63 {
+			return fmt.Errorf("label %q must be 1-63 characters", l)
+		}
+		if l[0] == '-' || l[len(l)-1] == '-' {
+			return fmt.Errorf("label %q starts or ends with a hyphen", l)
+		}
+		for _, c := range l {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return fmt.Errorf("label %q contains %q", l, c)
+			}
+		}
+	}
+	return nil
+}
+
+// jsonSetFields returns the JSON names of struct v's non-zero fields.
+func jsonSetFields(v any) []string {
+	rv := reflect.ValueOf(v)
+	var set []string
+	for i := range rv.NumField() {
+		if !rv.Field(i).IsZero() {
+			name, _, _ := strings.Cut(rv.Type().Field(i).Tag.Get("json"), ",")
+			set = append(set, name)
+		}
+	}
+	return set
 }
 
 // Voucher is returned by the hotspot voucher endpoints.
@@ -374,8 +1177,9 @@ type GuestAuthRequest struct {
 
 // DeviceTag is returned by GET /integration/v1/sites/{siteId}/device-tags.
 type DeviceTag struct {
-	ID   string `json:"id"`
-	Name string `json:"name,omitempty"`
+	ID        string   `json:"id"`
+	Name      string   `json:"name,omitempty"`
+	DeviceIDs []string `json:"deviceIds,omitempty"`
 }
 
 // DPICategory is returned by GET /integration/v1/dpi/categories.
@@ -397,3 +1201,59 @@ type RADIUSProfile struct {
 	Name     string                    `json:"name,omitempty"`
 	Metadata *FirewallResourceMetadata `json:"metadata,omitempty"`
 }
+
+// unmarshalWithExtra decodes data into v, a pointer to a struct without custom
+// JSON methods, and returns the object members none of v's fields claim.
+func unmarshalWithExtra(data []byte, v any) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	for name := range jsonFieldNames(reflect.TypeOf(v).Elem()) {
+		delete(all, name)
+	}
+	if len(all) == 0 {
+		return nil, nil
+	}
+	return all, nil
+}
+
+// marshalWithExtra encodes v, a struct without custom JSON methods, and adds
+// the members of extra that v does not already encode.
+func marshalWithExtra(v any, extra map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	known := jsonFieldNames(reflect.TypeOf(v))
+	for k, raw := range extra {
+		if _, ok := known[k]; !ok {
+			obj[k] = raw
+		}
+	}
+	return json.Marshal(obj)
+}
+
+// jsonFieldNames returns the JSON member names of struct type t's fields.
+func jsonFieldNames(t reflect.Type) map[string]struct{} {
+	names := make(map[string]struct{}, t.NumField())
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch {
+		case name == "-":
+			continue
+		case name == "":
+			name = f.Name
+		}
+		names[name] = struct{}{}
+	}
+	return names
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -92,3 +93,247 @@ func TestAPIError(t *testing.T) {
 		}
 	})
 }
+
+func TestACLRuleUnknownFields(t *testing.T) {
+	const body = `{
+		"id": "ar-1", "type": "IPV4", "name": "web", "action": "ALLOW", "enabled": true, "index": 3,
+		"sourceFilter": {"type": "IP_ADDRESSES_OR_SUBNETS", "ipAddressesOrSubnets": ["10.0.0.0/24"], "matchOpposite": true},
+		"protocolFilter": ["TCP"],
+		"loggingEnabled": true,
+		"schedule": {"mode": "ALWAYS"},
+		"metadata": {"origin": "USER_DEFINED", "configurable": true}
+	}`
+
+	var rule ACLRule
+	if err := json.Unmarshal([]byte(body), &rule); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	t.Run("modeled fields decoded", func(t *testing.T) {
+		if rule.SourceFilter == nil || rule.SourceFilter.IPAddressesOrSubnets[0] != "10.0.0.0/24" {
+			t.Errorf("SourceFilter = %+v", rule.SourceFilter)
+		}
+		if len(rule.Extra) != 2 {
+			t.Errorf("Extra = %v, want loggingEnabled and schedule", rule.Extra)
+		}
+		if _, ok := rule.Extra["id"]; ok {
+			t.Error("modeled member id leaked into Extra")
+		}
+	})
+
+	t.Run("request keeps unknown members", func(t *testing.T) {
+		req := rule.Request()
+		req.Enabled = false
+		data, err := json.Marshal(req)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		var got map[string]any
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got["loggingEnabled"] != true || got["schedule"] == nil {
+			t.Errorf("unknown top-level members dropped: %s", data)
+		}
+		sf, _ := got["sourceFilter"].(map[string]any)
+		if sf["matchOpposite"] != true {
+			t.Errorf("unknown filter member dropped: %s", data)
+		}
+		if got["enabled"] != false {
+			t.Errorf("enabled = %v, want false", got["enabled"])
+		}
+		for _, k := range []string{"id", "index", "metadata"} {
+			if _, ok := got[k]; ok {
+				t.Errorf("request contains read-only member %q", k)
+			}
+		}
+	})
+
+	t.Run("request does not alias rule", func(t *testing.T) {
+		req := rule.Request()
+		req.SourceFilter.IPAddressesOrSubnets[0] = "192.168.0.0/16"
+		req.Extra["schedule"] = json.RawMessage(`null`)
+		if rule.SourceFilter.IPAddressesOrSubnets[0] != "10.0.0.0/24" || string(rule.Extra["schedule"]) == "null" {
+			t.Error("modifying the request changed the rule")
+		}
+	})
+
+	t.Run("rule marshals extras", func(t *testing.T) {
+		data, err := json.Marshal(rule)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		var got map[string]any
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got["loggingEnabled"] != true || got["id"] != "ar-1" {
+			t.Errorf("got %s", data)
+		}
+	})
+}
+
+func TestACLRuleRequestValidate(t *testing.T) {
+	ipFilter := func(addrs []string, ports ...int) *ACLRuleFilter {
+		return &ACLRuleFilter{Type: ACLFilterIPAddresses, IPAddressesOrSubnets: addrs, PortFilter: ports}
+	}
+	macFilter := func(macs ...string) *ACLRuleFilter {
+		return &ACLRuleFilter{Type: ACLFilterMACAddresses, MACAddresses: macs}
+	}
+	base := func(typ string) ACLRuleRequest {
+		return ACLRuleRequest{Type: typ, Name: "r", Action: "BLOCK", Enabled: true}
+	}
+
+	cases := []struct {
+		name    string
+		mutate  func(r *ACLRuleRequest)
+		typ     string
+		wantErr bool
+	}{
+		{"minimal IPV4", func(*ACLRuleRequest) {}, ACLRuleTypeIPv4, false},
+		{"minimal MAC", func(*ACLRuleRequest) {}, ACLRuleTypeMAC, false},
+		{"unknown type", func(*ACLRuleRequest) {}, "IPV6", true},
+		{"missing name", func(r *ACLRuleRequest) { r.Name = "" }, ACLRuleTypeIPv4, true},
+		{"bad action", func(r *ACLRuleRequest) { r.Action = "DROP" }, ACLRuleTypeIPv4, true},
+		{"IPV4 full", func(r *ACLRuleRequest) {
+			r.SourceFilter = ipFilter([]string{"10.0.0.5", "10.0.1.0/24"})
+			r.DestinationFilter = ipFilter([]string{"192.168.1.10"}, 443, 8443)
+			r.ProtocolFilter = []string{"TCP"}
+			r.EnforcingDeviceFilter = &ACLDeviceFilter{Type: "DEVICES", DeviceIDs: []string{"sw-1"}}
+		}, ACLRuleTypeIPv4, false},
+		{"IPV4 bad subnet", func(r *ACLRuleRequest) { r.SourceFilter = ipFilter([]string{"10.0.0.0/33"}) }, ACLRuleTypeIPv4, true},
+		{"IPV4 rejects IPv6", func(r *ACLRuleRequest) { r.SourceFilter = ipFilter([]string{"fd00::/64"}) }, ACLRuleTypeIPv4, true},
+		{"IPV4 rejects bare IPv6", func(r *ACLRuleRequest) { r.SourceFilter = ipFilter([]string{"::1"}) }, ACLRuleTypeIPv4, true},
+		{"IPV4 rejects IPv4-mapped", func(r *ACLRuleRequest) { r.SourceFilter = ipFilter([]string{"::ffff:10.0.0.1"}) }, ACLRuleTypeIPv4, true},
+		{"IPV4 bad port", func(r *ACLRuleRequest) { r.DestinationFilter = ipFilter(nil, 70000) }, ACLRuleTypeIPv4, true},
+		{"IPV4 bad protocol", func(r *ACLRuleRequest) { r.ProtocolFilter = []string{"GRE"} }, ACLRuleTypeIPv4, true},
+		{"IPV4 with MAC filter", func(r *ACLRuleRequest) { r.SourceFilter = macFilter("aa:bb:cc:dd:ee:ff") }, ACLRuleTypeIPv4, true},
+		{"IPV4 with network scope", func(r *ACLRuleRequest) { r.NetworkIDFilter = "net-1" }, ACLRuleTypeIPv4, true},
+		{"MAC full", func(r *ACLRuleRequest) {
+			r.SourceFilter = macFilter("aa:bb:cc:dd:ee:ff")
+			r.DestinationFilter = macFilter("11-22-33-44-55-66")
+			r.NetworkIDFilter = "net-1"
+		}, ACLRuleTypeMAC, false},
+		{"MAC bad address", func(r *ACLRuleRequest) { r.SourceFilter = macFilter("aa:bb") }, ACLRuleTypeMAC, true},
+		{"MAC with protocol", func(r *ACLRuleRequest) { r.ProtocolFilter = []string{"TCP"} }, ACLRuleTypeMAC, true},
+		{"MAC with IP filter", func(r *ACLRuleRequest) { r.SourceFilter = ipFilter([]string{"10.0.0.1"}) }, ACLRuleTypeMAC, true},
+		{"empty enforcing devices", func(r *ACLRuleRequest) {
+			r.EnforcingDeviceFilter = &ACLDeviceFilter{Type: "DEVICES"}
+		}, ACLRuleTypeMAC, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := base(tc.typ)
+			tc.mutate(&r)
+			err := r.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestFirewallPolicyTrafficMatchingListIDs(t *testing.T) {
+	var p FirewallPolicy
+	err := json.Unmarshal([]byte(`{
+		"id": "fp-1", "name": "Block bad",
+		"source": {"zoneId": "z-1", "trafficFilter": {"type": "IP_ADDRESS",
+			"ipAddressFilter": {"type": "TRAFFIC_MATCHING_LIST", "trafficMatchingListId": "tml-ips"}}},
+		"destination": {"zoneId": "z-2", "trafficFilter": {"type": "PORT",
+			"ipAddressFilter": {"type": "TRAFFIC_MATCHING_LIST", "trafficMatchingListId": "tml-ips"},
+			"portFilter": {"type": "TRAFFIC_MATCHING_LIST", "trafficMatchingListId": "tml-ports"}}}
+	}`), &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := p.TrafficMatchingListIDs()
+	if len(got) != 2 || got[0] != "tml-ips" || got[1] != "tml-ports" {
+		t.Errorf("got %v, want [tml-ips tml-ports]", got)
+	}
+	if ids := (&FirewallPolicy{}).TrafficMatchingListIDs(); ids != nil {
+		t.Errorf("policy without filters: got %v", ids)
+	}
+}
+
+func TestDNSPolicyRequest(t *testing.T) {
+	var p DNSPolicy
+	err := json.Unmarshal([]byte(`{
+		"id": "dp-1", "type": "SRV_RECORD", "domain": "example.home",
+		"serverDomain": "sip.example.home", "service": "_sip", "protocol": "_tcp",
+		"port": 5060, "priority": 0, "weight": 5, "ttlSeconds": 300, "enabled": true,
+		"metadata": {"origin": "USER_DEFINED"}
+	}`), &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := p.Request()
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["priority"] != float64(0) || got["port"] != float64(5060) || got["metadata"] == nil {
+		t.Errorf("request dropped fields: %s", data)
+	}
+	*req.Weight = 9
+	if *p.Weight != 5 {
+		t.Error("modifying the request changed the policy")
+	}
+}
+
+func TestDNSPolicyRequestValidate(t *testing.T) {
+	intp := func(n int) *int { return &n }
+	cases := []struct {
+		name    string
+		req     DNSPolicyRequest
+		wantErr string
+	}{
+		{"A", DNSPolicyRequest{Type: DNSPolicyTypeA, Domain: "nas.home", IPv4Address: "192.168.1.10"}, ""},
+		{"A wildcard", DNSPolicyRequest{Type: DNSPolicyTypeA, Domain: "*.apps.home", IPv4Address: "192.168.1.10"}, ""},
+		{"A with IPv6", DNSPolicyRequest{Type: DNSPolicyTypeA, Domain: "nas.home", IPv4Address: "fd00::1"}, "not an IPv4"},
+		{"A missing address", DNSPolicyRequest{Type: DNSPolicyTypeA, Domain: "nas.home"}, "not an IPv4"},
+		{"A with CNAME target", DNSPolicyRequest{Type: DNSPolicyTypeA, Domain: "nas.home", IPv4Address: "10.0.0.1", TargetDomain: "x.home"}, "targetDomain do not apply"},
+		{"bad domain", DNSPolicyRequest{Type: DNSPolicyTypeA, Domain: "nas..home", IPv4Address: "10.0.0.1"}, "label"},
+		{"AAAA", DNSPolicyRequest{Type: DNSPolicyTypeAAAA, Domain: "nas.home", IPv6Address: "fd00::10"}, ""},
+		{"AAAA mapped IPv4", DNSPolicyRequest{Type: DNSPolicyTypeAAAA, Domain: "nas.home", IPv6Address: "::ffff:10.0.0.1"}, "not an IPv6"},
+		{"CNAME", DNSPolicyRequest{Type: DNSPolicyTypeCNAME, Domain: "files.home", TargetDomain: "nas.home."}, ""},
+		{"CNAME to itself", DNSPolicyRequest{Type: DNSPolicyTypeCNAME, Domain: "files.home", TargetDomain: "FILES.home"}, "must differ"},
+		{"CNAME bad target", DNSPolicyRequest{Type: DNSPolicyTypeCNAME, Domain: "files.home", TargetDomain: "nas home"}, "targetDomain"},
+		{"MX", DNSPolicyRequest{Type: DNSPolicyTypeMX, Domain: "home", MailServerDomain: "mail.home", Priority: intp(10)}, ""},
+		{"MX without priority", DNSPolicyRequest{Type: DNSPolicyTypeMX, Domain: "home", MailServerDomain: "mail.home"}, "priority is required"},
+		{"MX priority range", DNSPolicyRequest{Type: DNSPolicyTypeMX, Domain: "home", MailServerDomain: "mail.home", Priority: intp(70000)}, "out of range"},
+		{"TXT", DNSPolicyRequest{Type: DNSPolicyTypeTXT, Domain: "home", Text: "v=spf1 -all"}, ""},
+		{"TXT empty", DNSPolicyRequest{Type: DNSPolicyTypeTXT, Domain: "home"}, "text is required"},
+		{"TXT control char", DNSPolicyRequest{Type: DNSPolicyTypeTXT, Domain: "home", Text: "a\nb"}, "printable"},
+		{"SRV", DNSPolicyRequest{Type: DNSPolicyTypeSRV, Domain: "home", ServerDomain: "sip.home", Service: "_sip", Protocol: "_udp", Port: 5060, Priority: intp(0), Weight: intp(0)}, ""},
+		{"SRV bad service", DNSPolicyRequest{Type: DNSPolicyTypeSRV, Domain: "home", ServerDomain: "sip.home", Service: "sip", Protocol: "_udp", Port: 5060, Priority: intp(0), Weight: intp(0)}, "service"},
+		{"SRV bad protocol", DNSPolicyRequest{Type: DNSPolicyTypeSRV, Domain: "home", ServerDomain: "sip.home", Service: "_sip", Protocol: "tcp", Port: 5060, Priority: intp(0), Weight: intp(0)}, "protocol"},
+		{"SRV no port", DNSPolicyRequest{Type: DNSPolicyTypeSRV, Domain: "home", ServerDomain: "sip.home", Service: "_sip", Protocol: "_tcp", Priority: intp(0), Weight: intp(0)}, "port"},
+		{"SRV no weight", DNSPolicyRequest{Type: DNSPolicyTypeSRV, Domain: "home", ServerDomain: "sip.home", Service: "_sip", Protocol: "_tcp", Port: 5060, Priority: intp(0)}, "weight is required"},
+		{"forward", DNSPolicyRequest{Type: DNSPolicyTypeForwardDomain, Domain: "corp.example.com", IPAddress: "10.1.0.53"}, ""},
+		{"forward wildcard", DNSPolicyRequest{Type: DNSPolicyTypeForwardDomain, Domain: "*.corp.example.com", IPAddress: "10.1.0.53"}, "label"},
+		{"forward with text", DNSPolicyRequest{Type: DNSPolicyTypeForwardDomain, Domain: "corp.example.com", IPAddress: "10.1.0.53", Text: "x"}, "text do not apply"},
+		{"unknown type", DNSPolicyRequest{Type: "PTR_RECORD", Domain: "home"}, "must be one of"},
+		{"negative TTL", DNSPolicyRequest{Type: DNSPolicyTypeA, Domain: "nas.home", IPv4Address: "10.0.0.1", TTLSeconds: -1}, "ttlSeconds"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.req.Validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
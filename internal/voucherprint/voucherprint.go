@@ -0,0 +1,289 @@
+// Package voucherprint renders hotspot vouchers as a printable HTML sheet of
+// cut-out cards and as CSV for mail merges and label printers.
+package voucherprint
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+	"github.com/gordcurrie/unifi-mcp/internal/wifiqr"
+)
+
+// Field is one item a card can show.
+type Field string
+
+// Card fields, in the order they are printed.
+const (
+	FieldSite      Field = "site"
+	FieldSSID      Field = "ssid"
+	FieldDuration  Field = "duration"
+	FieldDataLimit Field = "data_limit"
+	FieldCode      Field = "code"
+	FieldQR        Field = "qr"
+)
+
+// Fields lists every Field.
+var Fields = []Field{FieldSite, FieldSSID, FieldDuration, FieldDataLimit, FieldCode, FieldQR}
+
+// ParseFields checks names against Fields. An empty list selects them all.
+func ParseFields(names []string) ([]Field, error) {
+	if len(names) == 0 {
+		return Fields, nil
+	}
+	out := make([]Field, 0, len(names))
+	for _, n := range names {
+		f := Field(strings.ToLower(strings.TrimSpace(n)))
+		found := false
+		for _, k := range Fields {
+			found = found || k == f
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown card field %q (use site, ssid, duration, data_limit, code or qr)", n)
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// QR selects what a card's QR code holds.
+type QR string
+
+// QR contents.
+const (
+	// QRWiFi joins the guest network SSID, which then shows the portal where
+	// the code is entered.
+	QRWiFi QR = "wifi"
+	// QRCode holds the voucher code itself, for portals with a scanner.
+	QRCode QR = "code"
+)
+
+// ParseQR checks a QR content name; empty picks QRWiFi when ssid is set and
+// QRCode otherwise.
+func ParseQR(s, ssid string) (QR, error) {
+	switch q := QR(strings.ToLower(strings.TrimSpace(s))); q {
+	case "":
+		if ssid != "" {
+			return QRWiFi, nil
+		}
+		return QRCode, nil
+	case QRWiFi:
+		if ssid == "" {
+			return "", fmt.Errorf("a wifi QR code needs the SSID")
+		}
+		return q, nil
+	case QRCode:
+		return q, nil
+	default:
+		return "", fmt.Errorf("unknown QR content %q (use wifi or code)", s)
+	}
+}
+
+// MaxColumns bounds Sheet.Columns.
+const MaxColumns = 6
+
+// qrSize is the edge length in pixels of card QR codes: sharp at the printed
+// size of about 3 cm without bloating the sheet.
+const qrSize = 256
+
+// Sheet describes the printed page.
+type Sheet struct {
+	// Title heads the page; empty prints none.
+	Title    string
+	SiteName string
+	SSID     string
+	// Security is the SSID's UniFi security type, such as OPEN or
+	// WPA2_PERSONAL, and Passphrase its key; a wifi QR code needs both (no
+	// passphrase for OPEN). Hidden marks an SSID that is not broadcast.
+	Security   string
+	Passphrase string
+	Hidden     bool
+	// Note is printed on every card below the other fields, e.g. "Valid
+	// from first use".
+	Note string
+	// Columns is the number of cards per row; zero means 3.
+	Columns int
+	// Fields are shown on each card; nil shows them all. Fields with no value
+	// (an SSID that was not given) are left out.
+	Fields []Field
+	QR     QR
+}
+
+// card is one voucher as the template prints it.
+type card struct {
+	Site, SSID, Duration, DataLimit, Code, Note string
+	QR                                          template.URL
+}
+
+//go:embed sheet.html.tmpl
+var sheetHTML string
+
+var sheetTemplate = template.Must(template.New("sheet").Parse(sheetHTML))
+
+// HTML writes vouchers as a sheet of cards.
+func HTML(w io.Writer, s Sheet, vouchers []unifi.Voucher) error {
+	if s.Columns == 0 {
+		s.Columns = 3
+	}
+	if s.Columns < 1 || s.Columns > MaxColumns {
+		return fmt.Errorf("columns must be 1-%d", MaxColumns)
+	}
+	fields := s.Fields
+	if fields == nil {
+		fields = Fields
+	}
+	show := make(map[Field]bool, len(fields))
+	for _, f := range fields {
+		show[f] = true
+	}
+
+	var wifiQR template.URL
+	if show[FieldQR] && s.QR == QRWiFi {
+		content, err := s.wifiURI()
+		if err != nil {
+			return err
+		}
+		if wifiQR, err = qrDataURI(content); err != nil {
+			return err
+		}
+	}
+	cards := make([]card, len(vouchers))
+	for i := range vouchers {
+		v := &vouchers[i]
+		c := card{Note: s.Note}
+		if show[FieldSite] {
+			c.Site = s.SiteName
+		}
+		if show[FieldSSID] {
+			c.SSID = s.SSID
+		}
+		if show[FieldDuration] {
+			c.Duration = Duration(v.TimeLimitMinutes)
+		}
+		if show[FieldDataLimit] {
+			c.DataLimit = DataLimit(v.DataLimitMb)
+		}
+		if show[FieldCode] {
+			c.Code = FormatCode(v.Code)
+		}
+		switch {
+		case !show[FieldQR]:
+		case s.QR == QRWiFi:
+			c.QR = wifiQR
+		case v.Code != "":
+			uri, err := qrDataURI(v.Code)
+			if err != nil {
+				return err
+			}
+			c.QR = uri
+		}
+		cards[i] = c
+	}
+	return sheetTemplate.Execute(w, struct {
+		Title   string
+		Columns int
+		Cards   []card
+	}{s.Title, s.Columns, cards})
+}
+
+// wifiURI returns the WIFI: URI that joins the sheet's SSID.
+func (s *Sheet) wifiURI() (string, error) {
+	switch {
+	case s.Security == "":
+		return "", fmt.Errorf("a wifi QR code needs the security type of %s", s.SSID)
+	case !strings.EqualFold(s.Security, "OPEN") && s.Passphrase == "":
+		return "", fmt.Errorf("a wifi QR code for %s (%s) needs its passphrase", s.SSID, s.Security)
+	}
+	return wifiqr.URI(wifiqr.Network{SSID: s.SSID, SecurityType: s.Security, Passphrase: s.Passphrase, Hidden: s.Hidden}), nil
+}
+
+func qrDataURI(content string) (template.URL, error) {
+	png, err := wifiqr.PNG(content, qrSize)
+	if err != nil {
+		return "", fmt.Errorf("QR code: %w", err)
+	}
+	return template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png)), nil //nolint:gosec // base64 PNG bytes only, no user text
+}
+
+// CSV writes one row per voucher after a header row.
+func CSV(w io.Writer, s Sheet, vouchers []unifi.Voucher) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"code", "name", "site", "ssid", "duration", "time_limit_minutes", "data_limit_mb", "usage_quota", "status", "created_at", "expires_at"})
+	for i := range vouchers {
+		v := &vouchers[i]
+		row := []string{
+			FormatCode(v.Code), v.Name, s.SiteName, s.SSID, Duration(v.TimeLimitMinutes),
+			strconv.Itoa(v.TimeLimitMinutes), strconv.Itoa(v.DataLimitMb), strconv.Itoa(v.UsageQuota),
+			v.Status, v.CreatedAt, v.ExpiresAt,
+		}
+		for j := range row {
+			row[j] = csvCell(row[j])
+		}
+		_ = cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvCell defuses a cell a spreadsheet would run as a formula, such as a
+// voucher name of "=HYPERLINK(...)", by prefixing it with a quote.
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// FormatCode splits a ten-digit voucher code as the UniFi portal shows it,
+// e.g. "12345-67890". Other codes are returned unchanged.
+func FormatCode(code string) string {
+	if len(code) != 10 || strings.Trim(code, "0123456789") != "" {
+		return code
+	}
+	return code[:5] + "-" + code[5:]
+}
+
+// Duration renders a voucher time limit, e.g. "8 hours" or "1 day 12 hours".
+func Duration(minutes int) string {
+	if minutes <= 0 {
+		return "Unlimited"
+	}
+	units := []struct {
+		name string
+		min  int
+	}{{"day", 24 * 60}, {"hour", 60}, {"minute", 1}}
+	var parts []string
+	for _, u := range units {
+		if n := minutes / u.min; n > 0 {
+			parts = append(parts, plural(n, u.name))
+			minutes -= n * u.min
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// DataLimit renders a voucher data cap, switching to GB (1024 MB) from 1 GB.
+func DataLimit(mb int) string {
+	switch {
+	case mb <= 0:
+		return "Unlimited"
+	case mb < 1024:
+		return strconv.Itoa(mb) + " MB"
+	default:
+		return strconv.FormatFloat(math.Round(float64(mb)/1024*100)/100, 'f', -1, 64) + " GB"
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return strconv.Itoa(n) + " " + unit + "s"
+}
@@ -0,0 +1,147 @@
+package voucherprint
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+func TestFormatting(t *testing.T) {
+	tests := []struct {
+		got, want string
+	}{
+		{FormatCode("1234567890"), "12345-67890"},
+		{FormatCode("ABC123"), "ABC123"},
+		{Duration(0), "Unlimited"},
+		{Duration(90), "1 hour 30 minutes"},
+		{Duration(3 * 24 * 60), "3 days"},
+		{DataLimit(0), "Unlimited"},
+		{DataLimit(500), "500 MB"},
+		{DataLimit(1536), "1.5 GB"},
+	}
+	for _, tc := range tests {
+		if tc.got != tc.want {
+			t.Errorf("got %q, want %q", tc.got, tc.want)
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+	vouchers := []unifi.Voucher{
+		{Code: "1234567890", TimeLimitMinutes: 480},
+		{Code: "0987654321", DataLimitMb: 2048},
+	}
+	tests := []struct {
+		name    string
+		sheet   Sheet
+		want    []string
+		notWant []string
+	}{
+		{
+			name:  "all fields, wifi QR",
+			sheet: Sheet{Title: "Lobby <guests>", SiteName: "HQ", SSID: "Guest", Security: "OPEN", QR: QRWiFi, Note: "Valid from first use"},
+			want: []string{
+				"<h1>Lobby &lt;guests&gt;</h1>", "repeat(3, 1fr)", "12345-67890", "09876-54321",
+				"Time: 8 hours", "Data: 2 GB", "Network: <strong>Guest</strong>", "data:image/png;base64,", "Valid from first use",
+			},
+		},
+		{
+			name:    "code and duration only",
+			sheet:   Sheet{SiteName: "HQ", SSID: "Guest", Columns: 4, Fields: []Field{FieldCode, FieldDuration}, QR: QRCode},
+			want:    []string{"repeat(4, 1fr)", "12345-67890", "Time: Unlimited"},
+			notWant: []string{"<h1>", "HQ", "Guest", "<img", "Data:"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := HTML(&b, tc.sheet, vouchers); err != nil {
+				t.Fatal(err)
+			}
+			out := b.String()
+			for _, w := range tc.want {
+				if !strings.Contains(out, w) {
+					t.Errorf("output lacks %q", w)
+				}
+			}
+			for _, w := range tc.notWant {
+				if strings.Contains(out, w) {
+					t.Errorf("output has %q", w)
+				}
+			}
+		})
+	}
+	if err := HTML(&bytes.Buffer{}, Sheet{Columns: MaxColumns + 1}, vouchers); err == nil {
+		t.Error("too many columns accepted")
+	}
+	if err := HTML(&bytes.Buffer{}, Sheet{SSID: "Guest", QR: QRWiFi}, vouchers); err == nil {
+		t.Error("wifi QR without a security type accepted")
+	}
+}
+
+func TestWiFiURI(t *testing.T) {
+	tests := []struct {
+		sheet   Sheet
+		want    string
+		wantErr string
+	}{
+		{Sheet{SSID: "Guest", Security: "OPEN"}, "WIFI:T:nopass;S:Guest;;", ""},
+		{Sheet{SSID: "Guest", Security: "WPA2_PERSONAL", Passphrase: "s3cret;pass"}, `WIFI:T:WPA;S:Guest;P:s3cret\;pass;;`, ""},
+		{Sheet{SSID: "Back", Security: "WPA3_PERSONAL", Passphrase: "x", Hidden: true}, "WIFI:T:WPA;S:Back;P:x;H:true;;", ""},
+		{Sheet{SSID: "Guest"}, "", "needs the security type"},
+		{Sheet{SSID: "Guest", Security: "WPA2_PERSONAL"}, "", "needs its passphrase"},
+	}
+	for _, tc := range tests {
+		got, err := tc.sheet.wifiURI()
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("%+v: got %q, %v; want error containing %q", tc.sheet, got, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("%+v: got %q, %v; want %q", tc.sheet, got, err, tc.want)
+		}
+	}
+}
+
+func TestCSV(t *testing.T) {
+	var b bytes.Buffer
+	err := CSV(&b, Sheet{SiteName: "HQ", SSID: "Guest, 5G"}, []unifi.Voucher{{Code: "1234567890", Name: "lobby", TimeLimitMinutes: 60, Status: "UNUSED"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "code,name,site,ssid,duration,time_limit_minutes,data_limit_mb,usage_quota,status,created_at,expires_at\n" +
+		`12345-67890,lobby,HQ,"Guest, 5G",1 hour,60,0,0,UNUSED,,` + "\n"
+	if b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+
+	b.Reset()
+	err = CSV(&b, Sheet{SiteName: "@HQ"}, []unifi.Voucher{{Code: "1234567890", Name: "=HYPERLINK(\"x\")", Status: "-1+1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "code,name,site,ssid,duration,time_limit_minutes,data_limit_mb,usage_quota,status,created_at,expires_at\n" +
+		`12345-67890,"'=HYPERLINK(""x"")",'@HQ,,Unlimited,0,0,0,'-1+1,,` + "\n"
+	if b.String() != want {
+		t.Errorf("formula cells: got %q, want %q", b.String(), want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	if _, err := ParseFields([]string{"code", "barcode"}); err == nil {
+		t.Error("unknown field accepted")
+	}
+	if q, err := ParseQR("", "Guest"); err != nil || q != QRWiFi {
+		t.Errorf("ParseQR default with SSID = %q, %v", q, err)
+	}
+	if q, err := ParseQR("", ""); err != nil || q != QRCode {
+		t.Errorf("ParseQR default without SSID = %q, %v", q, err)
+	}
+	if _, err := ParseQR("wifi", ""); err == nil {
+		t.Error("wifi QR without SSID accepted")
+	}
+}
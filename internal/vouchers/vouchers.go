@@ -0,0 +1,202 @@
+// Package vouchers classifies hotspot vouchers by where they are in their
+// life, summarizes a site's vouchers and picks those worth purging.
+package vouchers
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+// State is where a voucher is in its life.
+type State string
+
+// Voucher states. A voucher is in exactly one; expiry takes precedence.
+const (
+	// Expired vouchers can no longer authorize anyone.
+	Expired State = "expired"
+	// UsedUp vouchers have authorized as many guests as their quota allows.
+	UsedUp State = "used_up"
+	// Unused vouchers have never been redeemed.
+	Unused State = "unused"
+	// Active vouchers have been redeemed and can still be used or are still
+	// running.
+	Active State = "active"
+)
+
+// Classify returns v's state at now.
+func Classify(v *unifi.Voucher, now time.Time) State {
+	switch {
+	case v.Expired:
+		return Expired
+	case v.ExpiresAt != "":
+		if t, err := time.Parse(time.RFC3339, v.ExpiresAt); err == nil && !t.After(now) {
+			return Expired
+		}
+	}
+	switch {
+	case v.UsageQuota > 0 && v.UsageCount >= v.UsageQuota:
+		return UsedUp
+	case v.UsageCount == 0 && v.AuthorizedGuestCount == 0:
+		return Unused
+	default:
+		return Active
+	}
+}
+
+// Remaining returns how long v stays valid after now. It reports false when
+// the voucher has no expiry time yet, which is the case until first use for
+// vouchers whose time limit starts then.
+func Remaining(v *unifi.Voucher, now time.Time) (time.Duration, bool) {
+	t, err := time.Parse(time.RFC3339, v.ExpiresAt)
+	if err != nil {
+		return 0, false
+	}
+	return max(t.Sub(now), 0), true
+}
+
+// Entry is one voucher in a Summary.
+type Entry struct {
+	ID               string `json:"id"`
+	Code             string `json:"code,omitempty"`
+	Name             string `json:"name,omitempty"`
+	State            State  `json:"state"`
+	Status           string `json:"status,omitempty"`
+	UsageCount       int    `json:"usageCount"`
+	UsageQuota       int    `json:"usageQuota,omitempty"`
+	AuthorizedGuests int    `json:"authorizedGuests"`
+	// Remaining is the time left before expiry, e.g. "5h30m0s", for
+	// vouchers that are not expired and have an expiry time.
+	Remaining string `json:"remaining,omitempty"`
+	CreatedAt string `json:"createdAt,omitempty"`
+	ExpiresAt string `json:"expiresAt,omitempty"`
+}
+
+// Batch summarizes the vouchers sharing a name, which is how vouchers
+// generated together are labelled.
+type Batch struct {
+	Name    string `json:"name"`
+	Total   int    `json:"total"`
+	Unused  int    `json:"unused"`
+	Active  int    `json:"active"`
+	UsedUp  int    `json:"usedUp"`
+	Expired int    `json:"expired"`
+	// Uses and Quota add up UsageCount and UsageQuota; a quota of zero
+	// (unlimited) adds nothing.
+	Uses             int `json:"uses"`
+	Quota            int `json:"quota"`
+	AuthorizedGuests int `json:"authorizedGuests"`
+	// NextExpiry is the earliest expiry among the batch's vouchers that are
+	// not expired yet.
+	NextExpiry string `json:"nextExpiry,omitempty"`
+}
+
+// Summary is a site's vouchers at a point in time.
+type Summary struct {
+	Total            int            `json:"total"`
+	ByState          map[State]int  `json:"byState"`
+	ByStatus         map[string]int `json:"byStatus"`
+	AuthorizedGuests int            `json:"authorizedGuests"`
+	Batches          []Batch        `json:"batches"`
+	// Vouchers is only filled when asked for.
+	Vouchers []Entry `json:"vouchers,omitempty"`
+}
+
+// Summarize builds the summary of vs at now. Batches are sorted by name;
+// entries, when withEntries is set, by batch and then code.
+func Summarize(vs []unifi.Voucher, now time.Time, withEntries bool) Summary {
+	s := Summary{Total: len(vs), ByState: map[State]int{}, ByStatus: map[string]int{}, Batches: []Batch{}}
+	batches := map[string]*Batch{}
+	nextExpiry := map[string]time.Time{}
+	for i := range vs {
+		v := &vs[i]
+		state := Classify(v, now)
+		s.ByState[state]++
+		if v.Status != "" {
+			s.ByStatus[v.Status]++
+		}
+		s.AuthorizedGuests += v.AuthorizedGuestCount
+
+		b := batches[v.Name]
+		if b == nil {
+			b = &Batch{Name: v.Name}
+			batches[v.Name] = b
+		}
+		b.Total++
+		switch state {
+		case Unused:
+			b.Unused++
+		case Active:
+			b.Active++
+		case UsedUp:
+			b.UsedUp++
+		case Expired:
+			b.Expired++
+		}
+		b.Uses += v.UsageCount
+		b.Quota += v.UsageQuota
+		b.AuthorizedGuests += v.AuthorizedGuestCount
+
+		e := Entry{
+			ID: v.ID, Code: v.Code, Name: v.Name, State: state, Status: v.Status,
+			UsageCount: v.UsageCount, UsageQuota: v.UsageQuota, AuthorizedGuests: v.AuthorizedGuestCount,
+			CreatedAt: v.CreatedAt, ExpiresAt: v.ExpiresAt,
+		}
+		if left, ok := Remaining(v, now); ok && state != Expired {
+			e.Remaining = left.Round(time.Second).String()
+			if t := now.Add(left); nextExpiry[v.Name].IsZero() || t.Before(nextExpiry[v.Name]) {
+				nextExpiry[v.Name] = t
+				b.NextExpiry = v.ExpiresAt
+			}
+		}
+		if withEntries {
+			s.Vouchers = append(s.Vouchers, e)
+		}
+	}
+	for _, b := range batches {
+		s.Batches = append(s.Batches, *b)
+	}
+	slices.SortFunc(s.Batches, func(a, b Batch) int { return cmp.Compare(a.Name, b.Name) })
+	slices.SortFunc(s.Vouchers, func(a, b Entry) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Code, b.Code))
+	})
+	return s
+}
+
+// PurgeRule selects vouchers to delete. A voucher matching any enabled
+// criterion is selected.
+type PurgeRule struct {
+	Expired bool
+	// UsedUp selects vouchers whose quota is reached, except those still
+	// running for authorized guests: deleting one would cut them off.
+	UsedUp bool
+	// UnusedOlderThan selects never-used vouchers created at least this long
+	// ago; zero disables the criterion.
+	UnusedOlderThan time.Duration
+}
+
+// IsZero reports whether r selects nothing.
+func (r PurgeRule) IsZero() bool {
+	return !r.Expired && !r.UsedUp && r.UnusedOlderThan <= 0
+}
+
+// Match reports whether r selects v at now, and why.
+func (r PurgeRule) Match(v *unifi.Voucher, now time.Time) (string, bool) {
+	switch state := Classify(v, now); {
+	case r.Expired && state == Expired:
+		return "expired", true
+	case r.UsedUp && state == UsedUp:
+		if left, ok := Remaining(v, now); v.AuthorizedGuestCount > 0 && (!ok || left > 0) {
+			return "", false
+		}
+		return "usage quota reached", true
+	case r.UnusedOlderThan > 0 && state == Unused:
+		created, err := time.Parse(time.RFC3339, v.CreatedAt)
+		if err == nil && now.Sub(created) >= r.UnusedOlderThan {
+			return "never used, created " + v.CreatedAt, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,98 @@
+package vouchers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+var now = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		v    unifi.Voucher
+		want State
+	}{
+		{"flagged expired", unifi.Voucher{Expired: true, UsageCount: 0}, Expired},
+		{"past expiry", unifi.Voucher{ExpiresAt: "2026-05-01T11:00:00Z", UsageCount: 1, UsageQuota: 1}, Expired},
+		{"quota reached", unifi.Voucher{ExpiresAt: "2026-05-02T00:00:00Z", UsageCount: 1, UsageQuota: 1}, UsedUp},
+		{"never used", unifi.Voucher{UsageQuota: 1}, Unused},
+		{"multi-use in use", unifi.Voucher{UsageCount: 3}, Active},
+		{"guest without usage count", unifi.Voucher{AuthorizedGuestCount: 1}, Active},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Classify(&tc.v, now); got != tc.want {
+				t.Errorf("Classify = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	vs := []unifi.Voucher{
+		{ID: "1", Code: "b", Name: "lobby", UsageCount: 1, UsageQuota: 2, AuthorizedGuestCount: 1, ExpiresAt: "2026-05-01T18:00:00Z"},
+		{ID: "2", Code: "a", Name: "lobby", UsageQuota: 1, ExpiresAt: "2026-05-01T14:00:00Z"},
+		{ID: "3", Code: "c", Name: "event", Expired: true, Status: "EXPIRED"},
+	}
+	s := Summarize(vs, now, true)
+	if s.Total != 3 || s.ByState[Active] != 1 || s.ByState[Unused] != 1 || s.ByState[Expired] != 1 || s.ByStatus["EXPIRED"] != 1 {
+		t.Errorf("counts = %+v", s)
+	}
+	if len(s.Batches) != 2 || s.Batches[0].Name != "event" {
+		t.Fatalf("batches = %+v", s.Batches)
+	}
+	lobby := s.Batches[1]
+	if lobby.Total != 2 || lobby.Uses != 1 || lobby.Quota != 3 || lobby.AuthorizedGuests != 1 || lobby.NextExpiry != "2026-05-01T14:00:00Z" {
+		t.Errorf("lobby = %+v", lobby)
+	}
+	if len(s.Vouchers) != 3 || s.Vouchers[1].Code != "a" || s.Vouchers[1].Remaining != "2h0m0s" || s.Vouchers[0].Remaining != "" {
+		t.Errorf("vouchers = %+v", s.Vouchers)
+	}
+}
+
+func TestPurgeRuleMatch(t *testing.T) {
+	rule := PurgeRule{Expired: true, UnusedOlderThan: 30 * 24 * time.Hour}
+	tests := []struct {
+		name string
+		v    unifi.Voucher
+		want bool
+	}{
+		{"expired", unifi.Voucher{Expired: true}, true},
+		{"used up, not selected", unifi.Voucher{UsageCount: 1, UsageQuota: 1}, false},
+		{"old unused", unifi.Voucher{CreatedAt: "2026-03-01T00:00:00Z"}, true},
+		{"recent unused", unifi.Voucher{CreatedAt: "2026-04-20T00:00:00Z"}, false},
+		{"unused without creation time", unifi.Voucher{}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, got := rule.Match(&tc.v, now); got != tc.want {
+				t.Errorf("Match = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	used := PurgeRule{UsedUp: true}
+	usedTests := []struct {
+		name string
+		v    unifi.Voucher
+		want bool
+	}{
+		{"used up, no guests", unifi.Voucher{UsageCount: 1, UsageQuota: 1, ExpiresAt: "2026-05-02T00:00:00Z"}, true},
+		{"used up, guests with time left", unifi.Voucher{UsageCount: 2, UsageQuota: 2, AuthorizedGuestCount: 2, ExpiresAt: "2026-05-02T00:00:00Z"}, false},
+		{"used up, guests, no expiry yet", unifi.Voucher{UsageCount: 1, UsageQuota: 1, AuthorizedGuestCount: 1}, false},
+		{"unused", unifi.Voucher{UsageQuota: 1}, false},
+	}
+	for _, tc := range usedTests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, got := used.Match(&tc.v, now); got != tc.want {
+				t.Errorf("Match = %v, want %v", got, tc.want)
+			}
+		})
+	}
+	if !(PurgeRule{}).IsZero() {
+		t.Error("empty rule is not zero")
+	}
+}
@@ -0,0 +1,73 @@
+// Package wifiqr builds WIFI: join URIs and renders them as QR codes that
+// phone cameras recognise.
+//
+// Encoding uses github.com/skip2/go-qrcode: a pure-Go, MIT-licensed encoder
+// with no dependencies of its own and no cgo. The symbol selection,
+// Reed-Solomon and masking a QR encoder needs are too much to maintain here,
+// and the module is pinned by go.sum.
+package wifiqr
+
+import (
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// DefaultSize is the PNG edge length in pixels used when Size is zero.
+const DefaultSize = 512
+
+// Network is what a WIFI: URI describes.
+type Network struct {
+	SSID string
+	// SecurityType is a UniFi security type such as WPA2_PERSONAL or OPEN.
+	SecurityType string
+	Passphrase   string
+	Hidden       bool
+}
+
+// URI returns n in the WIFI: URI format (as defined by ZXing and adopted by
+// the Wi-Fi Alliance), e.g. WIFI:T:WPA;S:guest;P:secret;;
+func URI(n Network) string {
+	var b strings.Builder
+	b.WriteString("WIFI:T:")
+	if strings.EqualFold(n.SecurityType, "OPEN") {
+		b.WriteString("nopass")
+	} else {
+		// WPA covers WPA2 and WPA3 personal; phones pick the mode from the
+		// access point's beacon.
+		b.WriteString("WPA")
+	}
+	b.WriteString(";S:")
+	b.WriteString(escape(n.SSID))
+	if !strings.EqualFold(n.SecurityType, "OPEN") {
+		b.WriteString(";P:")
+		b.WriteString(escape(n.Passphrase))
+	}
+	if n.Hidden {
+		b.WriteString(";H:true")
+	}
+	b.WriteString(";;")
+	return b.String()
+}
+
+// escape backslash-escapes the characters the WIFI: format reserves.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`\;,:"`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// PNG renders content as a QR code PNG size pixels square. Medium error
+// correction keeps the code scannable from a slightly damaged printout without
+// making it too dense for a phone at arm's length.
+func PNG(content string, size int) ([]byte, error) {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return qrcode.Encode(content, qrcode.Medium, size)
+}
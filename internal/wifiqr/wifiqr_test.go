@@ -0,0 +1,57 @@
+package wifiqr
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestURI(t *testing.T) {
+	tests := []struct {
+		name string
+		n    Network
+		want string
+	}{
+		{
+			name: "wpa2",
+			n:    Network{SSID: "guest", SecurityType: "WPA2_PERSONAL", Passphrase: "correct-horse"},
+			want: "WIFI:T:WPA;S:guest;P:correct-horse;;",
+		},
+		{
+			name: "wpa3 hidden",
+			n:    Network{SSID: "lab", SecurityType: "WPA3_PERSONAL", Passphrase: "s3cretpass", Hidden: true},
+			want: "WIFI:T:WPA;S:lab;P:s3cretpass;H:true;;",
+		},
+		{
+			name: "open",
+			n:    Network{SSID: "cafe", SecurityType: "OPEN", Passphrase: "ignored"},
+			want: "WIFI:T:nopass;S:cafe;;",
+		},
+		{
+			name: "reserved characters escaped",
+			n:    Network{SSID: `my;net,"1":x\`, SecurityType: "WPA2_PERSONAL", Passphrase: `a;b:c`},
+			want: `WIFI:T:WPA;S:my\;net\,\"1\"\:x\\;P:a\;b\:c;;`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := URI(tc.n); got != tc.want {
+				t.Errorf("URI = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPNG(t *testing.T) {
+	data, err := PNG("WIFI:T:WPA;S:guest;P:correct-horse;;", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != DefaultSize || b.Dy() != DefaultSize {
+		t.Errorf("size = %dx%d, want %dx%d", b.Dx(), b.Dy(), DefaultSize, DefaultSize)
+	}
+}
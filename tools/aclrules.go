@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+// aclRuleFields are the optional match and scope inputs shared by
+// create_acl_rule and update_acl_rule. Every list is a comma-separated *string
+// (see the NetworkIDs comment in firewallZoneMutateInput): nil leaves the
+// current value alone on update, and "" clears it.
+type aclRuleFields struct {
+	Description           *string `json:"description,omitempty"             jsonschema:"free-text description; empty string clears"`
+	SourceIPs             *string `json:"source_ips,omitempty"              jsonschema:"IPV4 rules: comma-separated source IPv4 addresses or CIDR subnets"`
+	SourcePorts           *string `json:"source_ports,omitempty"            jsonschema:"IPV4 rules: comma-separated source ports (1-65535)"`
+	SourceMACs            *string `json:"source_macs,omitempty"             jsonschema:"MAC rules: comma-separated source MAC addresses in any notation"`
+	SourceNetworkIDs      *string `json:"source_network_ids,omitempty"      jsonschema:"comma-separated network IDs the source must be on"`
+	DestinationIPs        *string `json:"destination_ips,omitempty"         jsonschema:"IPV4 rules: comma-separated destination IPv4 addresses or CIDR subnets"`
+	DestinationPorts      *string `json:"destination_ports,omitempty"       jsonschema:"IPV4 rules: comma-separated destination ports (1-65535)"`
+	DestinationMACs       *string `json:"destination_macs,omitempty"        jsonschema:"MAC rules: comma-separated destination MAC addresses in any notation"`
+	DestinationNetworkIDs *string `json:"destination_network_ids,omitempty" jsonschema:"comma-separated network IDs the destination must be on"`
+	Protocols             *string `json:"protocols,omitempty"               jsonschema:"IPV4 rules: comma-separated protocols (TCP, UDP, ICMP); empty for any"`
+	NetworkID             *string `json:"network_id,omitempty"              jsonschema:"MAC rules: ID of the network (VLAN) the rule applies on; empty string clears"`
+	EnforcingDeviceIDs    *string `json:"enforcing_device_ids,omitempty"    jsonschema:"comma-separated IDs of the switches that enforce the rule; empty string for all"`
+}
+
+// apply copies the provided fields onto req and validates the result.
+func (f *aclRuleFields) apply(req *unifi.ACLRuleRequest) error {
+	if f.Description != nil {
+		req.Description = *f.Description
+	}
+	if f.Protocols != nil {
+		req.ProtocolFilter = splitIDs(f.Protocols)
+		for i, p := range req.ProtocolFilter {
+			req.ProtocolFilter[i] = strings.ToUpper(p)
+		}
+	}
+	if f.NetworkID != nil {
+		req.NetworkIDFilter = strings.TrimSpace(*f.NetworkID)
+	}
+	if f.EnforcingDeviceIDs != nil {
+		if ids := splitIDs(f.EnforcingDeviceIDs); len(ids) > 0 {
+			req.EnforcingDeviceFilter = &unifi.ACLDeviceFilter{Type: "DEVICES", DeviceIDs: ids}
+		} else {
+			req.EnforcingDeviceFilter = nil
+		}
+	}
+	var err error
+	req.SourceFilter, err = applyACLFilter(req.Type, req.SourceFilter, f.SourceIPs, f.SourcePorts, f.SourceMACs, f.SourceNetworkIDs)
+	if err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+	req.DestinationFilter, err = applyACLFilter(req.Type, req.DestinationFilter, f.DestinationIPs, f.DestinationPorts, f.DestinationMACs, f.DestinationNetworkIDs)
+	if err != nil {
+		return fmt.Errorf("destination: %w", err)
+	}
+	return req.Validate()
+}
+
+// applyACLFilter returns cur updated with whichever of ips, ports, macs and
+// networkIDs are non-nil. The filter type follows ruleType. A filter left with
+// nothing to match is dropped, which the controller treats as "any".
+func applyACLFilter(ruleType string, cur *unifi.ACLRuleFilter, ips, ports, macs, networkIDs *string) (*unifi.ACLRuleFilter, error) {
+	if ips == nil && ports == nil && macs == nil && networkIDs == nil {
+		return cur, nil
+	}
+	f := &unifi.ACLRuleFilter{}
+	if cur != nil {
+		c := *cur
+		f = &c
+	}
+	switch ruleType {
+	case unifi.ACLRuleTypeMAC:
+		f.Type = unifi.ACLFilterMACAddresses
+	default:
+		f.Type = unifi.ACLFilterIPAddresses
+	}
+	if ips != nil {
+		f.IPAddressesOrSubnets = splitIDs(ips)
+	}
+	if ports != nil {
+		f.PortFilter = nil
+		for _, p := range splitIDs(ports) {
+			n, err := strconv.Atoi(p)
+			if err != nil {
+				return nil, fmt.Errorf("port %q is not a number", p)
+			}
+			f.PortFilter = append(f.PortFilter, n)
+		}
+	}
+	if macs != nil {
+		f.MACAddresses = nil
+		for _, m := range splitIDs(macs) {
+			norm, ok := normalizeMAC(m)
+			if !ok {
+				return nil, fmt.Errorf("%q is not a MAC address", m)
+			}
+			f.MACAddresses = append(f.MACAddresses, norm)
+		}
+	}
+	if networkIDs != nil {
+		f.NetworkIDs = splitIDs(networkIDs)
+	}
+	if len(f.IPAddressesOrSubnets) == 0 && len(f.PortFilter) == 0 && len(f.MACAddresses) == 0 && len(f.NetworkIDs) == 0 {
+		return nil, nil
+	}
+	return f, nil
+}
@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/gordcurrie/unifi-mcp/internal/adoption"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// AutoAdopt applies policy to the pending devices and, when apply is set,
+// adopts those it allows into siteID. It is exported for the auto-adopt
+// poller started in main.
+func AutoAdopt(ctx context.Context, client unifiClient, siteID string, policy adoption.Policy, apply bool) ([]adoption.Decision, error) {
+	pending, err := listAll(ctx, client.ListPendingDevices)
+	if err != nil {
+		return nil, err
+	}
+	decisions := policy.Decide(pending)
+	if !apply {
+		return decisions, nil
+	}
+	for i := range decisions {
+		d := &decisions[i]
+		if !d.Adopt {
+			continue
+		}
+		dev, err := client.AdoptDevice(ctx, siteID, d.MAC)
+		if err != nil {
+			d.Error = err.Error()
+			continue
+		}
+		d.Adopted, d.DeviceID = true, dev.ID
+	}
+	return decisions, nil
+}
+
+// registerAdoptionTools registers adopt_device, and auto_adopt_devices when
+// an allowlist is configured.
+func registerAdoptionTools(s *mcp.Server, client unifiClient, policy adoption.Policy) {
+	destructiveTrue := true
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name:        "adopt_device",
+		Description: "Adopt a pending (unadopted) device into a site by MAC address; see list_pending_devices. Set confirmed=true to proceed.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID    string `json:"site_id,omitempty" jsonschema:"site to adopt the device into; omit to use default"`
+		MAC       string `json:"mac"               jsonschema:"MAC address of the pending device, in any notation"`
+		Confirmed bool   `json:"confirmed"         jsonschema:"must be true to confirm the adoption"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		if !input.Confirmed {
+			return errorResult(fmt.Errorf("adopt_device: set confirmed=true to confirm the adoption"))
+		}
+		mac, ok := normalizeMAC(input.MAC)
+		if !ok {
+			return errorResult(fmt.Errorf("adopt_device: invalid MAC address %q", input.MAC))
+		}
+		pending, err := listAll(ctx, client.ListPendingDevices)
+		if err != nil {
+			return errorResult(fmt.Errorf("adopt_device: %w", err))
+		}
+		if !slices.ContainsFunc(pending, func(d unifi.PendingDevice) bool {
+			m, ok := normalizeMAC(d.MAC)
+			return ok && m == mac
+		}) {
+			return errorResult(fmt.Errorf("adopt_device: no pending device with MAC %s", mac))
+		}
+		dev, err := client.AdoptDevice(ctx, input.SiteID, mac)
+		if err != nil {
+			return errorResult(fmt.Errorf("adopt_device: %w", err))
+		}
+		return jsonResult(newDeviceView(dev))
+	})
+
+	if policy.IsZero() {
+		return
+	}
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "auto_adopt_devices",
+		Description: "Adopt the pending devices allowed by the configured allowlist (UNIFI_AUTO_ADOPT_MACS and " +
+			"UNIFI_AUTO_ADOPT_MODELS) and flag every other pending device as a possible rogue device. Without " +
+			"confirmed=true only the decisions are returned.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID    string `json:"site_id,omitempty" jsonschema:"site to adopt devices into; omit to use default"`
+		Confirmed bool   `json:"confirmed"         jsonschema:"true to adopt; omit for a dry run"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		decisions, err := AutoAdopt(ctx, client, input.SiteID, policy, input.Confirmed)
+		if err != nil {
+			return errorResult(fmt.Errorf("auto_adopt_devices: %w", err))
+		}
+		return jsonResult(map[string]any{"dryRun": !input.Confirmed, "devices": decisions})
+	})
+}
@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gordcurrie/unifi-mcp/internal/adoption"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+func TestAutoAdopt(t *testing.T) {
+	policy, err := adoption.ParsePolicy("aa:bb:cc:00:00:01", "U6-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending := []unifi.PendingDevice{
+		{MAC: "aa:bb:cc:00:00:01", Model: "USW-Flex"},
+		{MAC: "aa:bb:cc:00:00:02", Model: "U6-Lite"},
+		{MAC: "aa:bb:cc:00:00:03", Model: "U6-Pro"},
+		{MAC: "aa:bb:cc:00:00:04", Model: "UAP-AC-Pro"},
+	}
+
+	t.Run("dry run adopts nothing", func(t *testing.T) {
+		fake := &fakeClient{pending: pending}
+		decisions, err := AutoAdopt(context.Background(), fake, "", policy, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(fake.adopted) != 0 {
+			t.Errorf("adopted %v on a dry run", fake.adopted)
+		}
+		var allowed []bool
+		for _, d := range decisions {
+			allowed = append(allowed, d.Adopt)
+		}
+		if fmt.Sprint(allowed) != "[true true true false]" {
+			t.Errorf("allowed %v, want [true true true false]", allowed)
+		}
+	})
+
+	t.Run("adopts only allowed devices and reports failures", func(t *testing.T) {
+		fake := &fakeClient{
+			pending:   pending,
+			adoptErrs: map[string]error{"aa:bb:cc:00:00:03": errors.New("device busy")},
+		}
+		decisions, err := AutoAdopt(context.Background(), fake, "", policy, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "[aa:bb:cc:00:00:01 aa:bb:cc:00:00:02 aa:bb:cc:00:00:03]"; fmt.Sprint(fake.adopted) != want {
+			t.Errorf("adopted %v, want %s", fake.adopted, want)
+		}
+		want := []adoption.Decision{
+			{Adopted: true, DeviceID: "dev-01"},
+			{Adopted: true, DeviceID: "dev-02"},
+			{Error: "device busy"},
+			{},
+		}
+		for i, d := range decisions {
+			if d.Adopted != want[i].Adopted || d.DeviceID != want[i].DeviceID || d.Error != want[i].Error {
+				t.Errorf("%s: adopted=%v device=%q err=%q, want %+v", d.MAC, d.Adopted, d.DeviceID, d.Error, want[i])
+			}
+		}
+	})
+}
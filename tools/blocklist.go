@@ -0,0 +1,254 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gordcurrie/unifi-mcp/internal/blocklist"
+	"github.com/gordcurrie/unifi-mcp/internal/matchlist"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// maxBlocklistBytes caps the size of one feed file.
+	maxBlocklistBytes = 32 << 20
+	// defaultBlocklistChunk is how many delta entries go into one list update.
+	defaultBlocklistChunk = 200
+	// defaultBlocklistMaxEntries caps a synced list when max_entries is not
+	// given. The Integration API publishes no per-list maximum, so this stays
+	// well inside what the Network application's own list editor accepts;
+	// every entry is pushed to each gateway rule that references the list.
+	defaultBlocklistMaxEntries = 4000
+	// maxReportedEntries caps the added/removed entries echoed in a result.
+	maxReportedEntries = 100
+)
+
+// blocklistFile is the per-file part of an import_blocklist result.
+type blocklistFile struct {
+	Name string `json:"name"`
+	blocklist.Result
+	Entries int `json:"entries"`
+}
+
+// blocklistReport is the result of import_blocklist.
+type blocklistReport struct {
+	ListID         string          `json:"listId"`
+	ListName       string          `json:"listName"`
+	Files          []blocklistFile `json:"files"`
+	CurrentEntries int             `json:"currentEntries"`
+	DesiredEntries int             `json:"desiredEntries"`
+	AddedCount     int             `json:"addedCount"`
+	RemovedCount   int             `json:"removedCount"`
+	Added          []string        `json:"added"`
+	Removed        []string        `json:"removed"`
+	Truncated      bool            `json:"truncated,omitempty"`
+	Applied        bool            `json:"applied"`
+	ChunksApplied  int             `json:"chunksApplied"`
+	ChunksTotal    int             `json:"chunksTotal"`
+	EntriesAfter   int             `json:"entriesAfter,omitempty"`
+}
+
+// registerBlocklistTools registers import_blocklist, which reads feed files
+// from dir only. Names are resolved through os.Root, so "..", absolute paths
+// and symlinks cannot reach outside it.
+func registerBlocklistTools(s *mcp.Server, client unifiClient, dir string) {
+	destructiveTrue := true
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "import_blocklist",
+		Description: "Sync an IP traffic matching list from threat-intel feed files in the configured blocklist directory " +
+			"(UNIFI_BLOCKLIST_DIR). Reads plain text, CSV, Spamhaus DROP and FireHOL netset files, validates and aggregates the " +
+			"entries, and diffs them against the list. Without confirmed=true only the diff is returned; with it the delta is " +
+			"applied in chunks, removals first. Entries broader than /8 (IPv4) or /16 (IPv6) are skipped.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID     string `json:"site_id,omitempty"     jsonschema:"site ID; omit to use default"`
+		ListID     string `json:"list_id"               jsonschema:"ID of the IP traffic matching list to sync"`
+		Files      string `json:"files"                 jsonschema:"comma-separated feed file names, relative to the blocklist directory; their union becomes the list"`
+		Format     string `json:"format,omitempty"      jsonschema:"auto (default; by extension), text, csv, drop or netset"`
+		CSVColumn  string `json:"csv_column,omitempty"  jsonschema:"CSV column holding the addresses, by header name or 1-based index; default is the first column with an address"`
+		ChunkSize  int    `json:"chunk_size,omitempty"  jsonschema:"delta entries per list update (default 200)"`
+		MaxEntries int    `json:"max_entries,omitempty" jsonschema:"refuse if the synced list would have more entries than this (default 4000)"`
+		Confirmed  bool   `json:"confirmed"             jsonschema:"true to apply the delta; false or omitted returns the diff only"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		if input.ListID == "" {
+			return errorResult(fmt.Errorf("import_blocklist: list_id is required"))
+		}
+		names := splitIDs(&input.Files)
+		if len(names) == 0 {
+			return errorResult(fmt.Errorf("import_blocklist: files is required"))
+		}
+		format, err := blocklist.ParseFormat(input.Format)
+		if err != nil {
+			return errorResult(fmt.Errorf("import_blocklist: %w", err))
+		}
+		chunk := input.ChunkSize
+		if chunk <= 0 {
+			chunk = defaultBlocklistChunk
+		}
+
+		report := blocklistReport{ListID: input.ListID}
+		var all []string
+		for _, name := range names {
+			res, err := readBlocklist(dir, name, format, input.CSVColumn)
+			if err != nil {
+				return errorResult(fmt.Errorf("import_blocklist: %s: %w", name, err))
+			}
+			report.Files = append(report.Files, blocklistFile{Name: name, Result: res, Entries: len(res.Entries)})
+			all = append(all, res.Entries...)
+		}
+		desired, err := matchlist.Normalize(matchlist.IP, all)
+		if err != nil {
+			return errorResult(fmt.Errorf("import_blocklist: %w", err))
+		}
+		if len(desired) == 0 {
+			return errorResult(fmt.Errorf("import_blocklist: the files contain no usable entries; refusing to empty the list"))
+		}
+		maxEntries := input.MaxEntries
+		if maxEntries <= 0 {
+			maxEntries = defaultBlocklistMaxEntries
+		}
+		if len(desired) > maxEntries {
+			return errorResult(fmt.Errorf("import_blocklist: the feeds aggregate to %d entries, over max_entries %d", len(desired), maxEntries))
+		}
+
+		list, err := client.GetTrafficMatchingList(ctx, input.SiteID, input.ListID)
+		if err != nil {
+			return errorResult(fmt.Errorf("import_blocklist: %w", err))
+		}
+		if !strings.EqualFold(list.Type, unifi.TrafficMatchingListTypeIP) {
+			return errorResult(fmt.Errorf("import_blocklist: list %s is a %s list, not an IP list", list.Name, list.Type))
+		}
+		report.ListName = list.Name
+		current, err := matchlist.Normalize(matchlist.IP, list.Entries)
+		if err != nil {
+			return errorResult(fmt.Errorf("import_blocklist: current list entries: %w", err))
+		}
+		added, err := matchlist.Remove(matchlist.IP, desired, current)
+		if err != nil {
+			return errorResult(fmt.Errorf("import_blocklist: %w", err))
+		}
+		removed, err := matchlist.Remove(matchlist.IP, current, desired)
+		if err != nil {
+			return errorResult(fmt.Errorf("import_blocklist: %w", err))
+		}
+		report.CurrentEntries, report.DesiredEntries = len(list.Entries), len(desired)
+		report.AddedCount, report.RemovedCount = len(added), len(removed)
+		report.Added, report.Truncated = capEntries(added)
+		var truncated bool
+		report.Removed, truncated = capEntries(removed)
+		report.Truncated = report.Truncated || truncated
+		removeChunks, addChunks := chunkEntries(removed, chunk), chunkEntries(added, chunk)
+		report.ChunksTotal = len(removeChunks) + len(addChunks)
+
+		if !input.Confirmed || report.ChunksTotal == 0 {
+			return jsonResult(report)
+		}
+
+		state, applied, applyErr := applyChunks(ctx, client, input.SiteID, input.ListID, current, removeChunks, addChunks)
+		report.ChunksApplied = applied
+		report.EntriesAfter = len(state)
+		if applyErr != nil {
+			return errorResult(fmt.Errorf("import_blocklist: %w; %d of %d chunks applied, the list now has %d entries and is partially synced; run again to finish",
+				applyErr, report.ChunksApplied, report.ChunksTotal, len(state)))
+		}
+		report.Applied = true
+		return jsonResult(report)
+	})
+}
+
+// applyChunks updates the list one chunk at a time, starting from current, and
+// returns the entries it last wrote and how many chunks that covers. Removals
+// go first so the list never grows past its final size. If a removal would
+// leave the list empty, the first additions ride along in the same update.
+func applyChunks(ctx context.Context, client unifiClient, siteID, listID string, current []string, removeChunks, addChunks [][]string) ([]string, int, error) {
+	state, applied := current, 0
+	apply := func(next []string, chunks int) error {
+		if _, err := client.UpdateTrafficMatchingList(ctx, siteID, listID, unifi.TrafficMatchingListUpdate{Entries: next}); err != nil {
+			return err
+		}
+		state = next
+		applied += chunks
+		return nil
+	}
+	for _, c := range removeChunks {
+		next, err := matchlist.Remove(matchlist.IP, state, c)
+		chunks := 1
+		if err == nil && len(next) == 0 && len(addChunks) > 0 {
+			next, err = matchlist.Add(matchlist.IP, next, addChunks[0])
+			addChunks, chunks = addChunks[1:], 2
+		}
+		if err == nil {
+			err = apply(next, chunks)
+		}
+		if err != nil {
+			return state, applied, err
+		}
+	}
+	for _, c := range addChunks {
+		next, err := matchlist.Add(matchlist.IP, state, c)
+		if err == nil {
+			err = apply(next, 1)
+		}
+		if err != nil {
+			return state, applied, err
+		}
+	}
+	return state, applied, nil
+}
+
+// readBlocklist opens name inside dir and parses it.
+func readBlocklist(dir, name string, format blocklist.Format, csvColumn string) (blocklist.Result, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return blocklist.Result{}, fmt.Errorf("open blocklist directory: %w", err)
+	}
+	defer func() { _ = root.Close() }()
+	f, err := root.Open(name)
+	if err != nil {
+		return blocklist.Result{}, err
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return blocklist.Result{}, err
+	}
+	if !info.Mode().IsRegular() {
+		return blocklist.Result{}, fmt.Errorf("not a regular file")
+	}
+	if info.Size() > maxBlocklistBytes {
+		return blocklist.Result{}, fmt.Errorf("file is %d bytes; the limit is %d", info.Size(), maxBlocklistBytes)
+	}
+	if format == blocklist.FormatAuto {
+		format = blocklist.DetectFormat(name)
+	}
+	return blocklist.Parse(io.LimitReader(f, maxBlocklistBytes), format, csvColumn)
+}
+
+// chunkEntries splits entries into consecutive slices of at most size.
+func chunkEntries(entries []string, size int) [][]string {
+	var chunks [][]string
+	for len(entries) > 0 {
+		n := min(size, len(entries))
+		chunks = append(chunks, entries[:n])
+		entries = entries[n:]
+	}
+	return chunks
+}
+
+// capEntries returns at most maxReportedEntries of entries, and whether any
+// were dropped. A nil input becomes an empty slice for stable JSON.
+func capEntries(entries []string) ([]string, bool) {
+	if len(entries) > maxReportedEntries {
+		return entries[:maxReportedEntries], true
+	}
+	if entries == nil {
+		return []string{}, false
+	}
+	return entries, false
+}
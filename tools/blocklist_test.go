@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestChunkEntries(t *testing.T) {
+	entries := []string{"a", "b", "c", "d", "e"}
+	tests := []struct {
+		size int
+		want [][]string
+	}{
+		{2, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}},
+		{5, [][]string{{"a", "b", "c", "d", "e"}}},
+		{10, [][]string{{"a", "b", "c", "d", "e"}}},
+	}
+	for _, tc := range tests {
+		got := chunkEntries(entries, tc.size)
+		if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Errorf("chunkEntries(size %d) = %v, want %v", tc.size, got, tc.want)
+		}
+	}
+	if got := chunkEntries(nil, 3); got != nil {
+		t.Errorf("chunkEntries(nil) = %v, want nil", got)
+	}
+}
+
+func TestApplyChunks(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     []string
+		remove, add []string
+		failUpdate  int
+		wantUpdates [][]string
+		wantApplied int
+		wantErr     bool
+	}{
+		{
+			name:    "removals before additions",
+			current: []string{"10.0.1.1", "10.0.2.1", "10.0.3.1"},
+			remove:  []string{"10.0.1.1", "10.0.2.1"},
+			add:     []string{"10.0.8.1", "10.0.9.1"},
+			wantUpdates: [][]string{
+				{"10.0.2.1", "10.0.3.1"},
+				{"10.0.3.1"},
+				{"10.0.3.1", "10.0.8.1"},
+				{"10.0.3.1", "10.0.8.1", "10.0.9.1"},
+			},
+			wantApplied: 4,
+		},
+		{
+			name:    "additions ride along instead of emptying the list",
+			current: []string{"10.0.1.1"},
+			remove:  []string{"10.0.1.1"},
+			add:     []string{"10.0.8.1", "10.0.9.1"},
+			wantUpdates: [][]string{
+				{"10.0.8.1"},
+				{"10.0.8.1", "10.0.9.1"},
+			},
+			wantApplied: 3,
+		},
+		{
+			name:       "stops at the first failed update",
+			current:    []string{"10.0.1.1", "10.0.2.1"},
+			remove:     []string{"10.0.1.1"},
+			add:        []string{"10.0.8.1", "10.0.9.1"},
+			failUpdate: 2,
+			wantUpdates: [][]string{
+				{"10.0.2.1"},
+			},
+			wantApplied: 1,
+			wantErr:     true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := &fakeClient{failListUpdate: tc.failUpdate}
+			state, applied, err := applyChunks(context.Background(), fake, "", "list-1",
+				tc.current, chunkEntries(tc.remove, 1), chunkEntries(tc.add, 1))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tc.wantErr)
+			}
+			if fmt.Sprint(fake.listUpdates) != fmt.Sprint(tc.wantUpdates) {
+				t.Errorf("updates = %v, want %v", fake.listUpdates, tc.wantUpdates)
+			}
+			if applied != tc.wantApplied {
+				t.Errorf("applied %d chunks, want %d", applied, tc.wantApplied)
+			}
+			if last := tc.wantUpdates[len(tc.wantUpdates)-1]; !slices.Equal(state, last) {
+				t.Errorf("state = %v, want the last update %v", state, last)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/gordcurrie/unifi-mcp/internal/unifi"
 )
@@ -10,6 +11,7 @@ import (
 // *unifi.Client satisfies this interface automatically.
 type unifiClient interface {
 	// Sites
+	SiteID(siteID string) string
 	GetInfo(ctx context.Context) (unifi.ApplicationInfo, error)
 	ListSites(ctx context.Context, offset, limit int) (unifi.Page[unifi.Site], error)
 	GetSite(ctx context.Context, siteID string) (unifi.Site, error)
@@ -18,8 +20,10 @@ type unifiClient interface {
 	ListDevices(ctx context.Context, siteID string, offset, limit int) (unifi.Page[unifi.Device], error)
 	GetDevice(ctx context.Context, siteID, deviceID string) (unifi.Device, error)
 	RestartDevice(ctx context.Context, siteID, deviceID string) error
+	UpgradeDevice(ctx context.Context, siteID, deviceID string) error
 	GetDeviceStats(ctx context.Context, siteID, deviceID string) (unifi.DeviceStats, error)
 	ListPendingDevices(ctx context.Context, offset, limit int) (unifi.Page[unifi.PendingDevice], error)
+	AdoptDevice(ctx context.Context, siteID, mac string) (unifi.Device, error)
 	PowerCyclePort(ctx context.Context, siteID, deviceID string, portIdx int) error
 
 	// Clients
@@ -31,7 +35,13 @@ type unifiClient interface {
 	ListWiFiBroadcasts(ctx context.Context, siteID string, offset, limit int) (unifi.Page[unifi.WiFiBroadcast], error)
 	GetWiFiBroadcast(ctx context.Context, siteID, broadcastID string) (unifi.WiFiBroadcast, error)
 	SetWiFiBroadcastEnabled(ctx context.Context, siteID, broadcastID string, enabled bool) (unifi.WiFiBroadcast, error)
+	CreateWiFiBroadcast(ctx context.Context, siteID string, req unifi.WiFiBroadcastRequest) (unifi.WiFiBroadcast, error)
+	UpdateWiFiBroadcast(ctx context.Context, siteID, broadcastID string, update unifi.WiFiBroadcastUpdate) (unifi.WiFiBroadcast, error)
 	ListNetworks(ctx context.Context, siteID string, offset, limit int) (unifi.Page[unifi.NetworkConf], error)
+	GetNetwork(ctx context.Context, siteID, networkID string) (unifi.NetworkConf, error)
+	CreateNetwork(ctx context.Context, siteID string, req unifi.NetworkRequest) (unifi.NetworkConf, error)
+	UpdateNetwork(ctx context.Context, siteID, networkID string, update unifi.NetworkUpdate) (unifi.NetworkConf, error)
+	DeleteNetwork(ctx context.Context, siteID, networkID string) error
 	ListFirewallPolicies(ctx context.Context, siteID string, offset, limit int) (unifi.Page[unifi.FirewallPolicy], error)
 	GetFirewallPolicy(ctx context.Context, siteID, policyID string) (unifi.FirewallPolicy, error)
 	SetFirewallPolicyEnabled(ctx context.Context, siteID, policyID string, enabled bool) (unifi.FirewallPolicy, error)
@@ -51,10 +61,17 @@ type unifiClient interface {
 	ReorderACLRules(ctx context.Context, siteID string, orderedIDs []string) (unifi.ACLRuleOrdering, error)
 	ListTrafficMatchingLists(ctx context.Context, siteID string, offset, limit int) (unifi.Page[unifi.TrafficMatchingList], error)
 	GetTrafficMatchingList(ctx context.Context, siteID, listID string) (unifi.TrafficMatchingList, error)
+	CreateTrafficMatchingList(ctx context.Context, siteID string, req unifi.TrafficMatchingListRequest) (unifi.TrafficMatchingList, error)
+	UpdateTrafficMatchingList(ctx context.Context, siteID, listID string, update unifi.TrafficMatchingListUpdate) (unifi.TrafficMatchingList, error)
+	DeleteTrafficMatchingList(ctx context.Context, siteID, listID string) error
 	ListWANs(ctx context.Context, siteID string, offset, limit int) (unifi.Page[unifi.WAN], error)
 	ListVPNTunnels(ctx context.Context, siteID string, offset, limit int) (unifi.Page[unifi.VPNTunnel], error)
 	ListVPNServers(ctx context.Context, siteID string, offset, limit int) (unifi.Page[unifi.VPNServer], error)
 
+	// Raw resources
+	GetRaw(ctx context.Context, siteID, collection, resourceID string) (json.RawMessage, error)
+	PutRaw(ctx context.Context, siteID, collection, resourceID string, body json.RawMessage) (json.RawMessage, error)
+
 	// DNS policies
 	ListDNSPolicies(ctx context.Context, siteID string, offset, limit int) (unifi.Page[unifi.DNSPolicy], error)
 	GetDNSPolicy(ctx context.Context, siteID, policyID string) (unifi.DNSPolicy, error)
@@ -8,53 +8,56 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func registerClientTools(s *mcp.Server, client unifiClient) {
+func registerClientTools(s *mcp.Server, client unifiClient, res *resolver) {
 	type siteInput struct {
 		SiteID string `json:"site_id,omitempty" jsonschema:"site ID; omit to use default"`
 		Offset int    `json:"offset,omitempty" jsonschema:"pagination offset (0-based); omit or 0 to start from the beginning"`
 		Limit  int    `json:"limit,omitempty"  jsonschema:"maximum number of items to return (max 1000); omit or 0 to use the API default"`
 	}
 	type clientInput struct {
-		SiteID   string `json:"site_id,omitempty" jsonschema:"site ID; omit to use default"`
-		ClientID string `json:"client_id"         jsonschema:"client ID"`
+		SiteID   string `json:"site_id,omitempty"   jsonschema:"site ID; omit to use default"`
+		ClientID string `json:"client_id,omitempty" jsonschema:"client ID; provide this or client"`
+		Client   string `json:"client,omitempty"    jsonschema:"client selector: ID, name (exact or partial), MAC in any notation, or IP; used when client_id is omitted"`
 	}
 
 	mcp.AddTool(s, &mcp.Tool{
 		Name:        "list_clients",
-		Description: "List currently connected clients on the network. Use offset/limit to paginate.",
+		Description: "List currently connected clients on the network, with MAC vendor and a randomized-MAC flag. Use offset/limit to paginate.",
 		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
 	}, func(ctx context.Context, _ *mcp.CallToolRequest, input siteInput) (*mcp.CallToolResult, any, error) {
 		clients, err := client.ListClients(ctx, input.SiteID, input.Offset, input.Limit)
 		if err != nil {
 			return errorResult(fmt.Errorf("list_clients: %w", err))
 		}
-		return jsonResult(clients)
+		return jsonResult(mapPage(clients, newClientView))
 	})
 
 	mcp.AddTool(s, &mcp.Tool{
 		Name:        "get_client",
-		Description: "Get details for a specific connected client by ID.",
+		Description: "Get details for a specific connected client by ID, or by a client selector (name, MAC, or IP).",
 		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
 	}, func(ctx context.Context, _ *mcp.CallToolRequest, input clientInput) (*mcp.CallToolResult, any, error) {
-		if input.ClientID == "" {
-			return errorResult(fmt.Errorf("get_client: client_id is required"))
+		clientID, err := res.clientID(ctx, input.SiteID, input.ClientID, input.Client, false)
+		if err != nil {
+			return errorResult(fmt.Errorf("get_client: client_id or client: %w", err))
 		}
-		c, err := client.GetClient(ctx, input.SiteID, input.ClientID)
+		c, err := client.GetClient(ctx, input.SiteID, clientID)
 		if err != nil {
 			return errorResult(fmt.Errorf("get_client: %w", err))
 		}
-		return jsonResult(c)
+		return jsonResult(newClientView(c))
 	})
 
 	destructiveTrue := true
 
 	mcp.AddTool(s, &mcp.Tool{
 		Name:        "authorize_guest_client",
-		Description: "Authorize a connected client for guest network access. Set confirmed=true to proceed. Optional: time_limit_minutes, data_limit_mb, download_bandwidth_kbps, upload_bandwidth_kbps (0 = unlimited).",
+		Description: "Authorize a connected client for guest network access, identified by client ID or client selector (name, MAC, or IP). Set confirmed=true to proceed. Optional: time_limit_minutes, data_limit_mb, download_bandwidth_kbps, upload_bandwidth_kbps (0 = unlimited).",
 		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
 	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
 		SiteID                string `json:"site_id,omitempty"              jsonschema:"site ID; omit to use default"`
-		ClientID              string `json:"client_id"                      jsonschema:"client ID to authorize"`
+		ClientID              string `json:"client_id,omitempty"            jsonschema:"client ID to authorize; provide this or client"`
+		Client                string `json:"client,omitempty"               jsonschema:"client selector: ID, exact name, MAC in any notation, or IP; used when client_id is omitted"`
 		TimeLimitMinutes      int    `json:"time_limit_minutes,omitempty"   jsonschema:"access duration in minutes; 0 or omit for unlimited"`
 		DataLimitMb           int    `json:"data_limit_mb,omitempty"        jsonschema:"data cap in MB; 0 or omit for unlimited"`
 		DownloadBandwidthKbps int    `json:"download_bandwidth_kbps,omitempty" jsonschema:"download rate limit in Kbps; 0 or omit for unlimited"`
@@ -65,10 +68,11 @@ func registerClientTools(s *mcp.Server, client unifiClient) {
 		if !input.Confirmed {
 			return errorResult(fmt.Errorf("authorize_guest_client: set confirmed=true to confirm the authorization"))
 		}
-		if input.ClientID == "" {
-			return errorResult(fmt.Errorf("authorize_guest_client: client_id is required"))
+		clientID, err := res.clientID(ctx, input.SiteID, input.ClientID, input.Client, true)
+		if err != nil {
+			return errorResult(fmt.Errorf("authorize_guest_client: client_id or client: %w", err))
 		}
-		err := client.AuthorizeGuestClient(ctx, input.SiteID, input.ClientID, unifi.GuestAuthRequest{
+		err = client.AuthorizeGuestClient(ctx, input.SiteID, clientID, unifi.GuestAuthRequest{
 			Action:                "AUTHORIZE_GUEST_ACCESS",
 			TimeLimitMinutes:      input.TimeLimitMinutes,
 			DataLimitMb:           input.DataLimitMb,
@@ -78,6 +82,6 @@ func registerClientTools(s *mcp.Server, client unifiClient) {
 		if err != nil {
 			return errorResult(fmt.Errorf("authorize_guest_client: %w", err))
 		}
-		return textResult(fmt.Sprintf("client %s authorized for guest access", input.ClientID))
+		return textResult(fmt.Sprintf("client %s authorized for guest access", clientID))
 	})
 }
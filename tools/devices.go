@@ -1,13 +1,84 @@
 package tools
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
 
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func registerDeviceTools(s *mcp.Server, client unifiClient) {
+// portView is a device port with what is plugged into it.
+type portView struct {
+	unifi.DevicePort
+	PoECapable bool `json:"poeCapable"`
+	PoEEnabled bool `json:"poeEnabled"`
+	// Connected names the adopted device or client whose MAC the port
+	// reports.
+	Connected *portPeer `json:"connected,omitempty"`
+}
+
+// portPeer is an adopted device or client seen on a port.
+type portPeer struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	MAC  string `json:"macAddress"`
+	IP   string `json:"ipAddress,omitempty"`
+}
+
+// devicePorts returns dev's ports ordered by index, naming what is connected
+// to each where the port reports a MAC, and the wired clients whose uplink is
+// dev but that no port accounts for.
+func devicePorts(ctx context.Context, res *resolver, siteID string, dev *unifi.Device) ([]portView, []portPeer, error) {
+	devices, err := res.listDevices(ctx, siteID, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	clients, err := res.listClients(ctx, siteID, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	peers := map[string]portPeer{}
+	for i := range devices {
+		if mac, ok := normalizeMAC(devices[i].MAC); ok {
+			peers[mac] = portPeer{Kind: "device", ID: devices[i].ID, Name: devices[i].Name, MAC: mac, IP: devices[i].IP}
+		}
+	}
+	for i := range clients {
+		if mac, ok := normalizeMAC(clients[i].MAC); ok {
+			peers[mac] = portPeer{Kind: "client", ID: clients[i].ID, Name: clients[i].Name, MAC: mac, IP: clients[i].IP}
+		}
+	}
+
+	ports := make([]portView, len(dev.Interfaces.Ports))
+	placed := map[string]bool{}
+	for i, p := range dev.Interfaces.Ports {
+		v := portView{DevicePort: p, PoECapable: p.PoE != nil, PoEEnabled: p.PoE != nil && p.PoE.Enabled}
+		if mac, ok := normalizeMAC(p.ConnectedMAC); ok {
+			placed[mac] = true
+			if peer, ok := peers[mac]; ok {
+				v.Connected = &peer
+			}
+		}
+		ports[i] = v
+	}
+	slices.SortFunc(ports, func(a, b portView) int { return cmp.Compare(a.Idx, b.Idx) })
+
+	unplaced := []portPeer{}
+	for i := range clients {
+		c := &clients[i]
+		mac, _ := normalizeMAC(c.MAC)
+		if c.UplinkDeviceID == dev.ID && c.Type == "WIRED" && !placed[mac] {
+			unplaced = append(unplaced, peers[mac])
+		}
+	}
+	return ports, unplaced, nil
+}
+
+func registerDeviceTools(s *mcp.Server, client unifiClient, res *resolver) {
 	destructiveTrue := true
 
 	type siteInput struct {
@@ -16,68 +87,73 @@ func registerDeviceTools(s *mcp.Server, client unifiClient) {
 		Limit  int    `json:"limit,omitempty"  jsonschema:"maximum number of items to return (max 1000); omit or 0 to use the API default"`
 	}
 	type deviceInput struct {
-		SiteID   string `json:"site_id,omitempty" jsonschema:"site ID; omit to use default"`
-		DeviceID string `json:"device_id"         jsonschema:"device ID"`
+		SiteID   string `json:"site_id,omitempty"   jsonschema:"site ID; omit to use default"`
+		DeviceID string `json:"device_id,omitempty" jsonschema:"device ID; provide this or device"`
+		Device   string `json:"device,omitempty"    jsonschema:"device selector: ID, name (exact or partial), MAC in any notation, or IP; used when device_id is omitted"`
 	}
 	type restartDeviceInput struct {
-		SiteID    string `json:"site_id,omitempty" jsonschema:"site ID; omit to use default"`
-		DeviceID  string `json:"device_id"          jsonschema:"device ID"`
-		Confirmed bool   `json:"confirmed"          jsonschema:"must be true to confirm the restart"`
+		SiteID    string `json:"site_id,omitempty"   jsonschema:"site ID; omit to use default"`
+		DeviceID  string `json:"device_id,omitempty" jsonschema:"device ID; provide this or device"`
+		Device    string `json:"device,omitempty"    jsonschema:"device selector: ID, exact name, MAC in any notation, or IP; used when device_id is omitted"`
+		Confirmed bool   `json:"confirmed"           jsonschema:"must be true to confirm the restart"`
 	}
 
 	mcp.AddTool(s, &mcp.Tool{
 		Name:        "list_devices",
-		Description: "List adopted devices (APs, switches, gateways) for a site. Use offset/limit to paginate.",
+		Description: "List adopted devices (APs, switches, gateways) for a site, with MAC vendor. Use offset/limit to paginate.",
 		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
 	}, func(ctx context.Context, _ *mcp.CallToolRequest, input siteInput) (*mcp.CallToolResult, any, error) {
 		devices, err := client.ListDevices(ctx, input.SiteID, input.Offset, input.Limit)
 		if err != nil {
 			return errorResult(fmt.Errorf("list_devices: %w", err))
 		}
-		return jsonResult(devices)
+		return jsonResult(mapPage(devices, newDeviceView))
 	})
 
 	mcp.AddTool(s, &mcp.Tool{
 		Name:        "get_device",
-		Description: "Get details for a specific device by ID.",
+		Description: "Get details for a specific device by ID, or by a device selector (name, MAC, or IP).",
 		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
 	}, func(ctx context.Context, _ *mcp.CallToolRequest, input deviceInput) (*mcp.CallToolResult, any, error) {
-		if input.DeviceID == "" {
-			return errorResult(fmt.Errorf("get_device: device_id is required"))
+		deviceID, err := res.deviceID(ctx, input.SiteID, input.DeviceID, input.Device, false)
+		if err != nil {
+			return errorResult(fmt.Errorf("get_device: device_id or device: %w", err))
 		}
-		dev, err := client.GetDevice(ctx, input.SiteID, input.DeviceID)
+		dev, err := client.GetDevice(ctx, input.SiteID, deviceID)
 		if err != nil {
 			return errorResult(fmt.Errorf("get_device: %w", err))
 		}
-		return jsonResult(dev)
+		return jsonResult(newDeviceView(dev))
 	})
 
 	mcp.AddTool(s, &mcp.Tool{
 		Name:        "restart_device",
-		Description: "Restart a UniFi device by device ID. Set confirmed=true to proceed.",
+		Description: "Restart a UniFi device by device ID or device selector (name, MAC, or IP). Set confirmed=true to proceed.",
 		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
 	}, func(ctx context.Context, _ *mcp.CallToolRequest, input restartDeviceInput) (*mcp.CallToolResult, any, error) {
 		if !input.Confirmed {
 			return errorResult(fmt.Errorf("restart_device: set confirmed=true to confirm the restart"))
 		}
-		if input.DeviceID == "" {
-			return errorResult(fmt.Errorf("restart_device: device_id is required"))
+		deviceID, err := res.deviceID(ctx, input.SiteID, input.DeviceID, input.Device, true)
+		if err != nil {
+			return errorResult(fmt.Errorf("restart_device: device_id or device: %w", err))
 		}
-		if err := client.RestartDevice(ctx, input.SiteID, input.DeviceID); err != nil {
+		if err := client.RestartDevice(ctx, input.SiteID, deviceID); err != nil {
 			return errorResult(fmt.Errorf("restart_device: %w", err))
 		}
-		return textResult("restart command sent to " + input.DeviceID)
+		return textResult("restart command sent to " + deviceID)
 	})
 
 	mcp.AddTool(s, &mcp.Tool{
 		Name:        "get_device_stats",
-		Description: "Get the latest statistics (CPU, memory, uptime) for a specific device.",
+		Description: "Get the latest statistics (CPU, memory, uptime) for a specific device by ID or device selector (name, MAC, or IP).",
 		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
 	}, func(ctx context.Context, _ *mcp.CallToolRequest, input deviceInput) (*mcp.CallToolResult, any, error) {
-		if input.DeviceID == "" {
-			return errorResult(fmt.Errorf("get_device_stats: device_id is required"))
+		deviceID, err := res.deviceID(ctx, input.SiteID, input.DeviceID, input.Device, false)
+		if err != nil {
+			return errorResult(fmt.Errorf("get_device_stats: device_id or device: %w", err))
 		}
-		stats, err := client.GetDeviceStats(ctx, input.SiteID, input.DeviceID)
+		stats, err := client.GetDeviceStats(ctx, input.SiteID, deviceID)
 		if err != nil {
 			return errorResult(fmt.Errorf("get_device_stats: %w", err))
 		}
@@ -86,7 +162,7 @@ func registerDeviceTools(s *mcp.Server, client unifiClient) {
 
 	mcp.AddTool(s, &mcp.Tool{
 		Name:        "list_pending_devices",
-		Description: "List devices visible on the network that have not yet been adopted. Use offset/limit to paginate.",
+		Description: "List devices visible on the network that have not yet been adopted, with MAC vendor. Use offset/limit to paginate.",
 		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
 	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
 		Offset int `json:"offset,omitempty" jsonschema:"pagination offset (0-based); omit or 0 to start from the beginning"`
@@ -97,33 +173,93 @@ func registerDeviceTools(s *mcp.Server, client unifiClient) {
 		if err != nil {
 			return errorResult(fmt.Errorf("list_pending_devices: %w", err))
 		}
-		return jsonResult(devices)
+		return jsonResult(mapPage(devices, newPendingDeviceView))
 	})
 
 	type powerCyclePortInput struct {
-		SiteID    string `json:"site_id,omitempty" jsonschema:"site ID; omit to use default"`
-		DeviceID  string `json:"device_id"          jsonschema:"device ID of the switch"`
-		PortIdx   int    `json:"port_idx"           jsonschema:"port index number to power-cycle"`
-		Confirmed bool   `json:"confirmed"          jsonschema:"must be true to confirm the port power cycle"`
+		SiteID    string `json:"site_id,omitempty"   jsonschema:"site ID; omit to use default"`
+		DeviceID  string `json:"device_id,omitempty" jsonschema:"device ID of the switch; provide this or device"`
+		Device    string `json:"device,omitempty"    jsonschema:"switch selector: ID, exact name, MAC in any notation, or IP; used when device_id is omitted"`
+		PortIdx   int    `json:"port_idx"            jsonschema:"port index number to power-cycle"`
+		Confirmed bool   `json:"confirmed"           jsonschema:"must be true to confirm the port power cycle"`
 	}
 
 	mcp.AddTool(s, &mcp.Tool{
 		Name:        "power_cycle_port",
-		Description: "Power-cycle a single PoE port on a switch. Set confirmed=true to proceed.",
+		Description: "Power-cycle a single PoE port on a switch, identified by device ID or device selector (name, MAC, or IP). Set confirmed=true to proceed.",
 		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
 	}, func(ctx context.Context, _ *mcp.CallToolRequest, input powerCyclePortInput) (*mcp.CallToolResult, any, error) {
 		if !input.Confirmed {
 			return errorResult(fmt.Errorf("power_cycle_port: set confirmed=true to confirm the port power cycle"))
 		}
-		if input.DeviceID == "" {
-			return errorResult(fmt.Errorf("power_cycle_port: device_id is required"))
-		}
 		if input.PortIdx <= 0 {
 			return errorResult(fmt.Errorf("power_cycle_port: port_idx is required and must be greater than 0"))
 		}
-		if err := client.PowerCyclePort(ctx, input.SiteID, input.DeviceID, input.PortIdx); err != nil {
+		deviceID, err := res.deviceID(ctx, input.SiteID, input.DeviceID, input.Device, true)
+		if err != nil {
+			return errorResult(fmt.Errorf("power_cycle_port: device_id or device: %w", err))
+		}
+		if err := checkPoEPort(ctx, client, input.SiteID, deviceID, input.PortIdx); err != nil {
+			return errorResult(fmt.Errorf("power_cycle_port: %w", err))
+		}
+		if err := client.PowerCyclePort(ctx, input.SiteID, deviceID, input.PortIdx); err != nil {
 			return errorResult(fmt.Errorf("power_cycle_port: %w", err))
 		}
-		return textResult(fmt.Sprintf("power cycle command sent to port %d on device %s", input.PortIdx, input.DeviceID))
+		return textResult(fmt.Sprintf("power cycle command sent to port %d on device %s", input.PortIdx, deviceID))
 	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "list_device_ports",
+		Description: "List the ports of a switch or gateway by device ID or device selector (name, MAC, or IP): index, name, " +
+			"link state and speed, connector, PoE capability, whether PoE is enabled and delivering power, power drawn where " +
+			"reported, and the adopted device or client plugged in where the port reports its MAC. Wired clients whose " +
+			"uplink is the device but that no port accounts for are listed separately. Use it to pick port_idx for power_cycle_port.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input deviceInput) (*mcp.CallToolResult, any, error) {
+		deviceID, err := res.deviceID(ctx, input.SiteID, input.DeviceID, input.Device, false)
+		if err != nil {
+			return errorResult(fmt.Errorf("list_device_ports: device_id or device: %w", err))
+		}
+		dev, err := client.GetDevice(ctx, input.SiteID, deviceID)
+		if err != nil {
+			return errorResult(fmt.Errorf("list_device_ports: %w", err))
+		}
+		if dev.Interfaces == nil || len(dev.Interfaces.Ports) == 0 {
+			return errorResult(fmt.Errorf("list_device_ports: device %s reports no ports", deviceID))
+		}
+		ports, unplaced, err := devicePorts(ctx, res, input.SiteID, &dev)
+		if err != nil {
+			return errorResult(fmt.Errorf("list_device_ports: %w", err))
+		}
+		return jsonResult(struct {
+			DeviceID     string     `json:"deviceId"`
+			Name         string     `json:"name,omitempty"`
+			Model        string     `json:"model,omitempty"`
+			Ports        []portView `json:"ports"`
+			WiredClients []portPeer `json:"wiredClientsWithoutPort"`
+		}{dev.ID, dev.Name, dev.Model, ports, unplaced})
+	})
+}
+
+// checkPoEPort refuses a port the switch reports as missing or not powering,
+// and fails closed when the switch cannot be read. A switch that reports no
+// ports at all is left to the controller to judge.
+func checkPoEPort(ctx context.Context, client unifiClient, siteID, deviceID string, portIdx int) error {
+	dev, err := client.GetDevice(ctx, siteID, deviceID)
+	if err != nil {
+		return fmt.Errorf("checking port %d of device %s: %w", portIdx, deviceID, err)
+	}
+	if dev.Interfaces == nil || len(dev.Interfaces.Ports) == 0 {
+		return nil
+	}
+	i := slices.IndexFunc(dev.Interfaces.Ports, func(p unifi.DevicePort) bool { return p.Idx == portIdx })
+	switch {
+	case i < 0:
+		return fmt.Errorf("device %s has no port %d; see list_device_ports", deviceID, portIdx)
+	case dev.Interfaces.Ports[i].PoE == nil:
+		return fmt.Errorf("port %d on device %s cannot supply PoE", portIdx, deviceID)
+	case !dev.Interfaces.Ports[i].PoE.Enabled:
+		return fmt.Errorf("PoE is disabled on port %d of device %s", portIdx, deviceID)
+	}
+	return nil
 }
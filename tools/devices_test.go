@@ -0,0 +1,44 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+func TestCheckPoEPort(t *testing.T) {
+	sw := unifi.Device{ID: "sw-1", Interfaces: &unifi.DeviceInterfaces{Ports: []unifi.DevicePort{
+		{Idx: 1, PoE: &unifi.PortPoE{Enabled: true}},
+		{Idx: 2, PoE: &unifi.PortPoE{Enabled: false}},
+		{Idx: 9},
+	}}}
+	tests := []struct {
+		name    string
+		dev     unifi.Device
+		err     error
+		port    int
+		wantErr string
+	}{
+		{name: "PoE port", dev: sw, port: 1},
+		{name: "PoE disabled", dev: sw, port: 2, wantErr: "PoE is disabled on port 2"},
+		{name: "no PoE", dev: sw, port: 9, wantErr: "port 9 on device sw-1 cannot supply PoE"},
+		{name: "missing port", dev: sw, port: 5, wantErr: "has no port 5"},
+		{name: "no ports reported", dev: unifi.Device{ID: "sw-1"}, port: 5},
+		{name: "switch unreadable", err: errors.New("timeout"), port: 1, wantErr: "checking port 1 of device sw-1: timeout"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := &fakeClient{getDevice: func(string, int) (unifi.Device, error) { return tc.dev, tc.err }}
+			err := checkPoEPort(context.Background(), fake, "", "sw-1", tc.port)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("got %v, want no error", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Fatalf("got %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gordcurrie/unifi-mcp/internal/dnslint"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// dnsLintReport is the result of lint_dns_policies.
+type dnsLintReport struct {
+	Policies int `json:"policies"`
+	// Subnets are the network subnets A records were checked against.
+	Subnets  []string          `json:"subnets"`
+	Counts   map[string]int    `json:"counts"`
+	Findings []dnslint.Finding `json:"findings"`
+}
+
+// registerDNSLintTools registers lint_dns_policies.
+func registerDNSLintTools(s *mcp.Server, client unifiClient) {
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "lint_dns_policies",
+		Description: "Check the site's local DNS policies for mistakes and return structured findings, most serious first: " +
+			"duplicate policies and names with conflicting answers (including a CNAME beside other records), records that " +
+			"shadow names in public DNS, A records pointing outside every network subnet, CNAME/MX/SRV records whose target " +
+			"only has disabled records, names overlapping a wildcard, and unusually short or long TTLs.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID       string  `json:"site_id,omitempty"       jsonschema:"site ID; omit to use default"`
+		LocalDomains *string `json:"local_domains,omitempty" jsonschema:"comma-separated domains the site answers for on purpose (e.g. a split-horizon company domain); records under them are not reported as shadowing public DNS"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		policies, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.DNSPolicy], error) {
+			return client.ListDNSPolicies(ctx, input.SiteID, offset, limit)
+		})
+		if err != nil {
+			return errorResult(fmt.Errorf("lint_dns_policies: %w", err))
+		}
+		plan, _, err := loadIPPlan(ctx, client, input.SiteID)
+		if err != nil {
+			return errorResult(fmt.Errorf("lint_dns_policies: %w", err))
+		}
+		opts := dnslint.Options{LocalDomains: splitIDs(input.LocalDomains)}
+		report := dnsLintReport{Policies: len(policies), Subnets: []string{}, Counts: map[string]int{}}
+		for _, n := range plan {
+			if n.Subnet.IsValid() {
+				opts.Subnets = append(opts.Subnets, n.Subnet)
+				report.Subnets = append(report.Subnets, n.Subnet.String())
+			}
+		}
+		report.Findings = dnslint.Lint(policies, opts)
+		if report.Findings == nil {
+			report.Findings = []dnslint.Finding{}
+		}
+		for _, f := range report.Findings {
+			report.Counts[string(f.Severity)]++
+		}
+		return jsonResult(report)
+	})
+}
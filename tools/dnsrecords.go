@@ -0,0 +1,240 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gordcurrie/unifi-mcp/internal/dnsrecords"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxDNSRecordsContent caps the text import_dns_records accepts.
+const maxDNSRecordsContent = 4 << 20
+
+// policyRecord converts a DNS policy to a record. It reports false for
+// policy types that have no record form.
+func policyRecord(p *unifi.DNSPolicy) (dnsrecords.Record, bool) {
+	r := dnsrecords.Record{ID: p.ID, Name: strings.ToLower(strings.TrimSuffix(p.Domain, ".")), TTL: p.TTLSeconds}
+	switch p.Type {
+	case unifi.DNSPolicyTypeA:
+		r.Type, r.Value = dnsrecords.A, p.IPv4Address
+	case unifi.DNSPolicyTypeAAAA:
+		r.Type, r.Value = dnsrecords.AAAA, p.IPv6Address
+	case unifi.DNSPolicyTypeCNAME:
+		r.Type, r.Value = dnsrecords.CNAME, strings.ToLower(strings.TrimSuffix(p.TargetDomain, "."))
+	default:
+		return r, false
+	}
+	return r, true
+}
+
+// setRecord sets req's type, domain, value and TTL from r.
+func setRecord(req *unifi.DNSPolicyRequest, r dnsrecords.Record) {
+	req.Domain, req.TTLSeconds = r.Name, r.TTL
+	switch r.Type {
+	case dnsrecords.A:
+		req.Type, req.IPv4Address = unifi.DNSPolicyTypeA, r.Value
+	case dnsrecords.AAAA:
+		req.Type, req.IPv6Address = unifi.DNSPolicyTypeAAAA, r.Value
+	case dnsrecords.CNAME:
+		req.Type, req.TargetDomain = unifi.DNSPolicyTypeCNAME, r.Value
+	}
+}
+
+// dnsRecordResult is the outcome of one change applied by import_dns_records.
+type dnsRecordResult struct {
+	Action string            `json:"action"`
+	Record dnsrecords.Record `json:"record"`
+	OK     bool              `json:"ok"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// dnsImportReport is the result of import_dns_records.
+type dnsImportReport struct {
+	Lines        int               `json:"lines"`
+	Records      int               `json:"records"`
+	Skipped      []dnsrecords.Skip `json:"skipped,omitempty"`
+	Plan         dnsrecords.Plan   `json:"plan"`
+	DeleteExtras bool              `json:"deleteExtras"`
+	Applied      bool              `json:"applied"`
+	Results      []dnsRecordResult `json:"results,omitempty"`
+	Failed       int               `json:"failed,omitempty"`
+}
+
+// registerDNSRecordTools registers bulk DNS record import and export. Only
+// A, AAAA and CNAME policies take part; other policy types are never touched.
+// Deleting extra records is refused unless allowDestructive is set.
+func registerDNSRecordTools(s *mcp.Server, client unifiClient, allowDestructive bool) {
+	destructiveTrue := true
+
+	// recordPolicies lists the site's DNS policies that have a record form and
+	// lie under domain, keyed by ID.
+	recordPolicies := func(ctx context.Context, siteID, domain string) ([]dnsrecords.Record, map[string]unifi.DNSPolicy, error) {
+		policies, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.DNSPolicy], error) {
+			return client.ListDNSPolicies(ctx, siteID, offset, limit)
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		var records []dnsrecords.Record
+		byID := map[string]unifi.DNSPolicy{}
+		for i := range policies {
+			r, ok := policyRecord(&policies[i])
+			if !ok || !dnsrecords.InDomain(r.Name, domain) {
+				continue
+			}
+			records = append(records, r)
+			byID[r.ID] = policies[i]
+		}
+		return records, byID, nil
+	}
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "import_dns_records",
+		Description: "Reconcile local DNS policies with records from an /etc/hosts file, a BIND zone file (A, AAAA and CNAME " +
+			"records) or a CSV file (columns name, type, value, ttl). Missing records are created and records whose address, " +
+			"target or TTL differ are updated, matching against all of the site's records; a CNAME may not share its name with a record " +
+			"that stays. With delete_extras=true, existing A/AAAA/CNAME policies under origin (required) " +
+			"that are not in the file are deleted. Without confirmed=true only the plan is returned; with it every change is applied " +
+			"as one batch and reported per record.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID       string `json:"site_id,omitempty"       jsonschema:"site ID; omit to use default"`
+		Format       string `json:"format"                  jsonschema:"hosts, zone or csv"`
+		Content      string `json:"content"                 jsonschema:"the file's text"`
+		Origin       string `json:"origin,omitempty"        jsonschema:"domain for relative names: the initial $ORIGIN of a zone file, appended to single-label names in hosts and CSV files; required with delete_extras, which only deletes records under it"`
+		DefaultTTL   int    `json:"default_ttl,omitempty"   jsonschema:"TTL in seconds for records that give none; 0 keeps existing TTLs and uses the server default for new records"`
+		DeleteExtras bool   `json:"delete_extras,omitempty" jsonschema:"also delete existing A/AAAA/CNAME policies under origin that are not in the file; requires origin and UNIFI_ALLOW_DESTRUCTIVE=true"`
+		Confirmed    bool   `json:"confirmed"               jsonschema:"true to apply the plan; false or omitted returns the plan only"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		format, err := dnsrecords.ParseFormat(input.Format)
+		if err != nil {
+			return errorResult(fmt.Errorf("import_dns_records: %w", err))
+		}
+		if len(input.Content) > maxDNSRecordsContent {
+			return errorResult(fmt.Errorf("import_dns_records: content is %d bytes; the limit is %d", len(input.Content), maxDNSRecordsContent))
+		}
+		if input.DeleteExtras && !allowDestructive {
+			return errorResult(fmt.Errorf("import_dns_records: delete_extras requires UNIFI_ALLOW_DESTRUCTIVE=true"))
+		}
+		// Without a domain to stay under, delete_extras would also remove
+		// records other tools manage, such as sync_client_dns's.
+		if input.DeleteExtras && strings.Trim(strings.TrimSpace(input.Origin), ".") == "" {
+			return errorResult(fmt.Errorf("import_dns_records: delete_extras requires origin; only records under it are deleted"))
+		}
+		if input.DefaultTTL < 0 || input.DefaultTTL > dnsrecords.MaxTTL {
+			return errorResult(fmt.Errorf("import_dns_records: default_ttl %d is out of range", input.DefaultTTL))
+		}
+		parsed, err := dnsrecords.Parse(strings.NewReader(input.Content), format, input.Origin)
+		if err != nil {
+			return errorResult(fmt.Errorf("import_dns_records: %w", err))
+		}
+		if len(parsed.Records) == 0 {
+			return errorResult(fmt.Errorf("import_dns_records: the content has no A, AAAA or CNAME records (%d lines, %d skipped)",
+				parsed.Lines, len(parsed.Skipped)))
+		}
+		for i := range parsed.Records {
+			if parsed.Records[i].TTL == 0 {
+				parsed.Records[i].TTL = input.DefaultTTL
+			}
+		}
+
+		// Records are matched against every policy, so a name outside origin
+		// is updated rather than duplicated; origin only bounds what
+		// delete_extras may delete.
+		existing, byID, err := recordPolicies(ctx, input.SiteID, "")
+		if err != nil {
+			return errorResult(fmt.Errorf("import_dns_records: %w", err))
+		}
+		var deletable func(dnsrecords.Record) bool
+		if input.DeleteExtras {
+			deletable = func(r dnsrecords.Record) bool { return dnsrecords.InDomain(r.Name, input.Origin) }
+		}
+		plan, err := dnsrecords.Diff(parsed.Records, existing, deletable)
+		if err != nil {
+			return errorResult(fmt.Errorf("import_dns_records: %w", err))
+		}
+		report := dnsImportReport{
+			Lines:        parsed.Lines,
+			Records:      len(parsed.Records),
+			Skipped:      parsed.Skipped,
+			Plan:         plan,
+			DeleteExtras: input.DeleteExtras,
+		}
+		if !input.Confirmed {
+			return jsonResult(report)
+		}
+
+		// Deletes go first so a name freed of an extra record can take a CNAME.
+		record := func(action string, r dnsrecords.Record, err error) {
+			res := dnsRecordResult{Action: action, Record: r, OK: err == nil}
+			if err != nil {
+				res.Error = err.Error()
+				report.Failed++
+			}
+			report.Results = append(report.Results, res)
+		}
+		for _, r := range plan.Extra {
+			record("delete", r, client.DeleteDNSPolicy(ctx, input.SiteID, r.ID))
+		}
+		for _, u := range plan.Update {
+			current := byID[u.To.ID]
+			req := current.Request()
+			setRecord(&req, u.To)
+			_, err := client.UpdateDNSPolicy(ctx, input.SiteID, u.To.ID, req)
+			record("update", u.To, err)
+		}
+		for _, r := range plan.Create {
+			req := unifi.DNSPolicyRequest{Enabled: true}
+			setRecord(&req, r)
+			p, err := client.CreateDNSPolicy(ctx, input.SiteID, req)
+			r.ID = p.ID
+			record("create", r, err)
+		}
+		report.Applied = true
+		res, _, _ := jsonResult(report)
+		res.IsError = report.Failed > 0
+		return res, nil, nil
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "export_dns_records",
+		Description: "Export the site's A, AAAA and CNAME DNS policies as an /etc/hosts file, a BIND zone file or CSV. " +
+			"Hosts files cannot hold CNAME or wildcard records, so those are left out and counted in a trailing comment.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID          string `json:"site_id,omitempty"          jsonschema:"site ID; omit to use default"`
+		Format          string `json:"format"                     jsonschema:"hosts, zone or csv"`
+		Origin          string `json:"origin,omitempty"           jsonschema:"only export records under this domain; zone files write names relative to it"`
+		IncludeDisabled bool   `json:"include_disabled,omitempty" jsonschema:"also export disabled policies"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		format, err := dnsrecords.ParseFormat(input.Format)
+		if err != nil {
+			return errorResult(fmt.Errorf("export_dns_records: %w", err))
+		}
+		all, byID, err := recordPolicies(ctx, input.SiteID, input.Origin)
+		if err != nil {
+			return errorResult(fmt.Errorf("export_dns_records: %w", err))
+		}
+		records := all[:0]
+		for _, r := range all {
+			if input.IncludeDisabled || byID[r.ID].Enabled {
+				records = append(records, r)
+			}
+		}
+		dnsrecords.Sort(records)
+		var b strings.Builder
+		omitted, err := dnsrecords.Write(&b, format, records, input.Origin)
+		if err != nil {
+			return errorResult(fmt.Errorf("export_dns_records: %w", err))
+		}
+		if omitted > 0 {
+			fmt.Fprintf(&b, "# %d CNAME or wildcard records cannot be expressed in a hosts file and were left out\n", omitted)
+		}
+		return textResult(b.String())
+	})
+}
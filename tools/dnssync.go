@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/dnssync"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SyncClientDNS plans, and with apply set carries out, a sync of client names
+// into DNS A records under opts.Domain. It is exported for the background
+// sync in main. Changes are applied one by one and every failure is recorded
+// in the report rather than stopping the run; the registry is saved once at
+// the end, so a crash mid-run leaves at worst records the sync treats as
+// someone else's. Syncs are serialized on the store.
+func SyncClientDNS(ctx context.Context, client unifiClient, store *dnssync.Store, siteID string, opts dnssync.Options, apply bool) (dnssync.Report, error) {
+	store.Lock()
+	defer store.Unlock()
+	siteID = client.SiteID(siteID)
+
+	clients, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.NetworkClient], error) {
+		return client.ListClients(ctx, siteID, offset, limit)
+	})
+	if err != nil {
+		return dnssync.Report{}, fmt.Errorf("list clients: %w", err)
+	}
+	policies, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.DNSPolicy], error) {
+		return client.ListDNSPolicies(ctx, siteID, offset, limit)
+	})
+	if err != nil {
+		return dnssync.Report{}, fmt.Errorf("list dns policies: %w", err)
+	}
+	seen := make([]dnssync.Client, len(clients))
+	for i := range clients {
+		seen[i] = dnssync.Client{MAC: clients[i].MAC, Name: clients[i].Name, IP: clients[i].IP}
+	}
+	owned := store.List(siteID)
+	if siteID == client.SiteID("") {
+		// Records saved under "" are the default site's; rewriting them
+		// below moves them to its ID.
+		owned = append(owned, store.List("")...)
+	}
+	rep := dnssync.Report{Plan: dnssync.Compute(seen, policies, owned, opts)}
+	if !apply {
+		return rep, nil
+	}
+
+	byID := make(map[string]*unifi.DNSPolicy, len(policies))
+	for i := range policies {
+		byID[policies[i].ID] = &policies[i]
+	}
+	var (
+		put    []dnssync.Record
+		remove []string
+	)
+	own := func(c dnssync.Change) {
+		put = append(put, dnssync.Record{
+			PolicyID: c.PolicyID, SiteID: siteID, MAC: c.MAC, ClientName: c.ClientName,
+			Domain: c.Domain, IP: c.IP, LastSeen: opts.Now,
+		})
+	}
+	fail := func(action string, c dnssync.Change, err error) {
+		rep.Errors = append(rep.Errors, fmt.Sprintf("%s %s: %v", action, c.Domain, err))
+	}
+
+	for _, c := range rep.Delete {
+		if err := client.DeleteDNSPolicy(ctx, siteID, c.PolicyID); err != nil {
+			var apiErr *unifi.APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+				fail("delete", c, err)
+				continue
+			}
+		}
+		remove = append(remove, c.PolicyID)
+	}
+	for _, c := range rep.Forget {
+		remove = append(remove, c.PolicyID)
+	}
+	for _, c := range rep.Update {
+		req := unifi.DNSPolicyRequest{Enabled: true}
+		if p := byID[c.PolicyID]; p != nil && p.Type == unifi.DNSPolicyTypeA {
+			req = p.Request()
+		}
+		req.Type, req.Domain, req.IPv4Address = unifi.DNSPolicyTypeA, c.Domain, c.IP
+		if _, err := client.UpdateDNSPolicy(ctx, siteID, c.PolicyID, req); err != nil {
+			fail("update", c, err)
+			continue
+		}
+		own(c)
+	}
+	for _, c := range rep.Create {
+		p, err := client.CreateDNSPolicy(ctx, siteID, unifi.DNSPolicyRequest{
+			Type: unifi.DNSPolicyTypeA, Domain: c.Domain, IPv4Address: c.IP, Enabled: true,
+		})
+		if err != nil {
+			fail("create", c, err)
+			continue
+		}
+		c.PolicyID = p.ID
+		own(c)
+	}
+	for _, c := range rep.Unchanged {
+		own(c)
+	}
+	// Owned records whose client is connected but was skipped this run stay
+	// owned; only their last-seen time moves.
+	touched := map[string]bool{}
+	for _, r := range put {
+		touched[r.MAC] = true
+	}
+	for _, mac := range rep.Seen {
+		for _, r := range owned {
+			if r.MAC == mac && !touched[mac] {
+				r.SiteID, r.LastSeen = siteID, opts.Now
+				put = append(put, r)
+			}
+		}
+	}
+	if err := store.Update(put, remove); err != nil {
+		return rep, err
+	}
+	rep.Applied = true
+	return rep, nil
+}
+
+// registerDNSSyncTools registers sync_client_dns. domain is the configured
+// suffix client names are placed under.
+func registerDNSSyncTools(s *mcp.Server, client unifiClient, store *dnssync.Store, domain string) {
+	destructiveTrue := true
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "sync_client_dns",
+		Description: "Give every named client a local DNS A record <name>." + domain + " pointing at its current IPv4 address. " +
+			"Names are sanitized into host names; clients sharing a name get the end of their MAC appended. Only records " +
+			"created by this sync (kept in a local ownership registry) are ever updated or deleted; a name already used by " +
+			"any other DNS policy is skipped. Records of clients not seen for remove_after are deleted. Without " +
+			"confirmed=true only the dry-run plan is returned.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID      string `json:"site_id,omitempty"      jsonschema:"site ID; omit to use default"`
+		RemoveAfter string `json:"remove_after,omitempty" jsonschema:"delete a client's record once the client has not been seen for this Go duration (default 168h)"`
+		Confirmed   bool   `json:"confirmed"              jsonschema:"true to apply the plan; false or omitted returns a dry run"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		opts := dnssync.Options{Domain: domain, Now: time.Now()}
+		if input.RemoveAfter != "" {
+			d, err := time.ParseDuration(input.RemoveAfter)
+			if err != nil || d <= 0 {
+				return errorResult(fmt.Errorf("sync_client_dns: invalid remove_after %q (use e.g. 72h)", input.RemoveAfter))
+			}
+			opts.RemoveAfter = d
+		}
+		rep, err := SyncClientDNS(ctx, client, store, input.SiteID, opts, input.Confirmed)
+		if err != nil {
+			return errorResult(fmt.Errorf("sync_client_dns: %w", err))
+		}
+		res, _, _ := jsonResult(rep)
+		res.IsError = len(rep.Errors) > 0
+		return res, nil, nil
+	})
+}
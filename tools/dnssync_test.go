@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/dnssync"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+func TestSyncClientDNSKeysTheEffectiveSite(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, siteID := range []string{"", fakeDefaultSite} {
+		t.Run("site "+siteID, func(t *testing.T) {
+			store, err := dnssync.Open(filepath.Join(t.TempDir(), "dnssync.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// A record the background sync saved before sites were resolved.
+			legacy := dnssync.Record{PolicyID: "p-1", MAC: "aa:bb:cc:00:00:01", ClientName: "Laptop",
+				Domain: "laptop.lan", IP: "10.0.0.5", LastSeen: now.Add(-time.Hour)}
+			if err := store.Update([]dnssync.Record{legacy}, nil); err != nil {
+				t.Fatal(err)
+			}
+			fake := &fakeClient{
+				clients: []unifi.NetworkClient{{MAC: legacy.MAC, Name: "Laptop", IP: "10.0.0.5"}},
+				dnsPolicies: []unifi.DNSPolicy{{ID: "p-1", Type: unifi.DNSPolicyTypeA, Domain: "laptop.lan",
+					IPv4Address: "10.0.0.5", Enabled: true}},
+			}
+			rep, err := SyncClientDNS(context.Background(), fake, store, siteID, dnssync.Options{Domain: "lan", Now: now}, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rep.Create)+len(rep.Update)+len(rep.Delete) > 0 || len(rep.Unchanged) != 1 {
+				t.Fatalf("plan = %+v, want the legacy record unchanged", rep.Plan)
+			}
+			if got := store.List(""); len(got) != 0 {
+				t.Errorf("records left under \"\": %+v", got)
+			}
+			if got := store.List(fakeDefaultSite); len(got) != 1 || got[0].PolicyID != "p-1" || !got[0].LastSeen.Equal(now) {
+				t.Errorf("records under %s = %+v, want p-1 seen now", fakeDefaultSite, got)
+			}
+		})
+	}
+}
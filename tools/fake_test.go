@@ -0,0 +1,172 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+// fakeClient is an in-memory unifiClient. Methods a test does not override
+// through the embedded interface panic, so a test fails loudly when the code
+// under test makes a call it did not expect.
+type fakeClient struct {
+	unifiClient
+
+	mu          sync.Mutex
+	devices     []unifi.Device
+	clients     []unifi.NetworkClient
+	networks    []unifi.NetworkConf
+	broadcasts  []unifi.WiFiBroadcast
+	dnsPolicies []unifi.DNSPolicy
+	deviceLists int
+	clientLists int
+
+	// raw holds GetRaw/PutRaw objects keyed by "collection/id"; puts counts
+	// the PutRaw calls.
+	raw  map[string]json.RawMessage
+	puts int
+
+	// listUpdates records the entries of each UpdateTrafficMatchingList call;
+	// the call numbered failListUpdate (from 1) fails instead.
+	listUpdates    [][]string
+	failListUpdate int
+
+	// getDevice and deviceStats answer GetDevice and GetDeviceStats; call
+	// counts each device's GetDevice calls from 1. restarts records the
+	// RestartDevice calls, which fail for devices in restartErrs, and
+	// upgrades the UpgradeDevice calls.
+	getDevice   func(id string, call int) (unifi.Device, error)
+	deviceStats func(id string) (unifi.DeviceStats, error)
+	deviceCalls map[string]int
+	restarts    []string
+	restartErrs map[string]error
+	upgrades    []string
+
+	// pending answers ListPendingDevices; adopted records the AdoptDevice
+	// calls, which fail for MACs in adoptErrs.
+	pending   []unifi.PendingDevice
+	adopted   []string
+	adoptErrs map[string]error
+}
+
+// fakeDefaultSite is the site the fake acts on when siteID is empty.
+const fakeDefaultSite = "default-site"
+
+// fakePage returns the [offset, offset+limit) slice of items as a page.
+func fakePage[T any](items []T, offset, limit int) unifi.Page[T] {
+	end := min(offset+limit, len(items))
+	offset = min(offset, end)
+	return unifi.Page[T]{Data: items[offset:end], TotalCount: len(items), Offset: offset, Limit: limit, Count: end - offset}
+}
+
+func (f *fakeClient) SiteID(siteID string) string {
+	if siteID == "" {
+		return fakeDefaultSite
+	}
+	return siteID
+}
+
+func (f *fakeClient) ListDevices(_ context.Context, _ string, offset, limit int) (unifi.Page[unifi.Device], error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deviceLists++
+	return fakePage(f.devices, offset, limit), nil
+}
+
+func (f *fakeClient) ListClients(_ context.Context, _ string, offset, limit int) (unifi.Page[unifi.NetworkClient], error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.clientLists++
+	return fakePage(f.clients, offset, limit), nil
+}
+
+func (f *fakeClient) ListNetworks(_ context.Context, _ string, offset, limit int) (unifi.Page[unifi.NetworkConf], error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return fakePage(f.networks, offset, limit), nil
+}
+
+func (f *fakeClient) GetRaw(_ context.Context, _, collection, resourceID string) (json.RawMessage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body, ok := f.raw[collection+"/"+resourceID]
+	if !ok {
+		return nil, fmt.Errorf("%s/%s not found", collection, resourceID)
+	}
+	return body, nil
+}
+
+func (f *fakeClient) PutRaw(_ context.Context, _, collection, resourceID string, body json.RawMessage) (json.RawMessage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.puts++
+	f.raw[collection+"/"+resourceID] = body
+	return body, nil
+}
+
+func (f *fakeClient) UpdateTrafficMatchingList(_ context.Context, _, listID string, update unifi.TrafficMatchingListUpdate) (unifi.TrafficMatchingList, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.listUpdates)+1 == f.failListUpdate {
+		return unifi.TrafficMatchingList{}, errors.New("update rejected")
+	}
+	f.listUpdates = append(f.listUpdates, update.Entries)
+	return unifi.TrafficMatchingList{ID: listID, Entries: update.Entries}, nil
+}
+
+func (f *fakeClient) ListDNSPolicies(_ context.Context, _ string, offset, limit int) (unifi.Page[unifi.DNSPolicy], error) {
+	return fakePage(f.dnsPolicies, offset, limit), nil
+}
+
+func (f *fakeClient) ListWiFiBroadcasts(_ context.Context, _ string, offset, limit int) (unifi.Page[unifi.WiFiBroadcast], error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return fakePage(f.broadcasts, offset, limit), nil
+}
+
+func (f *fakeClient) GetDevice(_ context.Context, _, deviceID string) (unifi.Device, error) {
+	f.mu.Lock()
+	if f.deviceCalls == nil {
+		f.deviceCalls = map[string]int{}
+	}
+	f.deviceCalls[deviceID]++
+	call := f.deviceCalls[deviceID]
+	f.mu.Unlock()
+	return f.getDevice(deviceID, call)
+}
+
+func (f *fakeClient) GetDeviceStats(_ context.Context, _, deviceID string) (unifi.DeviceStats, error) {
+	return f.deviceStats(deviceID)
+}
+
+func (f *fakeClient) RestartDevice(_ context.Context, _, deviceID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.restarts = append(f.restarts, deviceID)
+	return f.restartErrs[deviceID]
+}
+
+func (f *fakeClient) UpgradeDevice(_ context.Context, _, deviceID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.upgrades = append(f.upgrades, deviceID)
+	return nil
+}
+
+func (f *fakeClient) ListPendingDevices(_ context.Context, offset, limit int) (unifi.Page[unifi.PendingDevice], error) {
+	return fakePage(f.pending, offset, limit), nil
+}
+
+func (f *fakeClient) AdoptDevice(_ context.Context, _, mac string) (unifi.Device, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.adopted = append(f.adopted, mac)
+	if err := f.adoptErrs[mac]; err != nil {
+		return unifi.Device{}, err
+	}
+	return unifi.Device{ID: "dev-" + mac[len(mac)-2:], MAC: mac}, nil
+}
@@ -0,0 +1,212 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/firmware"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func registerFirmwareTools(s *mcp.Server, client unifiClient, res *resolver) {
+	destructiveTrue := true
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "firmware_report",
+		Description: "Report the firmware on a site's adopted devices, grouped by model and version: how many devices run " +
+			"each version, which have an update available, which versions are behind the newest one running on the same " +
+			"model, and whether each model runs a single version.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID string `json:"site_id,omitempty" jsonschema:"site ID; omit to use default"`
+		Model  string `json:"model,omitempty"   jsonschema:"only report devices of this model, e.g. U6-Pro"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		devices, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.Device], error) {
+			return client.ListDevices(ctx, input.SiteID, offset, limit)
+		})
+		if err != nil {
+			return errorResult(fmt.Errorf("firmware_report: %w", err))
+		}
+		if model := strings.TrimSpace(input.Model); model != "" {
+			kept := devices[:0]
+			for i := range devices {
+				if strings.EqualFold(devices[i].Model, model) {
+					kept = append(kept, devices[i])
+				}
+			}
+			devices = kept
+		}
+		return jsonResult(firmware.Summarize(devices))
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "upgrade_devices",
+		Description: "Upgrade device firmware in waves. Select devices by tag (device tag name or ID), model, or devices " +
+			"(comma-separated IDs, names, MACs or IPs); devices without an available update or not online are skipped. " +
+			"The first canary devices are upgraded alone, then the rest wave_size at a time. Every device in a wave must " +
+			"come back online on a new firmware version within timeout_minutes of the wave starting; otherwise the rollout " +
+			"halts before the next wave. Progress is reported as MCP progress notifications. Without confirmed=true only the waves are returned.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input struct {
+		SiteID         string `json:"site_id,omitempty"         jsonschema:"site ID; omit to use default"`
+		Tag            string `json:"tag,omitempty"             jsonschema:"upgrade the devices with this device tag (name or ID)"`
+		Model          string `json:"model,omitempty"           jsonschema:"upgrade the devices of this model, e.g. U6-Pro"`
+		Devices        string `json:"devices,omitempty"         jsonschema:"comma-separated device IDs, names, MACs or IPs; the first are the canaries"`
+		Canary         int    `json:"canary,omitempty"          jsonschema:"how many devices to upgrade in the first wave (default 1)"`
+		WaveSize       int    `json:"wave_size,omitempty"       jsonschema:"how many devices to upgrade at once after the canaries (default 5)"`
+		TimeoutMinutes int    `json:"timeout_minutes,omitempty" jsonschema:"how long to wait for each wave to come back on new firmware (default 20)"`
+		Confirmed      bool   `json:"confirmed"                 jsonschema:"true to upgrade; omit to preview the waves"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		if input.Canary < 0 || input.WaveSize < 0 || input.TimeoutMinutes < 0 {
+			return errorResult(fmt.Errorf("upgrade_devices: canary, wave_size and timeout_minutes must not be negative"))
+		}
+		devices, err := selectDevices(ctx, client, res, input.SiteID, input.Tag, input.Model, input.Devices)
+		if err != nil {
+			return errorResult(fmt.Errorf("upgrade_devices: %w", err))
+		}
+		var (
+			runs    []deviceRun
+			skipped []deviceRun
+		)
+		for i := range devices {
+			r := newDeviceRun(&devices[i])
+			switch {
+			case !devices[i].FirmwareUpdatable:
+				r.Status, r.Error = "skipped", "no update available"
+				skipped = append(skipped, r)
+			case devices[i].State != deviceOnline:
+				r.Status, r.Error = "skipped", "device is "+devices[i].State
+				skipped = append(skipped, r)
+			default:
+				runs = append(runs, r)
+			}
+		}
+		waves := firmware.Waves(len(runs), cmp.Or(input.Canary, 1), cmp.Or(input.WaveSize, 5))
+		type wave struct {
+			Wave    int         `json:"wave"`
+			Canary  bool        `json:"canary,omitempty"`
+			Devices []deviceRun `json:"devices"`
+		}
+		report := func(dryRun, aborted bool, reason string) (*mcp.CallToolResult, any, error) {
+			out := struct {
+				DryRun  bool        `json:"dryRun,omitempty"`
+				Aborted bool        `json:"aborted"`
+				Reason  string      `json:"reason,omitempty"`
+				Waves   []wave      `json:"waves"`
+				Skipped []deviceRun `json:"skipped,omitempty"`
+			}{DryRun: dryRun, Aborted: aborted, Reason: reason, Waves: []wave{}, Skipped: skipped}
+			for i, w := range waves {
+				out.Waves = append(out.Waves, wave{Wave: i + 1, Canary: i == 0, Devices: runs[w[0]:w[1]]})
+			}
+			return jsonResult(out)
+		}
+		if !input.Confirmed || len(runs) == 0 {
+			return report(!input.Confirmed, false, "")
+		}
+
+		timeout := time.Duration(cmp.Or(input.TimeoutMinutes, 20)) * time.Minute
+		progress := progressNotifier{req: req, total: len(runs)}
+		aborted, reason := upgradeWaves(ctx, client, input.SiteID, runs, waves, timeout, progress)
+		return report(false, aborted, reason)
+	})
+}
+
+// upgradeWaves upgrades the devices in runs wave by wave. Each wave's devices
+// are upgraded together and then watched concurrently until all are back on
+// new firmware or the wave's timeout runs out; a wave with any failure halts
+// the rollout. It updates runs in place and reports whether, and why, the
+// rollout was aborted.
+func upgradeWaves(ctx context.Context, client unifiClient, siteID string, runs []deviceRun, waves [][2]int, timeout time.Duration, progress progressNotifier) (bool, string) {
+	var (
+		mu   sync.Mutex
+		done int
+	)
+	for n, w := range waves {
+		batch := runs[w[0]:w[1]]
+		progress.notify(ctx, w[0], fmt.Sprintf("wave %d of %d: upgrading %d devices", n+1, len(waves), len(batch)))
+		start := time.Now()
+		deadline := start.Add(timeout)
+		uptimes := make([]int64, len(batch))
+		sent := make([]time.Time, len(batch))
+		for i := range batch {
+			uptimes[i] = deviceUptime(ctx, client, siteID, batch[i].ID)
+			sent[i] = time.Now()
+			if err := client.UpgradeDevice(ctx, siteID, batch[i].ID); err != nil {
+				batch[i].Status, batch[i].Error = "failed", err.Error()
+			}
+		}
+		done = w[0]
+		var wg sync.WaitGroup
+		for i := range batch {
+			wg.Go(func() {
+				r := &batch[i]
+				if r.Status != "failed" {
+					before := r.Firmware
+					dev, err := restartedDevice(ctx, client, siteID, r.ID, uptimes[i], sent[i], deadline)
+					if err == nil {
+						dev, err = upgradedFirmware(ctx, client, siteID, r.ID, dev, before, deadline)
+					}
+					if err != nil {
+						r.Status, r.Error = "failed", err.Error()
+					} else {
+						r.Status, r.Firmware = "done", dev.FirmwareVersion
+					}
+					r.Seconds = int(time.Since(start).Seconds())
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				done++
+				progress.notify(ctx, done, fmt.Sprintf("%s: %s %s", cmp.Or(r.Name, r.ID), r.Status, cmp.Or(r.Error, r.Firmware)))
+			})
+		}
+		wg.Wait()
+		failed := 0
+		for i := range batch {
+			if batch[i].Status == "failed" {
+				failed++
+			}
+		}
+		if failed > 0 {
+			for i := w[1]; i < len(runs); i++ {
+				runs[i].Status = "skipped"
+			}
+			what := fmt.Sprintf("wave %d", n+1)
+			if n == 0 {
+				what = "the canary wave"
+			}
+			return true, fmt.Sprintf("%d devices in %s failed; later waves were not started", failed, what)
+		}
+	}
+	return false, ""
+}
+
+// upgradedFirmware waits for a device that is back online after an upgrade
+// to report a firmware version other than before, since the controller can
+// keep reporting the old one for a while after the device reconnects. dev is
+// the device as last read; it gives up at deadline.
+func upgradedFirmware(ctx context.Context, client unifiClient, siteID, deviceID string, dev unifi.Device, before string, deadline time.Time) (unifi.Device, error) {
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	for dev.FirmwareVersion == before {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return dev, fmt.Errorf("came back on the same firmware %s", before)
+			}
+			return dev, ctx.Err()
+		case <-time.After(deviceReturnPoll):
+		}
+		if d, err := client.GetDevice(ctx, siteID, deviceID); err == nil {
+			dev = d
+		}
+	}
+	return dev, nil
+}
@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/firmware"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+func TestUpgradeWaves(t *testing.T) {
+	fastDevicePolls(t)
+	const timeout = 100 * time.Millisecond
+	tests := []struct {
+		name         string
+		devices      int
+		canary, size int
+		// sameFirmware devices reboot but come back on 1.0; stuck ones
+		// never go offline; lateFirmware ones report 1.0 for a few polls
+		// after they are back.
+		sameFirmware, stuck, lateFirmware string
+		wantUpgrades                      []string
+		wantStatus                        []string
+		wantReason                        string
+	}{
+		{
+			name: "upgrades every wave", devices: 4, canary: 1, size: 2,
+			wantUpgrades: []string{"dev-1", "dev-2", "dev-3", "dev-4"},
+			wantStatus:   []string{"done", "done", "done", "done"},
+		},
+		{
+			name: "waits for the new firmware to be reported", devices: 2, canary: 1, size: 1,
+			lateFirmware: "dev-1",
+			wantUpgrades: []string{"dev-1", "dev-2"},
+			wantStatus:   []string{"done", "done"},
+		},
+		{
+			name: "halts when the canary returns on the same firmware", devices: 4, canary: 1, size: 2,
+			sameFirmware: "dev-1",
+			wantUpgrades: []string{"dev-1"},
+			wantStatus:   []string{"failed", "skipped", "skipped", "skipped"},
+			wantReason:   "1 devices in the canary wave failed",
+		},
+		{
+			name: "halts when the canary does not come back", devices: 3, canary: 1, size: 2,
+			stuck:        "dev-1",
+			wantUpgrades: []string{"dev-1"},
+			wantStatus:   []string{"failed", "skipped", "skipped"},
+			wantReason:   "the canary wave failed",
+		},
+		{
+			name: "halts after a failed wave", devices: 5, canary: 1, size: 2,
+			stuck:        "dev-3",
+			wantUpgrades: []string{"dev-1", "dev-2", "dev-3"},
+			wantStatus:   []string{"done", "done", "failed", "skipped", "skipped"},
+			wantReason:   "1 devices in wave 2 failed",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := &fakeClient{
+				getDevice: func(id string, call int) (unifi.Device, error) {
+					switch {
+					case id == tc.stuck:
+						return unifi.Device{ID: id, State: deviceOnline, FirmwareVersion: "1.0"}, nil
+					case call == 1:
+						return unifi.Device{ID: id, State: "OFFLINE"}, nil
+					case id == tc.sameFirmware, id == tc.lateFirmware && call <= 4:
+						return unifi.Device{ID: id, State: deviceOnline, FirmwareVersion: "1.0"}, nil
+					}
+					return unifi.Device{ID: id, State: deviceOnline, FirmwareVersion: "2.0"}, nil
+				},
+				deviceStats: func(string) (unifi.DeviceStats, error) { return unifi.DeviceStats{UptimeSec: 86400}, nil },
+			}
+			runs := make([]deviceRun, tc.devices)
+			for i := range runs {
+				runs[i] = deviceRun{ID: fmt.Sprintf("dev-%d", i+1), Status: "pending", Firmware: "1.0"}
+			}
+			aborted, reason := upgradeWaves(context.Background(), fake, "", runs,
+				firmware.Waves(len(runs), tc.canary, tc.size), timeout, progressNotifier{})
+			if fmt.Sprint(fake.upgrades) != fmt.Sprint(tc.wantUpgrades) {
+				t.Errorf("upgraded %v, want %v", fake.upgrades, tc.wantUpgrades)
+			}
+			var status []string
+			for _, r := range runs {
+				status = append(status, r.Status)
+			}
+			if fmt.Sprint(status) != fmt.Sprint(tc.wantStatus) {
+				t.Errorf("status %v, want %v", status, tc.wantStatus)
+			}
+			if aborted != (tc.wantReason != "") || !strings.Contains(reason, tc.wantReason) {
+				t.Errorf("aborted=%v reason %q, want %q", aborted, reason, tc.wantReason)
+			}
+		})
+	}
+}
+
+func TestUpgradeWavesShareOneDeadline(t *testing.T) {
+	fastDevicePolls(t)
+	const timeout = 100 * time.Millisecond
+	fake := &fakeClient{
+		getDevice: func(id string, _ int) (unifi.Device, error) {
+			return unifi.Device{ID: id, State: deviceOnline, FirmwareVersion: "1.0"}, nil
+		},
+		deviceStats: func(string) (unifi.DeviceStats, error) { return unifi.DeviceStats{UptimeSec: 86400}, nil },
+	}
+	runs := make([]deviceRun, 4)
+	for i := range runs {
+		runs[i] = deviceRun{ID: fmt.Sprintf("dev-%d", i+1), Status: "pending", Firmware: "1.0"}
+	}
+	start := time.Now()
+	aborted, _ := upgradeWaves(context.Background(), fake, "", runs, [][2]int{{0, 4}}, timeout, progressNotifier{})
+	// Waiting on each device in turn would take four timeouts.
+	if elapsed := time.Since(start); elapsed > 3*timeout {
+		t.Errorf("wave took %s, want about %s", elapsed, timeout)
+	}
+	if !aborted {
+		t.Error("a wave of stuck devices did not abort")
+	}
+	for _, r := range runs {
+		if r.Status != "failed" {
+			t.Errorf("%s is %s, want failed", r.ID, r.Status)
+		}
+	}
+}
@@ -1,12 +1,56 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// listAllPageSize is the page size used when walking every page of a list endpoint.
+const listAllPageSize = 200
+
+// listAll walks every page of a paginated list endpoint and returns the concatenated data.
+func listAll[T any](ctx context.Context, fetch func(ctx context.Context, offset, limit int) (unifi.Page[T], error)) ([]T, error) {
+	var all []T
+	offset := 0
+	for {
+		page, err := fetch(ctx, offset, listAllPageSize)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Data...)
+		offset += len(page.Data)
+		// TotalCount is not reported by every endpoint, so a page shorter
+		// than the one asked for (or capped by the server) also ends the walk.
+		limit := listAllPageSize
+		if page.Limit > 0 {
+			limit = min(limit, page.Limit)
+		}
+		if len(page.Data) < limit || (page.TotalCount > 0 && offset >= page.TotalCount) {
+			return all, nil
+		}
+	}
+}
+
+// mapPage converts every item of a page with f, preserving the pagination metadata.
+func mapPage[T, U any](p unifi.Page[T], f func(T) U) unifi.Page[U] {
+	out := unifi.Page[U]{
+		Data:       make([]U, len(p.Data)),
+		TotalCount: p.TotalCount,
+		Offset:     p.Offset,
+		Limit:      p.Limit,
+		Count:      p.Count,
+	}
+	for i, v := range p.Data {
+		out.Data[i] = f(v)
+	}
+	return out
+}
+
 // jsonResult marshals v to a JSON TextContent result.
 func jsonResult(v any) (*mcp.CallToolResult, any, error) {
 	b, err := json.Marshal(v)
@@ -44,3 +88,19 @@ func errorResult(err error) (*mcp.CallToolResult, any, error) {
 		IsError: true,
 	}, nil, nil
 }
+
+// splitIDs parses a comma-separated list, trimming whitespace and dropping empty
+// entries. A nil or empty input yields an empty (non-nil) slice.
+func splitIDs(s *string) []string {
+	if s == nil || *s == "" {
+		return []string{}
+	}
+	parts := strings.Split(*s, ",")
+	result := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if trimmed := strings.TrimSpace(p); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
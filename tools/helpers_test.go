@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+func TestListAll(t *testing.T) {
+	items := make([]int, 450)
+	for i := range items {
+		items[i] = i
+	}
+	tests := []struct {
+		name      string
+		page      func(offset, limit int) unifi.Page[int]
+		wantCalls int
+	}{
+		{
+			name:      "stops at totalCount",
+			page:      func(offset, limit int) unifi.Page[int] { return fakePage(items, offset, limit) },
+			wantCalls: 3,
+		},
+		{
+			name: "pages on when totalCount is missing",
+			page: func(offset, limit int) unifi.Page[int] {
+				p := fakePage(items, offset, limit)
+				p.TotalCount = 0
+				return p
+			},
+			wantCalls: 3,
+		},
+		{
+			name: "follows a server-capped page size",
+			page: func(offset, _ int) unifi.Page[int] {
+				p := fakePage(items, offset, 100)
+				p.TotalCount = 0
+				return p
+			},
+			wantCalls: 5,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			got, err := listAll(context.Background(), func(_ context.Context, offset, limit int) (unifi.Page[int], error) {
+				calls++
+				return tc.page(offset, limit), nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(items) || got[len(got)-1] != len(items)-1 {
+				t.Errorf("got %d items, want %d", len(got), len(items))
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("fetched %d pages, want %d", calls, tc.wantCalls)
+			}
+		})
+	}
+
+	t.Run("returns fetch errors", func(t *testing.T) {
+		boom := errors.New("boom")
+		_, err := listAll(context.Background(), func(context.Context, int, int) (unifi.Page[int], error) {
+			return unifi.Page[int]{}, boom
+		})
+		if !errors.Is(err, boom) {
+			t.Errorf("got %v, want %v", err, boom)
+		}
+	})
+}
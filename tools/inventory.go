@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/inventory"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// unknownClient is a list_unknown_clients row: the inventory record plus the
+// live client (when connected) enriched with MAC vendor information.
+type unknownClient struct {
+	inventory.Client
+	Connected bool        `json:"connected"`
+	Live      *clientView `json:"live,omitempty"`
+}
+
+// ClientObservations lists every connected client on siteID in the form the
+// inventory poller records. Pass an empty siteID to use the client default.
+func ClientObservations(ctx context.Context, client unifiClient, siteID string) ([]inventory.Observation, error) {
+	clients, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.NetworkClient], error) {
+		return client.ListClients(ctx, siteID, offset, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return observationsOf(clients), nil
+}
+
+func observationsOf(clients []unifi.NetworkClient) []inventory.Observation {
+	obs := make([]inventory.Observation, len(clients))
+	for i := range clients {
+		obs[i] = inventory.Observation{MAC: clients[i].MAC, Name: clients[i].Name, IP: clients[i].IP}
+	}
+	return obs
+}
+
+func registerInventoryTools(s *mcp.Server, client unifiClient, res *resolver, inv *inventory.Store) {
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "list_unknown_clients",
+		Description: "List connected clients whose MAC address has not been marked known in the local inventory, with vendor, " +
+			"randomized-MAC flag, and first/last seen times. Refreshes the inventory's last-seen data; makes no controller changes. " +
+			"Set include_offline=true to also list unknown MACs seen previously but not connected now.",
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID         string `json:"site_id,omitempty"         jsonschema:"site ID; omit to use default"`
+		IncludeOffline bool   `json:"include_offline,omitempty" jsonschema:"also list unknown MACs from the inventory that are not currently connected"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		clients, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.NetworkClient], error) {
+			return client.ListClients(ctx, input.SiteID, offset, limit)
+		})
+		if err != nil {
+			return errorResult(fmt.Errorf("list_unknown_clients: %w", err))
+		}
+		newEvents, err := inv.Record(observationsOf(clients))
+		if err != nil {
+			return errorResult(fmt.Errorf("list_unknown_clients: %w", err))
+		}
+
+		connected := make(map[string]bool, len(clients))
+		unknown := []unknownClient{}
+		for i := range clients {
+			mac, ok := normalizeMAC(clients[i].MAC)
+			if !ok {
+				continue
+			}
+			connected[mac] = true
+			rec, _ := inv.Get(mac)
+			if rec.Known {
+				continue
+			}
+			live := newClientView(clients[i])
+			unknown = append(unknown, unknownClient{Client: rec, Connected: true, Live: &live})
+		}
+		if input.IncludeOffline {
+			for _, rec := range inv.List() {
+				if !rec.Known && !connected[rec.MAC] {
+					unknown = append(unknown, unknownClient{Client: rec})
+				}
+			}
+		}
+		return jsonResult(struct {
+			Unknown        []unknownClient   `json:"unknown"`
+			UnknownCount   int               `json:"unknownCount"`
+			ConnectedCount int               `json:"connectedCount"`
+			NewlySeen      []inventory.Event `json:"newlySeen,omitempty"`
+		}{unknown, len(unknown), len(clients), newEvents})
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "list_new_clients",
+		Description: "List first-seen events from the local client inventory: MAC addresses that joined the network for the first time " +
+			"within the lookback window (default 24h). Events are recorded by the background poller and by list_unknown_clients; " +
+			"the clients present when the inventory first records anything are its baseline and get no event.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(_ context.Context, _ *mcp.CallToolRequest, input struct {
+		Since string `json:"since,omitempty" jsonschema:"lookback window as a Go duration, e.g. 1h, 24h, 168h; omit for 24h"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		window := 24 * time.Hour
+		if input.Since != "" {
+			d, err := time.ParseDuration(input.Since)
+			if err != nil || d <= 0 {
+				return errorResult(fmt.Errorf("list_new_clients: since must be a positive duration such as 24h"))
+			}
+			window = d
+		}
+		events := inv.Events(time.Now().Add(-window))
+		type row struct {
+			inventory.Event
+			Known bool `json:"known"`
+			macVendorInfo
+		}
+		rows := make([]row, len(events))
+		for i, e := range events {
+			rec, _ := inv.Get(e.MAC)
+			rows[i] = row{e, rec.Known, describeMAC(e.MAC)}
+		}
+		return jsonResult(rows)
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "mark_client_known",
+		Description: "Mark a client MAC address as known in the local inventory, with an optional owner and label, so it no longer " +
+			"appears in list_unknown_clients. Identify it by client_id, client selector (name, MAC, or IP), or a raw mac (which need not be connected). " +
+			"Set known=false to undo. Makes no controller changes.",
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID   string `json:"site_id,omitempty"   jsonschema:"site ID; omit to use default"`
+		ClientID string `json:"client_id,omitempty" jsonschema:"client ID; provide this, client, or mac"`
+		Client   string `json:"client,omitempty"    jsonschema:"client selector: ID, name (exact or partial), MAC in any notation, or IP"`
+		MAC      string `json:"mac,omitempty"       jsonschema:"MAC address to mark directly, without looking up a connected client"`
+		Owner    string `json:"owner,omitempty"     jsonschema:"who the device belongs to; omit to keep the existing value"`
+		Label    string `json:"label,omitempty"     jsonschema:"what the device is, e.g. 'kitchen tablet'; omit to keep the existing value"`
+		Known    *bool  `json:"known,omitempty"     jsonschema:"true (default) to mark known; false to mark unknown again"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		mac := input.MAC
+		if mac == "" {
+			var c unifi.NetworkClient
+			var err error
+			switch {
+			case input.ClientID != "":
+				c, err = client.GetClient(ctx, input.SiteID, input.ClientID)
+			case input.Client != "":
+				c, err = res.resolveClient(ctx, input.SiteID, input.Client, false)
+			default:
+				return errorResult(fmt.Errorf("mark_client_known: one of client_id, client, or mac is required"))
+			}
+			if err != nil {
+				return errorResult(fmt.Errorf("mark_client_known: %w", err))
+			}
+			mac = c.MAC
+		}
+		var rec inventory.Client
+		var err error
+		if input.Known != nil && !*input.Known {
+			rec, err = inv.MarkUnknown(mac)
+		} else {
+			rec, err = inv.MarkKnown(mac, input.Owner, input.Label)
+		}
+		if err != nil {
+			return errorResult(fmt.Errorf("mark_client_known: %w", err))
+		}
+		return jsonResult(rec)
+	})
+}
@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gordcurrie/unifi-mcp/internal/oui"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// macVendorInfo is appended to device and client output so a human can tell
+// what an unnamed MAC address is without a separate lookup.
+type macVendorInfo struct {
+	MACVendor              string `json:"macVendor,omitempty"`
+	MACLocallyAdministered bool   `json:"macLocallyAdministered"`
+	MACRandomized          bool   `json:"macRandomized"`
+}
+
+// deviceView is a Device enriched with MAC vendor information.
+type deviceView struct {
+	unifi.Device
+	macVendorInfo
+}
+
+// clientView is a NetworkClient enriched with MAC vendor information.
+type clientView struct {
+	unifi.NetworkClient
+	macVendorInfo
+}
+
+// pendingDeviceView is a PendingDevice enriched with MAC vendor information.
+type pendingDeviceView struct {
+	unifi.PendingDevice
+	macVendorInfo
+}
+
+// describeMAC returns the vendor info for mac; an unparseable MAC yields the zero value.
+func describeMAC(mac string) macVendorInfo {
+	info, err := oui.Describe(mac)
+	if err != nil {
+		return macVendorInfo{}
+	}
+	return macVendorInfo{
+		MACVendor:              info.Vendor,
+		MACLocallyAdministered: info.LocallyAdministered,
+		MACRandomized:          info.Randomized,
+	}
+}
+
+func newDeviceView(d unifi.Device) deviceView { return deviceView{d, describeMAC(d.MAC)} }
+
+func newClientView(c unifi.NetworkClient) clientView { return clientView{c, describeMAC(c.MAC)} }
+
+func newPendingDeviceView(d unifi.PendingDevice) pendingDeviceView {
+	return pendingDeviceView{d, describeMAC(d.MAC)}
+}
+
+func registerMACVendorTools(s *mcp.Server) {
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "lookup_mac_vendor",
+		Description: "Look up the registered vendor for a MAC address (any notation) in the built-in offline OUI table. " +
+			"The table is the IEEE MA-L registry; addresses in MA-M or MA-S blocks report the block's MA-L holder. Also reports whether the address is locally administered or randomized (private WiFi addresses), which have no vendor.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(_ context.Context, _ *mcp.CallToolRequest, input struct {
+		MAC string `json:"mac" jsonschema:"MAC address, e.g. aa:bb:cc:dd:ee:ff, aa-bb-cc-dd-ee-ff, aabb.ccdd.eeff or aabbccddeeff"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		if input.MAC == "" {
+			return errorResult(fmt.Errorf("lookup_mac_vendor: mac is required"))
+		}
+		info, err := oui.Describe(input.MAC)
+		if err != nil {
+			return errorResult(fmt.Errorf("lookup_mac_vendor: %w", err))
+		}
+		return jsonResult(info)
+	})
+}
@@ -3,12 +3,59 @@ package tools
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/gordcurrie/unifi-mcp/internal/unifi"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// dnsRecordInput holds the type-specific DNS policy fields shared by
+// create_dns_policy and update_dns_policy.
+type dnsRecordInput struct {
+	IPv4Address      string `json:"ipv4_address,omitempty"       jsonschema:"A_RECORD: IPv4 address the domain maps to"`
+	IPv6Address      string `json:"ipv6_address,omitempty"       jsonschema:"AAAA_RECORD: IPv6 address the domain maps to"`
+	TargetDomain     string `json:"target_domain,omitempty"      jsonschema:"CNAME_RECORD: canonical domain name"`
+	MailServerDomain string `json:"mail_server_domain,omitempty" jsonschema:"MX_RECORD: mail server domain name"`
+	Text             string `json:"text,omitempty"               jsonschema:"TXT_RECORD: record text (printable ASCII)"`
+	ServerDomain     string `json:"server_domain,omitempty"      jsonschema:"SRV_RECORD: target host domain name"`
+	Service          string `json:"service,omitempty"            jsonschema:"SRV_RECORD: service label, e.g. _sip"`
+	Protocol         string `json:"protocol,omitempty"           jsonschema:"SRV_RECORD: _tcp, _udp or _tls"`
+	Port             *int   `json:"port,omitempty"               jsonschema:"SRV_RECORD: target port"`
+	Priority         *int   `json:"priority,omitempty"           jsonschema:"MX_RECORD and SRV_RECORD: 0-65535, lower is preferred"`
+	Weight           *int   `json:"weight,omitempty"             jsonschema:"SRV_RECORD: 0-65535, for load sharing between equal priorities"`
+	IPAddress        string `json:"ip_address,omitempty"         jsonschema:"FORWARD_DOMAIN: DNS server that queries for the domain are forwarded to"`
+}
+
+// apply copies the fields that are set onto req.
+func (in *dnsRecordInput) apply(req *unifi.DNSPolicyRequest) {
+	for _, f := range []struct {
+		src string
+		dst *string
+	}{
+		{in.IPv4Address, &req.IPv4Address},
+		{in.IPv6Address, &req.IPv6Address},
+		{in.TargetDomain, &req.TargetDomain},
+		{in.MailServerDomain, &req.MailServerDomain},
+		{in.Text, &req.Text},
+		{in.ServerDomain, &req.ServerDomain},
+		{in.Service, &req.Service},
+		{in.Protocol, &req.Protocol},
+		{in.IPAddress, &req.IPAddress},
+	} {
+		if f.src != "" {
+			*f.dst = f.src
+		}
+	}
+	if in.Port != nil {
+		req.Port = *in.Port
+	}
+	if in.Priority != nil {
+		req.Priority = in.Priority
+	}
+	if in.Weight != nil {
+		req.Weight = in.Weight
+	}
+}
+
 func registerNetworkTools(s *mcp.Server, client unifiClient, allowDestructive bool) {
 	// siteInput is used by non-list tools that only need a site ID (no pagination).
 	type siteInput struct {
@@ -99,6 +146,25 @@ func registerNetworkTools(s *mcp.Server, client unifiClient, allowDestructive bo
 		return jsonResult(nets)
 	})
 
+	mcp.AddTool(s, &mcp.Tool{
+		Name:        "get_network",
+		Description: "Get details for a specific network by ID, including its subnet, gateway address, and DHCP range.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID    string `json:"site_id,omitempty" jsonschema:"site ID; omit to use default"`
+		NetworkID string `json:"network_id"        jsonschema:"network ID"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		if input.NetworkID == "" {
+			return errorResult(fmt.Errorf("get_network: network_id is required"))
+		}
+		n, err := client.GetNetwork(ctx, input.SiteID, input.NetworkID)
+		if err != nil {
+			return errorResult(fmt.Errorf("get_network: %w", err))
+		}
+		return jsonResult(n)
+	})
+
 	type firewallPolicyPageInput struct {
 		SiteID   string `json:"site_id,omitempty"  jsonschema:"site ID; omit to use default"`
 		Offset   int    `json:"offset,omitempty"   jsonschema:"pagination offset (0-based); omit or 0 to start from the beginning"`
@@ -232,22 +298,22 @@ func registerNetworkTools(s *mcp.Server, client unifiClient, allowDestructive bo
 		PolicyID string `json:"policy_id"         jsonschema:"DNS policy ID"`
 	}
 	type createDNSPolicyInput struct {
-		SiteID      string `json:"site_id,omitempty"      jsonschema:"site ID; omit to use default"`
-		Type        string `json:"type"                   jsonschema:"policy type, e.g. A_RECORD"`
-		Domain      string `json:"domain"                 jsonschema:"domain name to resolve"`
-		IPv4Address string `json:"ipv4_address,omitempty" jsonschema:"IPv4 address the domain maps to"`
-		TTLSeconds  int    `json:"ttl_seconds"            jsonschema:"TTL in seconds; required by the API (send 0 to use the server default)"`
-		Enabled     *bool  `json:"enabled"                jsonschema:"true to activate the policy, false to create disabled"`
+		SiteID     string `json:"site_id,omitempty" jsonschema:"site ID; omit to use default"`
+		Type       string `json:"type"              jsonschema:"A_RECORD, AAAA_RECORD, CNAME_RECORD, MX_RECORD, TXT_RECORD, SRV_RECORD or FORWARD_DOMAIN"`
+		Domain     string `json:"domain"            jsonschema:"domain name the policy answers for"`
+		TTLSeconds int    `json:"ttl_seconds"       jsonschema:"TTL in seconds; required by the API (send 0 to use the server default)"`
+		Enabled    *bool  `json:"enabled"           jsonschema:"true to activate the policy, false to create disabled"`
+		dnsRecordInput
 	}
 	type updateDNSPolicyInput struct {
-		SiteID      string `json:"site_id,omitempty"      jsonschema:"site ID; omit to use default"`
-		PolicyID    string `json:"policy_id"              jsonschema:"DNS policy ID to update"`
-		Type        string `json:"type"                   jsonschema:"policy type, e.g. A_RECORD"`
-		Domain      string `json:"domain"                 jsonschema:"domain name to resolve"`
-		IPv4Address string `json:"ipv4_address,omitempty" jsonschema:"IPv4 address the domain maps to"`
-		TTLSeconds  int    `json:"ttl_seconds"            jsonschema:"TTL in seconds; required by the API (send 0 to use the server default)"`
-		Enabled     *bool  `json:"enabled"                jsonschema:"true to activate the policy, false to disable"`
-		Confirmed   bool   `json:"confirmed"              jsonschema:"must be true to confirm the change"`
+		SiteID     string `json:"site_id,omitempty"     jsonschema:"site ID; omit to use default"`
+		PolicyID   string `json:"policy_id"             jsonschema:"DNS policy ID to update"`
+		Type       string `json:"type,omitempty"        jsonschema:"new policy type; omit to keep. Changing it drops the old type's fields"`
+		Domain     string `json:"domain,omitempty"      jsonschema:"new domain name; omit to keep"`
+		TTLSeconds *int   `json:"ttl_seconds,omitempty" jsonschema:"new TTL in seconds; omit to keep"`
+		Enabled    *bool  `json:"enabled,omitempty"     jsonschema:"true to activate the policy, false to disable; omit to keep"`
+		dnsRecordInput
+		Confirmed bool `json:"confirmed" jsonschema:"must be true to confirm the change"`
 	}
 	type deleteDNSPolicyInput struct {
 		SiteID    string `json:"site_id,omitempty" jsonschema:"site ID; omit to use default"`
@@ -257,7 +323,7 @@ func registerNetworkTools(s *mcp.Server, client unifiClient, allowDestructive bo
 
 	mcp.AddTool(s, &mcp.Tool{
 		Name:        "list_dns_policies",
-		Description: "List local DNS policies (A-record overrides) for a site. Use offset/limit to paginate.",
+		Description: "List local DNS policies (A, AAAA, CNAME, MX, TXT and SRV records and forwarded domains) for a site. Use offset/limit to paginate.",
 		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
 	}, func(ctx context.Context, _ *mcp.CallToolRequest, input sitePageInput) (*mcp.CallToolResult, any, error) {
 		policies, err := client.ListDNSPolicies(ctx, input.SiteID, input.Offset, input.Limit)
@@ -283,8 +349,10 @@ func registerNetworkTools(s *mcp.Server, client unifiClient, allowDestructive bo
 	})
 
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_dns_policy",
-		Description: "Create a new local DNS A-record policy mapping a domain to an IP address.",
+		Name: "create_dns_policy",
+		Description: "Create a local DNS policy. Set the fields of the chosen type: ipv4_address (A_RECORD), ipv6_address " +
+			"(AAAA_RECORD), target_domain (CNAME_RECORD), mail_server_domain and priority (MX_RECORD), text (TXT_RECORD), " +
+			"server_domain, service, protocol, port, priority and weight (SRV_RECORD), or ip_address (FORWARD_DOMAIN).",
 	}, func(ctx context.Context, _ *mcp.CallToolRequest, input createDNSPolicyInput) (*mcp.CallToolResult, any, error) {
 		if input.Type == "" {
 			return errorResult(fmt.Errorf("create_dns_policy: type is required"))
@@ -296,11 +364,14 @@ func registerNetworkTools(s *mcp.Server, client unifiClient, allowDestructive bo
 			return errorResult(fmt.Errorf("create_dns_policy: enabled is required"))
 		}
 		req := unifi.DNSPolicyRequest{
-			Type:        input.Type,
-			Domain:      input.Domain,
-			IPv4Address: input.IPv4Address,
-			TTLSeconds:  input.TTLSeconds,
-			Enabled:     *input.Enabled,
+			Type:       input.Type,
+			Domain:     input.Domain,
+			TTLSeconds: input.TTLSeconds,
+			Enabled:    *input.Enabled,
+		}
+		input.apply(&req)
+		if err := req.Validate(); err != nil {
+			return errorResult(fmt.Errorf("create_dns_policy: %w", err))
 		}
 		policy, err := client.CreateDNSPolicy(ctx, input.SiteID, req)
 		if err != nil {
@@ -310,8 +381,9 @@ func registerNetworkTools(s *mcp.Server, client unifiClient, allowDestructive bo
 	})
 
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "update_dns_policy",
-		Description: "Update an existing local DNS policy by ID. Set confirmed=true to proceed.",
+		Name: "update_dns_policy",
+		Description: "Update a local DNS policy by ID. Only the given fields change; the rest, including fields this server " +
+			"does not model, are kept. Record fields are as in create_dns_policy. Set confirmed=true to proceed.",
 		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
 	}, func(ctx context.Context, _ *mcp.CallToolRequest, input updateDNSPolicyInput) (*mcp.CallToolResult, any, error) {
 		if !input.Confirmed {
@@ -320,21 +392,26 @@ func registerNetworkTools(s *mcp.Server, client unifiClient, allowDestructive bo
 		if input.PolicyID == "" {
 			return errorResult(fmt.Errorf("update_dns_policy: policy_id is required"))
 		}
-		if input.Type == "" {
-			return errorResult(fmt.Errorf("update_dns_policy: type is required"))
+		current, err := client.GetDNSPolicy(ctx, input.SiteID, input.PolicyID)
+		if err != nil {
+			return errorResult(fmt.Errorf("update_dns_policy: %w", err))
 		}
-		if input.Domain == "" {
-			return errorResult(fmt.Errorf("update_dns_policy: domain is required"))
+		req := current.Request()
+		if input.Type != "" && input.Type != current.Type {
+			req = unifi.DNSPolicyRequest{Type: input.Type, Domain: req.Domain, TTLSeconds: req.TTLSeconds, Enabled: req.Enabled}
 		}
-		if input.Enabled == nil {
-			return errorResult(fmt.Errorf("update_dns_policy: enabled is required"))
+		if input.Domain != "" {
+			req.Domain = input.Domain
 		}
-		req := unifi.DNSPolicyRequest{
-			Type:        input.Type,
-			Domain:      input.Domain,
-			IPv4Address: input.IPv4Address,
-			TTLSeconds:  input.TTLSeconds,
-			Enabled:     *input.Enabled,
+		if input.TTLSeconds != nil {
+			req.TTLSeconds = *input.TTLSeconds
+		}
+		if input.Enabled != nil {
+			req.Enabled = *input.Enabled
+		}
+		input.apply(&req)
+		if err := req.Validate(); err != nil {
+			return errorResult(fmt.Errorf("update_dns_policy: %w", err))
 		}
 		policy, err := client.UpdateDNSPolicy(ctx, input.SiteID, input.PolicyID, req)
 		if err != nil {
@@ -468,20 +545,6 @@ func registerNetworkTools(s *mcp.Server, client unifiClient, allowDestructive bo
 		NetworkIDs *string `json:"network_ids,omitempty" jsonschema:"comma-separated list of network IDs to assign to this zone; omit for no networks"`
 	}
 
-	splitIDs := func(s *string) []string {
-		if s == nil || *s == "" {
-			return []string{}
-		}
-		parts := strings.Split(*s, ",")
-		result := make([]string, 0, len(parts))
-		for _, p := range parts {
-			if trimmed := strings.TrimSpace(p); trimmed != "" {
-				result = append(result, trimmed)
-			}
-		}
-		return result
-	}
-
 	mcp.AddTool(s, &mcp.Tool{
 		Name:        "create_firewall_zone",
 		Description: "Create a new firewall zone.",
@@ -630,11 +693,14 @@ func registerNetworkTools(s *mcp.Server, client unifiClient, allowDestructive bo
 			Action    string `json:"action"             jsonschema:"rule action: ALLOW or BLOCK"`
 			Enabled   *bool  `json:"enabled"            jsonschema:"true to enable the rule, false to disable"`
 			Confirmed bool   `json:"confirmed"          jsonschema:"must be true to confirm the change"`
+			aclRuleFields
 		}
 
 		mcp.AddTool(s, &mcp.Tool{
-			Name:        "create_acl_rule",
-			Description: "Create a new ACL rule. type must be IPV4 or MAC; action must be ALLOW or BLOCK. Requires UNIFI_ALLOW_DESTRUCTIVE=true. Set confirmed=true to proceed.",
+			Name: "create_acl_rule",
+			Description: "Create a new ACL rule. type must be IPV4 or MAC; action must be ALLOW or BLOCK. " +
+				"IPV4 rules match on source/destination IPs, subnets, ports and protocols; MAC rules match on source/destination MACs and may be scoped to one network. " +
+				"Omitted filters match any traffic. Requires UNIFI_ALLOW_DESTRUCTIVE=true. Set confirmed=true to proceed.",
 			Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
 		}, func(ctx context.Context, _ *mcp.CallToolRequest, input aclRuleMutateInput) (*mcp.CallToolResult, any, error) {
 			if !input.Confirmed {
@@ -652,12 +718,16 @@ func registerNetworkTools(s *mcp.Server, client unifiClient, allowDestructive bo
 			if input.Enabled == nil {
 				return errorResult(fmt.Errorf("create_acl_rule: enabled is required"))
 			}
-			rule, err := client.CreateACLRule(ctx, input.SiteID, unifi.ACLRuleRequest{
+			req := unifi.ACLRuleRequest{
 				Type:    input.Type,
 				Name:    input.Name,
 				Action:  input.Action,
 				Enabled: *input.Enabled,
-			})
+			}
+			if err := input.apply(&req); err != nil {
+				return errorResult(fmt.Errorf("create_acl_rule: %w", err))
+			}
+			rule, err := client.CreateACLRule(ctx, input.SiteID, req)
 			if err != nil {
 				return errorResult(fmt.Errorf("create_acl_rule: %w", err))
 			}
@@ -667,16 +737,19 @@ func registerNetworkTools(s *mcp.Server, client unifiClient, allowDestructive bo
 		type updateACLRuleInput struct {
 			SiteID    string `json:"site_id,omitempty" jsonschema:"site ID; omit to use default"`
 			RuleID    string `json:"rule_id"            jsonschema:"ACL rule ID"`
-			Type      string `json:"type"               jsonschema:"rule type: IPV4 or MAC"`
-			Name      string `json:"name"               jsonschema:"rule name"`
-			Action    string `json:"action"             jsonschema:"rule action: ALLOW or BLOCK"`
-			Enabled   *bool  `json:"enabled"            jsonschema:"true to enable the rule, false to disable"`
+			Type      string `json:"type,omitempty"     jsonschema:"rule type: IPV4 or MAC; omit to keep"`
+			Name      string `json:"name,omitempty"     jsonschema:"rule name; omit to keep"`
+			Action    string `json:"action,omitempty"   jsonschema:"rule action: ALLOW or BLOCK; omit to keep"`
+			Enabled   *bool  `json:"enabled,omitempty"  jsonschema:"true to enable the rule, false to disable; omit to keep"`
 			Confirmed bool   `json:"confirmed"          jsonschema:"must be true to confirm the change"`
+			aclRuleFields
 		}
 
 		mcp.AddTool(s, &mcp.Tool{
-			Name:        "update_acl_rule",
-			Description: "Update an existing ACL rule by ID. type must be IPV4 or MAC; action must be ALLOW or BLOCK. Requires UNIFI_ALLOW_DESTRUCTIVE=true. Set confirmed=true to proceed.",
+			Name: "update_acl_rule",
+			Description: "Update an existing ACL rule by ID. Only the fields provided change; the rest, including fields this server does not model, are kept. " +
+				"Pass an empty string to clear a list. type must be IPV4 or MAC; action must be ALLOW or BLOCK. " +
+				"Requires UNIFI_ALLOW_DESTRUCTIVE=true. Set confirmed=true to proceed.",
 			Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
 		}, func(ctx context.Context, _ *mcp.CallToolRequest, input updateACLRuleInput) (*mcp.CallToolResult, any, error) {
 			if !input.Confirmed {
@@ -685,24 +758,27 @@ func registerNetworkTools(s *mcp.Server, client unifiClient, allowDestructive bo
 			if input.RuleID == "" {
 				return errorResult(fmt.Errorf("update_acl_rule: rule_id is required"))
 			}
-			if input.Type == "" {
-				return errorResult(fmt.Errorf("update_acl_rule: type is required (IPV4 or MAC)"))
+			existing, err := client.GetACLRule(ctx, input.SiteID, input.RuleID)
+			if err != nil {
+				return errorResult(fmt.Errorf("update_acl_rule: %w", err))
 			}
-			if input.Name == "" {
-				return errorResult(fmt.Errorf("update_acl_rule: name is required"))
+			req := existing.Request()
+			if input.Type != "" {
+				req.Type = input.Type
 			}
-			if input.Action == "" {
-				return errorResult(fmt.Errorf("update_acl_rule: action is required (ALLOW or BLOCK)"))
+			if input.Name != "" {
+				req.Name = input.Name
 			}
-			if input.Enabled == nil {
-				return errorResult(fmt.Errorf("update_acl_rule: enabled is required"))
+			if input.Action != "" {
+				req.Action = input.Action
 			}
-			rule, err := client.UpdateACLRule(ctx, input.SiteID, input.RuleID, unifi.ACLRuleRequest{
-				Type:    input.Type,
-				Name:    input.Name,
-				Action:  input.Action,
-				Enabled: *input.Enabled,
-			})
+			if input.Enabled != nil {
+				req.Enabled = *input.Enabled
+			}
+			if err := input.apply(&req); err != nil {
+				return errorResult(fmt.Errorf("update_acl_rule: %w", err))
+			}
+			rule, err := client.UpdateACLRule(ctx, input.SiteID, input.RuleID, req)
 			if err != nil {
 				return errorResult(fmt.Errorf("update_acl_rule: %w", err))
 			}
@@ -0,0 +1,367 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gordcurrie/unifi-mcp/internal/ipplan"
+	"github.com/gordcurrie/unifi-mcp/internal/mergepatch"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// patchableType describes one resource type patch_resource can modify.
+type patchableType struct {
+	collection string
+	// fields are the top-level members a patch may add, change or remove.
+	// Everything else, including id and metadata, is read-only.
+	fields []string
+	// destructive types directly control traffic and, like the ACL write
+	// tools, are only patchable with UNIFI_ALLOW_DESTRUCTIVE=true.
+	destructive bool
+	// normalize optionally checks the patched object against the current one
+	// and returns it with any members the change leaves meaningless removed.
+	normalize func(current, patched []byte, allowDestructive bool) ([]byte, error)
+	// validate optionally checks the patched object before it is sent.
+	validate func(ctx context.Context, client unifiClient, siteID string, patched []byte) error
+}
+
+// patchableTypes maps patch_resource's resource_type values to their rules.
+var patchableTypes = map[string]patchableType{
+	"wifi_broadcast": {
+		collection: unifi.CollectionWiFiBroadcasts,
+		fields: []string{
+			"name", "enabled", "hideName", "clientIsolationEnabled", "network", "securityConfiguration",
+			"broadcastingFrequenciesGHz", "bandSteering", "multicastToUnicastConversionEnabled", "uapsdEnabled",
+		},
+		normalize: normalizeWiFiPatch,
+	},
+	"network": {
+		collection:  unifi.CollectionNetworks,
+		fields:      []string{"name", "enabled", "vlanId"},
+		destructive: true,
+		validate:    validateNetworkPatch,
+	},
+	"dns_policy": {
+		collection: unifi.CollectionDNSPolicies,
+		fields: []string{
+			"type", "domain", "ipv4Address", "ipv6Address", "targetDomain", "mailServerDomain", "text",
+			"serverDomain", "service", "protocol", "port", "priority", "weight", "ipAddress", "ttlSeconds", "enabled",
+		},
+		validate: func(_ context.Context, _ unifiClient, _ string, patched []byte) error {
+			var policy unifi.DNSPolicy
+			if err := json.Unmarshal(patched, &policy); err != nil {
+				return err
+			}
+			return policy.Request().Validate()
+		},
+	},
+	"firewall_zone": {
+		collection: unifi.CollectionFirewallZones,
+		fields:     []string{"name", "networkIds"},
+	},
+	"firewall_policy": {
+		collection: unifi.CollectionFirewallPolicies,
+		fields: []string{
+			"name", "description", "enabled", "action", "source", "destination",
+			"ipProtocolScope", "connectionStateFilter", "ipsecFilter", "loggingEnabled", "schedule",
+		},
+		destructive: true,
+	},
+	"acl_rule": {
+		collection: unifi.CollectionACLRules,
+		fields: []string{
+			"name", "description", "enabled", "action", "enforcingDeviceFilter",
+			"sourceFilter", "destinationFilter", "protocolFilter", "networkIdFilter",
+		},
+		destructive: true,
+		validate: func(_ context.Context, _ unifiClient, _ string, patched []byte) error {
+			var rule unifi.ACLRule
+			if err := json.Unmarshal(patched, &rule); err != nil {
+				return err
+			}
+			return rule.Request().Validate()
+		},
+	},
+}
+
+// validateNetworkPatch applies update_network's rules to a patched network:
+// the default (management) network is refused, and the result must fit the
+// site's IP plan.
+func validateNetworkPatch(ctx context.Context, client unifiClient, siteID string, patched []byte) error {
+	var n unifi.NetworkConf
+	if err := json.Unmarshal(patched, &n); err != nil {
+		return err
+	}
+	if n.Default {
+		return fmt.Errorf("%s is the default (management) network and cannot be changed here", n.Name)
+	}
+	candidate, err := planNetwork(&n)
+	if err != nil {
+		return err
+	}
+	plan, _, err := loadIPPlan(ctx, client, siteID)
+	if err != nil {
+		return err
+	}
+	if err := ipplan.Check(candidate, plan); err != nil {
+		return errors.New(indentErrors(err))
+	}
+	return nil
+}
+
+// normalizeWiFiPatch applies update_wifi_broadcast's security rules to a
+// patched broadcast. When the broadcast ends up OPEN it also drops every key
+// member of securityConfiguration, since merging {"type":"OPEN"} alone would
+// keep the old passphrase.
+func normalizeWiFiPatch(current, patched []byte, allowDestructive bool) ([]byte, error) {
+	var before, after struct {
+		Security map[string]json.RawMessage `json:"securityConfiguration"`
+	}
+	if err := json.Unmarshal(current, &before); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patched, &after); err != nil {
+		return nil, err
+	}
+	str := func(sec map[string]json.RawMessage, key string) string {
+		var s string
+		_ = json.Unmarshal(sec[key], &s)
+		return s
+	}
+	change := wifiSecurityChange{
+		fromType: str(before.Security, "type"), fromPMF: str(before.Security, "pmfMode"),
+		toType: str(after.Security, "type"), toPMF: str(after.Security, "pmfMode"),
+	}
+	for key := range after.Security {
+		if isSecretPath(key) && str(after.Security, key) != "" && str(after.Security, key) != str(before.Security, key) {
+			change.newPassphrase = true
+		}
+	}
+	if err := change.check(allowDestructive); err != nil {
+		return nil, err
+	}
+	if change.toType != "OPEN" {
+		return patched, nil
+	}
+
+	secrets := 0
+	for key := range after.Security {
+		if isSecretPath(key) {
+			delete(after.Security, key)
+			secrets++
+		}
+	}
+	if secrets == 0 {
+		return patched, nil
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(patched, &doc); err != nil {
+		return nil, err
+	}
+	sec, err := json.Marshal(after.Security)
+	if err != nil {
+		return nil, err
+	}
+	doc["securityConfiguration"] = sec
+	return json.Marshal(doc)
+}
+
+// secretKeys are substrings of member names whose values patch_resource never
+// echoes back, in diffs or in the resulting object.
+var secretKeys = []string{"passphrase", "password", "secret", "psk"}
+
+// patchResult is patch_resource's response body.
+type patchResult struct {
+	ResourceType string              `json:"resourceType"`
+	ResourceID   string              `json:"resourceId"`
+	Applied      bool                `json:"applied"`
+	Changes      []mergepatch.Change `json:"changes"`
+	Resource     json.RawMessage     `json:"resource"`
+}
+
+// patchResource implements patch_resource: it applies patch to the current
+// resource, normalizes it, checks the changed fields against the type's
+// allowlist, validates the result, and writes it when confirmed.
+func patchResource(ctx context.Context, client unifiClient, allowDestructive bool, siteID, resourceType, resourceID string, patch []byte, confirmed bool) (patchResult, error) {
+	typ, ok := patchableTypes[resourceType]
+	if !ok || (typ.destructive && !allowDestructive) {
+		return patchResult{}, fmt.Errorf("resource_type must be one of %s", strings.Join(patchableTypeNames(allowDestructive), ", "))
+	}
+	if resourceID == "" {
+		return patchResult{}, errors.New("resource_id is required")
+	}
+	patch = []byte(strings.TrimSpace(string(patch)))
+	if len(patch) == 0 || patch[0] != '{' || !json.Valid(patch) {
+		return patchResult{}, errors.New("patch must be a JSON object")
+	}
+
+	current, err := client.GetRaw(ctx, siteID, typ.collection, resourceID)
+	if err != nil {
+		return patchResult{}, err
+	}
+	patched, err := mergepatch.Apply(current, patch)
+	if err != nil {
+		return patchResult{}, err
+	}
+	if typ.normalize != nil {
+		if patched, err = typ.normalize(current, patched, allowDestructive); err != nil {
+			return patchResult{}, fmt.Errorf("patched %s is invalid: %w", resourceType, err)
+		}
+	}
+	changes, err := mergepatch.Diff(current, patched)
+	if err != nil {
+		return patchResult{}, err
+	}
+	var denied []string
+	for _, c := range changes {
+		if top := mergepatch.TopLevel(c.Path); !slices.Contains(typ.fields, top) && !slices.Contains(denied, top) {
+			denied = append(denied, top)
+		}
+	}
+	if len(denied) > 0 {
+		return patchResult{}, fmt.Errorf("%s may not change %s; patchable fields: %s",
+			resourceType, strings.Join(denied, ", "), strings.Join(typ.fields, ", "))
+	}
+	if typ.validate != nil {
+		if err := typ.validate(ctx, client, siteID, patched); err != nil {
+			return patchResult{}, fmt.Errorf("patched %s is invalid: %w", resourceType, err)
+		}
+	}
+
+	for i := range changes {
+		if isSecretPath(changes[i].Path) {
+			changes[i].Old = redactRaw(changes[i].Old)
+			changes[i].New = redactRaw(changes[i].New)
+		} else {
+			changes[i].Old = redactSecrets(changes[i].Old)
+			changes[i].New = redactSecrets(changes[i].New)
+		}
+	}
+	result := patchResult{ResourceType: resourceType, ResourceID: resourceID, Changes: changes}
+	if result.Changes == nil {
+		result.Changes = []mergepatch.Change{}
+	}
+	if !confirmed || len(changes) == 0 {
+		result.Resource = redactSecrets(patched)
+		return result, nil
+	}
+	updated, err := client.PutRaw(ctx, siteID, typ.collection, resourceID, patched)
+	if err != nil {
+		return patchResult{}, err
+	}
+	result.Applied = true
+	result.Resource = redactSecrets(updated)
+	return result, nil
+}
+
+// patchableTypeNames returns the resource types patch_resource accepts, sorted.
+func patchableTypeNames(allowDestructive bool) []string {
+	var names []string
+	for name, t := range patchableTypes {
+		if !t.destructive || allowDestructive {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func registerPatchTools(s *mcp.Server, client unifiClient, allowDestructive bool) {
+	typeNames := patchableTypeNames(allowDestructive)
+	destructiveTrue := true
+
+	gating := " A wifi_broadcast's security is checked the way update_wifi_broadcast checks it"
+	if !allowDestructive {
+		gating += "; making a secured SSID OPEN requires UNIFI_ALLOW_DESTRUCTIVE=true"
+	}
+	gating += "."
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "patch_resource",
+		Description: "Change any patchable field of a resource with a JSON Merge Patch (RFC 7396): members in the patch replace the " +
+			"current ones, nested objects merge, and null removes a member. Fields this server does not model are kept as-is. " +
+			"Only an allowlist of fields per type may change; id and metadata never can." + gating + " " +
+			"Without confirmed=true the tool only returns the diff it would apply. Resource types: " + strings.Join(typeNames, ", ") + ".",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID       string `json:"site_id,omitempty" jsonschema:"site ID; omit to use default"`
+		ResourceType string `json:"resource_type"     jsonschema:"wifi_broadcast, network, dns_policy, firewall_zone, firewall_policy or acl_rule"`
+		ResourceID   string `json:"resource_id"       jsonschema:"ID of the resource to patch"`
+		Patch        string `json:"patch"             jsonschema:"JSON merge patch object, e.g. {\"hideName\":true,\"securityConfiguration\":{\"pmfMode\":\"REQUIRED\"}}"`
+		Confirmed    bool   `json:"confirmed"         jsonschema:"true to apply the change; false or omitted returns a preview diff only"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		result, err := patchResource(ctx, client, allowDestructive, input.SiteID, input.ResourceType, input.ResourceID, []byte(input.Patch), input.Confirmed)
+		if err != nil {
+			return errorResult(fmt.Errorf("patch_resource: %w", err))
+		}
+		return jsonResult(result)
+	})
+}
+
+// isSecretPath reports whether any segment of a JSON Pointer names a secret.
+func isSecretPath(path string) bool {
+	for _, seg := range strings.Split(strings.ToLower(path), "/") {
+		for _, k := range secretKeys {
+			if strings.Contains(seg, k) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// redactRaw replaces a present value with the marker SensitiveString encodes to.
+func redactRaw(v json.RawMessage) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	marker, _ := json.Marshal(unifi.SensitiveString(""))
+	return marker
+}
+
+// redactSecrets returns doc with the value of every secret-named member, at
+// any depth, replaced by the redaction marker. Non-object documents and
+// arrays of objects are handled recursively; scalars pass through.
+func redactSecrets(doc json.RawMessage) json.RawMessage {
+	trimmed := strings.TrimSpace(string(doc))
+	switch {
+	case strings.HasPrefix(trimmed, "{"):
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(doc, &obj); err != nil {
+			return doc
+		}
+		for k, v := range obj {
+			if isSecretPath(k) {
+				obj[k] = redactRaw(v)
+			} else {
+				obj[k] = redactSecrets(v)
+			}
+		}
+		out, err := json.Marshal(obj)
+		if err != nil {
+			return doc
+		}
+		return out
+	case strings.HasPrefix(trimmed, "["):
+		var arr []json.RawMessage
+		if err := json.Unmarshal(doc, &arr); err != nil {
+			return doc
+		}
+		for i := range arr {
+			arr[i] = redactSecrets(arr[i])
+		}
+		out, err := json.Marshal(arr)
+		if err != nil {
+			return doc
+		}
+		return out
+	default:
+		return doc
+	}
+}
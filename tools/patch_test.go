@@ -0,0 +1,200 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+func TestPatchResource(t *testing.T) {
+	const (
+		wlan = `{"id":"w-1","name":"Home","enabled":true,"hideName":false,"network":{"type":"NATIVE"},` +
+			`"securityConfiguration":{"type":"WPA2_PERSONAL","passphrase":"hunter22"},"metadata":{"origin":"USER_DEFINED"}}`
+		zone = `{"id":"z-1","name":"Internal","networkIds":["n-1"]}`
+		net  = `{"id":"n-2","name":"IoT","enabled":true,"vlanId":20,"management":"GATEWAY",` +
+			`"ipv4Configuration":{"hostIpAddress":"10.0.20.1","prefixLength":24}}`
+		mgmt = `{"id":"n-1","name":"Default","enabled":true,"default":true,"management":"GATEWAY",` +
+			`"ipv4Configuration":{"hostIpAddress":"192.168.1.1","prefixLength":24}}`
+	)
+	networks := []unifi.NetworkConf{
+		{ID: "n-1", Name: "Default", Default: true, Management: "GATEWAY",
+			IPv4Configuration: &unifi.NetworkIPv4Configuration{HostIPAddress: "192.168.1.1", PrefixLength: 24}},
+		{ID: "n-2", Name: "IoT", VLANID: 20, Management: "GATEWAY",
+			IPv4Configuration: &unifi.NetworkIPv4Configuration{HostIPAddress: "10.0.20.1", PrefixLength: 24}},
+		{ID: "n-3", Name: "Guest", VLANID: 30, Management: "GATEWAY",
+			IPv4Configuration: &unifi.NetworkIPv4Configuration{HostIPAddress: "10.0.30.1", PrefixLength: 24}},
+	}
+
+	tests := []struct {
+		name             string
+		resourceType     string
+		resourceID       string
+		patch            string
+		allowDestructive bool
+		confirmed        bool
+		wantErr          string
+		wantPaths        []string
+		wantPut          bool
+	}{
+		{
+			name: "previews an allowed change", resourceType: "wifi_broadcast", resourceID: "w-1",
+			patch: `{"hideName":true}`, wantPaths: []string{"/hideName"},
+		},
+		{
+			name: "applies when confirmed", resourceType: "wifi_broadcast", resourceID: "w-1",
+			patch: `{"hideName":true,"name":"Home 2"}`, confirmed: true,
+			wantPaths: []string{"/hideName", "/name"}, wantPut: true,
+		},
+		{
+			name: "an unchanged patch is not sent", resourceType: "wifi_broadcast", resourceID: "w-1",
+			patch: `{"hideName":false}`, confirmed: true, wantPaths: []string{},
+		},
+		{
+			name: "refuses fields outside the allowlist", resourceType: "wifi_broadcast", resourceID: "w-1",
+			patch: `{"id":"w-2","metadata":null}`, allowDestructive: true, wantErr: "may not change id, metadata",
+		},
+		{
+			name: "gates a downgrade to OPEN", resourceType: "wifi_broadcast", resourceID: "w-1",
+			patch: `{"securityConfiguration":{"type":"OPEN"}}`, wantErr: "requires UNIFI_ALLOW_DESTRUCTIVE=true",
+		},
+		{
+			name: "a downgrade to OPEN drops the passphrase", resourceType: "wifi_broadcast", resourceID: "w-1",
+			patch: `{"securityConfiguration":{"type":"OPEN"}}`, allowDestructive: true, confirmed: true,
+			wantPaths: []string{"/securityConfiguration/passphrase", "/securityConfiguration/type"}, wantPut: true,
+		},
+		{
+			name: "an OPEN network takes no passphrase", resourceType: "wifi_broadcast", resourceID: "w-1",
+			patch: `{"securityConfiguration":{"type":"OPEN","passphrase":"letmein99"}}`, allowDestructive: true,
+			wantErr: "takes no passphrase",
+		},
+		{
+			name: "WPA3 needs PMF required", resourceType: "wifi_broadcast", resourceID: "w-1",
+			patch: `{"securityConfiguration":{"type":"WPA3_PERSONAL","pmfMode":"OPTIONAL"}}`, wantErr: "requires PMF mode REQUIRED",
+		},
+		{
+			name: "changes security within the rules", resourceType: "wifi_broadcast", resourceID: "w-1",
+			patch:     `{"securityConfiguration":{"type":"WPA3_PERSONAL","pmfMode":"REQUIRED"}}`,
+			wantPaths: []string{"/securityConfiguration/pmfMode", "/securityConfiguration/type"},
+		},
+		{
+			name: "wifi network is not gated", resourceType: "wifi_broadcast", resourceID: "w-1",
+			patch:     `{"network":{"type":"SPECIFIC","networkId":"n-3"}}`,
+			wantPaths: []string{"/network/networkId", "/network/type"},
+		},
+		{
+			name: "zone membership is not gated", resourceType: "firewall_zone", resourceID: "z-1",
+			patch: `{"networkIds":["n-1","n-3"]}`, wantPaths: []string{"/networkIds"},
+		},
+		{
+			name: "network needs destructive", resourceType: "network", resourceID: "n-2",
+			patch: `{"name":"Things"}`, wantErr: "resource_type must be one of",
+		},
+		{
+			name: "network checks the IP plan", resourceType: "network", resourceID: "n-2",
+			patch: `{"vlanId":30}`, allowDestructive: true, wantErr: "VLAN ID 30 is already used",
+		},
+		{
+			name: "network refuses the default network", resourceType: "network", resourceID: "n-1",
+			patch: `{"name":"LAN"}`, allowDestructive: true, wantErr: "default (management) network",
+		},
+		{
+			name: "network accepts a valid change", resourceType: "network", resourceID: "n-2",
+			patch: `{"vlanId":40}`, allowDestructive: true, confirmed: true,
+			wantPaths: []string{"/vlanId"}, wantPut: true,
+		},
+		{
+			name: "unknown type", resourceType: "site", resourceID: "s-1",
+			patch: `{}`, wantErr: "resource_type must be one of",
+		},
+		{
+			name: "patch must be an object", resourceType: "wifi_broadcast", resourceID: "w-1",
+			patch: `[1]`, wantErr: "patch must be a JSON object",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := &fakeClient{
+				networks: networks,
+				raw: map[string]json.RawMessage{
+					unifi.CollectionWiFiBroadcasts + "/w-1": json.RawMessage(wlan),
+					unifi.CollectionFirewallZones + "/z-1":  json.RawMessage(zone),
+					unifi.CollectionNetworks + "/n-1":       json.RawMessage(mgmt),
+					unifi.CollectionNetworks + "/n-2":       json.RawMessage(net),
+				},
+			}
+			got, err := patchResource(context.Background(), fake, tc.allowDestructive, "",
+				tc.resourceType, tc.resourceID, []byte(tc.patch), tc.confirmed)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("got %v, want error containing %q", err, tc.wantErr)
+				}
+				if fake.puts != 0 {
+					t.Errorf("a refused patch was sent")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			paths := make([]string, 0, len(got.Changes))
+			for _, c := range got.Changes {
+				paths = append(paths, c.Path)
+			}
+			if strings.Join(paths, ",") != strings.Join(tc.wantPaths, ",") {
+				t.Errorf("changed %v, want %v", paths, tc.wantPaths)
+			}
+			if got.Applied != tc.wantPut || (fake.puts == 1) != tc.wantPut {
+				t.Errorf("applied=%v with %d puts, want %v", got.Applied, fake.puts, tc.wantPut)
+			}
+			if strings.Contains(string(got.Resource), "hunter22") {
+				t.Errorf("passphrase leaked: %s", got.Resource)
+			}
+		})
+	}
+}
+
+func TestPatchResourceMerge(t *testing.T) {
+	fake := &fakeClient{raw: map[string]json.RawMessage{
+		unifi.CollectionWiFiBroadcasts + "/w-1": json.RawMessage(
+			`{"id":"w-1","name":"Home","bandSteering":{"mode":"PREFER_5G","enabled":true},"unknownField":{"kept":1}}`),
+	}}
+	_, err := patchResource(context.Background(), fake, false, "", "wifi_broadcast", "w-1",
+		[]byte(`{"bandSteering":{"mode":"OFF"},"name":"Cabin"}`), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sent map[string]any
+	if err := json.Unmarshal(fake.raw[unifi.CollectionWiFiBroadcasts+"/w-1"], &sent); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"id":           "w-1",
+		"name":         "Cabin",
+		"bandSteering": map[string]any{"mode": "OFF", "enabled": true},
+		"unknownField": map[string]any{"kept": float64(1)},
+	}
+	gotJSON, _ := json.Marshal(sent)
+	wantJSON, _ := json.Marshal(want)
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("sent %s, want %s", gotJSON, wantJSON)
+	}
+}
+
+func TestPatchResourceOpenDropsSecrets(t *testing.T) {
+	fake := &fakeClient{raw: map[string]json.RawMessage{
+		unifi.CollectionWiFiBroadcasts + "/w-1": json.RawMessage(
+			`{"id":"w-1","securityConfiguration":{"type":"WPA2_PERSONAL","passphrase":"hunter22","pmfMode":"OPTIONAL"}}`),
+	}}
+	_, err := patchResource(context.Background(), fake, true, "", "wifi_broadcast", "w-1",
+		[]byte(`{"securityConfiguration":{"type":"OPEN"}}`), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(fake.raw[unifi.CollectionWiFiBroadcasts+"/w-1"]),
+		`{"id":"w-1","securityConfiguration":{"pmfMode":"OPTIONAL","type":"OPEN"}}`; got != want {
+		t.Errorf("sent %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,222 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/quarantine"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// registerQuarantineTools registers list_quarantined_clients and, when
+// allowDestructive is set, quarantine_client and release_client. Like the other
+// ACL writes, quarantine directly controls traffic, so the opt-in flag is the
+// primary guard and confirmed=true the secondary one.
+func registerQuarantineTools(s *mcp.Server, client unifiClient, res *resolver, store *quarantine.Store, allowDestructive bool) {
+	mcp.AddTool(s, &mcp.Tool{
+		Name:        "list_quarantined_clients",
+		Description: "List clients currently quarantined by quarantine_client, with the blocking ACL rule ID, reason, and the ACL ordering that release_client will restore.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(_ context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+		return jsonResult(store.List())
+	})
+
+	if !allowDestructive {
+		return
+	}
+	destructiveTrue := true
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "quarantine_client",
+		Description: "Block a client at layer 2: creates an enabled MAC-type BLOCK ACL rule named \"" + quarantine.RulePrefix + "<client>\" " +
+			"for the client's MAC and moves it to the top of the ACL ordering. The previous ordering is saved locally so release_client " +
+			"can restore it. Identify the client by client_id or client selector (name, MAC, or IP). " +
+			"Requires UNIFI_ALLOW_DESTRUCTIVE=true. Set confirmed=true to proceed.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID    string `json:"site_id,omitempty"   jsonschema:"site ID; omit to use default"`
+		ClientID  string `json:"client_id,omitempty" jsonschema:"client ID; provide this or client"`
+		Client    string `json:"client,omitempty"    jsonschema:"client selector: ID, exact name, MAC in any notation, or IP"`
+		Reason    string `json:"reason,omitempty"    jsonschema:"why the client is being quarantined; stored as the rule description"`
+		Confirmed bool   `json:"confirmed"           jsonschema:"must be true to confirm the change"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		if !input.Confirmed {
+			return errorResult(fmt.Errorf("quarantine_client: set confirmed=true to confirm the change"))
+		}
+		var c unifi.NetworkClient
+		var err error
+		switch {
+		case input.ClientID != "":
+			c, err = client.GetClient(ctx, input.SiteID, input.ClientID)
+		case input.Client != "":
+			c, err = res.resolveClient(ctx, input.SiteID, input.Client, true)
+		default:
+			return errorResult(fmt.Errorf("quarantine_client: client_id or client is required"))
+		}
+		if err != nil {
+			return errorResult(fmt.Errorf("quarantine_client: %w", err))
+		}
+		mac, ok := normalizeMAC(c.MAC)
+		if !ok {
+			return errorResult(fmt.Errorf("quarantine_client: client %s has no valid MAC address (%q)", c.ID, c.MAC))
+		}
+		siteID := client.SiteID(input.SiteID)
+		if existing, err := store.Get(siteID, mac); err == nil {
+			return errorResult(fmt.Errorf("quarantine_client: %s is already quarantined by rule %s", mac, existing.RuleID))
+		}
+
+		ordering, err := client.GetACLRuleOrdering(ctx, input.SiteID)
+		if err != nil {
+			return errorResult(fmt.Errorf("quarantine_client: %w", err))
+		}
+		original := ordering.OrderedACLRuleIDs
+
+		label := c.Name
+		if label == "" {
+			label = mac
+		}
+		rule, err := client.CreateACLRule(ctx, input.SiteID, unifi.ACLRuleRequest{
+			Type:         unifi.ACLRuleTypeMAC,
+			Name:         quarantine.RulePrefix + label,
+			Description:  input.Reason,
+			Action:       "BLOCK",
+			Enabled:      true,
+			SourceFilter: &unifi.ACLRuleFilter{Type: unifi.ACLFilterMACAddresses, MACAddresses: []string{mac}},
+		})
+		if err != nil {
+			return errorResult(fmt.Errorf("quarantine_client: create rule: %w", err))
+		}
+
+		top := append([]string{rule.ID}, slices.DeleteFunc(slices.Clone(original), func(id string) bool { return id == rule.ID })...)
+		if _, err := client.ReorderACLRules(ctx, input.SiteID, top); err != nil {
+			return errorResult(fmt.Errorf("quarantine_client: move rule %s to top: %w%s",
+				rule.ID, err, rollbackQuarantine(ctx, client, input.SiteID, rule.ID, nil)))
+		}
+
+		rec, err := store.Add(quarantine.Record{
+			MAC:              mac,
+			SiteID:           siteID,
+			ClientID:         c.ID,
+			ClientName:       c.Name,
+			RuleID:           rule.ID,
+			Reason:           input.Reason,
+			OriginalOrdering: original,
+			QuarantinedAt:    time.Now().UTC(),
+		})
+		if err != nil {
+			return errorResult(fmt.Errorf("quarantine_client: record quarantine: %w%s",
+				err, rollbackQuarantine(ctx, client, input.SiteID, rule.ID, original)))
+		}
+		return jsonResult(struct {
+			Quarantine quarantine.Record `json:"quarantine"`
+			Rule       unifi.ACLRule     `json:"rule"`
+		}{rec, rule})
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "release_client",
+		Description: "Release a client quarantined by quarantine_client: deletes its blocking ACL rule and restores the ACL ordering saved " +
+			"at quarantine time exactly. If ACL rules were created during the quarantine, nothing is changed until new_rules says whether " +
+			"they go first or last. Identify the client by MAC, name, or client ID " +
+			"as recorded at quarantine time; it need not be connected. Requires UNIFI_ALLOW_DESTRUCTIVE=true. Set confirmed=true to proceed.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID    string `json:"site_id,omitempty" jsonschema:"only release a client quarantined on this site; omit to match on every site"`
+		Client    string `json:"client"            jsonschema:"quarantined client: MAC in any notation, name, or client ID"`
+		NewRules  string `json:"new_rules,omitempty" jsonschema:"where ACL rules created during the quarantine go: first or last; needed only when there are any"`
+		Confirmed bool   `json:"confirmed"         jsonschema:"must be true to confirm the change"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		if !input.Confirmed {
+			return errorResult(fmt.Errorf("release_client: set confirmed=true to confirm the change"))
+		}
+		records := store.List()
+		if input.SiteID != "" {
+			site := client.SiteID(input.SiteID)
+			records = slices.DeleteFunc(records, func(r quarantine.Record) bool { return client.SiteID(r.SiteID) != site })
+		}
+		candidates := make([]selectorCandidate, len(records))
+		for i, r := range records {
+			candidates[i] = selectorCandidate{ID: r.ClientID, Name: r.ClientName, MAC: r.MAC}
+		}
+		idx, err := matchSelector("quarantined client", input.Client, candidates, true)
+		var ambiguous *ambiguousError
+		if errors.As(err, &ambiguous) && input.SiteID == "" {
+			return errorResult(fmt.Errorf("release_client: %w, or give site_id if the client is quarantined on several sites", err))
+		}
+		if err != nil {
+			return errorResult(fmt.Errorf("release_client: %w", err))
+		}
+		rec := records[idx]
+		siteID := client.SiteID(rec.SiteID)
+
+		place := quarantine.Placement(strings.ToLower(strings.TrimSpace(input.NewRules)))
+		restoreFrom := func() ([]string, []string, error) {
+			ordering, err := client.GetACLRuleOrdering(ctx, siteID)
+			if err != nil {
+				return nil, nil, err
+			}
+			current := slices.DeleteFunc(slices.Clone(ordering.OrderedACLRuleIDs), func(id string) bool { return id == rec.RuleID })
+			restored, err := quarantine.RestoreOrdering(rec.OriginalOrdering, current, place)
+			return ordering.OrderedACLRuleIDs, restored, err
+		}
+		// Check before deleting anything that the ordering can be restored
+		// without guessing where new rules go.
+		if _, _, err := restoreFrom(); err != nil {
+			return errorResult(fmt.Errorf("release_client: nothing changed: %w", err))
+		}
+
+		ruleDeleted := true
+		if err := client.DeleteACLRule(ctx, siteID, rec.RuleID); err != nil {
+			var apiErr *unifi.APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+				return errorResult(fmt.Errorf("release_client: delete rule %s: %w", rec.RuleID, err))
+			}
+			ruleDeleted = false // already removed by hand; still restore the ordering
+		}
+
+		current, restored, err := restoreFrom()
+		if err != nil {
+			return errorResult(fmt.Errorf("release_client: rule deleted but ordering not restored: %w", err))
+		}
+		if !slices.Equal(restored, current) {
+			if _, err := client.ReorderACLRules(ctx, siteID, restored); err != nil {
+				return errorResult(fmt.Errorf("release_client: rule deleted but ordering not restored: %w", err))
+			}
+		}
+
+		if err := store.Remove(rec.SiteID, rec.MAC); err != nil {
+			return errorResult(fmt.Errorf("release_client: %w", err))
+		}
+		return jsonResult(struct {
+			Released    quarantine.Record `json:"released"`
+			RuleDeleted bool              `json:"ruleDeleted"`
+			Ordering    []string          `json:"ordering"`
+		}{rec, ruleDeleted, restored})
+	})
+}
+
+// rollbackQuarantine undoes a partially applied quarantine: it deletes ruleID
+// and then, when original is non-nil, reapplies that ordering. It returns a suffix for the
+// caller's error message describing anything that could not be undone.
+func rollbackQuarantine(ctx context.Context, client unifiClient, siteID, ruleID string, original []string) string {
+	var failed []string
+	if err := client.DeleteACLRule(ctx, siteID, ruleID); err != nil {
+		failed = append(failed, fmt.Sprintf("delete rule %s: %v", ruleID, err))
+	} else if original != nil {
+		if _, err := client.ReorderACLRules(ctx, siteID, original); err != nil {
+			failed = append(failed, fmt.Sprintf("restore ordering: %v", err))
+		}
+	}
+	if len(failed) == 0 {
+		return " (rolled back)"
+	}
+	return " (rollback failed: " + strings.Join(failed, "; ") + ")"
+}
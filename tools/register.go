@@ -2,16 +2,82 @@
 package tools
 
 import (
+	"github.com/gordcurrie/unifi-mcp/internal/adoption"
+	"github.com/gordcurrie/unifi-mcp/internal/dnssync"
+	"github.com/gordcurrie/unifi-mcp/internal/inventory"
+	"github.com/gordcurrie/unifi-mcp/internal/quarantine"
+	"github.com/gordcurrie/unifi-mcp/internal/schedule"
+	"github.com/gordcurrie/unifi-mcp/internal/toggles"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// Config controls which optional tool groups RegisterAll enables and supplies
+// the local state they need.
+type Config struct {
+	// AllowDestructive also registers tools that permanently delete resources
+	// or directly control traffic (UNIFI_ALLOW_DESTRUCTIVE=true).
+	AllowDestructive bool
+	// Inventory is the known-clients store. Nil disables the inventory tools.
+	Inventory *inventory.Store
+	// Quarantine records quarantined clients and their saved ACL ordering.
+	// Nil disables the quarantine tools.
+	Quarantine *quarantine.Store
+	// Schedule holds the scheduled guest authorizations and voucher batches.
+	// Nil disables the scheduling tools.
+	Schedule *schedule.Store
+	// Toggles holds the recurring WiFi broadcast and firewall policy toggles.
+	// Nil disables the toggle schedule tools.
+	Toggles *toggles.Store
+	// BlocklistDir is the only directory import_blocklist reads feed files
+	// from. Empty disables the tool.
+	BlocklistDir string
+	// DNSSync is the ownership registry of records made by sync_client_dns,
+	// and DNSSyncDomain the suffix client names go under. The tool is only
+	// registered when both are set.
+	DNSSync       *dnssync.Store
+	DNSSyncDomain string
+	// AdoptPolicy is the allowlist of pending devices that may be adopted
+	// without asking. auto_adopt_devices is only registered when it is set.
+	AdoptPolicy adoption.Policy
+}
+
 // RegisterAll registers every enabled tool group with the MCP server.
 // client must implement the unifiClient interface; *unifi.Client satisfies
-// it automatically. Set allowDestructive to true (via UNIFI_ALLOW_DESTRUCTIVE=true)
-// to also register tools that permanently delete resources.
-func RegisterAll(s *mcp.Server, client unifiClient, allowDestructive bool) {
+// it automatically.
+func RegisterAll(s *mcp.Server, client unifiClient, cfg Config) {
+	res := newResolver(client)
 	registerSiteTools(s, client)
-	registerDeviceTools(s, client)
-	registerClientTools(s, client)
-	registerNetworkTools(s, client, allowDestructive)
+	registerDeviceTools(s, client, res)
+	registerRollingRestartTools(s, client, res)
+	registerFirmwareTools(s, client, res)
+	registerAdoptionTools(s, client, cfg.AdoptPolicy)
+	registerClientTools(s, client, res)
+	registerNetworkTools(s, client, cfg.AllowDestructive)
+	registerWiFiTools(s, client, cfg.AllowDestructive)
+	registerVLANTools(s, client, cfg.AllowDestructive)
+	registerTrafficMatchingListTools(s, client, cfg.AllowDestructive)
+	registerDNSRecordTools(s, client, cfg.AllowDestructive)
+	registerDNSLintTools(s, client)
+	registerVoucherTools(s, client, cfg.AllowDestructive)
+	registerPatchTools(s, client, cfg.AllowDestructive)
+	registerSearchTools(s, client)
+	registerMACVendorTools(s)
+	if cfg.Inventory != nil {
+		registerInventoryTools(s, client, res, cfg.Inventory)
+	}
+	if cfg.Quarantine != nil {
+		registerQuarantineTools(s, client, res, cfg.Quarantine, cfg.AllowDestructive)
+	}
+	if cfg.Schedule != nil {
+		registerScheduleTools(s, client, res, cfg.Schedule)
+	}
+	if cfg.Toggles != nil {
+		registerToggleTools(s, client, cfg.Toggles)
+	}
+	if cfg.BlocklistDir != "" {
+		registerBlocklistTools(s, client, cfg.BlocklistDir)
+	}
+	if cfg.DNSSync != nil && cfg.DNSSyncDomain != "" {
+		registerDNSSyncTools(s, client, cfg.DNSSync, cfg.DNSSyncDomain)
+	}
 }
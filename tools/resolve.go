@@ -0,0 +1,290 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/oui"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+// resolverCacheTTL is how long a fetched device or client list is reused before
+// the resolver goes back to the controller. Short enough that a client that just
+// joined is found within a few tool calls, long enough that a burst of selector
+// lookups in one agent turn costs a single list round-trip.
+const resolverCacheTTL = 30 * time.Second
+
+// errSelectorRequired is returned when neither an explicit ID nor a selector is provided.
+var errSelectorRequired = errors.New("an ID or selector is required")
+
+// errNoMatch is returned when a selector matches no object.
+var errNoMatch = errors.New("no match")
+
+// errInexact is returned when an exact match is required but the selector only
+// matches by partial name. Destructive tools refuse such matches so that a
+// typo cannot restart or block the wrong object.
+var errInexact = errors.New("matches only by partial name; use the exact ID, name, MAC or IP")
+
+// ambiguousError is returned when a selector matches more than one object at the
+// same precedence level. The candidate list lets the caller retry with an ID.
+type ambiguousError struct {
+	kind       string
+	selector   string
+	candidates []string
+}
+
+// Error implements the error interface.
+func (e *ambiguousError) Error() string {
+	return fmt.Sprintf("%s selector %q is ambiguous; matches %d candidates: %s — retry with one of these IDs",
+		e.kind, e.selector, len(e.candidates), strings.Join(e.candidates, "; "))
+}
+
+// resolver maps human-friendly selectors (ID, name, MAC in any notation, or IP)
+// onto devices and clients. List results are cached per site for resolverCacheTTL,
+// except for exact lookups: destructive tools must not act on an IP or name
+// that has since moved to another object.
+type resolver struct {
+	client unifiClient
+
+	mu      sync.Mutex
+	devices map[string]cachedList[unifi.Device]
+	clients map[string]cachedList[unifi.NetworkClient]
+}
+
+// cachedList is one site's fetched list and the time it was fetched.
+type cachedList[T any] struct {
+	items     []T
+	fetchedAt time.Time
+}
+
+func newResolver(client unifiClient) *resolver {
+	return &resolver{
+		client:  client,
+		devices: make(map[string]cachedList[unifi.Device]),
+		clients: make(map[string]cachedList[unifi.NetworkClient]),
+	}
+}
+
+// selectorCandidate is the subset of fields the resolver matches on.
+type selectorCandidate struct {
+	ID   string
+	Name string
+	MAC  string
+	IP   string
+}
+
+// listDevices returns every adopted device for siteID, using the cache when it
+// is fresh and refresh is not set.
+func (r *resolver) listDevices(ctx context.Context, siteID string, refresh bool) ([]unifi.Device, error) {
+	r.mu.Lock()
+	entry, ok := r.devices[siteID]
+	r.mu.Unlock()
+	if ok && !refresh && time.Since(entry.fetchedAt) < resolverCacheTTL {
+		return entry.items, nil
+	}
+	items, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.Device], error) {
+		return r.client.ListDevices(ctx, siteID, offset, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.devices[siteID] = cachedList[unifi.Device]{items: items, fetchedAt: time.Now()}
+	r.mu.Unlock()
+	return items, nil
+}
+
+// listClients returns every connected client for siteID, using the cache when
+// it is fresh and refresh is not set.
+func (r *resolver) listClients(ctx context.Context, siteID string, refresh bool) ([]unifi.NetworkClient, error) {
+	r.mu.Lock()
+	entry, ok := r.clients[siteID]
+	r.mu.Unlock()
+	if ok && !refresh && time.Since(entry.fetchedAt) < resolverCacheTTL {
+		return entry.items, nil
+	}
+	items, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.NetworkClient], error) {
+		return r.client.ListClients(ctx, siteID, offset, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.clients[siteID] = cachedList[unifi.NetworkClient]{items: items, fetchedAt: time.Now()}
+	r.mu.Unlock()
+	return items, nil
+}
+
+// resolveDevice returns the single device matching selector. With exact set,
+// partial name matches are refused (see matchSelector) and the device list is
+// fetched afresh.
+func (r *resolver) resolveDevice(ctx context.Context, siteID, selector string, exact bool) (unifi.Device, error) {
+	devices, err := r.listDevices(ctx, siteID, exact)
+	if err != nil {
+		return unifi.Device{}, fmt.Errorf("resolve device %q: %w", selector, err)
+	}
+	candidates := make([]selectorCandidate, len(devices))
+	for i := range devices {
+		candidates[i] = selectorCandidate{ID: devices[i].ID, Name: devices[i].Name, MAC: devices[i].MAC, IP: devices[i].IP}
+	}
+	idx, err := matchSelector("device", selector, candidates, exact)
+	if err != nil {
+		return unifi.Device{}, err
+	}
+	return devices[idx], nil
+}
+
+// resolveClient returns the single connected client matching selector. With
+// exact set, partial name matches are refused (see matchSelector) and the
+// client list is fetched afresh.
+func (r *resolver) resolveClient(ctx context.Context, siteID, selector string, exact bool) (unifi.NetworkClient, error) {
+	clients, err := r.listClients(ctx, siteID, exact)
+	if err != nil {
+		return unifi.NetworkClient{}, fmt.Errorf("resolve client %q: %w", selector, err)
+	}
+	candidates := make([]selectorCandidate, len(clients))
+	for i := range clients {
+		candidates[i] = selectorCandidate{ID: clients[i].ID, Name: clients[i].Name, MAC: clients[i].MAC, IP: clients[i].IP}
+	}
+	idx, err := matchSelector("client", selector, candidates, exact)
+	if err != nil {
+		return unifi.NetworkClient{}, err
+	}
+	return clients[idx], nil
+}
+
+// deviceID returns id when set, otherwise resolves selector to a device ID.
+func (r *resolver) deviceID(ctx context.Context, siteID, id, selector string, exact bool) (string, error) {
+	if id != "" {
+		return id, nil
+	}
+	if strings.TrimSpace(selector) == "" {
+		return "", errSelectorRequired
+	}
+	dev, err := r.resolveDevice(ctx, siteID, selector, exact)
+	if err != nil {
+		return "", err
+	}
+	return dev.ID, nil
+}
+
+// clientID returns id when set, otherwise resolves selector to a client ID.
+func (r *resolver) clientID(ctx context.Context, siteID, id, selector string, exact bool) (string, error) {
+	if id != "" {
+		return id, nil
+	}
+	if strings.TrimSpace(selector) == "" {
+		return "", errSelectorRequired
+	}
+	c, err := r.resolveClient(ctx, siteID, selector, exact)
+	if err != nil {
+		return "", err
+	}
+	return c.ID, nil
+}
+
+// matchSelector returns the index of the single candidate matching selector.
+// Precedence, highest first: exact ID, MAC (any notation), IP, case-insensitive
+// exact name, then fuzzy name (case, spacing and punctuation ignored, substring).
+// The first tier with any hits decides: one hit wins, several is ambiguous.
+// With exact set the fuzzy tier never wins: destructive tools pass it so that
+// a partial name is reported with its candidates instead of acted on.
+func matchSelector(kind, selector string, candidates []selectorCandidate, exact bool) (int, error) {
+	sel := strings.TrimSpace(selector)
+	if sel == "" {
+		return -1, errSelectorRequired
+	}
+
+	selMAC, isMAC := normalizeMAC(sel)
+	selIP, ipErr := netip.ParseAddr(sel)
+	selFold := foldName(sel)
+
+	tiers := []func(c selectorCandidate) bool{
+		func(c selectorCandidate) bool { return c.ID == sel },
+		func(c selectorCandidate) bool {
+			if !isMAC {
+				return false
+			}
+			m, ok := normalizeMAC(c.MAC)
+			return ok && m == selMAC
+		},
+		func(c selectorCandidate) bool {
+			if ipErr != nil {
+				return false
+			}
+			a, err := netip.ParseAddr(c.IP)
+			return err == nil && a == selIP
+		},
+		func(c selectorCandidate) bool { return c.Name != "" && strings.EqualFold(c.Name, sel) },
+		func(c selectorCandidate) bool {
+			return selFold != "" && c.Name != "" && strings.Contains(foldName(c.Name), selFold)
+		},
+	}
+
+	fuzzy := len(tiers) - 1
+	for tier, match := range tiers {
+		var hits []int
+		for i := range candidates {
+			if match(candidates[i]) {
+				hits = append(hits, i)
+			}
+		}
+		if len(hits) == 0 {
+			continue
+		}
+		desc := make([]string, len(hits))
+		for j, i := range hits {
+			desc[j] = describeCandidate(candidates[i])
+		}
+		switch {
+		case exact && tier == fuzzy:
+			return -1, fmt.Errorf("%s selector %q %w: %s", kind, sel, errInexact, strings.Join(desc, "; "))
+		case len(hits) == 1:
+			return hits[0], nil
+		default:
+			return -1, &ambiguousError{kind: kind, selector: sel, candidates: desc}
+		}
+	}
+	return -1, fmt.Errorf("%s selector %q: %w", kind, sel, errNoMatch)
+}
+
+// describeCandidate renders a candidate as "id (name, mac, ip)" for ambiguity errors.
+func describeCandidate(c selectorCandidate) string {
+	parts := make([]string, 0, 3)
+	for _, p := range []string{c.Name, c.MAC, c.IP} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		return c.ID
+	}
+	return c.ID + " (" + strings.Join(parts, ", ") + ")"
+}
+
+// normalizeMAC parses a 48-bit MAC address written with colons, hyphens, Cisco
+// dots, or no separators at all, and returns it as lower-case colon-separated hex.
+func normalizeMAC(s string) (string, bool) {
+	hw, err := oui.ParseMAC(s)
+	if err != nil {
+		return "", false
+	}
+	return hw.String(), true
+}
+
+// foldName lower-cases s and drops everything but letters and digits so that
+// "Living Room AP", "living-room-ap" and "livingroomap" compare equal.
+func foldName(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,145 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+func TestMatchSelector(t *testing.T) {
+	candidates := []selectorCandidate{
+		{ID: "dev-1", Name: "Living Room AP", MAC: "aa:bb:cc:00:00:01", IP: "10.0.0.11"},
+		{ID: "dev-2", Name: "Office AP", MAC: "aa:bb:cc:00:00:02", IP: "10.0.0.12"},
+		{ID: "dev-3", Name: "Office Switch", MAC: "aa:bb:cc:00:00:03", IP: "10.0.0.13"},
+		{ID: "10.0.0.12", Name: "odd ID"},
+	}
+	tests := []struct {
+		name     string
+		selector string
+		exact    bool
+		want     int
+		wantErr  error
+		wantAmb  bool
+	}{
+		{name: "ID", selector: "dev-2", want: 1},
+		{name: "ID beats IP", selector: "10.0.0.12", want: 3},
+		{name: "MAC hyphens", selector: "AA-BB-CC-00-00-03", want: 2},
+		{name: "MAC Cisco dots", selector: "aabb.cc00.0001", want: 0},
+		{name: "IP", selector: "10.0.0.13", want: 2},
+		{name: "exact name ignores case", selector: "office ap", want: 1},
+		{name: "fuzzy name", selector: "living-room", want: 0},
+		{name: "fuzzy name ambiguous", selector: "office", wantAmb: true},
+		{name: "no match", selector: "garage", wantErr: errNoMatch},
+		{name: "empty", selector: "  ", wantErr: errSelectorRequired},
+		{name: "exact allows full name", selector: "Office Switch", exact: true, want: 2},
+		{name: "exact allows MAC", selector: "aabbcc000001", exact: true, want: 0},
+		{name: "exact refuses fuzzy", selector: "living", exact: true, wantErr: errInexact},
+		{name: "exact refuses ambiguous fuzzy", selector: "office", exact: true, wantErr: errInexact},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := matchSelector("device", tc.selector, candidates, tc.exact)
+			var amb *ambiguousError
+			switch {
+			case tc.wantAmb:
+				if !errors.As(err, &amb) || len(amb.candidates) != 2 {
+					t.Fatalf("got %d, %v; want ambiguous error with 2 candidates", got, err)
+				}
+			case tc.wantErr != nil:
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("got %d, %v; want %v", got, err, tc.wantErr)
+				}
+			case err != nil || got != tc.want:
+				t.Fatalf("got %d, %v; want %d", got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeMAC(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"AA:BB:CC:DD:EE:FF", "aa:bb:cc:dd:ee:ff", true},
+		{"aa-bb-cc-dd-ee-ff", "aa:bb:cc:dd:ee:ff", true},
+		{"aabb.ccdd.eeff", "aa:bb:cc:dd:ee:ff", true},
+		{"AABBCCDDEEFF", "aa:bb:cc:dd:ee:ff", true},
+		{"aa:bb:cc:dd:ee", "", false},
+		{"aa:bb:cc:dd:ee:ff:00:11", "", false},
+		{"printer", "", false},
+		{"", "", false},
+	}
+	for _, tc := range tests {
+		got, ok := normalizeMAC(tc.in)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("normalizeMAC(%q) = %q, %v; want %q, %v", tc.in, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestResolverCache(t *testing.T) {
+	fake := &fakeClient{
+		devices: []unifi.Device{{ID: "dev-1", Name: "Hall AP", MAC: "aa:bb:cc:00:00:01"}},
+		clients: []unifi.NetworkClient{{ID: "cl-1", Name: "phone", MAC: "aa:bb:cc:00:00:10"}},
+	}
+	res := newResolver(fake)
+	ctx := context.Background()
+
+	for range 3 {
+		if id, err := res.deviceID(ctx, "", "", "hall ap", false); err != nil || id != "dev-1" {
+			t.Fatalf("deviceID = %q, %v", id, err)
+		}
+		if id, err := res.clientID(ctx, "", "", "phone", false); err != nil || id != "cl-1" {
+			t.Fatalf("clientID = %q, %v", id, err)
+		}
+	}
+	if fake.deviceLists != 1 || fake.clientLists != 1 {
+		t.Errorf("lists fetched %d/%d times within the TTL, want 1/1", fake.deviceLists, fake.clientLists)
+	}
+
+	if _, err := res.listDevices(ctx, "other-site", false); err != nil {
+		t.Fatal(err)
+	}
+	if fake.deviceLists != 2 {
+		t.Errorf("another site should not share the cache; %d device lists", fake.deviceLists)
+	}
+
+	res.mu.Lock()
+	entry := res.devices[""]
+	entry.fetchedAt = time.Now().Add(-resolverCacheTTL)
+	res.devices[""] = entry
+	res.mu.Unlock()
+	if _, err := res.resolveDevice(ctx, "", "dev-1", false); err != nil {
+		t.Fatal(err)
+	}
+	if fake.deviceLists != 3 {
+		t.Errorf("expired entry should be refetched; %d device lists", fake.deviceLists)
+	}
+
+	// Exact lookups act on what the controller reports now: an IP that
+	// moved to another device within the TTL must not resolve to the old one.
+	fake.mu.Lock()
+	fake.devices = []unifi.Device{{ID: "dev-2", Name: "Hall AP", MAC: "aa:bb:cc:00:00:02", IP: "10.0.0.5"}}
+	fake.mu.Unlock()
+	if id, err := res.deviceID(ctx, "", "", "10.0.0.5", true); err != nil || id != "dev-2" {
+		t.Errorf("exact deviceID = %q, %v; want dev-2 from a fresh list", id, err)
+	}
+	if id, err := res.clientID(ctx, "", "", "phone", true); err != nil || id != "cl-1" {
+		t.Errorf("exact clientID = %q, %v", id, err)
+	}
+	if fake.deviceLists != 4 || fake.clientLists != 2 {
+		t.Errorf("exact lookups fetched %d/%d lists, want 4/2", fake.deviceLists, fake.clientLists)
+	}
+
+	if id, err := res.deviceID(ctx, "", "explicit", "", true); err != nil || id != "explicit" {
+		t.Errorf("explicit ID = %q, %v", id, err)
+	}
+	if _, err := res.deviceID(ctx, "", "", " ", true); !errors.Is(err, errSelectorRequired) {
+		t.Errorf("blank selector: %v", err)
+	}
+}
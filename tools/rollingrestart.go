@@ -0,0 +1,294 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// deviceOnline is the state of a device that is connected to the controller.
+const deviceOnline = "ONLINE"
+
+// A restarting device is polled every deviceReturnFastPoll for the first
+// deviceReturnFastFor, so a quick reboot is still seen offline, and every
+// deviceReturnPoll after that. They are variables so tests can shorten them.
+var (
+	deviceReturnPoll     = 5 * time.Second
+	deviceReturnFastPoll = time.Second
+	deviceReturnFastFor  = 30 * time.Second
+)
+
+// selectDevices returns the adopted devices carrying tag (a device tag name or
+// ID), of model, or named in selectors (a comma-separated list of device IDs,
+// names, MACs or IPs); exactly one criterion must be given. Devices picked by
+// tag or model are sorted by name; a list keeps its order.
+func selectDevices(ctx context.Context, client unifiClient, res *resolver, siteID, tag, model, selectors string) ([]unifi.Device, error) {
+	given := 0
+	for _, v := range []string{tag, model, selectors} {
+		if strings.TrimSpace(v) != "" {
+			given++
+		}
+	}
+	if given != 1 {
+		return nil, errors.New("give exactly one of tag, model or devices")
+	}
+	if list := splitIDs(&selectors); len(list) > 0 {
+		var out []unifi.Device
+		seen := map[string]bool{}
+		for _, sel := range list {
+			dev, err := res.resolveDevice(ctx, siteID, sel, true)
+			if err != nil {
+				return nil, err
+			}
+			if !seen[dev.ID] {
+				seen[dev.ID] = true
+				out = append(out, dev)
+			}
+		}
+		return out, nil
+	}
+
+	devices, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.Device], error) {
+		return client.ListDevices(ctx, siteID, offset, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var keep func(*unifi.Device) bool
+	if tag = strings.TrimSpace(tag); tag != "" {
+		tags, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.DeviceTag], error) {
+			return client.ListDeviceTags(ctx, siteID, offset, limit)
+		})
+		if err != nil {
+			return nil, err
+		}
+		i := slices.IndexFunc(tags, func(t unifi.DeviceTag) bool { return t.ID == tag || strings.EqualFold(t.Name, tag) })
+		if i < 0 {
+			return nil, fmt.Errorf("no device tag %q", tag)
+		}
+		members := tags[i].DeviceIDs
+		keep = func(d *unifi.Device) bool { return slices.Contains(members, d.ID) }
+	} else {
+		model = strings.TrimSpace(model)
+		keep = func(d *unifi.Device) bool { return strings.EqualFold(d.Model, model) }
+	}
+	var out []unifi.Device
+	for i := range devices {
+		if keep(&devices[i]) {
+			out = append(out, devices[i])
+		}
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no devices match")
+	}
+	slices.SortFunc(out, func(a, b unifi.Device) int { return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID)) })
+	return out, nil
+}
+
+// restartedDevice returns the device back online after a restart or upgrade
+// issued at since, when its uptime was uptimeBefore (zero if unknown). A
+// device counts as back once it is online and has been seen offline, or
+// reports a lower uptime than before, or an uptime shorter than the time since
+// the restart was issued. The last catches a reboot too quick to be seen
+// offline even when the uptime could not be read beforehand. It gives up at
+// deadline.
+func restartedDevice(ctx context.Context, client unifiClient, siteID, deviceID string, uptimeBefore int64, since, deadline time.Time) (unifi.Device, error) {
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	wentDown := false
+	state := "unknown"
+	for {
+		wait := deviceReturnPoll
+		if time.Since(since) < deviceReturnFastFor {
+			wait = deviceReturnFastPoll
+		}
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return unifi.Device{}, fmt.Errorf("did not come back online within %s (last state %s)",
+					time.Since(since).Round(time.Second), state)
+			}
+			return unifi.Device{}, ctx.Err()
+		case <-time.After(wait):
+		}
+		dev, err := client.GetDevice(ctx, siteID, deviceID)
+		if err != nil {
+			// The controller may briefly lose track of a rebooting device.
+			continue
+		}
+		state = dev.State
+		if dev.State != deviceOnline {
+			wentDown = true
+			continue
+		}
+		if wentDown {
+			return dev, nil
+		}
+		stats, err := client.GetDeviceStats(ctx, siteID, deviceID)
+		if err != nil {
+			continue
+		}
+		// Uptime is whole seconds; the extra second keeps a device that
+		// did not restart from looking like one that did.
+		if (uptimeBefore > 0 && stats.UptimeSec < uptimeBefore) ||
+			time.Duration(stats.UptimeSec+1)*time.Second < time.Since(since) {
+			return dev, nil
+		}
+	}
+}
+
+// deviceUptime returns the device's uptime in seconds, or zero if it cannot
+// be read.
+func deviceUptime(ctx context.Context, client unifiClient, siteID, deviceID string) int64 {
+	stats, err := client.GetDeviceStats(ctx, siteID, deviceID)
+	if err != nil {
+		return 0
+	}
+	return stats.UptimeSec
+}
+
+// progressNotifier sends MCP progress notifications for a tool call when the
+// caller asked for them.
+type progressNotifier struct {
+	req   *mcp.CallToolRequest
+	total int
+}
+
+func (p progressNotifier) notify(ctx context.Context, done int, msg string) {
+	if p.req == nil || p.req.Session == nil {
+		return
+	}
+	token := p.req.Params.GetProgressToken()
+	if token == nil {
+		return
+	}
+	_ = p.req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+		ProgressToken: token,
+		Progress:      float64(done),
+		Total:         float64(p.total),
+		Message:       msg,
+	})
+}
+
+// deviceRun is one device's part in a rolling operation.
+type deviceRun struct {
+	ID    string `json:"id"`
+	Name  string `json:"name,omitempty"`
+	Model string `json:"model,omitempty"`
+	// Status is pending, done, failed or skipped.
+	Status   string `json:"status"`
+	Seconds  int    `json:"seconds,omitempty"`
+	Firmware string `json:"firmware,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// rolloutResult reports a rolling operation.
+type rolloutResult struct {
+	DryRun  bool        `json:"dryRun,omitempty"`
+	Aborted bool        `json:"aborted"`
+	Reason  string      `json:"reason,omitempty"`
+	Devices []deviceRun `json:"devices"`
+}
+
+func newDeviceRun(d *unifi.Device) deviceRun {
+	return deviceRun{ID: d.ID, Name: d.Name, Model: d.Model, Status: "pending", Firmware: d.FirmwareVersion}
+}
+
+func registerRollingRestartTools(s *mcp.Server, client unifiClient, res *resolver) {
+	destructiveTrue := true
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "rolling_restart",
+		Description: "Restart devices one at a time so coverage never drops: each device is restarted and must come back " +
+			"ONLINE before the next one is restarted. Select devices by tag (device tag name or ID), model, or devices " +
+			"(comma-separated IDs, names, MACs or IPs). The rollout stops at the first device that fails to restart or " +
+			"does not return within timeout_minutes. All devices must be online to start. Progress is reported as MCP " +
+			"progress notifications. Without confirmed=true only the restart order is returned.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input struct {
+		SiteID         string `json:"site_id,omitempty"         jsonschema:"site ID; omit to use default"`
+		Tag            string `json:"tag,omitempty"             jsonschema:"restart the devices with this device tag (name or ID)"`
+		Model          string `json:"model,omitempty"           jsonschema:"restart the devices of this model, e.g. U6-Pro"`
+		Devices        string `json:"devices,omitempty"         jsonschema:"comma-separated device IDs, names, MACs or IPs, restarted in this order"`
+		TimeoutMinutes int    `json:"timeout_minutes,omitempty" jsonschema:"how long to wait for each device to come back online (default 10)"`
+		SettleSeconds  int    `json:"settle_seconds,omitempty"  jsonschema:"pause after each device is back before restarting the next (default 30)"`
+		Confirmed      bool   `json:"confirmed"                 jsonschema:"true to restart; omit to preview the order"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		if input.TimeoutMinutes < 0 || input.SettleSeconds < 0 {
+			return errorResult(fmt.Errorf("rolling_restart: timeout_minutes and settle_seconds must not be negative"))
+		}
+		devices, err := selectDevices(ctx, client, res, input.SiteID, input.Tag, input.Model, input.Devices)
+		if err != nil {
+			return errorResult(fmt.Errorf("rolling_restart: %w", err))
+		}
+		runs := make([]deviceRun, len(devices))
+		var offline []string
+		for i := range devices {
+			runs[i] = newDeviceRun(&devices[i])
+			if devices[i].State != deviceOnline {
+				offline = append(offline, fmt.Sprintf("%s (%s)", cmp.Or(devices[i].Name, devices[i].ID), devices[i].State))
+			}
+		}
+		if len(offline) > 0 {
+			return errorResult(fmt.Errorf("rolling_restart: not all devices are online: %s", strings.Join(offline, ", ")))
+		}
+		if !input.Confirmed {
+			return jsonResult(rolloutResult{DryRun: true, Devices: runs})
+		}
+		timeout := time.Duration(cmp.Or(input.TimeoutMinutes, 10)) * time.Minute
+		settle := time.Duration(cmp.Or(input.SettleSeconds, 30)) * time.Second
+		progress := progressNotifier{req: req, total: len(runs)}
+		return jsonResult(rollingRestart(ctx, client, input.SiteID, runs, timeout, settle, progress))
+	})
+}
+
+// rollingRestart restarts the devices in runs one at a time, waiting up to
+// timeout for each to come back and settle between them, and stops at the
+// first failure. It updates runs in place.
+func rollingRestart(ctx context.Context, client unifiClient, siteID string, runs []deviceRun, timeout, settle time.Duration, progress progressNotifier) rolloutResult {
+	result := rolloutResult{Devices: runs}
+rollout:
+	for i := range runs {
+		r := &runs[i]
+		label := cmp.Or(r.Name, r.ID)
+		progress.notify(ctx, i, fmt.Sprintf("restarting %s (%d of %d)", label, i+1, len(runs)))
+		start := time.Now()
+		uptime := deviceUptime(ctx, client, siteID, r.ID)
+		if err := client.RestartDevice(ctx, siteID, r.ID); err != nil {
+			r.Status, r.Error = "failed", err.Error()
+		} else if _, err := restartedDevice(ctx, client, siteID, r.ID, uptime, start, start.Add(timeout)); err != nil {
+			r.Status, r.Error = "failed", err.Error()
+		} else {
+			r.Status = "done"
+		}
+		r.Seconds = int(time.Since(start).Seconds())
+		if r.Status == "failed" {
+			result.Aborted, result.Reason = true, fmt.Sprintf("stopped after %s failed; later devices were not restarted", label)
+			progress.notify(ctx, i+1, fmt.Sprintf("%s failed: %s", label, r.Error))
+			break
+		}
+		progress.notify(ctx, i+1, fmt.Sprintf("%s is back online after %ds", label, r.Seconds))
+		if i < len(runs)-1 {
+			select {
+			case <-ctx.Done():
+				result.Aborted, result.Reason = true, "cancelled"
+				break rollout
+			case <-time.After(settle):
+			}
+		}
+	}
+	for i := range runs {
+		if runs[i].Status == "pending" {
+			runs[i].Status = "skipped"
+		}
+	}
+	return result
+}
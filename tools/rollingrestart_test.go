@@ -0,0 +1,172 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+// fastDevicePolls shortens the restart polling intervals for one test.
+func fastDevicePolls(t *testing.T) {
+	t.Helper()
+	poll, fast, fastFor := deviceReturnPoll, deviceReturnFastPoll, deviceReturnFastFor
+	deviceReturnPoll, deviceReturnFastPoll, deviceReturnFastFor = time.Millisecond, time.Millisecond, time.Millisecond
+	t.Cleanup(func() { deviceReturnPoll, deviceReturnFastPoll, deviceReturnFastFor = poll, fast, fastFor })
+}
+
+func online(id string) unifi.Device { return unifi.Device{ID: id, State: deviceOnline} }
+
+func TestRestartedDevice(t *testing.T) {
+	fastDevicePolls(t)
+	statsErr := errors.New("stats unavailable")
+	tests := []struct {
+		name         string
+		getDevice    func(id string, call int) (unifi.Device, error)
+		uptime       int64
+		statsErr     error
+		uptimeBefore int64
+		sinceAgo     time.Duration
+		wantErr      string
+	}{
+		{
+			name: "seen offline, then online",
+			getDevice: func(id string, call int) (unifi.Device, error) {
+				if call == 1 {
+					return unifi.Device{ID: id, State: "OFFLINE"}, nil
+				}
+				return online(id), nil
+			},
+			statsErr: statsErr,
+		},
+		{
+			name:         "never offline but uptime dropped",
+			getDevice:    func(id string, _ int) (unifi.Device, error) { return online(id), nil },
+			uptime:       12,
+			uptimeBefore: 86400,
+		},
+		{
+			name:      "quick reboot with unknown uptime before",
+			getDevice: func(id string, _ int) (unifi.Device, error) { return online(id), nil },
+			uptime:    20,
+			sinceAgo:  time.Minute,
+		},
+		{
+			name: "controller loses track, then reports the device",
+			getDevice: func(id string, call int) (unifi.Device, error) {
+				if call < 3 {
+					return unifi.Device{}, errors.New("not found")
+				}
+				return online(id), nil
+			},
+			uptime:   5,
+			sinceAgo: time.Minute,
+		},
+		{
+			name:         "never restarts",
+			getDevice:    func(id string, _ int) (unifi.Device, error) { return online(id), nil },
+			uptime:       86460,
+			uptimeBefore: 86400,
+			sinceAgo:     time.Minute,
+			wantErr:      "did not come back online",
+		},
+		{
+			name:      "uptime never readable and never offline",
+			getDevice: func(id string, _ int) (unifi.Device, error) { return online(id), nil },
+			statsErr:  statsErr,
+			wantErr:   "did not come back online",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := &fakeClient{
+				getDevice: tc.getDevice,
+				deviceStats: func(string) (unifi.DeviceStats, error) {
+					return unifi.DeviceStats{UptimeSec: tc.uptime}, tc.statsErr
+				},
+			}
+			_, err := restartedDevice(context.Background(), fake, "", "dev-1", tc.uptimeBefore,
+				time.Now().Add(-tc.sinceAgo), time.Now().Add(50*time.Millisecond))
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("got %v, want the device back", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Fatalf("got %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRollingRestart(t *testing.T) {
+	fastDevicePolls(t)
+	// Each device answers OFFLINE on its first poll after a restart and
+	// ONLINE after that, unless it is stuck.
+	rebooting := func(stuck string) func(id string, call int) (unifi.Device, error) {
+		return func(id string, call int) (unifi.Device, error) {
+			if id == stuck {
+				return unifi.Device{ID: id, State: "OFFLINE"}, nil
+			}
+			if call == 1 {
+				return unifi.Device{ID: id, State: "OFFLINE"}, nil
+			}
+			return online(id), nil
+		}
+	}
+	tests := []struct {
+		name         string
+		stuck        string
+		restartErrs  map[string]error
+		wantRestarts []string
+		wantStatus   []string
+		wantAborted  bool
+	}{
+		{
+			name:         "restarts every device in order",
+			wantRestarts: []string{"dev-1", "dev-2", "dev-3"},
+			wantStatus:   []string{"done", "done", "done"},
+		},
+		{
+			name:         "aborts after the first failed restart",
+			restartErrs:  map[string]error{"dev-1": errors.New("device busy")},
+			wantRestarts: []string{"dev-1"},
+			wantStatus:   []string{"failed", "skipped", "skipped"},
+			wantAborted:  true,
+		},
+		{
+			name:         "aborts when a device does not come back",
+			stuck:        "dev-2",
+			wantRestarts: []string{"dev-1", "dev-2"},
+			wantStatus:   []string{"done", "failed", "skipped"},
+			wantAborted:  true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := &fakeClient{
+				getDevice:   rebooting(tc.stuck),
+				deviceStats: func(string) (unifi.DeviceStats, error) { return unifi.DeviceStats{UptimeSec: 86400}, nil },
+				restartErrs: tc.restartErrs,
+			}
+			runs := []deviceRun{
+				{ID: "dev-1", Status: "pending"},
+				{ID: "dev-2", Status: "pending"},
+				{ID: "dev-3", Status: "pending"},
+			}
+			result := rollingRestart(context.Background(), fake, "", runs, 50*time.Millisecond, 0, progressNotifier{})
+			if fmt.Sprint(fake.restarts) != fmt.Sprint(tc.wantRestarts) {
+				t.Errorf("restarted %v, want %v", fake.restarts, tc.wantRestarts)
+			}
+			var status []string
+			for _, r := range result.Devices {
+				status = append(status, r.Status)
+			}
+			if fmt.Sprint(status) != fmt.Sprint(tc.wantStatus) || result.Aborted != tc.wantAborted {
+				t.Errorf("status %v aborted=%v, want %v aborted=%v", status, result.Aborted, tc.wantStatus, tc.wantAborted)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/schedule"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ScheduleExecutor returns the executor that runs scheduled jobs through
+// client. It is exported for the scheduler started in main.
+func ScheduleExecutor(client unifiClient) schedule.Executor {
+	return func(ctx context.Context, job schedule.Job) (string, []string, error) {
+		switch job.Kind {
+		case schedule.KindGuestAccess:
+			return runGuestAccessJob(ctx, client, job)
+		case schedule.KindVouchers:
+			v := job.Vouchers
+			created, err := client.CreateVouchers(ctx, job.SiteID, unifi.VoucherRequest{
+				Count:            v.Count,
+				Name:             v.Name,
+				TimeLimitMinutes: v.TimeLimitMinutes,
+				DataLimitMb:      v.DataLimitMb,
+			})
+			if err != nil {
+				return "", nil, err
+			}
+			ids := make([]string, len(created))
+			for i := range created {
+				ids[i] = created[i].ID
+			}
+			return fmt.Sprintf("created %d vouchers", len(created)), ids, nil
+		default:
+			return "", nil, fmt.Errorf("unknown job kind %q", job.Kind)
+		}
+	}
+}
+
+// runGuestAccessJob authorizes the job's guest until the end of its window.
+// A guest whose device is not connected yet is reported as not ready, so the
+// scheduler keeps trying until the window closes.
+func runGuestAccessJob(ctx context.Context, client unifiClient, job schedule.Job) (string, []string, error) {
+	g := job.GuestAccess
+	clients, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.NetworkClient], error) {
+		return client.ListClients(ctx, job.SiteID, offset, limit)
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	var guest *unifi.NetworkClient
+	for i := range clients {
+		if mac, ok := normalizeMAC(clients[i].MAC); ok && mac == g.MAC {
+			guest = &clients[i]
+			break
+		}
+	}
+	if guest == nil {
+		return "", nil, fmt.Errorf("%s is not connected: %w", g.MAC, schedule.ErrNotReady)
+	}
+	minutes := int(math.Ceil(time.Until(job.End).Minutes()))
+	if minutes < 1 {
+		return "", nil, fmt.Errorf("the access window has closed: %w", schedule.ErrNotReady)
+	}
+	err = client.AuthorizeGuestClient(ctx, job.SiteID, guest.ID, unifi.GuestAuthRequest{
+		Action:                "AUTHORIZE_GUEST_ACCESS",
+		TimeLimitMinutes:      minutes,
+		DataLimitMb:           g.DataLimitMb,
+		DownloadBandwidthKbps: g.DownloadBandwidthKbps,
+		UploadBandwidthKbps:   g.UploadBandwidthKbps,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("authorized client %s (%s) for %d minutes", guest.ID, g.MAC, minutes), nil, nil
+}
+
+// localJob returns job with its times in the zone they were given in.
+func localJob(job schedule.Job) schedule.Job {
+	loc, err := time.LoadLocation(job.TimeZone)
+	if err != nil {
+		return job
+	}
+	for _, t := range []*time.Time{&job.Start, &job.End, &job.CreatedAt, &job.LastAttempt, &job.FinishedAt} {
+		if !t.IsZero() {
+			*t = t.In(loc)
+		}
+	}
+	return job
+}
+
+// registerScheduleTools registers the tools that schedule, list and cancel
+// future guest authorizations and voucher batches. The jobs are run by the
+// scheduler started in main.
+func registerScheduleTools(s *mcp.Server, client unifiClient, res *resolver, store *schedule.Store) {
+	destructiveTrue := true
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "schedule_guest_access",
+		Description: "Schedule guest access for later. With client, the guest's device is authorized from start to end " +
+			"(or for duration): if it is not connected at start, it is authorized as soon as it connects within the window, " +
+			"for the time left. With voucher_count, a batch of vouchers is generated at start instead. Times are wall-clock " +
+			"times such as 2026-03-10 09:00 in time_zone, or RFC 3339. Jobs are kept in a local file and survive restarts. " +
+			"Set confirmed=true to proceed.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID                string `json:"site_id,omitempty"                  jsonschema:"site ID; omit to use default"`
+		Client                string `json:"client,omitempty"                   jsonschema:"guest device: MAC address (need not be connected now), or name, IP or client ID of a client connected now"`
+		Start                 string `json:"start"                              jsonschema:"when access begins or vouchers are generated, e.g. 2026-03-10 09:00"`
+		End                   string `json:"end,omitempty"                      jsonschema:"when guest access ends; give this or duration"`
+		Duration              string `json:"duration,omitempty"                 jsonschema:"guest access length as a Go duration, e.g. 8h"`
+		TimeZone              string `json:"time_zone,omitempty"                jsonschema:"IANA time zone for start and end, e.g. America/Toronto; defaults to the server's"`
+		DataLimitMb           int    `json:"data_limit_mb,omitempty"            jsonschema:"data cap in MB for the guest or each voucher; 0 or omit for unlimited"`
+		DownloadBandwidthKbps int    `json:"download_bandwidth_kbps,omitempty"  jsonschema:"guest download rate limit in Kbps; 0 or omit for unlimited"`
+		UploadBandwidthKbps   int    `json:"upload_bandwidth_kbps,omitempty"    jsonschema:"guest upload rate limit in Kbps; 0 or omit for unlimited"`
+		VoucherCount          int    `json:"voucher_count,omitempty"            jsonschema:"generate this many vouchers (1-100) at start instead of authorizing a client"`
+		VoucherName           string `json:"voucher_name,omitempty"             jsonschema:"label for the generated vouchers"`
+		TimeLimitMinutes      int    `json:"time_limit_minutes,omitempty"       jsonschema:"voucher access duration in minutes; 0 or omit for unlimited"`
+		Confirmed             bool   `json:"confirmed"                          jsonschema:"must be true to confirm the schedule"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		if !input.Confirmed {
+			return errorResult(fmt.Errorf("schedule_guest_access: set confirmed=true to confirm the schedule"))
+		}
+		if (input.Client == "") == (input.VoucherCount == 0) {
+			return errorResult(fmt.Errorf("schedule_guest_access: give either client or voucher_count"))
+		}
+		loc := time.Local
+		if input.TimeZone != "" {
+			l, err := time.LoadLocation(input.TimeZone)
+			if err != nil {
+				return errorResult(fmt.Errorf("schedule_guest_access: time_zone: %w", err))
+			}
+			loc = l
+		}
+		start, err := schedule.ParseTime(input.Start, loc)
+		if err != nil {
+			return errorResult(fmt.Errorf("schedule_guest_access: start: %w", err))
+		}
+		now := time.Now()
+		job := schedule.Job{SiteID: input.SiteID, Start: start, TimeZone: loc.String()}
+
+		if input.VoucherCount > 0 {
+			if input.End != "" || input.Duration != "" {
+				return errorResult(fmt.Errorf("schedule_guest_access: end and duration apply to client access; use time_limit_minutes for vouchers"))
+			}
+			job.Kind = schedule.KindVouchers
+			job.Vouchers = &schedule.Vouchers{
+				Count:            input.VoucherCount,
+				Name:             input.VoucherName,
+				TimeLimitMinutes: input.TimeLimitMinutes,
+				DataLimitMb:      input.DataLimitMb,
+			}
+		} else {
+			switch {
+			case input.End != "" && input.Duration != "":
+				return errorResult(fmt.Errorf("schedule_guest_access: give end or duration, not both"))
+			case input.End != "":
+				if job.End, err = schedule.ParseTime(input.End, loc); err != nil {
+					return errorResult(fmt.Errorf("schedule_guest_access: end: %w", err))
+				}
+			case input.Duration != "":
+				d, err := time.ParseDuration(input.Duration)
+				if err != nil || d <= 0 {
+					return errorResult(fmt.Errorf("schedule_guest_access: invalid duration %q (use e.g. 8h)", input.Duration))
+				}
+				job.End = start.Add(d)
+			default:
+				return errorResult(fmt.Errorf("schedule_guest_access: end or duration is required for client access"))
+			}
+			if !job.End.After(now) {
+				return errorResult(fmt.Errorf("schedule_guest_access: the access window has already ended"))
+			}
+			g := &schedule.GuestAccess{
+				DataLimitMb:           input.DataLimitMb,
+				DownloadBandwidthKbps: input.DownloadBandwidthKbps,
+				UploadBandwidthKbps:   input.UploadBandwidthKbps,
+			}
+			if mac, ok := normalizeMAC(input.Client); ok {
+				g.MAC = mac
+			} else {
+				c, err := res.resolveClient(ctx, input.SiteID, input.Client, true)
+				if err != nil {
+					return errorResult(fmt.Errorf("schedule_guest_access: client: %w (give a MAC address for a device that is not connected)", err))
+				}
+				g.MAC, _ = normalizeMAC(c.MAC)
+				g.ClientName = c.Name
+			}
+			job.Kind = schedule.KindGuestAccess
+			job.GuestAccess = g
+		}
+
+		job, err = store.Add(job, now)
+		if err != nil {
+			return errorResult(fmt.Errorf("schedule_guest_access: %w", err))
+		}
+		return jsonResult(localJob(job))
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "list_scheduled_jobs",
+		Description: "List scheduled guest authorizations and voucher batches with their status, attempts and results. " +
+			"Finished jobs (done, failed, missed, cancelled) are only included with include_finished=true.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(_ context.Context, _ *mcp.CallToolRequest, input struct {
+		IncludeFinished bool `json:"include_finished,omitempty" jsonschema:"also list jobs that have finished"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		jobs := store.List(input.IncludeFinished)
+		for i := range jobs {
+			jobs[i] = localJob(jobs[i])
+		}
+		return jsonResult(jobs)
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name:        "cancel_scheduled_job",
+		Description: "Cancel a pending scheduled job. Access already granted by a job that has run is not revoked.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, input struct {
+		JobID string `json:"job_id" jsonschema:"scheduled job ID from list_scheduled_jobs"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		id := strings.TrimSpace(input.JobID)
+		if id == "" {
+			return errorResult(fmt.Errorf("cancel_scheduled_job: job_id is required"))
+		}
+		job, err := store.Cancel(id, time.Now())
+		if err != nil {
+			if errors.Is(err, schedule.ErrNotFound) {
+				return errorResult(fmt.Errorf("cancel_scheduled_job: no job %s", id))
+			}
+			return errorResult(fmt.Errorf("cancel_scheduled_job: %w", err))
+		}
+		return jsonResult(localJob(job))
+	})
+}
@@ -0,0 +1,265 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gordcurrie/unifi-mcp/internal/search"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// searchResourceTypes lists every resource type the search tool can query, in
+// the order they are fetched.
+var searchResourceTypes = []string{
+	"device", "client", "network", "dns_policy", "traffic_matching_list",
+	"firewall_policy", "wan", "voucher",
+}
+
+// searchHit is a single ranked search result.
+type searchHit struct {
+	Type  string `json:"type"`
+	ID    string `json:"id"`
+	Name  string `json:"name,omitempty"`
+	Field string `json:"field"`
+	Value string `json:"value"`
+	Score int    `json:"score"`
+}
+
+// searchResult is the search tool's response body.
+type searchResult struct {
+	Query     string            `json:"query"`
+	QueryKind string            `json:"queryKind"`
+	Hits      []searchHit       `json:"hits"`
+	Total     int               `json:"total"`
+	Errors    map[string]string `json:"errors,omitempty"`
+}
+
+// searchFields is a resource's searchable field/value pairs.
+type searchFields struct {
+	typ    string
+	id     string
+	name   string
+	fields [][2]string
+}
+
+// best returns the highest-scoring field of r for q, or ok=false if nothing matched.
+func (r searchFields) best(q search.Query) (searchHit, bool) {
+	hit := searchHit{Type: r.typ, ID: r.id, Name: r.name}
+	for _, f := range r.fields {
+		if s := q.Score(f[1]); s > hit.Score {
+			hit.Field, hit.Value, hit.Score = f[0], f[1], s
+		}
+	}
+	return hit, hit.Score > 0
+}
+
+// searchCollect fetches one resource type and converts it into searchFields.
+func searchCollect(ctx context.Context, client unifiClient, siteID, typ string) ([]searchFields, error) {
+	var out []searchFields
+	switch typ {
+	case "device":
+		items, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.Device], error) {
+			return client.ListDevices(ctx, siteID, offset, limit)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			d := &items[i]
+			out = append(out, searchFields{typ: typ, id: d.ID, name: d.Name, fields: [][2]string{
+				{"name", d.Name}, {"macAddress", d.MAC}, {"ipAddress", d.IP}, {"model", d.Model},
+			}})
+		}
+	case "client":
+		items, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.NetworkClient], error) {
+			return client.ListClients(ctx, siteID, offset, limit)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			c := &items[i]
+			out = append(out, searchFields{typ: typ, id: c.ID, name: c.Name, fields: [][2]string{
+				{"name", c.Name}, {"macAddress", c.MAC}, {"ipAddress", c.IP},
+			}})
+		}
+	case "network":
+		items, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.NetworkConf], error) {
+			return client.ListNetworks(ctx, siteID, offset, limit)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			n := &items[i]
+			fields := [][2]string{{"name", n.Name}}
+			if n.VLANID != 0 {
+				fields = append(fields, [2]string{"vlanId", strconv.Itoa(n.VLANID)})
+			}
+			out = append(out, searchFields{typ: typ, id: n.ID, name: n.Name, fields: fields})
+		}
+	case "dns_policy":
+		items, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.DNSPolicy], error) {
+			return client.ListDNSPolicies(ctx, siteID, offset, limit)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			p := &items[i]
+			out = append(out, searchFields{typ: typ, id: p.ID, name: p.Domain, fields: [][2]string{
+				{"domain", p.Domain}, {"ipv4Address", p.IPv4Address}, {"ipv6Address", p.IPv6Address},
+				{"targetDomain", p.TargetDomain}, {"mailServerDomain", p.MailServerDomain},
+				{"serverDomain", p.ServerDomain}, {"ipAddress", p.IPAddress},
+			}})
+		}
+	case "traffic_matching_list":
+		items, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.TrafficMatchingList], error) {
+			return client.ListTrafficMatchingLists(ctx, siteID, offset, limit)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			l := &items[i]
+			fields := [][2]string{{"name", l.Name}}
+			for j, e := range l.Entries {
+				fields = append(fields, [2]string{fmt.Sprintf("entries[%d]", j), e})
+			}
+			out = append(out, searchFields{typ: typ, id: l.ID, name: l.Name, fields: fields})
+		}
+	case "firewall_policy":
+		items, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.FirewallPolicy], error) {
+			return client.ListFirewallPolicies(ctx, siteID, offset, limit)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			p := &items[i]
+			fields := [][2]string{{"name", p.Name}, {"description", p.Description}}
+			fields = appendIPFilterFields(fields, "source", p.Source.TrafficFilter)
+			fields = appendIPFilterFields(fields, "destination", p.Destination.TrafficFilter)
+			out = append(out, searchFields{typ: typ, id: p.ID, name: p.Name, fields: fields})
+		}
+	case "wan":
+		items, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.WAN], error) {
+			return client.ListWANs(ctx, siteID, offset, limit)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			w := &items[i]
+			fields := [][2]string{{"name", w.Name}, {"ipAddress", w.IPAddress}, {"gateway", w.Gateway}}
+			for j, d := range w.DNS {
+				fields = append(fields, [2]string{fmt.Sprintf("dns[%d]", j), d})
+			}
+			out = append(out, searchFields{typ: typ, id: w.ID, name: w.Name, fields: fields})
+		}
+	case "voucher":
+		items, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.Voucher], error) {
+			return client.ListVouchers(ctx, siteID, offset, limit)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			v := &items[i]
+			out = append(out, searchFields{typ: typ, id: v.ID, name: v.Name, fields: [][2]string{
+				{"code", v.Code}, {"name", v.Name},
+			}})
+		}
+	default:
+		return nil, fmt.Errorf("unknown resource type %q", typ)
+	}
+	return out, nil
+}
+
+// appendIPFilterFields adds each IP address filter item of a firewall policy
+// zone reference as a searchable field named "<side>.ipAddressFilter[i]".
+func appendIPFilterFields(fields [][2]string, side string, tf *unifi.FirewallPolicyTrafficFilter) [][2]string {
+	if tf == nil || tf.IPAddressFilter == nil {
+		return fields
+	}
+	for i, item := range tf.IPAddressFilter.Items {
+		fields = append(fields, [2]string{fmt.Sprintf("%s.ipAddressFilter[%d]", side, i), item.Value})
+	}
+	return fields
+}
+
+func registerSearchTools(s *mcp.Server, client unifiClient) {
+	type searchInput struct {
+		SiteID string `json:"site_id,omitempty" jsonschema:"site ID; omit to use default"`
+		Query  string `json:"query"             jsonschema:"free-text term, IP address, CIDR, or MAC address (any notation; an all-digit term without separators is text) to search for"`
+		Types  string `json:"types,omitempty"   jsonschema:"comma-separated resource types to search: device, client, network, dns_policy, traffic_matching_list, firewall_policy, wan, voucher; omit to search all"`
+		Limit  int    `json:"limit,omitempty"   jsonschema:"maximum number of hits to return (max 200); omit or 0 for 25"`
+	}
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "search",
+		Description: "Search devices, clients, networks, DNS policies, traffic matching lists, firewall policy IP filters, WANs and vouchers " +
+			"for a free-text term, IP address, CIDR, or MAC address. Returns hits ranked by match quality with the resource type, ID, and the field that matched. " +
+			"IP queries also match CIDRs and ranges that contain the address.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input searchInput) (*mcp.CallToolResult, any, error) {
+		query := strings.TrimSpace(input.Query)
+		if query == "" {
+			return errorResult(fmt.Errorf("search: query is required"))
+		}
+		if input.Limit < 0 || input.Limit > 200 {
+			return errorResult(fmt.Errorf("search: limit must be between 0 and 200"))
+		}
+		limit := input.Limit
+		if limit == 0 {
+			limit = 25
+		}
+		types := searchResourceTypes
+		if input.Types != "" {
+			types = splitIDs(&input.Types)
+			for _, t := range types {
+				if !slices.Contains(searchResourceTypes, t) {
+					return errorResult(fmt.Errorf("search: unknown type %q (valid: %s)", t, strings.Join(searchResourceTypes, ", ")))
+				}
+			}
+		}
+
+		q := search.Parse(query)
+		result := searchResult{Query: query, QueryKind: q.Kind(), Hits: []searchHit{}}
+		for _, typ := range types {
+			resources, err := searchCollect(ctx, client, input.SiteID, typ)
+			if err != nil {
+				// One unreachable resource type should not hide hits from the others.
+				if result.Errors == nil {
+					result.Errors = make(map[string]string)
+				}
+				result.Errors[typ] = err.Error()
+				continue
+			}
+			for _, r := range resources {
+				if hit, ok := r.best(q); ok {
+					result.Hits = append(result.Hits, hit)
+				}
+			}
+		}
+		slices.SortStableFunc(result.Hits, func(a, b searchHit) int {
+			if c := cmp.Compare(b.Score, a.Score); c != 0 {
+				return c
+			}
+			if c := cmp.Compare(a.Type, b.Type); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.Name, b.Name)
+		})
+		result.Total = len(result.Hits)
+		if len(result.Hits) > limit {
+			result.Hits = result.Hits[:limit]
+		}
+		return jsonResult(result)
+	})
+}
@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/toggles"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToggleExecutor returns the executor that applies toggle schedules through
+// client. It is exported for the scheduler started in main.
+func ToggleExecutor(client unifiClient) toggles.Executor {
+	return func(ctx context.Context, rule toggles.Rule) error {
+		switch rule.Target {
+		case toggles.WiFiBroadcast:
+			_, err := client.SetWiFiBroadcastEnabled(ctx, rule.SiteID, rule.ResourceID, rule.Enable)
+			return err
+		case toggles.FirewallPolicy:
+			_, err := client.SetFirewallPolicyEnabled(ctx, rule.SiteID, rule.ResourceID, rule.Enable)
+			return err
+		default:
+			return fmt.Errorf("unknown target %q", rule.Target)
+		}
+	}
+}
+
+// localRule returns rule with its times in the rule's time zone.
+func localRule(rule toggles.Rule) toggles.Rule {
+	loc, err := time.LoadLocation(rule.TimeZone)
+	if err != nil {
+		return rule
+	}
+	for _, t := range []*time.Time{&rule.CreatedAt, &rule.NextRun, &rule.RetryOf, &rule.RetryAt, &rule.LastRun} {
+		if !t.IsZero() {
+			*t = t.In(loc)
+		}
+	}
+	return rule
+}
+
+// registerToggleTools registers the tools that create, list and delete
+// recurring WiFi broadcast and firewall policy toggles. The rules are run by
+// the scheduler started in main.
+func registerToggleTools(s *mcp.Server, client unifiClient, store *toggles.Store) {
+	destructiveTrue := true
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "create_toggle_schedule",
+		Description: "Create a recurring rule that enables or disables a WiFi broadcast (SSID) or firewall policy on a cron " +
+			"schedule, e.g. cron \"0 22 * * *\" with enable=false to turn the kids' SSID off at 22:00 every night. Pair " +
+			"two rules to switch something off and back on. Cron is minute hour day-of-month month day-of-week in " +
+			"time_zone. When rules for the same resource fall due together, the later occurrence wins; failed runs are " +
+			"retried with backoff. Rules are kept in a local file and survive restarts. Set confirmed=true to proceed.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID     string `json:"site_id,omitempty"   jsonschema:"site ID; omit to use default"`
+		Target     string `json:"target"              jsonschema:"what to toggle: wifi_broadcast or firewall_policy"`
+		ResourceID string `json:"resource_id"         jsonschema:"WiFi broadcast ID or firewall policy ID"`
+		Enable     *bool  `json:"enable"              jsonschema:"true to enable the resource at each run, false to disable it"`
+		Cron       string `json:"cron"                jsonschema:"five-field cron expression, e.g. 0 22 * * * or 0 8 * * mon-fri"`
+		TimeZone   string `json:"time_zone,omitempty" jsonschema:"IANA time zone the cron expression is in, e.g. America/Toronto; defaults to the server's"`
+		Name       string `json:"name,omitempty"      jsonschema:"label for the rule, e.g. kids wifi off at night"`
+		Confirmed  bool   `json:"confirmed"           jsonschema:"must be true to confirm the schedule"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		if !input.Confirmed {
+			return errorResult(fmt.Errorf("create_toggle_schedule: set confirmed=true to confirm the schedule"))
+		}
+		if input.Enable == nil {
+			return errorResult(fmt.Errorf("create_toggle_schedule: enable is required"))
+		}
+		rule := toggles.Rule{
+			Name:       input.Name,
+			SiteID:     input.SiteID,
+			Target:     toggles.Target(input.Target),
+			ResourceID: strings.TrimSpace(input.ResourceID),
+			Enable:     *input.Enable,
+			Cron:       input.Cron,
+			TimeZone:   input.TimeZone,
+		}
+		if rule.TimeZone == "" {
+			rule.TimeZone = time.Local.String()
+		}
+		if _, _, err := rule.Validate(); err != nil {
+			return errorResult(fmt.Errorf("create_toggle_schedule: %w", err))
+		}
+		switch rule.Target {
+		case toggles.WiFiBroadcast:
+			bc, err := client.GetWiFiBroadcast(ctx, input.SiteID, rule.ResourceID)
+			if err != nil {
+				return errorResult(fmt.Errorf("create_toggle_schedule: %w", err))
+			}
+			rule.ResourceName = bc.Name
+		case toggles.FirewallPolicy:
+			policy, err := client.GetFirewallPolicy(ctx, input.SiteID, rule.ResourceID)
+			if err != nil {
+				return errorResult(fmt.Errorf("create_toggle_schedule: %w", err))
+			}
+			rule.ResourceName = policy.Name
+		}
+		rule, err := store.Add(rule, time.Now())
+		if err != nil {
+			return errorResult(fmt.Errorf("create_toggle_schedule: %w", err))
+		}
+		return jsonResult(localRule(rule))
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "list_toggle_schedules",
+		Description: "List toggle schedules with their next run, retry state and last outcome, plus the most recent runs " +
+			"(ok, retrying, failed or superseded). Give rule_id to see one rule's history.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(_ context.Context, _ *mcp.CallToolRequest, input struct {
+		RuleID       string `json:"rule_id,omitempty"       jsonschema:"only show this rule and its history"`
+		HistoryLimit int    `json:"history_limit,omitempty" jsonschema:"how many recent runs to include (default 20)"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		limit := input.HistoryLimit
+		if limit <= 0 {
+			limit = 20
+		}
+		var rules []toggles.Rule
+		if id := strings.TrimSpace(input.RuleID); id != "" {
+			rule, err := store.Get(id)
+			if err != nil && !errors.Is(err, toggles.ErrNotFound) {
+				return errorResult(fmt.Errorf("list_toggle_schedules: %w", err))
+			}
+			// A deleted rule has no entry but may still have history.
+			if err == nil {
+				rules = append(rules, rule)
+			}
+		} else {
+			rules = store.List()
+		}
+		for i := range rules {
+			rules[i] = localRule(rules[i])
+		}
+		history := store.History(strings.TrimSpace(input.RuleID), limit)
+		if history == nil {
+			history = []toggles.Run{}
+		}
+		return jsonResult(map[string]any{"rules": rules, "history": history})
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name:        "delete_toggle_schedule",
+		Description: "Delete a toggle schedule. The resource is left in whatever state the rule last set; its run history is kept.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, input struct {
+		RuleID string `json:"rule_id" jsonschema:"toggle schedule ID from list_toggle_schedules"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		id := strings.TrimSpace(input.RuleID)
+		if id == "" {
+			return errorResult(fmt.Errorf("delete_toggle_schedule: rule_id is required"))
+		}
+		rule, err := store.Delete(id)
+		if err != nil {
+			if errors.Is(err, toggles.ErrNotFound) {
+				return errorResult(fmt.Errorf("delete_toggle_schedule: no toggle schedule %s", id))
+			}
+			return errorResult(fmt.Errorf("delete_toggle_schedule: %w", err))
+		}
+		return jsonResult(localRule(rule))
+	})
+}
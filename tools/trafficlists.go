@@ -0,0 +1,232 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gordcurrie/unifi-mcp/internal/matchlist"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// matchlistKind maps a traffic matching list type onto the entry parser.
+func matchlistKind(listType string) (matchlist.Kind, error) {
+	switch strings.ToUpper(listType) {
+	case unifi.TrafficMatchingListTypeIP:
+		return matchlist.IP, nil
+	case unifi.TrafficMatchingListTypePort:
+		return matchlist.Port, nil
+	default:
+		return 0, fmt.Errorf("list type %q is not supported (use %s or %s)",
+			listType, unifi.TrafficMatchingListTypeIP, unifi.TrafficMatchingListTypePort)
+	}
+}
+
+// trafficListEditResult is returned by the entry add and remove tools.
+type trafficListEditResult struct {
+	List    unifi.TrafficMatchingList `json:"list"`
+	Changed bool                      `json:"changed"`
+}
+
+// registerTrafficMatchingListTools registers the traffic matching list write
+// tools. Entries are always normalized (see package matchlist) before they are
+// sent. Deleting is gated on allowDestructive like the other deletes, and is
+// refused while any firewall policy still references the list.
+func registerTrafficMatchingListTools(s *mcp.Server, client unifiClient, allowDestructive bool) {
+	destructiveTrue := true
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "create_traffic_matching_list",
+		Description: "Create a traffic matching list for firewall policies. IP lists take IPv4/IPv6 addresses, CIDRs and a-b ranges; " +
+			"PORT lists take ports and a-b ranges. Entries are de-duplicated and overlapping ones merged.",
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID  string `json:"site_id,omitempty" jsonschema:"site ID; omit to use default"`
+		Name    string `json:"name"              jsonschema:"list name"`
+		Type    string `json:"type"              jsonschema:"IP or PORT"`
+		Entries string `json:"entries"           jsonschema:"comma-separated entries, e.g. 10.0.0.0/8, 192.0.2.1-192.0.2.9 or 80, 8000-8080"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		if input.Name == "" {
+			return errorResult(fmt.Errorf("create_traffic_matching_list: name is required"))
+		}
+		kind, err := matchlistKind(input.Type)
+		if err != nil {
+			return errorResult(fmt.Errorf("create_traffic_matching_list: %w", err))
+		}
+		entries, err := matchlist.Normalize(kind, splitIDs(&input.Entries))
+		if err != nil {
+			return errorResult(fmt.Errorf("create_traffic_matching_list: %w", err))
+		}
+		if len(entries) == 0 {
+			return errorResult(fmt.Errorf("create_traffic_matching_list: entries is required"))
+		}
+		list, err := client.CreateTrafficMatchingList(ctx, input.SiteID, unifi.TrafficMatchingListRequest{
+			Name:    input.Name,
+			Type:    strings.ToUpper(input.Type),
+			Entries: entries,
+		})
+		if err != nil {
+			return errorResult(fmt.Errorf("create_traffic_matching_list: %w", err))
+		}
+		return jsonResult(list)
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "update_traffic_matching_list",
+		Description: "Rename a traffic matching list and/or replace all of its entries. Entries are normalized as in " +
+			"create_traffic_matching_list. Firewall policies using the list change immediately. Set confirmed=true to proceed.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID    string  `json:"site_id,omitempty" jsonschema:"site ID; omit to use default"`
+		ListID    string  `json:"list_id"           jsonschema:"traffic matching list ID"`
+		Name      *string `json:"name,omitempty"    jsonschema:"new list name"`
+		Entries   *string `json:"entries,omitempty" jsonschema:"comma-separated entries replacing the current ones; omit to keep them"`
+		Confirmed bool    `json:"confirmed"         jsonschema:"must be true to confirm the change"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		if !input.Confirmed {
+			return errorResult(fmt.Errorf("update_traffic_matching_list: set confirmed=true to confirm the change"))
+		}
+		if input.ListID == "" {
+			return errorResult(fmt.Errorf("update_traffic_matching_list: list_id is required"))
+		}
+		if input.Name == nil && input.Entries == nil {
+			return errorResult(fmt.Errorf("update_traffic_matching_list: no changes given"))
+		}
+		if input.Name != nil && *input.Name == "" {
+			return errorResult(fmt.Errorf("update_traffic_matching_list: name must not be empty"))
+		}
+		update := unifi.TrafficMatchingListUpdate{Name: input.Name}
+		if input.Entries != nil {
+			current, err := client.GetTrafficMatchingList(ctx, input.SiteID, input.ListID)
+			if err != nil {
+				return errorResult(fmt.Errorf("update_traffic_matching_list: %w", err))
+			}
+			kind, err := matchlistKind(current.Type)
+			if err != nil {
+				return errorResult(fmt.Errorf("update_traffic_matching_list: %w", err))
+			}
+			if update.Entries, err = matchlist.Normalize(kind, splitIDs(input.Entries)); err != nil {
+				return errorResult(fmt.Errorf("update_traffic_matching_list: %w", err))
+			}
+			if len(update.Entries) == 0 {
+				return errorResult(fmt.Errorf("update_traffic_matching_list: a list needs at least one entry; delete it instead"))
+			}
+		}
+		list, err := client.UpdateTrafficMatchingList(ctx, input.SiteID, input.ListID, update)
+		if err != nil {
+			return errorResult(fmt.Errorf("update_traffic_matching_list: %w", err))
+		}
+		return jsonResult(list)
+	})
+
+	type entriesInput struct {
+		SiteID    string `json:"site_id,omitempty" jsonschema:"site ID; omit to use default"`
+		ListID    string `json:"list_id"           jsonschema:"traffic matching list ID"`
+		Entries   string `json:"entries"           jsonschema:"comma-separated addresses, CIDRs, ranges or ports"`
+		Confirmed bool   `json:"confirmed"         jsonschema:"must be true to confirm the change"`
+	}
+
+	// editEntries applies edit to a list's entries and saves the result when it
+	// differs from what is stored.
+	editEntries := func(ctx context.Context, tool string, input *entriesInput,
+		edit func(kind matchlist.Kind, current, entries []string) ([]string, error),
+	) (*mcp.CallToolResult, any, error) {
+		if !input.Confirmed {
+			return errorResult(fmt.Errorf("%s: set confirmed=true to confirm the change", tool))
+		}
+		if input.ListID == "" {
+			return errorResult(fmt.Errorf("%s: list_id is required", tool))
+		}
+		entries := splitIDs(&input.Entries)
+		if len(entries) == 0 {
+			return errorResult(fmt.Errorf("%s: entries is required", tool))
+		}
+		current, err := client.GetTrafficMatchingList(ctx, input.SiteID, input.ListID)
+		if err != nil {
+			return errorResult(fmt.Errorf("%s: %w", tool, err))
+		}
+		kind, err := matchlistKind(current.Type)
+		if err != nil {
+			return errorResult(fmt.Errorf("%s: %w", tool, err))
+		}
+		next, err := edit(kind, current.Entries, entries)
+		if err != nil {
+			return errorResult(fmt.Errorf("%s: %w", tool, err))
+		}
+		if len(next) == 0 {
+			return errorResult(fmt.Errorf("%s: that would leave the list empty; delete it instead", tool))
+		}
+		if slices.Equal(next, current.Entries) {
+			return jsonResult(trafficListEditResult{List: current})
+		}
+		list, err := client.UpdateTrafficMatchingList(ctx, input.SiteID, input.ListID, unifi.TrafficMatchingListUpdate{Entries: next})
+		if err != nil {
+			return errorResult(fmt.Errorf("%s: %w", tool, err))
+		}
+		return jsonResult(trafficListEditResult{List: list, Changed: true})
+	}
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "add_traffic_matching_list_entries",
+		Description: "Add entries to a traffic matching list. The merged list is de-duplicated and overlapping or adjacent " +
+			"entries are collapsed. Firewall policies using the list change immediately. Set confirmed=true to proceed.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input entriesInput) (*mcp.CallToolResult, any, error) {
+		return editEntries(ctx, "add_traffic_matching_list_entries", &input, matchlist.Add)
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "remove_traffic_matching_list_entries",
+		Description: "Remove addresses or ports from a traffic matching list. Removing part of a CIDR or range splits it, so " +
+			"e.g. removing 10.0.0.10 from 10.0.0.0/24 leaves two ranges. Set confirmed=true to proceed.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input entriesInput) (*mcp.CallToolResult, any, error) {
+		return editEntries(ctx, "remove_traffic_matching_list_entries", &input, matchlist.Remove)
+	})
+
+	if !allowDestructive {
+		return
+	}
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "delete_traffic_matching_list",
+		Description: "Permanently delete a traffic matching list by ID. Refused while any firewall policy references the list. " +
+			"Requires UNIFI_ALLOW_DESTRUCTIVE=true. Set confirmed=true to proceed.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID    string `json:"site_id,omitempty" jsonschema:"site ID; omit to use default"`
+		ListID    string `json:"list_id"           jsonschema:"traffic matching list ID"`
+		Confirmed bool   `json:"confirmed"         jsonschema:"must be true to confirm the deletion"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		if !input.Confirmed {
+			return errorResult(fmt.Errorf("delete_traffic_matching_list: set confirmed=true to confirm the deletion"))
+		}
+		if input.ListID == "" {
+			return errorResult(fmt.Errorf("delete_traffic_matching_list: list_id is required"))
+		}
+		policies, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.FirewallPolicy], error) {
+			return client.ListFirewallPolicies(ctx, input.SiteID, offset, limit)
+		})
+		if err != nil {
+			return errorResult(fmt.Errorf("delete_traffic_matching_list: check firewall policies: %w", err))
+		}
+		var users []string
+		for i := range policies {
+			if slices.Contains(policies[i].TrafficMatchingListIDs(), input.ListID) {
+				users = append(users, fmt.Sprintf("%s (%s)", policies[i].Name, policies[i].ID))
+			}
+		}
+		if len(users) > 0 {
+			return errorResult(fmt.Errorf("delete_traffic_matching_list: list %s is referenced by firewall policies %s; change or delete them first",
+				input.ListID, strings.Join(users, ", ")))
+		}
+		if err := client.DeleteTrafficMatchingList(ctx, input.SiteID, input.ListID); err != nil {
+			return errorResult(fmt.Errorf("delete_traffic_matching_list: %w", err))
+		}
+		return textResult(fmt.Sprintf("Traffic matching list %s deleted", input.ListID))
+	})
+}
@@ -0,0 +1,299 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gordcurrie/unifi-mcp/internal/ipplan"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// registerVLANTools registers create_network, update_network and
+// delete_network. Changing a network's addressing can cut off every client on
+// it, so like the ACL writes these are only registered when allowDestructive
+// is set, and every change is checked against the site's IP plan first.
+func registerVLANTools(s *mcp.Server, client unifiClient, allowDestructive bool) {
+	if !allowDestructive {
+		return
+	}
+	destructiveTrue := true
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "create_network",
+		Description: "Create a gateway-managed network (VLAN) with a subnet, gateway address, and optional DHCP range. " +
+			"Refused if the subnet overlaps another network, the VLAN ID is taken, or the DHCP range is not inside the subnet. " +
+			"Requires UNIFI_ALLOW_DESTRUCTIVE=true. Set confirmed=true to proceed.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID       string `json:"site_id,omitempty"            jsonschema:"site ID; omit to use default"`
+		Name         string `json:"name"                         jsonschema:"network name"`
+		VLANID       int    `json:"vlan_id"                      jsonschema:"VLAN ID (2-4094)"`
+		Gateway      string `json:"gateway"                      jsonschema:"gateway IPv4 address on the network, e.g. 192.168.30.1"`
+		PrefixLength int    `json:"prefix_length"                jsonschema:"subnet prefix length (8-30), e.g. 24"`
+		DHCPStart    string `json:"dhcp_start,omitempty"         jsonschema:"first DHCP address; omit with dhcp_stop for no DHCP server"`
+		DHCPStop     string `json:"dhcp_stop,omitempty"          jsonschema:"last DHCP address"`
+		LeaseSeconds int    `json:"lease_time_seconds,omitempty" jsonschema:"DHCP lease time in seconds; omit for the controller default"`
+		Enabled      *bool  `json:"enabled,omitempty"            jsonschema:"default true"`
+		Confirmed    bool   `json:"confirmed"                    jsonschema:"must be true to confirm the change"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		if !input.Confirmed {
+			return errorResult(fmt.Errorf("create_network: set confirmed=true to confirm the change"))
+		}
+		if input.Name == "" {
+			return errorResult(fmt.Errorf("create_network: name is required"))
+		}
+		if input.VLANID == 0 {
+			return errorResult(fmt.Errorf("create_network: vlan_id is required"))
+		}
+		if input.Gateway == "" || input.PrefixLength == 0 {
+			return errorResult(fmt.Errorf("create_network: gateway and prefix_length are required"))
+		}
+		candidate, err := ipplan.Parse("", input.Name, input.VLANID, input.Gateway, input.PrefixLength, input.DHCPStart, input.DHCPStop)
+		if err != nil {
+			return errorResult(fmt.Errorf("create_network: %w", err))
+		}
+		plan, _, err := loadIPPlan(ctx, client, input.SiteID)
+		if err != nil {
+			return errorResult(fmt.Errorf("create_network: %w", err))
+		}
+		if err := ipplan.Check(candidate, plan); err != nil {
+			return errorResult(fmt.Errorf("create_network: %s", indentErrors(err)))
+		}
+
+		dhcp := &unifi.NetworkDHCPConfiguration{Mode: "NONE"}
+		if candidate.DHCPStart.IsValid() {
+			dhcp = &unifi.NetworkDHCPConfiguration{
+				Mode:             "SERVER",
+				IPAddressRange:   &unifi.IPAddressRange{Start: candidate.DHCPStart.String(), Stop: candidate.DHCPStop.String()},
+				LeaseTimeSeconds: input.LeaseSeconds,
+			}
+		}
+		network, err := client.CreateNetwork(ctx, input.SiteID, unifi.NetworkRequest{
+			Management: "GATEWAY",
+			Name:       input.Name,
+			Enabled:    input.Enabled == nil || *input.Enabled,
+			VLANID:     input.VLANID,
+			IPv4Configuration: &unifi.NetworkIPv4Configuration{
+				HostIPAddress:     candidate.Gateway.String(),
+				PrefixLength:      input.PrefixLength,
+				DHCPConfiguration: dhcp,
+			},
+		})
+		if err != nil {
+			return errorResult(fmt.Errorf("create_network: %w", err))
+		}
+		return jsonResult(network)
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "update_network",
+		Description: "Change a network's name, VLAN ID, gateway address, prefix length, or DHCP range. Omitted fields and settings this " +
+			"server does not model are kept. The result is checked against every other network first; the default (management) " +
+			"network cannot be changed. Requires UNIFI_ALLOW_DESTRUCTIVE=true. Set confirmed=true to proceed.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID       string  `json:"site_id,omitempty"            jsonschema:"site ID; omit to use default"`
+		NetworkID    string  `json:"network_id"                   jsonschema:"network ID"`
+		Name         *string `json:"name,omitempty"               jsonschema:"new network name"`
+		Enabled      *bool   `json:"enabled,omitempty"            jsonschema:"enable or disable the network"`
+		VLANID       *int    `json:"vlan_id,omitempty"            jsonschema:"new VLAN ID (2-4094)"`
+		Gateway      *string `json:"gateway,omitempty"            jsonschema:"new gateway IPv4 address"`
+		PrefixLength *int    `json:"prefix_length,omitempty"      jsonschema:"new subnet prefix length (8-30)"`
+		DHCPEnabled  *bool   `json:"dhcp_enabled,omitempty"       jsonschema:"false turns the DHCP server off; true needs a DHCP range"`
+		DHCPStart    *string `json:"dhcp_start,omitempty"         jsonschema:"new first DHCP address; set together with dhcp_stop"`
+		DHCPStop     *string `json:"dhcp_stop,omitempty"          jsonschema:"new last DHCP address"`
+		LeaseSeconds *int    `json:"lease_time_seconds,omitempty" jsonschema:"new DHCP lease time in seconds"`
+		Confirmed    bool    `json:"confirmed"                    jsonschema:"must be true to confirm the change"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		if !input.Confirmed {
+			return errorResult(fmt.Errorf("update_network: set confirmed=true to confirm the change"))
+		}
+		if input.NetworkID == "" {
+			return errorResult(fmt.Errorf("update_network: network_id is required"))
+		}
+		if (input.DHCPStart == nil) != (input.DHCPStop == nil) {
+			return errorResult(fmt.Errorf("update_network: dhcp_start and dhcp_stop must be set together"))
+		}
+		plan, networks, err := loadIPPlan(ctx, client, input.SiteID)
+		if err != nil {
+			return errorResult(fmt.Errorf("update_network: %w", err))
+		}
+		var current *unifi.NetworkConf
+		for i := range networks {
+			if networks[i].ID == input.NetworkID {
+				current = &networks[i]
+			}
+		}
+		if current == nil {
+			return errorResult(fmt.Errorf("update_network: network %s not found", input.NetworkID))
+		}
+		if current.Default {
+			return errorResult(fmt.Errorf("update_network: %s is the default (management) network and cannot be changed here", current.Name))
+		}
+
+		// Overlay the requested changes on the current values to get the
+		// network as it will be, and check that against the rest of the plan.
+		name, vlan := current.Name, current.VLANID
+		var gateway, dhcpMode, start, stop string
+		var prefix int
+		if ip := current.IPv4Configuration; ip != nil {
+			gateway, prefix = ip.HostIPAddress, ip.PrefixLength
+			if d := ip.DHCPConfiguration; d != nil {
+				dhcpMode = d.Mode
+				if d.IPAddressRange != nil {
+					start, stop = d.IPAddressRange.Start, d.IPAddressRange.Stop
+				}
+			}
+		}
+		update := unifi.NetworkUpdate{Name: input.Name, Enabled: input.Enabled, VLANID: input.VLANID}
+		var ipv4 unifi.NetworkIPv4Configuration
+		var dhcp unifi.NetworkDHCPConfiguration
+		if input.Name != nil {
+			name = *input.Name
+		}
+		if input.VLANID != nil {
+			vlan = *input.VLANID
+		}
+		if input.Gateway != nil {
+			gateway = strings.TrimSpace(*input.Gateway)
+			ipv4.HostIPAddress = gateway
+		}
+		if input.PrefixLength != nil {
+			prefix = *input.PrefixLength
+			ipv4.PrefixLength = prefix
+		}
+		if input.DHCPStart != nil {
+			start, stop = strings.TrimSpace(*input.DHCPStart), strings.TrimSpace(*input.DHCPStop)
+			dhcp.IPAddressRange = &unifi.IPAddressRange{Start: start, Stop: stop}
+			if input.DHCPEnabled == nil && dhcpMode != "SERVER" {
+				dhcpMode = "SERVER"
+				dhcp.Mode = dhcpMode
+			}
+		}
+		if input.DHCPEnabled != nil {
+			dhcpMode = "NONE"
+			if *input.DHCPEnabled {
+				dhcpMode = "SERVER"
+			}
+			dhcp.Mode = dhcpMode
+		}
+		if input.LeaseSeconds != nil {
+			dhcp.LeaseTimeSeconds = *input.LeaseSeconds
+		}
+		if dhcp != (unifi.NetworkDHCPConfiguration{}) {
+			ipv4.DHCPConfiguration = &dhcp
+		}
+		if ipv4 != (unifi.NetworkIPv4Configuration{}) {
+			update.IPv4Configuration = &ipv4
+		}
+		if update == (unifi.NetworkUpdate{}) {
+			return errorResult(fmt.Errorf("update_network: no changes given"))
+		}
+
+		if dhcpMode != "SERVER" {
+			start, stop = "", ""
+		} else if start == "" {
+			return errorResult(fmt.Errorf("update_network: enabling DHCP needs dhcp_start and dhcp_stop"))
+		}
+		candidate, err := ipplan.Parse(current.ID, name, vlan, gateway, prefix, start, stop)
+		if err != nil {
+			return errorResult(fmt.Errorf("update_network: %w", err))
+		}
+		if err := ipplan.Check(candidate, plan); err != nil {
+			return errorResult(fmt.Errorf("update_network: %s", indentErrors(err)))
+		}
+
+		network, err := client.UpdateNetwork(ctx, input.SiteID, input.NetworkID, update)
+		if err != nil {
+			return errorResult(fmt.Errorf("update_network: %w", err))
+		}
+		return jsonResult(network)
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "delete_network",
+		Description: "Permanently delete a network (VLAN) by ID. The default (management) network cannot be deleted. " +
+			"Requires UNIFI_ALLOW_DESTRUCTIVE=true. Set confirmed=true to proceed.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID    string `json:"site_id,omitempty" jsonschema:"site ID; omit to use default"`
+		NetworkID string `json:"network_id"        jsonschema:"network ID"`
+		Confirmed bool   `json:"confirmed"         jsonschema:"must be true to confirm the deletion"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		if !input.Confirmed {
+			return errorResult(fmt.Errorf("delete_network: set confirmed=true to confirm the deletion"))
+		}
+		if input.NetworkID == "" {
+			return errorResult(fmt.Errorf("delete_network: network_id is required"))
+		}
+		current, err := client.GetNetwork(ctx, input.SiteID, input.NetworkID)
+		if err != nil {
+			return errorResult(fmt.Errorf("delete_network: %w", err))
+		}
+		if current.Default {
+			return errorResult(fmt.Errorf("delete_network: %s is the default (management) network and cannot be deleted", current.Name))
+		}
+		if err := client.DeleteNetwork(ctx, input.SiteID, input.NetworkID); err != nil {
+			return errorResult(fmt.Errorf("delete_network: %w", err))
+		}
+		return textResult(fmt.Sprintf("Network %s (%s) deleted", current.Name, input.NetworkID))
+	})
+}
+
+// loadIPPlan returns every network on the site, with IPv4 configuration, both
+// as returned by the controller and converted for ipplan.Check. Networks the
+// list response carries without addressing are fetched individually.
+func loadIPPlan(ctx context.Context, client unifiClient, siteID string) ([]ipplan.Network, []unifi.NetworkConf, error) {
+	networks, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.NetworkConf], error) {
+		return client.ListNetworks(ctx, siteID, offset, limit)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	plan := make([]ipplan.Network, 0, len(networks))
+	for i := range networks {
+		if networks[i].IPv4Configuration == nil && networks[i].Management == "GATEWAY" {
+			full, err := client.GetNetwork(ctx, siteID, networks[i].ID)
+			if err != nil {
+				return nil, nil, err
+			}
+			networks[i] = full
+		}
+		n := &networks[i]
+		p, err := planNetwork(n)
+		if err != nil {
+			// An existing network the controller accepted but we cannot
+			// parse still takes part in the VLAN checks.
+			p = ipplan.Network{ID: n.ID, Name: n.Name, VLANID: n.VLANID}
+		}
+		plan = append(plan, p)
+	}
+	return plan, networks, nil
+}
+
+// planNetwork converts a network's addressing for ipplan.
+func planNetwork(n *unifi.NetworkConf) (ipplan.Network, error) {
+	var gateway, start, stop string
+	var prefix int
+	if ip := n.IPv4Configuration; ip != nil {
+		gateway, prefix = ip.HostIPAddress, ip.PrefixLength
+		if d := ip.DHCPConfiguration; d != nil && d.Mode == "SERVER" && d.IPAddressRange != nil {
+			start, stop = d.IPAddressRange.Start, d.IPAddressRange.Stop
+		}
+	}
+	return ipplan.Parse(n.ID, n.Name, n.VLANID, gateway, prefix, start, stop)
+}
+
+// indentErrors renders a joined error one problem per line.
+func indentErrors(err error) string {
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) == 1 {
+		return lines[0]
+	}
+	return "the IP plan has conflicts:\n  - " + strings.Join(lines, "\n  - ")
+}
@@ -0,0 +1,308 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+	"github.com/gordcurrie/unifi-mcp/internal/voucherprint"
+	"github.com/gordcurrie/unifi-mcp/internal/vouchers"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxVoucherExport caps the vouchers export_vouchers renders at once.
+const maxVoucherExport = 500
+
+// selectVouchers returns the site's vouchers picked by ids or, without ids,
+// by name and status (both case-insensitive; empty matches all). Expired
+// vouchers are only picked by name and status when includeExpired is set.
+func selectVouchers(ctx context.Context, client unifiClient, siteID string, ids []string, name, status string, includeExpired bool) ([]unifi.Voucher, error) {
+	all, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.Voucher], error) {
+		return client.ListVouchers(ctx, siteID, offset, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 {
+		byID := make(map[string]unifi.Voucher, len(all))
+		for _, v := range all {
+			byID[v.ID] = v
+		}
+		out := make([]unifi.Voucher, 0, len(ids))
+		var missing []string
+		for _, id := range ids {
+			v, ok := byID[id]
+			if !ok {
+				missing = append(missing, id)
+				continue
+			}
+			out = append(out, v)
+		}
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("vouchers not found: %s", strings.Join(missing, ", "))
+		}
+		return out, nil
+	}
+	var out []unifi.Voucher
+	for _, v := range all {
+		if (v.Expired && !includeExpired) ||
+			(name != "" && !strings.EqualFold(v.Name, name)) ||
+			(status != "" && !strings.EqualFold(v.Status, status)) {
+			continue
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// voucherPurgeResult is one voucher purge_vouchers selected.
+type voucherPurgeResult struct {
+	ID      string `json:"id"`
+	Code    string `json:"code,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Reason  string `json:"reason"`
+	Deleted bool   `json:"deleted,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// guestWiFi fills in the security type, passphrase and hidden flag of the
+// WiFi broadcast named sheet.SSID, so the wifi QR code joins it as configured.
+func guestWiFi(ctx context.Context, client unifiClient, siteID string, sheet *voucherprint.Sheet) error {
+	broadcasts, err := listAll(ctx, func(ctx context.Context, offset, limit int) (unifi.Page[unifi.WiFiBroadcast], error) {
+		return client.ListWiFiBroadcasts(ctx, siteID, offset, limit)
+	})
+	if err != nil {
+		return err
+	}
+	var match []unifi.WiFiBroadcast
+	for i := range broadcasts {
+		if broadcasts[i].Name == sheet.SSID {
+			match = append(match, broadcasts[i])
+		}
+	}
+	switch {
+	case len(match) == 0:
+		return fmt.Errorf("no WiFi broadcast is named %q; use qr=code for another network", sheet.SSID)
+	case len(match) > 1:
+		return fmt.Errorf("%d WiFi broadcasts are named %q; use qr=code", len(match), sheet.SSID)
+	}
+	bc := match[0]
+	if bc.SecurityConfiguration == nil || bc.SecurityConfiguration.Type == "" {
+		return fmt.Errorf("the controller did not return the security type of %s", bc.Name)
+	}
+	sheet.Security = bc.SecurityConfiguration.Type
+	sheet.Hidden = bc.HideName != nil && *bc.HideName
+	if strings.EqualFold(sheet.Security, "OPEN") {
+		return nil
+	}
+	// The typed model never carries the passphrase, so read it from the raw object.
+	raw, err := client.GetRaw(ctx, siteID, unifi.CollectionWiFiBroadcasts, bc.ID)
+	if err != nil {
+		return err
+	}
+	var body struct {
+		SecurityConfiguration struct {
+			Passphrase string `json:"passphrase"`
+		} `json:"securityConfiguration"`
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return fmt.Errorf("decode %s: %w", bc.Name, err)
+	}
+	if body.SecurityConfiguration.Passphrase == "" {
+		return fmt.Errorf("the controller did not return the passphrase of %s (%s); use qr=code", bc.Name, sheet.Security)
+	}
+	sheet.Passphrase = body.SecurityConfiguration.Passphrase
+	return nil
+}
+
+// registerVoucherTools registers the voucher printing and housekeeping tools.
+// purge_vouchers is only registered when allowDestructive is set.
+func registerVoucherTools(s *mcp.Server, client unifiClient, allowDestructive bool) {
+	destructiveTrue := true
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "export_vouchers",
+		Description: "Render hotspot vouchers as a printable HTML sheet of cut-out cards and/or CSV, returned as embedded " +
+			"resources. Pick vouchers by voucher_ids (e.g. the IDs create_vouchers returned) or by name and status. Cards " +
+			"show the site name, SSID, duration, data limit, code and a QR code; choose which with fields. The QR code joins " +
+			"the WiFi broadcast named ssid with its security type and passphrase (qr=wifi, the default when ssid is given) or " +
+			"holds the voucher code (qr=code). CSV cells that a spreadsheet would run as a formula are prefixed with '.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID         string  `json:"site_id,omitempty"         jsonschema:"site ID; omit to use default"`
+		VoucherIDs     *string `json:"voucher_ids,omitempty"     jsonschema:"comma-separated voucher IDs; when given, name and status are ignored"`
+		Name           string  `json:"name,omitempty"            jsonschema:"only vouchers with this name (label)"`
+		Status         string  `json:"status,omitempty"          jsonschema:"only vouchers with this status"`
+		IncludeExpired bool    `json:"include_expired,omitempty" jsonschema:"also pick expired vouchers by name and status"`
+		Format         string  `json:"format,omitempty"          jsonschema:"html, csv or both (default both)"`
+		Title          string  `json:"title,omitempty"           jsonschema:"heading printed at the top of the HTML sheet"`
+		SiteName       string  `json:"site_name,omitempty"       jsonschema:"site name printed on cards; omit to use the site's name"`
+		SSID           string  `json:"ssid,omitempty"            jsonschema:"guest network name printed on cards and joined by the QR code"`
+		Note           string  `json:"note,omitempty"            jsonschema:"short text printed on every card, e.g. Valid from first use"`
+		Columns        int     `json:"columns,omitempty"         jsonschema:"cards per row, 1-6 (default 3)"`
+		Fields         *string `json:"fields,omitempty"          jsonschema:"comma-separated card fields: site, ssid, duration, data_limit, code, qr (default all)"`
+		QR             string  `json:"qr,omitempty"              jsonschema:"QR code content: wifi or code"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		format := strings.ToLower(cmp.Or(input.Format, "both"))
+		if !slices.Contains([]string{"html", "csv", "both"}, format) {
+			return errorResult(fmt.Errorf("export_vouchers: format must be html, csv or both"))
+		}
+		fields, err := voucherprint.ParseFields(splitIDs(input.Fields))
+		if err != nil {
+			return errorResult(fmt.Errorf("export_vouchers: %w", err))
+		}
+		qr, err := voucherprint.ParseQR(input.QR, input.SSID)
+		if err != nil {
+			return errorResult(fmt.Errorf("export_vouchers: %w", err))
+		}
+		if input.Columns < 0 || input.Columns > voucherprint.MaxColumns {
+			return errorResult(fmt.Errorf("export_vouchers: columns must be 1-%d", voucherprint.MaxColumns))
+		}
+
+		picked, err := selectVouchers(ctx, client, input.SiteID, splitIDs(input.VoucherIDs), input.Name, input.Status, input.IncludeExpired)
+		if err != nil {
+			return errorResult(fmt.Errorf("export_vouchers: %w", err))
+		}
+		if len(picked) == 0 {
+			return errorResult(fmt.Errorf("export_vouchers: no vouchers match"))
+		}
+		if len(picked) > maxVoucherExport {
+			return errorResult(fmt.Errorf("export_vouchers: %d vouchers match; export at most %d at a time", len(picked), maxVoucherExport))
+		}
+		siteName := input.SiteName
+		if siteName == "" && slices.Contains(fields, voucherprint.FieldSite) {
+			site, err := client.GetSite(ctx, input.SiteID)
+			if err != nil {
+				return errorResult(fmt.Errorf("export_vouchers: %w", err))
+			}
+			siteName = site.Name
+		}
+		sheet := voucherprint.Sheet{
+			Title: input.Title, SiteName: siteName, SSID: input.SSID, Note: input.Note,
+			Columns: input.Columns, Fields: fields, QR: qr,
+		}
+		if qr == voucherprint.QRWiFi && slices.Contains(fields, voucherprint.FieldQR) && format != "csv" {
+			if err := guestWiFi(ctx, client, input.SiteID, &sheet); err != nil {
+				return errorResult(fmt.Errorf("export_vouchers: %w", err))
+			}
+		}
+
+		summary, err := json.Marshal(struct {
+			Vouchers int    `json:"vouchers"`
+			Format   string `json:"format"`
+		}{len(picked), format})
+		if err != nil {
+			return errorResult(fmt.Errorf("export_vouchers: marshal result: %w", err))
+		}
+		content := []mcp.Content{&mcp.TextContent{Text: string(summary)}}
+		if format != "csv" {
+			var b strings.Builder
+			if err := voucherprint.HTML(&b, sheet, picked); err != nil {
+				return errorResult(fmt.Errorf("export_vouchers: %w", err))
+			}
+			content = append(content, &mcp.EmbeddedResource{Resource: &mcp.ResourceContents{
+				URI: "unifi://vouchers/vouchers.html", MIMEType: "text/html", Text: b.String(),
+			}})
+		}
+		if format != "html" {
+			var b strings.Builder
+			if err := voucherprint.CSV(&b, sheet, picked); err != nil {
+				return errorResult(fmt.Errorf("export_vouchers: %w", err))
+			}
+			content = append(content, &mcp.EmbeddedResource{Resource: &mcp.ResourceContents{
+				URI: "unifi://vouchers/vouchers.csv", MIMEType: "text/csv", Text: b.String(),
+			}})
+		}
+		return &mcp.CallToolResult{Content: content}, nil, nil
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "voucher_report",
+		Description: "Summarize the site's hotspot vouchers: counts by state (unused, active, used_up, expired) and by " +
+			"controller status, and per batch (vouchers sharing a name) the usage against quota, authorized guests and the " +
+			"next expiry. With include_vouchers=true every voucher is listed with its state and time remaining.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID          string `json:"site_id,omitempty"          jsonschema:"site ID; omit to use default"`
+		Name            string `json:"name,omitempty"             jsonschema:"only report the batch with this name"`
+		IncludeVouchers bool   `json:"include_vouchers,omitempty" jsonschema:"also list every voucher"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		all, err := selectVouchers(ctx, client, input.SiteID, nil, input.Name, "", true)
+		if err != nil {
+			return errorResult(fmt.Errorf("voucher_report: %w", err))
+		}
+		return jsonResult(vouchers.Summarize(all, time.Now(), input.IncludeVouchers))
+	})
+
+	if allowDestructive {
+		mcp.AddTool(s, &mcp.Tool{
+			Name: "purge_vouchers",
+			Description: "Delete hotspot vouchers that are expired (expired=true), have reached their usage quota and no longer serve a guest (used=true) " +
+				"or were never used and are older than unused_older_than_days. At least one criterion is required; name " +
+				"limits the purge to one batch. Without confirmed=true the selected vouchers are only listed. Requires " +
+				"UNIFI_ALLOW_DESTRUCTIVE=true.",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+		}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+			SiteID              string `json:"site_id,omitempty"               jsonschema:"site ID; omit to use default"`
+			Expired             bool   `json:"expired,omitempty"               jsonschema:"select expired vouchers"`
+			Used                bool   `json:"used,omitempty"                  jsonschema:"select vouchers whose usage quota is reached, except those still running for authorized guests"`
+			UnusedOlderThanDays int    `json:"unused_older_than_days,omitempty" jsonschema:"select never-used vouchers created at least this many days ago"`
+			Name                string `json:"name,omitempty"                  jsonschema:"only purge vouchers with this name"`
+			Confirmed           bool   `json:"confirmed"                       jsonschema:"true to delete the selected vouchers; false or omitted lists them only"`
+		},
+		) (*mcp.CallToolResult, any, error) {
+			if input.UnusedOlderThanDays < 0 {
+				return errorResult(fmt.Errorf("purge_vouchers: unused_older_than_days must not be negative"))
+			}
+			rule := vouchers.PurgeRule{
+				Expired:         input.Expired,
+				UsedUp:          input.Used,
+				UnusedOlderThan: time.Duration(input.UnusedOlderThanDays) * 24 * time.Hour,
+			}
+			if rule.IsZero() {
+				return errorResult(fmt.Errorf("purge_vouchers: set expired, used or unused_older_than_days"))
+			}
+			all, err := selectVouchers(ctx, client, input.SiteID, nil, input.Name, "", true)
+			if err != nil {
+				return errorResult(fmt.Errorf("purge_vouchers: %w", err))
+			}
+			now := time.Now()
+			selected := []voucherPurgeResult{}
+			for i := range all {
+				if reason, ok := rule.Match(&all[i], now); ok {
+					selected = append(selected, voucherPurgeResult{ID: all[i].ID, Code: all[i].Code, Name: all[i].Name, Reason: reason})
+				}
+			}
+			report := struct {
+				Selected int                  `json:"selected"`
+				Applied  bool                 `json:"applied"`
+				Deleted  int                  `json:"deleted"`
+				Failed   int                  `json:"failed,omitempty"`
+				Vouchers []voucherPurgeResult `json:"vouchers"`
+			}{Selected: len(selected), Vouchers: selected}
+			if !input.Confirmed {
+				return jsonResult(report)
+			}
+			for i := range selected {
+				if err := client.DeleteVoucher(ctx, input.SiteID, selected[i].ID); err != nil {
+					selected[i].Error = err.Error()
+					report.Failed++
+					continue
+				}
+				selected[i].Deleted = true
+				report.Deleted++
+			}
+			report.Applied = true
+			res, _, _ := jsonResult(report)
+			res.IsError = report.Failed > 0
+			return res, nil, nil
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+	"github.com/gordcurrie/unifi-mcp/internal/voucherprint"
+)
+
+func TestGuestWiFi(t *testing.T) {
+	hidden := true
+	fake := &fakeClient{
+		broadcasts: []unifi.WiFiBroadcast{
+			{ID: "w-1", Name: "Guest", SecurityConfiguration: &unifi.WiFiSecurityConfiguration{Type: "WPA2_PERSONAL"}, HideName: &hidden},
+			{ID: "w-2", Name: "Lobby", SecurityConfiguration: &unifi.WiFiSecurityConfiguration{Type: "OPEN"}},
+			{ID: "w-3", Name: "Twin", SecurityConfiguration: &unifi.WiFiSecurityConfiguration{Type: "OPEN"}},
+			{ID: "w-4", Name: "Twin", SecurityConfiguration: &unifi.WiFiSecurityConfiguration{Type: "OPEN"}},
+			{ID: "w-5", Name: "Keyless", SecurityConfiguration: &unifi.WiFiSecurityConfiguration{Type: "WPA3_PERSONAL"}},
+		},
+		raw: map[string]json.RawMessage{
+			unifi.CollectionWiFiBroadcasts + "/w-1": json.RawMessage(`{"securityConfiguration":{"type":"WPA2_PERSONAL","passphrase":"guest-pass"}}`),
+			unifi.CollectionWiFiBroadcasts + "/w-5": json.RawMessage(`{"securityConfiguration":{"type":"WPA3_PERSONAL"}}`),
+		},
+	}
+	tests := []struct {
+		ssid    string
+		want    voucherprint.Sheet
+		wantErr string
+	}{
+		{ssid: "Guest", want: voucherprint.Sheet{SSID: "Guest", Security: "WPA2_PERSONAL", Passphrase: "guest-pass", Hidden: true}},
+		{ssid: "Lobby", want: voucherprint.Sheet{SSID: "Lobby", Security: "OPEN"}},
+		{ssid: "Cafe", wantErr: "no WiFi broadcast is named"},
+		{ssid: "Twin", wantErr: "2 WiFi broadcasts"},
+		{ssid: "Keyless", wantErr: "did not return the passphrase"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.ssid, func(t *testing.T) {
+			sheet := voucherprint.Sheet{SSID: tc.ssid}
+			err := guestWiFi(context.Background(), fake, "", &sheet)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("got %v, want error containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sheet.Security != tc.want.Security || sheet.Passphrase != tc.want.Passphrase || sheet.Hidden != tc.want.Hidden {
+				t.Errorf("got %+v, want %+v", sheet, tc.want)
+			}
+		})
+	}
+}
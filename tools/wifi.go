@@ -0,0 +1,365 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/gordcurrie/unifi-mcp/internal/passphrase"
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+	"github.com/gordcurrie/unifi-mcp/internal/wifiqr"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// wifiSecurityTypes are the security types create_wifi_broadcast and
+// update_wifi_broadcast accept. Enterprise types need a RADIUS profile and are
+// left to the UI.
+var wifiSecurityTypes = []string{"OPEN", "WPA2_PERSONAL", "WPA2_WPA3_PERSONAL", "WPA3_PERSONAL"}
+
+// wifiPMFModes are the accepted protected-management-frames modes.
+var wifiPMFModes = []string{"DISABLED", "OPTIONAL", "REQUIRED"}
+
+// wifiSecurityInput is the security part of the WiFi write tools. Passphrase is
+// write-only: it is converted to unifi.SensitiveString before use and never
+// appears in a tool result or error.
+type wifiSecurityInput struct {
+	SecurityType string `json:"security_type,omitempty" jsonschema:"OPEN, WPA2_PERSONAL, WPA2_WPA3_PERSONAL or WPA3_PERSONAL"`
+	Passphrase   string `json:"passphrase,omitempty"    jsonschema:"WPA passphrase, 8-63 characters; write-only and never returned"`
+	PMFMode      string `json:"pmf_mode,omitempty"      jsonschema:"protected management frames: DISABLED, OPTIONAL or REQUIRED"`
+	FastRoaming  *bool  `json:"fast_roaming,omitempty"  jsonschema:"enable 802.11r fast roaming"`
+}
+
+// request validates each field of the input and returns the security block,
+// or nil when no security field was set. How the fields combine with the
+// broadcast's current security is checked by wifiSecurityChange.
+func (in *wifiSecurityInput) request() (*unifi.WiFiSecurityRequest, error) {
+	if in.SecurityType == "" && in.Passphrase == "" && in.PMFMode == "" && in.FastRoaming == nil {
+		return nil, nil
+	}
+	secType := strings.ToUpper(in.SecurityType)
+	if secType != "" && !slices.Contains(wifiSecurityTypes, secType) {
+		return nil, fmt.Errorf("security_type must be one of %s", strings.Join(wifiSecurityTypes, ", "))
+	}
+	pmf := strings.ToUpper(in.PMFMode)
+	if pmf != "" && !slices.Contains(wifiPMFModes, pmf) {
+		return nil, fmt.Errorf("pmf_mode must be one of %s", strings.Join(wifiPMFModes, ", "))
+	}
+	if in.Passphrase != "" {
+		if err := validatePassphrase(in.Passphrase); err != nil {
+			return nil, err
+		}
+	}
+	return &unifi.WiFiSecurityRequest{
+		Type:               secType,
+		Passphrase:         unifi.SensitiveString(in.Passphrase),
+		FastRoamingEnabled: in.FastRoaming,
+		PMFMode:            pmf,
+	}, nil
+}
+
+// wifiSecurityChange is a WiFi broadcast's security before and after a write.
+// The from fields are empty for a new broadcast or when the controller did
+// not report them.
+type wifiSecurityChange struct {
+	fromType, fromPMF string
+	toType, toPMF     string
+	// newPassphrase is set when the write supplies a passphrase.
+	newPassphrase bool
+}
+
+// newWiFiSecurityChange describes applying sec to a broadcast whose current
+// security is cur (nil when creating one).
+func newWiFiSecurityChange(cur *unifi.WiFiSecurityConfiguration, sec *unifi.WiFiSecurityRequest) wifiSecurityChange {
+	var c wifiSecurityChange
+	if cur != nil {
+		c.fromType, c.fromPMF = cur.Type, cur.PMFMode
+	}
+	c.toType, c.toPMF = cmp.Or(sec.Type, c.fromType), cmp.Or(sec.PMFMode, c.fromPMF)
+	c.newPassphrase = sec.Passphrase != ""
+	return c
+}
+
+// check applies the rules every WiFi security write follows, whichever tool
+// makes it: an OPEN network takes no passphrase, a personal network that was
+// open needs one, WPA3_PERSONAL needs PMF REQUIRED, and a secured network is
+// only made OPEN with allowOpen (UNIFI_ALLOW_DESTRUCTIVE=true).
+func (c wifiSecurityChange) check(allowOpen bool) error {
+	wasOpen := c.fromType == "" || c.fromType == "OPEN"
+	switch {
+	case c.toType == "OPEN" && c.newPassphrase:
+		return errors.New("an OPEN network takes no passphrase")
+	case c.toType == "OPEN" && !wasOpen && !allowOpen:
+		return fmt.Errorf("making a %s network OPEN removes its encryption and requires UNIFI_ALLOW_DESTRUCTIVE=true", c.fromType)
+	case strings.HasSuffix(c.toType, "_PERSONAL") && wasOpen && !c.newPassphrase:
+		return fmt.Errorf("a passphrase is required to make an open network %s", c.toType)
+	case c.toType == "WPA3_PERSONAL" && (c.toType != c.fromType || c.toPMF != c.fromPMF) && c.toPMF != "" && c.toPMF != "REQUIRED":
+		return errors.New("WPA3_PERSONAL requires PMF mode REQUIRED")
+	}
+	return nil
+}
+
+// validatePassphrase checks a WPA personal passphrase: 8-63 printable ASCII
+// characters, or exactly 64 hex digits (a raw PSK). The error never contains
+// the passphrase.
+func validatePassphrase(p string) error {
+	if len(p) == 64 && isHex(p) {
+		return nil
+	}
+	if len(p) < 8 || len(p) > 63 {
+		return fmt.Errorf("passphrase must be 8-63 characters (got %d)", len(p))
+	}
+	for _, r := range p {
+		if r < 0x20 || r > 0x7e {
+			return errors.New("passphrase must contain only printable ASCII characters")
+		}
+	}
+	return nil
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// scrubSecret removes secret from err's message, in case the controller echoed
+// the request body back in an error response.
+func scrubSecret(err error, secret string) error {
+	if err == nil || secret == "" || !strings.Contains(err.Error(), secret) {
+		return err
+	}
+	return errors.New(strings.ReplaceAll(err.Error(), secret, unifi.SensitiveString(secret).String()))
+}
+
+// wifiNetwork returns the VLAN binding for a network_id input: nil when unset,
+// the native (management) network for "", otherwise that network.
+func wifiNetwork(networkID *string) *unifi.WiFiBroadcastNetwork {
+	switch {
+	case networkID == nil:
+		return nil
+	case strings.TrimSpace(*networkID) == "":
+		return &unifi.WiFiBroadcastNetwork{Type: "NATIVE"}
+	default:
+		return &unifi.WiFiBroadcastNetwork{Type: "SPECIFIC", NetworkID: strings.TrimSpace(*networkID)}
+	}
+}
+
+// registerWiFiTools registers the WiFi broadcast write tools. Passphrases are
+// write-only in create and update; rotate_wifi_passphrase returns the one it
+// generates, since handing it out is the point, but never logs it and scrubs
+// it from every error.
+func registerWiFiTools(s *mcp.Server, client unifiClient, allowDestructive bool) {
+	destructiveTrue := true
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "create_wifi_broadcast",
+		Description: "Create a WiFi broadcast (SSID). Personal security types need a passphrase, which is write-only and never returned. " +
+			"Set network_id to bind the SSID to a VLAN; omit it for the native network. Set confirmed=true to proceed.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID          string  `json:"site_id,omitempty"          jsonschema:"site ID; omit to use default"`
+		Name            string  `json:"name"                       jsonschema:"SSID name"`
+		Enabled         *bool   `json:"enabled,omitempty"          jsonschema:"broadcast immediately; default true"`
+		HideName        *bool   `json:"hide_name,omitempty"        jsonschema:"hide the SSID from scans"`
+		ClientIsolation *bool   `json:"client_isolation,omitempty" jsonschema:"stop clients on this SSID reaching each other"`
+		NetworkID       *string `json:"network_id,omitempty"       jsonschema:"network (VLAN) ID to bind to; omit or empty for the native network"`
+		wifiSecurityInput
+		Confirmed bool `json:"confirmed" jsonschema:"must be true to confirm the change"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		if !input.Confirmed {
+			return errorResult(fmt.Errorf("create_wifi_broadcast: set confirmed=true to confirm the change"))
+		}
+		if input.Name == "" {
+			return errorResult(fmt.Errorf("create_wifi_broadcast: name is required"))
+		}
+		if input.SecurityType == "" {
+			return errorResult(fmt.Errorf("create_wifi_broadcast: security_type is required (%s)", strings.Join(wifiSecurityTypes, ", ")))
+		}
+		sec, err := input.request()
+		if err != nil {
+			return errorResult(fmt.Errorf("create_wifi_broadcast: %w", err))
+		}
+		if sec.Type != "OPEN" && sec.Passphrase == "" {
+			return errorResult(fmt.Errorf("create_wifi_broadcast: passphrase is required for %s", sec.Type))
+		}
+		if err := newWiFiSecurityChange(nil, sec).check(allowDestructive); err != nil {
+			return errorResult(fmt.Errorf("create_wifi_broadcast: %w", err))
+		}
+		network := wifiNetwork(input.NetworkID)
+		if network == nil {
+			network = &unifi.WiFiBroadcastNetwork{Type: "NATIVE"}
+		}
+		enabled := input.Enabled == nil || *input.Enabled
+		bc, err := client.CreateWiFiBroadcast(ctx, input.SiteID, unifi.WiFiBroadcastRequest{
+			Type:                   "STANDARD",
+			Name:                   input.Name,
+			Enabled:                enabled,
+			HideName:               input.HideName,
+			ClientIsolationEnabled: input.ClientIsolation,
+			Network:                network,
+			SecurityConfiguration:  sec,
+		})
+		if err != nil {
+			return errorResult(fmt.Errorf("create_wifi_broadcast: %w", scrubSecret(err, input.Passphrase)))
+		}
+		return jsonResult(bc)
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "update_wifi_broadcast",
+		Description: "Change settings of a WiFi broadcast (SSID): name, security type, passphrase (write-only), PMF mode, fast roaming, " +
+			"hidden SSID, client isolation, or VLAN binding. Omitted fields, and settings this server does not model, are kept. " +
+			"Security changes are checked against the SSID's current security; making a secured SSID OPEN requires " +
+			"UNIFI_ALLOW_DESTRUCTIVE=true. Set confirmed=true to proceed.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID          string  `json:"site_id,omitempty"          jsonschema:"site ID; omit to use default"`
+		BroadcastID     string  `json:"broadcast_id"               jsonschema:"WiFi broadcast ID"`
+		Name            *string `json:"name,omitempty"             jsonschema:"new SSID name"`
+		HideName        *bool   `json:"hide_name,omitempty"        jsonschema:"hide the SSID from scans"`
+		ClientIsolation *bool   `json:"client_isolation,omitempty" jsonschema:"stop clients on this SSID reaching each other"`
+		NetworkID       *string `json:"network_id,omitempty"       jsonschema:"network (VLAN) ID to bind to; empty string for the native network"`
+		wifiSecurityInput
+		Confirmed bool `json:"confirmed" jsonschema:"must be true to confirm the change"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		if !input.Confirmed {
+			return errorResult(fmt.Errorf("update_wifi_broadcast: set confirmed=true to confirm the change"))
+		}
+		if input.BroadcastID == "" {
+			return errorResult(fmt.Errorf("update_wifi_broadcast: broadcast_id is required"))
+		}
+		if input.Name != nil && *input.Name == "" {
+			return errorResult(fmt.Errorf("update_wifi_broadcast: name must not be empty"))
+		}
+		sec, err := input.request()
+		if err != nil {
+			return errorResult(fmt.Errorf("update_wifi_broadcast: %w", err))
+		}
+		if sec != nil {
+			cur, err := client.GetWiFiBroadcast(ctx, input.SiteID, input.BroadcastID)
+			if err != nil {
+				return errorResult(fmt.Errorf("update_wifi_broadcast: %w", err))
+			}
+			if err := newWiFiSecurityChange(cur.SecurityConfiguration, sec).check(allowDestructive); err != nil {
+				return errorResult(fmt.Errorf("update_wifi_broadcast: %w", err))
+			}
+		}
+		update := unifi.WiFiBroadcastUpdate{
+			Name:                   input.Name,
+			HideName:               input.HideName,
+			ClientIsolationEnabled: input.ClientIsolation,
+			Network:                wifiNetwork(input.NetworkID),
+			Security:               sec,
+		}
+		if update == (unifi.WiFiBroadcastUpdate{}) {
+			return errorResult(fmt.Errorf("update_wifi_broadcast: no changes given"))
+		}
+		bc, err := client.UpdateWiFiBroadcast(ctx, input.SiteID, input.BroadcastID, update)
+		if err != nil {
+			return errorResult(fmt.Errorf("update_wifi_broadcast: %w", scrubSecret(err, input.Passphrase)))
+		}
+		return jsonResult(bc)
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "rotate_wifi_passphrase",
+		Description: "Generate a new strong passphrase for a WPA personal WiFi broadcast (SSID) and apply it. Returns the new credentials, " +
+			"a WIFI: join URI, and a PNG QR code image that phones can scan to join. Style \"chars\" (default) uses random letters and digits " +
+			"without look-alikes; \"words\" joins random words from the built-in or a supplied wordlist, using enough words for at least 69 bits of entropy. " +
+			"Clients using the old passphrase are disconnected. Set confirmed=true to proceed.",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructiveTrue},
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, input struct {
+		SiteID      string `json:"site_id,omitempty"   jsonschema:"site ID; omit to use default"`
+		BroadcastID string `json:"broadcast_id"        jsonschema:"WiFi broadcast ID"`
+		Style       string `json:"style,omitempty"     jsonschema:"chars (default) or words"`
+		Length      int    `json:"length,omitempty"    jsonschema:"characters (12-63, default 20) or words (at most 12; default and minimum reach 69 bits, e.g. 9 from the built-in list)"`
+		Wordlist    string `json:"wordlist,omitempty"  jsonschema:"words style: comma-separated custom wordlist of at least 64 distinct words"`
+		Separator   string `json:"separator,omitempty" jsonschema:"words style: printable ASCII placed between words; default -"`
+		QRSize      int    `json:"qr_size,omitempty"   jsonschema:"QR code PNG edge length in pixels (128-2048, default 512)"`
+		Confirmed   bool   `json:"confirmed"           jsonschema:"must be true to confirm the change"`
+	},
+	) (*mcp.CallToolResult, any, error) {
+		if !input.Confirmed {
+			return errorResult(fmt.Errorf("rotate_wifi_passphrase: set confirmed=true to confirm the change"))
+		}
+		if input.BroadcastID == "" {
+			return errorResult(fmt.Errorf("rotate_wifi_passphrase: broadcast_id is required"))
+		}
+		if input.QRSize != 0 && (input.QRSize < 128 || input.QRSize > 2048) {
+			return errorResult(fmt.Errorf("rotate_wifi_passphrase: qr_size must be 128-2048"))
+		}
+		var wordlist []string
+		if input.Wordlist != "" {
+			wordlist = splitIDs(&input.Wordlist)
+		}
+		gen, err := passphrase.Generate(passphrase.Options{
+			Style:     passphrase.Style(strings.ToLower(input.Style)),
+			Length:    input.Length,
+			Wordlist:  wordlist,
+			Separator: input.Separator,
+		})
+		if err != nil {
+			return errorResult(fmt.Errorf("rotate_wifi_passphrase: %w", err))
+		}
+		secret := unifi.SensitiveString(gen.Passphrase)
+
+		bc, err := client.GetWiFiBroadcast(ctx, input.SiteID, input.BroadcastID)
+		if err != nil {
+			return errorResult(fmt.Errorf("rotate_wifi_passphrase: %w", err))
+		}
+		if bc.SecurityConfiguration == nil || !strings.HasSuffix(bc.SecurityConfiguration.Type, "_PERSONAL") {
+			secType := "unknown"
+			if bc.SecurityConfiguration != nil {
+				secType = bc.SecurityConfiguration.Type
+			}
+			return errorResult(fmt.Errorf("rotate_wifi_passphrase: %s uses %s security; only WPA personal networks have a passphrase", bc.Name, secType))
+		}
+
+		updated, err := client.UpdateWiFiBroadcast(ctx, input.SiteID, input.BroadcastID, unifi.WiFiBroadcastUpdate{
+			Security: &unifi.WiFiSecurityRequest{Passphrase: secret},
+		})
+		if err != nil {
+			return errorResult(fmt.Errorf("rotate_wifi_passphrase: %w", scrubSecret(err, gen.Passphrase)))
+		}
+
+		hidden := updated.HideName != nil && *updated.HideName
+		uri := wifiqr.URI(wifiqr.Network{
+			SSID:         updated.Name,
+			SecurityType: bc.SecurityConfiguration.Type,
+			Passphrase:   string(secret),
+			Hidden:       hidden,
+		})
+		png, err := wifiqr.PNG(uri, input.QRSize)
+		if err != nil {
+			return errorResult(fmt.Errorf("rotate_wifi_passphrase: passphrase changed but QR code failed: %w", scrubSecret(err, gen.Passphrase)))
+		}
+		b, err := json.Marshal(struct {
+			BroadcastID  string  `json:"broadcastId"`
+			SSID         string  `json:"ssid"`
+			SecurityType string  `json:"securityType"`
+			Hidden       bool    `json:"hidden"`
+			Passphrase   string  `json:"passphrase"`
+			EntropyBits  float64 `json:"entropyBits"`
+			WiFiURI      string  `json:"wifiUri"`
+		}{updated.ID, updated.Name, bc.SecurityConfiguration.Type, hidden, string(secret), math.Round(gen.EntropyBits), uri})
+		if err != nil {
+			return errorResult(fmt.Errorf("rotate_wifi_passphrase: marshal result: %w", err))
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: string(b)},
+				&mcp.ImageContent{Data: png, MIMEType: "image/png"},
+			},
+		}, nil, nil
+	})
+}
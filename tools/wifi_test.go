@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gordcurrie/unifi-mcp/internal/unifi"
+)
+
+func TestWiFiSecurityChange(t *testing.T) {
+	wpa2 := &unifi.WiFiSecurityConfiguration{Type: "WPA2_PERSONAL", PMFMode: "OPTIONAL"}
+	wpa3 := &unifi.WiFiSecurityConfiguration{Type: "WPA3_PERSONAL", PMFMode: "REQUIRED"}
+	open := &unifi.WiFiSecurityConfiguration{Type: "OPEN"}
+	tests := []struct {
+		name      string
+		cur       *unifi.WiFiSecurityConfiguration
+		sec       unifi.WiFiSecurityRequest
+		allowOpen bool
+		wantErr   string
+	}{
+		{name: "new passphrase", cur: wpa2, sec: unifi.WiFiSecurityRequest{Passphrase: "correct horse"}},
+		{name: "WPA2 to WPA3 keeps passphrase", cur: wpa2, sec: unifi.WiFiSecurityRequest{Type: "WPA3_PERSONAL", PMFMode: "REQUIRED"}},
+		{name: "WPA3 PMF disabled", cur: wpa3, sec: unifi.WiFiSecurityRequest{PMFMode: "DISABLED"}, wantErr: "requires PMF mode REQUIRED"},
+		{name: "WPA2 to WPA3 with optional PMF", cur: wpa2, sec: unifi.WiFiSecurityRequest{Type: "WPA3_PERSONAL"}, wantErr: "requires PMF mode REQUIRED"},
+		{name: "passphrase on open network", cur: open, sec: unifi.WiFiSecurityRequest{Passphrase: "correct horse"}, wantErr: "takes no passphrase"},
+		{name: "open to WPA2 without passphrase", cur: open, sec: unifi.WiFiSecurityRequest{Type: "WPA2_PERSONAL"}, wantErr: "passphrase is required"},
+		{name: "open to WPA2", cur: open, sec: unifi.WiFiSecurityRequest{Type: "WPA2_PERSONAL", Passphrase: "correct horse"}},
+		{name: "downgrade to open", cur: wpa2, sec: unifi.WiFiSecurityRequest{Type: "OPEN"}, wantErr: "requires UNIFI_ALLOW_DESTRUCTIVE=true"},
+		{name: "downgrade to open allowed", cur: wpa2, sec: unifi.WiFiSecurityRequest{Type: "OPEN"}, allowOpen: true},
+		{name: "create open", sec: unifi.WiFiSecurityRequest{Type: "OPEN"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := newWiFiSecurityChange(tc.cur, &tc.sec).check(tc.allowOpen)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("got %v, want no error", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Fatalf("got %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}